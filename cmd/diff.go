@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pablor21/goscanner/scanner"
+)
+
+// exitDiffers is the exit status of the diff command and of the -diff flag when the
+// compared results differ, apart from the failures of the scan (1, 2 and 3)
+const exitDiffers = 4
+
+// runDiff implements "goscanner diff old.json new.json": it compares two results written
+// with -format json and reports the added, removed and changed declarations, failing when
+// they differ. With -compat it reports the changes as breaking or compatible with the
+// version bump they call for, failing on breaking changes only. It returns the exit code:
+// exitDiffers when they differ, 1 when a result can't be read and 2 on usage errors.
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	unexported := fs.Bool("unexported", false, "Compare the unexported declarations and members too")
+	external := fs.Bool("external", false, "Compare the declarations of the dependencies too")
+	asJSON := fs.Bool("json", false, "Write the report as JSON")
+	compat := fs.Bool("compat", false, "Classify the changes as breaking or compatible and suggest the version bump")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: goscanner diff [flags] old.json new.json")
+		fmt.Fprintf(fs.Output(), "exits with status %d when the results differ (breaking changes with -compat), 1 on errors\n", exitDiffers)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	results := make([]map[string]any, 2)
+	for i, path := range fs.Args() {
+		tree, err := scanner.ReadSerializedResult(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+			return 1
+		}
+		results[i] = tree
+	}
+
+	diff := scanner.DiffResults(results[0], results[1], scanner.DiffOptions{IncludeUnexported: *unexported, IncludeExternal: *external})
+	if *compat {
+		report := diff.Compatibility()
+		if err := writeCompatibility(os.Stdout, report, *asJSON); err != nil {
+			fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+			return 1
+		}
+		if report.IsBreaking() {
+			return exitDiffers
+		}
+		return 0
+	}
+	if err := writeDiff(os.Stdout, diff, *asJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		return 1
+	}
+	if diff.IsEmpty() {
+		return 0
+	}
+	return exitDiffers
+}
+
+// writeDiff writes the report of diff to w, as JSON or one line per change
+func writeDiff(w io.Writer, diff *scanner.ResultDiff, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diff)
+	}
+	if diff.IsEmpty() {
+		_, err := fmt.Fprintln(w, "no differences")
+		return err
+	}
+	for _, id := range diff.Added {
+		fmt.Fprintf(w, "added    %s\n", id)
+	}
+	for _, id := range diff.Removed {
+		fmt.Fprintf(w, "removed  %s\n", id)
+	}
+	for _, decl := range diff.Changed {
+		fmt.Fprintf(w, "changed  %s\n", decl.ID)
+		for _, c := range decl.Changes {
+			what := c.Kind
+			if c.Name != "" {
+				what += " " + c.Name
+			}
+			switch c.Change {
+			case scanner.ChangeAdded:
+				fmt.Fprintf(w, "           %s added: %s\n", what, c.New)
+			case scanner.ChangeRemoved:
+				fmt.Fprintf(w, "           %s removed: %s\n", what, c.Old)
+			default:
+				fmt.Fprintf(w, "           %s: %s -> %s\n", what, c.Old, c.New)
+			}
+		}
+	}
+	return nil
+}
+
+// writeCompatibility writes a compatibility report to w, as JSON or one line per change
+func writeCompatibility(w io.Writer, report *scanner.CompatibilityReport, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	if _, err := fmt.Fprintf(w, "bump: %s\n", report.Bump); err != nil {
+		return err
+	}
+	for _, group := range []struct {
+		label   string
+		changes []*scanner.APIChange
+	}{
+		{"breaking", report.Breaking},
+		{"compatible", report.Compatible},
+	} {
+		for _, c := range group.changes {
+			line := c.ID + ": " + c.Reason
+			if c.Change != nil && c.Change.Name != "" {
+				line = c.ID + "." + c.Change.Name + ": " + c.Reason
+			}
+			if c.Change != nil && c.Change.Change == scanner.ChangeChanged {
+				line += " (" + c.Change.Old + " -> " + c.Change.New + ")"
+			}
+			fmt.Fprintf(w, "%-10s %s\n", group.label, line)
+		}
+	}
+	return nil
+}
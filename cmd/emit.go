@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pablor21/goscanner/export/openapi"
+	"github.com/pablor21/goscanner/export/proto"
+	"github.com/pablor21/goscanner/export/typescript"
+	"github.com/pablor21/goscanner/scanner"
+)
+
+// stdoutPath is the -o value writing the output to stdout
+const stdoutPath = "-"
+
+// emitter renders a scan result in one of the -format output formats
+type emitter func(w io.Writer, result *scanner.ScanningResult, opts scanner.EmitOptions) error
+
+// emitters are the output formats by -format name
+var emitters = map[string]emitter{
+	"csv": func(w io.Writer, result *scanner.ScanningResult, _ scanner.EmitOptions) error {
+		return result.EmitCSV(w)
+	},
+	"graphql": func(w io.Writer, result *scanner.ScanningResult, _ scanner.EmitOptions) error {
+		return scanner.EmitGraphQL(result, w, scanner.GraphQLOptions{})
+	},
+	"json": emitJSON,
+	"jsonschema": func(w io.Writer, result *scanner.ScanningResult, _ scanner.EmitOptions) error {
+		return scanner.EmitJSONSchema(result, w, scanner.JSONSchemaOptions{})
+	},
+	"manifest": func(w io.Writer, result *scanner.ScanningResult, _ scanner.EmitOptions) error {
+		return result.EmitManifest(w)
+	},
+	"openapi": func(w io.Writer, result *scanner.ScanningResult, _ scanner.EmitOptions) error {
+		return openapi.Emit(result, w, openapi.Options{})
+	},
+	"proto": func(w io.Writer, result *scanner.ScanningResult, opts scanner.EmitOptions) error {
+		return proto.Emit(result, w, proto.Options{EmitOptions: opts})
+	},
+	"sql": func(w io.Writer, result *scanner.ScanningResult, opts scanner.EmitOptions) error {
+		return scanner.EmitSQL(result, w, scanner.SQLOptions{EmitOptions: opts})
+	},
+	"typescript": func(w io.Writer, result *scanner.ScanningResult, _ scanner.EmitOptions) error {
+		return typescript.Emit(result, w, typescript.Options{})
+	},
+	"yaml": func(w io.Writer, result *scanner.ScanningResult, opts scanner.EmitOptions) error {
+		return result.EncodeWithOptions(w, scanner.FormatYAML, opts)
+	},
+}
+
+// formatAliases are the shorter -format names of some formats
+var formatAliases = map[string]string{
+	"ts": "typescript",
+}
+
+// fieldNameTransforms are the name transforms by -field-names value
+var fieldNameTransforms = map[string]func(goName string, tags map[string]string) string{
+	"snake": scanner.SnakeCase,
+	"camel": scanner.CamelCase,
+	"json":  scanner.TagName("json", nil),
+}
+
+// formatNames returns the sorted names of the output formats
+func formatNames() []string {
+	names := make([]string, 0, len(emitters))
+	for name := range emitters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupEmitter returns the emitter of format (or of the format it's an alias of), or an
+// error listing the available formats
+func lookupEmitter(format string) (emitter, error) {
+	if name, ok := formatAliases[format]; ok {
+		format = name
+	}
+	emit, ok := emitters[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q, available formats: %s", format, strings.Join(formatNames(), ", "))
+	}
+	return emit, nil
+}
+
+// writeOutput emits result to path, or to stdout when path is "-"
+func writeOutput(path string, emit emitter, result *scanner.ScanningResult, opts scanner.EmitOptions) error {
+	if path == stdoutPath {
+		return emit(os.Stdout, result, opts)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := emit(f, result, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// emitJSON writes the serialized result as indented JSON, streaming it entry by entry
+func emitJSON(w io.Writer, result *scanner.ScanningResult, opts scanner.EmitOptions) error {
+	return result.SerializeTo(w, opts)
+}
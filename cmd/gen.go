@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pablor21/goscanner/logger"
+	"github.com/pablor21/goscanner/scanner"
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// runGen implements "goscanner gen": it scans the packages and renders the templates for
+// each of them, or each of their types, writing the files under -out. It returns the exit code.
+func runGen(args []string) int {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	pkgs := fs.String("pkg", "./...", "Comma separated package patterns to scan")
+	templates := fs.String("template", "", "Comma separated template files (text/template)")
+	name := fs.String("name", "", "Template to execute (default the first file)")
+	perType := fs.Bool("per-type", false, "Render a file per type instead of a file per package")
+	out := fs.String("out", ".", "Directory the files are written to")
+	output := fs.String("output", "", "Template of the path of each file ({{dir .Package}}/... writes next to the sources, default {{.Package.Name}}_gen.go, {{snake .Type.Name}}_gen.go per type)")
+	pkgPath := fs.String("package-path", "", "Import path of the generated files (default the scanned package)")
+	annotation := fs.String("annotation", "", "Render only the types with this annotation in their comments (e.g. @gen)")
+	noFormat := fs.Bool("no-format", false, "Do not gofmt the generated .go files")
+	_ = fs.Parse(args)
+	if *templates == "" {
+		fmt.Fprintln(os.Stderr, "gen: -template is required")
+		fs.Usage()
+		return 2
+	}
+
+	cfg := scanner.NewDefaultConfig()
+	cfg.Packages = strings.Split(*pkgs, ",")
+	cfg.LogLevel = "error"
+	logger.SetupLogger(cfg.LogLevel)
+
+	result, err := scanner.NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+		return 1
+	}
+
+	opts := scanner.GenerateOptions{
+		TemplateFiles: strings.Split(*templates, ","),
+		Name:          *name,
+		PerType:       *perType,
+		Output:        *output,
+		PackagePath:   *pkgPath,
+		NoFormat:      *noFormat,
+	}
+	if *annotation != "" {
+		opts.Filter = func(t gstypes.Type) bool { return scanner.HasAnnotation(t, *annotation) }
+	}
+	files, err := scanner.Generate(result, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+		return 1
+	}
+	if err := scanner.WriteGenerated(*out, files); err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+		return 1
+	}
+	for _, f := range files {
+		fmt.Println(f.Path)
+	}
+	return 0
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 
 	"github.com/pablor21/goscanner/logger"
@@ -14,25 +17,74 @@ var pkg string
 var output string
 var cacheOut string
 var useCache bool
+var maxStructureLen int
+var manifestOut string
+var internRefs bool
+var format string
+var optionalPointers bool
+var keyStyle string
+var failOnWarning bool
+var failOnPackageErrors bool
+var incremental bool
+var diffBaseline string
+var lintTags bool
+var fieldNames string
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "gen" {
+		os.Exit(runGen(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "mock" {
+		os.Exit(runMock(os.Args[2:]))
+	}
+
 	// get the package scanning to (flag)
-	flag.StringVar(&pkg, "pkg", "../examples/starwars/basic,../examples/starwars/functions", "Package to scan")
-	flag.StringVar(&output, "out", "output.json", "Output file")
+	flag.StringVar(&pkg, "pkg", "../examples/starwars/basic,../examples/starwars/functions", "Comma separated package patterns to scan (./... for a tree, !pattern to leave one out)")
+	flag.StringVar(&output, "out", "output.json", "Output file (- writes to stdout), -o is an alias")
+	flag.StringVar(&output, "o", "output.json", "Alias for -out")
+	flag.StringVar(&format, "format", "json", "Output format: "+strings.Join(formatNames(), ", ")+" (ts is an alias of typescript)")
 	flag.StringVar(&cacheOut, "cache-out", ".scan.cache", "Output binary cache file (gzip-compressed JSON)")
 	flag.BoolVar(&useCache, "use-cache", false, "Load from cache if available (default: false)")
+	flag.IntVar(&maxStructureLen, "max-structure-len", 0, "Truncate structure strings longer than this (0 means no limit)")
+	flag.BoolVar(&internRefs, "intern-refs", false, "Replace repeated type references with pointers into a shared $defs table")
+	flag.BoolVar(&optionalPointers, "optional-pointers", false, "Render fields of a pointer to a basic type as optional scalars")
+	flag.StringVar(&keyStyle, "key-style", "camel", "Style of the output keys: camel, snake")
+	flag.StringVar(&fieldNames, "field-names", "", "Naming of the emitted fields: snake, camel, json (default the format's own)")
+	flag.BoolVar(&failOnWarning, "fail-on-warning", false, "Exit with status 3 when a type could not be fully resolved")
+	flag.BoolVar(&failOnPackageErrors, "fail-on-package-errors", false, "Exit with status 2 when a scanned package has errors (by default they're warnings)")
+	flag.BoolVar(&incremental, "incremental", false, "Scan again only the packages changed since -cache-out was written, keeping it up to date")
+	flag.StringVar(&manifestOut, "manifest-out", "", "Output file for the type manifest (id, kind, package, file of every type)")
+	flag.StringVar(&diffBaseline, "diff", "", fmt.Sprintf("Compare the exported API with a baseline written with -format json, exit with status %d when it differs (as the diff command)", exitDiffers))
+	flag.BoolVar(&lintTags, "lint", false, "Check the struct tags of the scanned packages, exit with status 5 on errors")
 	flag.Parse()
 
 	cfg := scanner.NewDefaultConfig()
 	cfg.Packages = strings.Split(pkg, ",")
 	cfg.LogLevel = "info"
+	cfg.FailOnPackageErrors = failOnPackageErrors
 
-	// Create a logger for the main function
+	// Create a logger for the main function, logs go to stderr so stdout holds only the output
 	logger.SetupLogger(cfg.LogLevel)
 	log := logger.NewDefaultLogger()
 
+	emit, err := lookupEmitter(format)
+	if err != nil {
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
+	nameTransform, ok := fieldNameTransforms[fieldNames]
+	if !ok && fieldNames != "" {
+		log.Errorf("unknown -field-names %q, available: snake, camel, json", fieldNames)
+		os.Exit(1)
+	}
+
 	var ret *scanner.ScanningResult
-	var err error
 
 	// Try to load from cache if requested
 	if useCache && cacheOut != "" && scanner.IsCacheValid(cacheOut) {
@@ -44,14 +96,43 @@ func main() {
 		log.Warnf("Failed to load cache, falling back to full scan: %v", err)
 	}
 
-	// Perform full scan
-	ret, err = scanner.NewScanner().ScanWithConfig(cfg)
+	// Perform full scan, or an incremental one maintaining its own cache
+	if incremental && cacheOut != "" {
+		var rescanned []string
+		ret, rescanned, err = scanner.ScanIncremental(cacheOut, cfg)
+		if err == nil {
+			log.Infof("Scanned %d changed packages: %v", len(rescanned), rescanned)
+		}
+	} else {
+		// Interrupting cancels the scan, what was resolved until then still goes to the cache
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		ret, err = scanner.NewScanner().ScanWithContext(scanner.NewScanningContext(ctx, cfg))
+		stop()
+	}
+	if err != nil && ret != nil && ret.Partial() {
+		log.Warnf("Scan interrupted: %v", err)
+		if cacheOut != "" {
+			if err := ret.ToCache(cacheOut); err != nil {
+				log.Warnf("Failed to write partial cache file %s: %v", cacheOut, err)
+			} else {
+				log.Infof("Partial cache written to: %s", cacheOut)
+			}
+		}
+		os.Exit(130)
+	}
 	if err != nil {
-		panic(err)
+		var loadErr *scanner.PackageLoadError
+		if errors.As(err, &loadErr) {
+			// The scanned code doesn't build, not a scanner failure
+			log.Errorf("%v", loadErr)
+			os.Exit(2)
+		}
+		log.Errorf("Scan failed: %v", err)
+		os.Exit(1)
 	}
 
-	// Ensure all types are fully loaded before caching
-	if cacheOut != "" {
+	// Ensure all types are fully loaded before caching, or checking for warnings
+	if cacheOut != "" || failOnWarning {
 		if err := ret.EnsureFullyLoaded(); err != nil {
 			log.Warnf("Failed to fully load types before caching: %v", err)
 		}
@@ -59,7 +140,7 @@ func main() {
 
 writeOutput:
 	// Save cache if specified
-	if cacheOut != "" {
+	if cacheOut != "" && !incremental {
 		if err := ret.ToCache(cacheOut); err != nil {
 			log.Warnf("Failed to write cache file %s: %v", cacheOut, err)
 		} else {
@@ -67,18 +148,69 @@ writeOutput:
 		}
 	}
 
-	// Save JSON output if specified
+	// Save the output in the requested format if specified
 	if output != "" {
-		serializedret := ret.Serialize()
+		opts := scanner.EmitOptions{MaxStructureLen: maxStructureLen, InternRefs: internRefs, TreatPointerScalarAsOptional: optionalPointers, KeyStyle: scanner.KeyStyle(keyStyle), NameTransform: nameTransform}
+		if err := writeOutput(output, emit, ret, opts); err != nil {
+			log.Errorf("Failed to write %s output: %v", format, err)
+			os.Exit(1)
+		}
+		if output != stdoutPath {
+			log.Infof("%s output written to: %s", format, output)
+		}
+	}
+
+	// Save the manifest from the same scan if specified
+	if manifestOut != "" {
+		if err := writeOutput(manifestOut, emitters["manifest"], ret, scanner.EmitOptions{}); err != nil {
+			log.Errorf("Failed to write the manifest: %v", err)
+			os.Exit(1)
+		}
+		log.Infof("Manifest written to: %s", manifestOut)
+	}
 
-		// convert the ret to a json
-		b, err := json.MarshalIndent(serializedret, "", "\t")
+	// Compare with the baseline once the outputs are written
+	if diffBaseline != "" {
+		baseline, err := scanner.ReadSerializedResult(diffBaseline)
+		if err != nil {
+			log.Errorf("Failed to read the diff baseline: %v", err)
+			os.Exit(1)
+		}
+		current, err := ret.SerializeWithOptions(scanner.EmitOptions{})
 		if err != nil {
-			panic(err)
+			log.Errorf("Failed to serialize the result: %v", err)
+			os.Exit(1)
+		}
+		tree, _ := current.(map[string]any)
+		diff := scanner.DiffResults(baseline, tree, scanner.DiffOptions{})
+		if err := writeDiff(os.Stderr, diff, false); err != nil {
+			log.Errorf("Failed to write the diff: %v", err)
 		}
+		if !diff.IsEmpty() {
+			os.Exit(exitDiffers)
+		}
+	}
 
-		// save the output to a file
-		_ = os.WriteFile(output, b, 0644)
-		log.Infof("JSON output written to: %s", output)
+	// Lint the tags here rather than with Config.LintTags, results read from a cache too
+	if lintTags {
+		errs := 0
+		for _, d := range ret.LintTags() {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", d.Severity, d)
+			if d.Severity == scanner.SeverityError {
+				errs++
+			}
+		}
+		if errs > 0 {
+			log.Errorf("%d struct tag errors", errs)
+			os.Exit(5)
+		}
+	}
+
+	if warnings := ret.Warnings(); failOnWarning && len(warnings) > 0 {
+		log.Errorf("%d resolution warnings, the output is incomplete:", len(warnings))
+		for _, w := range warnings {
+			log.Errorf("  %s", w)
+		}
+		os.Exit(3)
 	}
 }
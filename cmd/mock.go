@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pablor21/goscanner/logger"
+	"github.com/pablor21/goscanner/scanner"
+)
+
+// runMock implements "goscanner mock": it scans the packages and writes mocks of their
+// interfaces annotated with @mock, or of all of them with -all. It returns the exit code.
+func runMock(args []string) int {
+	fs := flag.NewFlagSet("mock", flag.ExitOnError)
+	pkgs := fs.String("pkg", "./...", "Comma separated package patterns to scan")
+	style := fs.String("style", scanner.MockStyleTestify, "Mock style: "+scanner.MockStyleTestify+", "+scanner.MockStyleGomock)
+	all := fs.Bool("all", false, "Mock every interface, not only the annotated ones")
+	annotation := fs.String("annotation", "@mock", "Annotation selecting the interfaces to mock")
+	out := fs.String("out", ".", "Directory the files are written to")
+	output := fs.String("output", "", "Template of the path of each file ({{dir .Package}}/... writes next to the sources, default {{.Package.Name}}_mock_test.go)")
+	pkgPath := fs.String("package-path", "", "Import path of the mocks (default the package of the interfaces)")
+	pkgName := fs.String("package-name", "", "Package name of the mocks (default the last element of -package-path)")
+	_ = fs.Parse(args)
+
+	cfg := scanner.NewDefaultConfig()
+	cfg.Packages = strings.Split(*pkgs, ",")
+	cfg.LogLevel = "error"
+	logger.SetupLogger(cfg.LogLevel)
+
+	result, err := scanner.NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mock: %v\n", err)
+		return 1
+	}
+
+	files, err := scanner.GenerateMocks(result, scanner.MockOptions{
+		Style:       *style,
+		Annotation:  *annotation,
+		All:         *all,
+		Output:      *output,
+		PackagePath: *pkgPath,
+		PackageName: *pkgName,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mock: %v\n", err)
+		return 1
+	}
+	if err := scanner.WriteGenerated(*out, files); err != nil {
+		fmt.Fprintf(os.Stderr, "mock: %v\n", err)
+		return 1
+	}
+	for _, f := range files {
+		fmt.Println(f.Path)
+	}
+	return 0
+}
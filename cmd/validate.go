@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pablor21/goscanner/logger"
+	"github.com/pablor21/goscanner/scanner"
+)
+
+// runValidate implements "goscanner validate": it rescans the packages and compares the
+// result with a cache, failing when the cache is stale. It returns the exit code.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	cachePath := fs.String("cache", ".scan.cache", "Cache file to check (written with -cache-out)")
+	pkgs := fs.String("pkg", "../examples/starwars/basic,../examples/starwars/functions", "Package to scan")
+	_ = fs.Parse(args)
+
+	cfg := scanner.NewDefaultConfig()
+	cfg.Packages = strings.Split(*pkgs, ",")
+	cfg.LogLevel = "error"
+	logger.SetupLogger(cfg.LogLevel)
+
+	if !scanner.IsCacheValid(*cachePath) {
+		fmt.Fprintf(os.Stderr, "cache %s is missing or invalid\n", *cachePath)
+		return 1
+	}
+
+	_, changes, err := scanner.ScanChangedSince(*cachePath, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "validate: %v\n", err)
+		return 1
+	}
+	if changes.IsEmpty() {
+		fmt.Printf("cache %s is up to date\n", *cachePath)
+		return 0
+	}
+
+	fmt.Printf("cache %s is stale, your generated types are out of date:\n", *cachePath)
+	for _, group := range []struct {
+		label string
+		ids   []string
+	}{
+		{"added", changes.Added},
+		{"removed", changes.Removed},
+		{"modified", changes.Modified},
+	} {
+		for _, id := range group.ids {
+			fmt.Printf("  %-8s %s\n", group.label, id)
+		}
+	}
+	return 1
+}
@@ -0,0 +1,59 @@
+// Package openapi generates an OpenAPI 3.1 document from the structs and enums of a scan
+package openapi
+
+import (
+	"io"
+	"sort"
+
+	"github.com/pablor21/goscanner/scanner"
+)
+
+// Options controls the document generated by Emit
+type Options struct {
+	// Title is the info.title of the document (default the name of the first scanned package)
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+	// Version is the info.version of the document (default "0.0.0")
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Format is the encoding of the document, scanner.FormatJSON (default) or scanner.FormatYAML
+	Format scanner.Format `json:"format,omitempty" yaml:"format,omitempty"`
+	// TagKey is the tag holding the property names and the omitempty option (default "json")
+	TagKey string `json:"tag_key,omitempty" yaml:"tag_key,omitempty"`
+}
+
+// refPrefix is the prefix of the references to the component schemas
+const refPrefix = "#/components/schemas/"
+
+// Emit writes an OpenAPI 3.1 document with a component schema for every struct and enum of
+// the scanned packages and every named type or generic instance they reference, built by
+// ScanningResult.Schemas. Only components are generated, the document has no paths.
+func Emit(result *scanner.ScanningResult, w io.Writer, opts Options) error {
+	schemas, err := result.Schemas(refPrefix, opts.TagKey)
+	if err != nil {
+		return err
+	}
+	if opts.Title == "" {
+		opts.Title = packageName(result)
+	}
+	if opts.Version == "" {
+		opts.Version = "0.0.0"
+	}
+	doc := map[string]any{
+		"openapi":    "3.1.0",
+		"info":       map[string]any{"title": opts.Title, "version": opts.Version},
+		"components": map[string]any{"schemas": schemas},
+	}
+	return scanner.EncodeTree(w, doc, opts.Format)
+}
+
+// packageName returns the name of the package of the first named type scanned, in id order
+func packageName(result *scanner.ScanningResult) string {
+	ids := result.Types.Keys()
+	sort.Strings(ids)
+	for _, id := range ids {
+		t, _ := result.Types.Get(id)
+		if t != nil && t.Distance() == 0 && t.IsNamed() && t.Package() != nil {
+			return t.Package().Name()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pablor21/goscanner/scanner"
+)
+
+// scanSource scans src as the only file of a module
+func scanSource(t *testing.T, src string) *scanner.ScanningResult {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{"go.mod": "module example.com/test\n\ngo 1.22\n", "test.go": src} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := scanner.NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./..."}
+	cfg.ModuleDirs = []string{dir}
+	result, err := scanner.NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+	return result
+}
+
+func TestEmit(t *testing.T) {
+	src := `
+	package test
+
+	// Status of a user
+	type Status string
+
+	const (
+		Active  Status = "active"
+		Blocked Status = "blocked"
+	)
+
+	type Base struct {
+		ID int64 ` + "`json:\"id\"`" + `
+	}
+
+	type Page[T any] struct {
+		Items []T     ` + "`json:\"items\"`" + `
+		Next  *string ` + "`json:\"next,omitempty\"`" + `
+	}
+
+	type User struct {
+		Base
+		// Name of the user
+		Name     string            ` + "`json:\"name\"`" + `
+		Status   *Status           ` + "`json:\"status\"`" + `
+		Scores   map[string]float64 ` + "`json:\"scores,omitempty\"`" + `
+		Friends  Page[User]
+		Avatar   []byte
+		Secret   string ` + "`json:\"-\"`" + `
+		Callback func()
+		internal int
+	}
+	`
+
+	result := scanSource(t, src)
+	var buf bytes.Buffer
+	if err := Emit(result, &buf, Options{Version: "1.0.0"}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Emit() wrote invalid JSON: %v", err)
+	}
+	if doc["openapi"] != "3.1.0" || !reflect.DeepEqual(doc["info"], map[string]any{"title": "test", "version": "1.0.0"}) {
+		t.Errorf("unexpected header: openapi %v, info %v", doc["openapi"], doc["info"])
+	}
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+
+	var want map[string]any
+	if err := json.Unmarshal([]byte(`{
+		"Base": {
+			"type": "object",
+			"properties": {"id": {"type": "integer", "format": "int64"}},
+			"required": ["id"]
+		},
+		"Status": {"type": "string", "enum": ["active", "blocked"], "description": "Status of a user"},
+		"Page_User": {
+			"type": "object",
+			"properties": {
+				"items": {"type": "array", "items": {"$ref": "#/components/schemas/User"}},
+				"next": {"type": ["string", "null"]}
+			},
+			"required": ["items"]
+		},
+		"User": {
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer", "format": "int64"},
+				"name": {"type": "string", "description": "Name of the user"},
+				"status": {"anyOf": [{"$ref": "#/components/schemas/Status"}, {"type": "null"}]},
+				"scores": {"type": "object", "additionalProperties": {"type": "number", "format": "double"}},
+				"Friends": {"$ref": "#/components/schemas/Page_User"},
+				"Avatar": {"type": "string", "contentEncoding": "base64"}
+			},
+			"required": ["Avatar", "Friends", "id", "name", "status"]
+		}
+	}`), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(schemas, want) {
+		got, _ := json.MarshalIndent(schemas, "", "  ")
+		t.Errorf("schemas =\n%s", got)
+	}
+
+	// The same document in YAML
+	buf.Reset()
+	if err := Emit(result, &buf, Options{Format: scanner.FormatYAML}); err != nil {
+		t.Fatalf("Emit(yaml) error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "components:\n  schemas:\n    Base:\n") || !strings.Contains(buf.String(), "openapi: \"3.1.0\"\n") {
+		t.Errorf("unexpected YAML document:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,526 @@
+// Package proto generates a proto3 schema from the structs, enums and interfaces of a scan
+package proto
+
+import (
+	"bufio"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pablor21/goscanner/scanner"
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// Options controls the proto file generated by Emit
+type Options struct {
+	// EmitOptions names the message fields with its NameTransform (default scanner.SnakeCase)
+	scanner.EmitOptions
+	// Package is the proto package (default the name of the first scanned package)
+	Package string `json:"package,omitempty" yaml:"package,omitempty"`
+	// GoPackage is the go_package option, omitted when empty
+	GoPackage string `json:"go_package,omitempty" yaml:"go_package,omitempty"`
+}
+
+// protoScalars maps the predeclared types to proto scalar types
+var protoScalars = map[string]string{
+	"bool":    "bool",
+	"int8":    "int32",
+	"int16":   "int32",
+	"int32":   "int32",
+	"rune":    "int32",
+	"int":     "int64",
+	"int64":   "int64",
+	"uint8":   "uint32",
+	"byte":    "uint32",
+	"uint16":  "uint32",
+	"uint32":  "uint32",
+	"uint":    "uint64",
+	"uint64":  "uint64",
+	"uintptr": "uint64",
+	"float32": "float",
+	"float64": "double",
+	"string":  "string",
+}
+
+// protoWellKnown maps well known named types to the proto well known types, with their import
+var protoWellKnown = map[string][2]string{
+	"time.Time":     {"google.protobuf.Timestamp", "google/protobuf/timestamp.proto"},
+	"time.Duration": {"google.protobuf.Duration", "google/protobuf/duration.proto"},
+}
+
+// protoField is a field of a generated message
+type protoField struct {
+	label  string // "", "optional" or "repeated"
+	typ    string
+	name   string
+	number int
+}
+
+// protoMessage is a message generated from a struct, or for the parameters or results of a
+// service method
+type protoMessage struct {
+	name     string
+	comments []string
+	fields   []protoField
+}
+
+// protoEnumValue is a value of a generated enum
+type protoEnumValue struct {
+	name   string
+	number int64
+}
+
+// protoEnum is an enum generated from a named integer type with constants
+type protoEnum struct {
+	name     string
+	comments []string
+	values   []protoEnumValue
+	alias    bool
+}
+
+// protoRPC is a method of a generated service
+type protoRPC struct {
+	name     string
+	request  string
+	response string
+}
+
+// protoService is a service generated from an interface
+type protoService struct {
+	name     string
+	comments []string
+	rpcs     []protoRPC
+}
+
+// protoFile is the generated proto file
+type protoFile struct {
+	opts     Options
+	names    map[string]string   // proto names of the exported types, by id
+	used     scanner.UniqueNames // proto names taken
+	imports  map[string]bool
+	enums    []*protoEnum
+	messages []*protoMessage
+	services []*protoService
+}
+
+// Emit writes a proto3 file with the structs, enums and interfaces of the scanned
+// packages, in id order. Structs become messages with a field per exported field that maps
+// to a proto type: the basic types (named ones by their underlying type), []byte, time.Time,
+// time.Duration, the exported structs and enums, slices of them (repeated) and maps with
+// scalar keys. Promoted fields are flattened into the message. Fields are numbered in order,
+// or by the number of their protobuf tag; fields tagged `protobuf:"-"` are skipped.
+//
+// Named integer types with constants become enums, with an UNSPECIFIED zero value added when
+// no constant is zero. Interfaces become services: a method with a single struct parameter
+// (after a leading context.Context) and a single struct result (before a trailing error)
+// uses them as request and response, the others get <Method>Request and <Method>Response
+// messages holding their parameters and results. Constraint-only interfaces
+// (Config.MarkConstraintOnly) are left out.
+func Emit(result *scanner.ScanningResult, w io.Writer, opts Options) error {
+	opts.EmitOptions = opts.EmitOptions.WithDefaultNames(scanner.SnakeCase)
+	file := &protoFile{opts: opts, names: map[string]string{}, used: scanner.UniqueNames{}, imports: map[string]bool{}}
+	constants := result.ConstantsByType()
+
+	ids := result.Types.Keys()
+	sort.Strings(ids)
+	var structs []*gstypes.Struct
+	var ifaces []*gstypes.Interface
+	for _, id := range ids {
+		t, _ := result.Types.Get(id)
+		if t == nil || t.Distance() != 0 || !t.IsNamed() {
+			continue
+		}
+		if err := t.Load(); err != nil {
+			return err
+		}
+		if file.opts.Package == "" && t.Package() != nil {
+			file.opts.Package = t.Package().Name()
+		}
+		switch typed := t.(type) {
+		case *gstypes.Struct:
+			if len(typed.TypeParams()) == 0 {
+				file.names[id] = file.name(t)
+				structs = append(structs, typed)
+			}
+		case *gstypes.Interface:
+			if len(typed.TypeParams()) == 0 && !typed.ConstraintOnly() && len(typed.Methods()) > 0 {
+				ifaces = append(ifaces, typed)
+			}
+		case *gstypes.Enum:
+			if enum, ok := file.enum(t, typed.Values()); ok {
+				file.names[id] = enum.name
+			}
+		case *gstypes.Basic:
+			if enum, ok := file.enum(t, constants[id]); ok {
+				file.names[id] = enum.name
+			}
+		}
+	}
+
+	for _, s := range structs {
+		msg := &protoMessage{name: file.names[s.Id()], comments: scanner.DocLines(s)}
+		for _, f := range s.Fields() {
+			if f.IsEmbedded() || f.Name() == "_" || !token.IsExported(f.Name()) {
+				continue
+			}
+			tags := scanner.ParseTags(f.Tag())
+			if tags["protobuf"] == "-" {
+				continue
+			}
+			label, typ, ok := file.fieldType(f.Type())
+			if !ok {
+				continue
+			}
+			msg.fields = append(msg.fields, protoField{label: label, typ: typ, name: opts.FieldName(f.Name(), f.Tag()), number: protoTagNumber(tags)})
+		}
+		msg.number()
+		file.messages = append(file.messages, msg)
+	}
+	for _, iface := range ifaces {
+		file.service(iface)
+	}
+
+	bw := bufio.NewWriter(w)
+	file.write(bw)
+	return bw.Flush()
+}
+
+// name returns a free proto name for t, its Go name prefixed by its package name on conflicts
+func (f *protoFile) name(t gstypes.Type) string {
+	name := t.Name()
+	if f.used[name] && t.Package() != nil {
+		name = scanner.ExportedName(t.Package().Name()) + name
+	}
+	return f.used.Reserve(name)
+}
+
+// enum adds the enum of the named integer type t with the given constants, ok is false when
+// t isn't an integer type or has no integer constants
+func (f *protoFile) enum(t gstypes.Type, constants []*gstypes.Value) (*protoEnum, bool) {
+	if len(constants) == 0 || !isIntegerType(t) {
+		return nil, false
+	}
+	numbers := make([]int64, len(constants))
+	for i, c := range constants {
+		n, ok := protoIntValue(c.Value())
+		if !ok {
+			return nil, false
+		}
+		numbers[i] = n
+	}
+	name := f.name(t)
+	enum := &protoEnum{name: name, comments: scanner.DocLines(t)}
+	prefix := strings.ToUpper(scanner.SnakeCase(name, nil)) + "_"
+	seen := map[int64]bool{}
+	for i, c := range constants {
+		n := numbers[i]
+		enum.alias = enum.alias || seen[n]
+		seen[n] = true
+		valueName := strings.ToUpper(scanner.SnakeCase(c.Name(), nil))
+		if !strings.HasPrefix(valueName, prefix) {
+			valueName = prefix + valueName
+		}
+		enum.values = append(enum.values, protoEnumValue{name: valueName, number: n})
+	}
+	// Aliases by name, constants and Enum values don't come in the same order
+	sort.SliceStable(enum.values, func(i, j int) bool {
+		if enum.values[i].number != enum.values[j].number {
+			return enum.values[i].number < enum.values[j].number
+		}
+		return enum.values[i].name < enum.values[j].name
+	})
+	if !seen[0] {
+		enum.values = append([]protoEnumValue{{name: prefix + "UNSPECIFIED"}}, enum.values...)
+	}
+	f.enums = append(f.enums, enum)
+	return enum, true
+}
+
+// service adds the service of iface, with the request and response messages it needs
+func (f *protoFile) service(iface *gstypes.Interface) {
+	svc := &protoService{name: f.used.Reserve(iface.Name()), comments: scanner.DocLines(iface)}
+	for _, m := range iface.Methods() {
+		if !token.IsExported(m.Name()) {
+			continue
+		}
+		var params, results []protoNamedType
+		for i, p := range m.Parameters() {
+			pt := p.Type()
+			if p.IsVariadic() {
+				pt = p.SliceType()
+			}
+			if i == 0 && pt != nil && pt.Id() == "context.Context" {
+				continue
+			}
+			params = append(params, protoNamedType{name: p.Name(), typ: pt})
+		}
+		for i, r := range m.Results() {
+			if i == len(m.Results())-1 && r.Type() != nil && r.Type().Id() == "error" {
+				continue
+			}
+			results = append(results, protoNamedType{name: r.Name(), typ: r.Type()})
+		}
+		svc.rpcs = append(svc.rpcs, protoRPC{
+			name:     m.Name(),
+			request:  f.rpcMessage(iface, m.Name()+"Request", params, "arg"),
+			response: f.rpcMessage(iface, m.Name()+"Response", results, "result"),
+		})
+	}
+	f.services = append(f.services, svc)
+}
+
+// protoNamedType is a parameter or result of a service method
+type protoNamedType struct {
+	name string
+	typ  gstypes.Type
+}
+
+// rpcMessage returns the message of the parameters or results of a service method: the
+// message of a single struct, or a new message named name holding them. Unnamed items are
+// named by unnamed and their position.
+func (f *protoFile) rpcMessage(iface *gstypes.Interface, name string, items []protoNamedType, unnamed string) string {
+	if len(items) == 1 {
+		t := items[0].typ
+		if ptr, ok := t.(*gstypes.Pointer); ok && ptr.Depth() == 1 {
+			t = ptr.Elem()
+		}
+		if _, isStruct := t.(*gstypes.Struct); isStruct && f.names[t.Id()] != "" {
+			return f.names[t.Id()]
+		}
+	}
+	if f.used[name] {
+		name = iface.Name() + name
+	}
+	msg := &protoMessage{name: f.used.Reserve(name)}
+	for i, item := range items {
+		label, typ, ok := f.fieldType(item.typ)
+		if !ok {
+			continue
+		}
+		fieldName := item.name
+		if fieldName == "" || fieldName == "_" {
+			fieldName = unnamed + strconv.Itoa(i)
+		}
+		msg.fields = append(msg.fields, protoField{label: label, typ: typ, name: f.opts.FieldName(fieldName, "")})
+	}
+	msg.number()
+	f.messages = append(f.messages, msg)
+	return msg.name
+}
+
+// fieldType returns the label and proto type of a field of type t, ok is false when t
+// doesn't map to a proto type
+func (f *protoFile) fieldType(t gstypes.Type) (label string, typ string, ok bool) {
+	if t == nil {
+		return "", "", false
+	}
+	switch typed := t.(type) {
+	case *gstypes.Pointer:
+		if typed.Depth() != 1 {
+			return "", "", false
+		}
+		label, typ, ok = f.fieldType(typed.Elem())
+		if ok && label == "" && protoIsScalar(typ) {
+			label = "optional"
+		}
+		return label, typ, ok
+	case *gstypes.Slice:
+		if elem := typed.Elem(); elem != nil && (elem.Id() == "uint8" || elem.Id() == "byte") {
+			return "", "bytes", true
+		}
+		elemLabel, elemType, ok := f.fieldType(typed.Elem())
+		if !ok || elemLabel == "repeated" || strings.HasPrefix(elemType, "map<") {
+			return "", "", false
+		}
+		return "repeated", elemType, true
+	case *gstypes.Map:
+		keyLabel, keyType, ok := f.fieldType(typed.Key())
+		if !ok || keyLabel != "" || !protoIsMapKey(keyType) {
+			return "", "", false
+		}
+		valueLabel, valueType, ok := f.fieldType(typed.Value())
+		if !ok || valueLabel == "repeated" || strings.HasPrefix(valueType, "map<") {
+			return "", "", false
+		}
+		return "", "map<" + keyType + ", " + valueType + ">", true
+	}
+	if wk, found := protoWellKnown[t.Id()]; found {
+		f.imports[wk[1]] = true
+		return "", wk[0], true
+	}
+	if name := f.names[t.Id()]; name != "" {
+		return "", name, true
+	}
+	if basic, isBasic := t.(*gstypes.Basic); isBasic {
+		id := basic.Id()
+		if basic.Underlying() != nil {
+			id = basic.Underlying().Id()
+		}
+		typ, ok = protoScalars[id]
+		return "", typ, ok
+	}
+	return "", "", false
+}
+
+// number numbers the fields without a number after the highest number of the message
+func (m *protoMessage) number() {
+	taken := map[int]bool{}
+	for _, field := range m.fields {
+		taken[field.number] = true
+	}
+	next := 1
+	for i := range m.fields {
+		if m.fields[i].number != 0 {
+			continue
+		}
+		for taken[next] {
+			next++
+		}
+		m.fields[i].number = next
+		taken[next] = true
+	}
+}
+
+// write writes the proto file
+func (f *protoFile) write(w *bufio.Writer) {
+	w.WriteString("syntax = \"proto3\";\n")
+	if f.opts.Package != "" {
+		fmt.Fprintf(w, "\npackage %s;\n", f.opts.Package)
+	}
+	if len(f.imports) > 0 {
+		imports := make([]string, 0, len(f.imports))
+		for imp := range f.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+		w.WriteString("\n")
+		for _, imp := range imports {
+			fmt.Fprintf(w, "import %q;\n", imp)
+		}
+	}
+	if f.opts.GoPackage != "" {
+		fmt.Fprintf(w, "\noption go_package = %q;\n", f.opts.GoPackage)
+	}
+
+	for _, enum := range f.enums {
+		w.WriteString("\n")
+		writeProtoComments(w, enum.comments)
+		fmt.Fprintf(w, "enum %s {\n", enum.name)
+		if enum.alias {
+			w.WriteString("\toption allow_alias = true;\n")
+		}
+		for _, v := range enum.values {
+			fmt.Fprintf(w, "\t%s = %d;\n", v.name, v.number)
+		}
+		w.WriteString("}\n")
+	}
+	for _, msg := range f.messages {
+		w.WriteString("\n")
+		writeProtoComments(w, msg.comments)
+		if len(msg.fields) == 0 {
+			fmt.Fprintf(w, "message %s {}\n", msg.name)
+			continue
+		}
+		fmt.Fprintf(w, "message %s {\n", msg.name)
+		for _, field := range msg.fields {
+			w.WriteString("\t")
+			if field.label != "" {
+				w.WriteString(field.label + " ")
+			}
+			fmt.Fprintf(w, "%s %s = %d;\n", field.typ, field.name, field.number)
+		}
+		w.WriteString("}\n")
+	}
+	for _, svc := range f.services {
+		w.WriteString("\n")
+		writeProtoComments(w, svc.comments)
+		fmt.Fprintf(w, "service %s {\n", svc.name)
+		for _, rpc := range svc.rpcs {
+			fmt.Fprintf(w, "\trpc %s(%s) returns (%s);\n", rpc.name, rpc.request, rpc.response)
+		}
+		w.WriteString("}\n")
+	}
+}
+
+// isIntegerType reports whether the underlying type of the named type t is an integer
+func isIntegerType(t gstypes.Type) bool {
+	var underlying gstypes.Type
+	switch typed := t.(type) {
+	case *gstypes.Basic:
+		underlying = typed.Underlying()
+	case *gstypes.Enum:
+		underlying = typed.Underlying()
+	}
+	if underlying == nil {
+		return false
+	}
+	switch protoScalars[underlying.Id()] {
+	case "int32", "int64", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
+// protoIntValue converts a constant value (go/constant when scanned, a JSON number when read
+// from a cache) to an int64
+func protoIntValue(value any) (int64, bool) {
+	switch v := value.(type) {
+	case constant.Value:
+		if v.Kind() != constant.Int {
+			return 0, false
+		}
+		return constant.Int64Val(v)
+	case float64:
+		return int64(v), v == float64(int64(v))
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// protoTagNumber returns the field number of a protobuf tag ("bytes,3,opt,name=x"), 0 if none
+func protoTagNumber(tags map[string]string) int {
+	parts := strings.Split(tags["protobuf"], ",")
+	if len(parts) < 2 {
+		return 0
+	}
+	n, err := strconv.Atoi(parts[1])
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// protoIsScalar reports whether typ is a proto scalar type
+func protoIsScalar(typ string) bool {
+	if typ == "bytes" {
+		return true
+	}
+	for _, scalar := range protoScalars {
+		if typ == scalar {
+			return true
+		}
+	}
+	return false
+}
+
+// protoIsMapKey reports whether typ can be the key of a proto map (integers, bool and string)
+func protoIsMapKey(typ string) bool {
+	return protoIsScalar(typ) && typ != "float" && typ != "double" && typ != "bytes"
+}
+
+func writeProtoComments(w *bufio.Writer, lines []string) {
+	for _, line := range lines {
+		if line == "" {
+			w.WriteString("//\n")
+			continue
+		}
+		fmt.Fprintf(w, "// %s\n", line)
+	}
+}
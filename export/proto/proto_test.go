@@ -0,0 +1,145 @@
+package proto
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablor21/goscanner/scanner"
+)
+
+func TestEmit(t *testing.T) {
+	dir := t.TempDir()
+	src := `package shop
+
+import (
+	"context"
+	"time"
+)
+
+// Status of a user
+type Status int
+
+const (
+	Active Status = iota + 1
+	Blocked
+	Banned = Blocked
+)
+
+type Base struct {
+	ID int64
+}
+
+// User is a customer
+type User struct {
+	Base
+	Name     string ` + "`protobuf:\"bytes,5,opt,name=name\"`" + `
+	Nick     *string
+	Tags     []string
+	Scores   map[string]float64
+	Status   Status
+	Created  time.Time
+	Friends  []*User
+	Avatar   []byte
+	Secret   string ` + "`protobuf:\"-\"`" + `
+	Extra    any
+	internal int
+}
+
+type Users interface {
+	Get(ctx context.Context, id int64) (*User, error)
+	Save(ctx context.Context, u *User) error
+	List(ctx context.Context, offset, limit int) ([]*User, int, error)
+}
+`
+	for name, content := range map[string]string{"go.mod": "module example.com/shop\n\ngo 1.22\n", "shop.go": src} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := scanner.NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./..."}
+	cfg.ModuleDirs = []string{dir}
+	result, err := scanner.NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Emit(result, &buf, Options{GoPackage: "example.com/shop/pb"}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+
+	want := `syntax = "proto3";
+
+package shop;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "example.com/shop/pb";
+
+// Status of a user
+enum Status {
+	option allow_alias = true;
+	STATUS_UNSPECIFIED = 0;
+	STATUS_ACTIVE = 1;
+	STATUS_BANNED = 2;
+	STATUS_BLOCKED = 2;
+}
+
+message Base {
+	int64 id = 1;
+}
+
+// User is a customer
+message User {
+	int64 id = 1;
+	string name = 5;
+	optional string nick = 2;
+	repeated string tags = 3;
+	map<string, double> scores = 4;
+	Status status = 6;
+	google.protobuf.Timestamp created = 7;
+	repeated User friends = 8;
+	bytes avatar = 9;
+}
+
+message GetRequest {
+	int64 id = 1;
+}
+
+message ListRequest {
+	int64 offset = 1;
+	int64 limit = 2;
+}
+
+message ListResponse {
+	repeated User result0 = 1;
+	int64 result1 = 2;
+}
+
+message SaveResponse {}
+
+service Users {
+	rpc Get(GetRequest) returns (User);
+	rpc List(ListRequest) returns (ListResponse);
+	rpc Save(User) returns (SaveResponse);
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Emit() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The shared name transform overrides the snake_case default
+	buf.Reset()
+	prefixed := func(goName string, _ map[string]string) string { return "go_" + goName }
+	if err := Emit(result, &buf, Options{EmitOptions: scanner.EmitOptions{NameTransform: prefixed}}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, "\tint64 go_ID = 1;\n") || !strings.Contains(got, "\tint64 go_offset = 1;\n") {
+		t.Errorf("Emit() with a NameTransform =\n%s", got)
+	}
+}
@@ -0,0 +1,310 @@
+// Package typescript generates TypeScript declarations from the named types of a scan
+package typescript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pablor21/goscanner/scanner"
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// Options controls the declarations generated by Emit
+type Options struct {
+	// TagKey is the tag holding the property names and the omitempty option (default "json")
+	TagKey string `json:"tag_key,omitempty" yaml:"tag_key,omitempty"`
+}
+
+// wellKnown are the TypeScript types of well known named types, as encoding/json encodes them
+var wellKnown = map[string]string{
+	"time.Time":                   "string",
+	"time.Duration":               "number",
+	"encoding/json.RawMessage":    "unknown",
+	"github.com/google/uuid.UUID": "string",
+}
+
+// identifier matches the property names written without quotes
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsFile builds the declarations of Emit
+type tsFile struct {
+	result    *scanner.ScanningResult
+	tagKey    string
+	names     map[string]string // declared names, by type id
+	used      scanner.UniqueNames
+	queue     []gstypes.Type // named types referenced, waiting for their declaration
+	constants map[string][]*gstypes.Value
+}
+
+// Emit writes TypeScript declarations for the named types of the scanned packages
+// and the named types they reference, in id order: structs become interfaces (generic ones
+// with their type parameters), named types with constants union types of their values, and
+// aliases and the other named types type aliases.
+//
+// Properties follow encoding/json: they're named by the json tag (the Go name when untagged),
+// optional when tagged omitempty or omitzero and nullable for pointers. Fields promoted from
+// embedded structs are flattened, fields tagged "-", unexported fields, channels and
+// functions are skipped. Numbers are number, []byte and time.Time are string, maps are
+// records and interfaces unknown. Comments become JSDoc.
+func Emit(result *scanner.ScanningResult, w io.Writer, opts Options) error {
+	if opts.TagKey == "" {
+		opts.TagKey = "json"
+	}
+	f := &tsFile{
+		result:    result,
+		tagKey:    opts.TagKey,
+		names:     map[string]string{},
+		used:      scanner.UniqueNames{},
+		constants: result.ConstantsByType(),
+	}
+
+	ids := result.Types.Keys()
+	sort.Strings(ids)
+	for _, id := range ids {
+		t, _ := result.Types.Get(id)
+		if t == nil || t.Distance() != 0 || !t.IsNamed() {
+			continue
+		}
+		switch typed := t.(type) {
+		case *gstypes.Struct, *gstypes.Alias, *gstypes.Enum, *gstypes.Slice, *gstypes.Map:
+			f.ref(t)
+		case *gstypes.Basic:
+			if typed.Underlying() != nil {
+				f.ref(t)
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for i := 0; len(f.queue) > 0; i++ {
+		t := f.queue[0]
+		f.queue = f.queue[1:]
+		if err := t.Load(); err != nil {
+			return err
+		}
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		writeJSDoc(bw, "", scanner.DocLines(t))
+		f.declare(bw, t)
+	}
+	return bw.Flush()
+}
+
+// ref returns the name of the declaration of t, queueing it on first use
+func (f *tsFile) ref(t gstypes.Type) string {
+	name, ok := f.names[t.Id()]
+	if !ok {
+		name = t.Name()
+		if f.used[name] && t.Package() != nil {
+			name = scanner.ExportedName(t.Package().Name()) + name
+		}
+		name = f.used.Reserve(name)
+		f.names[t.Id()] = name
+		f.queue = append(f.queue, t)
+	}
+	return name
+}
+
+// declare writes the declaration of the named type t
+func (f *tsFile) declare(w *bufio.Writer, t gstypes.Type) {
+	name := f.names[t.Id()]
+	if values := f.enumValues(t); len(values) > 0 {
+		fmt.Fprintf(w, "export type %s = %s;\n", name, strings.Join(values, " | "))
+		return
+	}
+	switch typed := t.(type) {
+	case *gstypes.Struct:
+		var params []string
+		for _, tp := range typed.TypeParams() {
+			params = append(params, tp.Name())
+		}
+		if len(params) > 0 {
+			name += "<" + strings.Join(params, ", ") + ">"
+		}
+		fmt.Fprintf(w, "export interface %s {\n", name)
+		f.writeProperties(w, typed.Fields(), "\t")
+		w.WriteString("}\n")
+		return
+	case *gstypes.Alias:
+		fmt.Fprintf(w, "export type %s = %s;\n", name, f.typeOf(typed.UnderlyingType()))
+		return
+	case *gstypes.Basic:
+		fmt.Fprintf(w, "export type %s = %s;\n", name, f.typeOf(typed.Underlying()))
+		return
+	case *gstypes.Enum:
+		fmt.Fprintf(w, "export type %s = %s;\n", name, f.typeOf(typed.Underlying()))
+		return
+	}
+	fmt.Fprintf(w, "export type %s = %s;\n", name, f.structure(t))
+}
+
+// writeProperties writes the properties of the fields, each on its own line after indent
+func (f *tsFile) writeProperties(w *bufio.Writer, fields []*gstypes.Field, indent string) {
+	for _, field := range fields {
+		if field.Name() == "_" || !token.IsExported(field.Name()) {
+			continue
+		}
+		typ, ok := f.fieldType(field.Type())
+		if !ok {
+			continue
+		}
+		tag := scanner.ParseTags(field.Tag())[f.tagKey]
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name()
+		}
+		if !identifier.MatchString(name) {
+			quoted, _ := json.Marshal(name)
+			name = string(quoted)
+		}
+		optional := ""
+		for _, opt := range strings.Split(options, ",") {
+			if opt == "omitempty" || opt == "omitzero" {
+				optional = "?"
+			}
+		}
+		if err := field.Load(); err == nil {
+			writeJSDoc(w, indent, scanner.DocLines(field))
+		}
+		fmt.Fprintf(w, "%s%s%s: %s;\n", indent, name, optional, typ)
+	}
+}
+
+// typeOf returns the TypeScript type of t, unknown for the types without a JSON form
+func (f *tsFile) typeOf(t gstypes.Type) string {
+	typ, ok := f.fieldType(t)
+	if !ok {
+		return "unknown"
+	}
+	return typ
+}
+
+// fieldType returns the TypeScript type of a value of type t: the declared name for the
+// named types, the structure otherwise. ok is false for channels and functions.
+func (f *tsFile) fieldType(t gstypes.Type) (string, bool) {
+	if t == nil {
+		return "unknown", true
+	}
+	if typ, found := wellKnown[t.Id()]; found {
+		return typ, true
+	}
+	switch typed := t.(type) {
+	case *gstypes.TypeParameter:
+		return typed.Name(), true
+	case *gstypes.Pointer:
+		elem, ok := f.fieldType(typed.Elem())
+		if !ok {
+			return "", false
+		}
+		if elem == "unknown" || strings.HasSuffix(elem, " | null") {
+			return elem, true
+		}
+		return elem + " | null", true
+	case *gstypes.InstantiatedGeneric:
+		origin := typed.Origin()
+		if origin == nil {
+			return "unknown", true
+		}
+		args := make([]string, len(typed.TypeArgs()))
+		for i, arg := range typed.TypeArgs() {
+			args[i] = f.typeOf(arg.Type)
+		}
+		return f.ref(origin) + "<" + strings.Join(args, ", ") + ">", true
+	case *gstypes.Basic:
+		if typed.Underlying() != nil {
+			return f.ref(t), true
+		}
+	case *gstypes.Struct, *gstypes.Alias, *gstypes.Enum, *gstypes.Slice, *gstypes.Map:
+		if t.IsNamed() {
+			return f.ref(t), true
+		}
+	case *gstypes.Chan, *gstypes.Function:
+		return "", false
+	}
+	return f.structure(t), true
+}
+
+// structure returns the TypeScript type of the structure of t, ignoring its name
+func (f *tsFile) structure(t gstypes.Type) string {
+	switch typed := t.(type) {
+	case *gstypes.Basic:
+		if typed.Underlying() != nil {
+			return f.typeOf(typed.Underlying())
+		}
+		switch t.Id() {
+		case "bool":
+			return "boolean"
+		case "string":
+			return "string"
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "byte", "uint16", "uint32",
+			"uint64", "uintptr", "rune", "float32", "float64":
+			return "number"
+		}
+	case *gstypes.Slice:
+		if elem := typed.Elem(); !typed.IsArray() && elem != nil && (elem.Id() == "uint8" || elem.Id() == "byte") {
+			return "string"
+		}
+		elem := f.typeOf(typed.Elem())
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case *gstypes.Map:
+		return "Record<string, " + f.typeOf(typed.Value()) + ">"
+	case *gstypes.Struct:
+		var b strings.Builder
+		bw := bufio.NewWriter(&b)
+		f.writeProperties(bw, typed.Fields(), "")
+		bw.Flush()
+		props := strings.ReplaceAll(strings.TrimSuffix(b.String(), "\n"), "\n", " ")
+		if props == "" {
+			return "{}"
+		}
+		return "{ " + props + " }"
+	case *gstypes.Alias:
+		return f.typeOf(typed.UnderlyingType())
+	}
+	return "unknown"
+}
+
+// enumValues returns the literals of the distinct values of the constants of the named type t
+func (f *tsFile) enumValues(t gstypes.Type) []string {
+	var literals []string
+	for _, v := range scanner.EnumValues(t, f.constants) {
+		literal, err := json.Marshal(v)
+		if err == nil {
+			literals = append(literals, string(literal))
+		}
+	}
+	return literals
+}
+
+// writeJSDoc writes lines as a JSDoc comment after indent
+func writeJSDoc(w *bufio.Writer, indent string, lines []string) {
+	switch len(lines) {
+	case 0:
+		return
+	case 1:
+		fmt.Fprintf(w, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	fmt.Fprintf(w, "%s/**\n", indent)
+	for _, line := range lines {
+		if line == "" {
+			fmt.Fprintf(w, "%s *\n", indent)
+			continue
+		}
+		fmt.Fprintf(w, "%s * %s\n", indent, line)
+	}
+	fmt.Fprintf(w, "%s */\n", indent)
+}
@@ -0,0 +1,134 @@
+package typescript
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pablor21/goscanner/scanner"
+)
+
+// scanSource scans src as the only file of a module
+func scanSource(t *testing.T, src string) *scanner.ScanningResult {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{"go.mod": "module example.com/test\n\ngo 1.22\n", "test.go": src} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := scanner.NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./..."}
+	cfg.ModuleDirs = []string{dir}
+	result, err := scanner.NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+	return result
+}
+
+func TestEmit(t *testing.T) {
+	src := `
+	package test
+
+	// Status of a user
+	type Status string
+
+	const (
+		Active Status = "active"
+		Blocked Status = "blocked"
+	)
+
+	type Level int
+
+	const (
+		High Level = 2
+		Low Level = 1
+	)
+
+	type ID = string
+
+	type Tags []string
+
+	type Base struct {
+		ID ID ` + "`json:\"id\"`" + `
+	}
+
+	type Page[T any] struct {
+		Items []T ` + "`json:\"items\"`" + `
+		Next  *string ` + "`json:\"next,omitempty\"`" + `
+	}
+
+	// User is a user
+	//
+	// Second paragraph
+	type User struct {
+		Base
+		// Name of the user
+		Name     string ` + "`json:\"name\"`" + `
+		Nick     *string ` + "`json:\"nick\"`" + `
+		Tags     Tags ` + "`json:\"tags,omitempty\"`" + `
+		Scores   map[string]float64
+		Status   Status
+		Levels   []*Level
+		Friends  Page[User]
+		Avatar   []byte
+		Secret   string ` + "`json:\"-\"`" + `
+		Extra    any ` + "`json:\"x-extra\"`" + `
+		Fn       func()
+		Inline   struct{ A int; B string ` + "`json:\"b,omitempty\"`" + ` }
+		internal int
+	}
+
+	`
+
+	result := scanSource(t, src)
+	var buf bytes.Buffer
+	if err := Emit(result, &buf, Options{}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+
+	want := `export interface Base {
+	id: ID;
+}
+
+export type ID = string;
+
+export type Level = 1 | 2;
+
+export interface Page<T> {
+	items: T[];
+	next?: string | null;
+}
+
+/** Status of a user */
+export type Status = "active" | "blocked";
+
+export type Tags = string[];
+
+/**
+ * User is a user
+ *
+ * Second paragraph
+ */
+export interface User {
+	id: ID;
+	/** Name of the user */
+	name: string;
+	nick: string | null;
+	tags?: Tags;
+	Scores: Record<string, number>;
+	Status: Status;
+	Levels: (Level | null)[];
+	Friends: Page<User>;
+	Avatar: string;
+	"x-extra": unknown;
+	Inline: { A: number; b?: string; };
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Emit() =\n%s\nwant\n%s", got, want)
+	}
+}
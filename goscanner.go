@@ -0,0 +1,100 @@
+// Package goscanner is the entry point of the library: Scan loads and resolves the types,
+// functions and values of Go packages. It wraps the scanner package, which holds the
+// resolver and the exporters, so programs only need this import for the common case and
+// can reach the scanner package for the advanced ones (processors, custom resolvers, cache).
+package goscanner
+
+import (
+	"context"
+	"io"
+	"maps"
+
+	"github.com/pablor21/goscanner/scanner"
+)
+
+// ResultVersion is the version of Result and of its serialized model. It's bumped on
+// incompatible changes, so consumers of the serialized output can check what they read.
+const ResultVersion = 1
+
+type (
+	// Config holds the scanning options, see NewDefaultConfig
+	Config = scanner.Config
+	// ScanMode selects what Scan extracts
+	ScanMode = scanner.ScanMode
+	// VisibilityLevel selects the declarations Scan keeps by whether they're exported
+	VisibilityLevel = scanner.VisibilityLevel
+)
+
+const (
+	ScanModeBasic   = scanner.ScanModeBasic
+	ScanModeDefault = scanner.ScanModeDefault
+	ScanModeFull    = scanner.ScanModeFull
+
+	VisibilityLevelExported   = scanner.VisibilityLevelExported
+	VisibilityLevelUnexported = scanner.VisibilityLevelUnexported
+	VisibilityLevelAll        = scanner.VisibilityLevelAll
+)
+
+// Result is the outcome of Scan: the scanner.ScanningResult with the version of its model
+type Result struct {
+	*scanner.ScanningResult
+	// Version is ResultVersion
+	Version int
+}
+
+// Serialize returns the serialized result with its version under "version"
+func (r *Result) Serialize() any {
+	data := r.ScanningResult.Serialize()
+	if m, ok := data.(map[string]any); ok {
+		m["version"] = r.Version
+	}
+	return data
+}
+
+// SerializeWithOptions is ScanningResult.SerializeWithOptions with the version under "version"
+func (r *Result) SerializeWithOptions(opts scanner.EmitOptions) (any, error) {
+	return r.ScanningResult.SerializeWithOptions(r.withVersion(opts))
+}
+
+// SerializeTo is ScanningResult.SerializeTo with the version under "version"
+func (r *Result) SerializeTo(w io.Writer, opts scanner.EmitOptions) error {
+	return r.ScanningResult.SerializeTo(w, r.withVersion(opts))
+}
+
+// Encode is ScanningResult.Encode with the version under "version"
+func (r *Result) Encode(w io.Writer, format scanner.Format) error {
+	return r.EncodeWithOptions(w, format, scanner.EmitOptions{})
+}
+
+// EncodeWithOptions is ScanningResult.EncodeWithOptions with the version under "version"
+func (r *Result) EncodeWithOptions(w io.Writer, format scanner.Format, opts scanner.EmitOptions) error {
+	return r.ScanningResult.EncodeWithOptions(w, format, r.withVersion(opts))
+}
+
+// withVersion returns opts adding the version to the root fields
+func (r *Result) withVersion(opts scanner.EmitOptions) scanner.EmitOptions {
+	fields := make(map[string]any, len(opts.RootFields)+1)
+	maps.Copy(fields, opts.RootFields)
+	fields["version"] = r.Version
+	opts.RootFields = fields
+	return opts
+}
+
+// NewDefaultConfig returns the default configuration (scanner/config.json)
+func NewDefaultConfig() *Config {
+	return scanner.NewDefaultConfig()
+}
+
+// Scan scans the packages of cfg, NewDefaultConfig when nil. Cancelling ctx stops the scan:
+// once packages were processed, the partial result (Result.Partial) is returned along with
+// the error.
+func Scan(ctx context.Context, cfg *Config) (*Result, error) {
+	if cfg == nil {
+		cfg = NewDefaultConfig()
+	}
+	result, err := scanner.NewScanner().ScanWithContext(scanner.NewScanningContext(ctx, cfg))
+	if result == nil {
+		return nil, err
+	}
+	return &Result{ScanningResult: result, Version: ResultVersion}, err
+}
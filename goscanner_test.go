@@ -0,0 +1,62 @@
+package goscanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pablor21/goscanner/scanner"
+)
+
+func TestResult_version(t *testing.T) {
+	r := &Result{ScanningResult: scanner.NewScanningResult(), Version: ResultVersion}
+	versionOf := func(t *testing.T, data []byte) any {
+		t.Helper()
+		var tree map[string]any
+		if err := json.Unmarshal(data, &tree); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, data)
+		}
+		return tree["version"]
+	}
+
+	serialized, err := json.Marshal(r.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versionOf(t, serialized); got != float64(ResultVersion) {
+		t.Errorf("Serialize() version = %v, want %d", got, ResultVersion)
+	}
+
+	tree, err := r.SerializeWithOptions(scanner.EmitOptions{KeyStyle: scanner.KeyStyleSnake})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tree.(map[string]any)["version"]; got != ResultVersion {
+		t.Errorf("SerializeWithOptions() version = %v, want %d", got, ResultVersion)
+	}
+
+	var buf bytes.Buffer
+	if err := r.SerializeTo(&buf, scanner.EmitOptions{InternRefs: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := versionOf(t, buf.Bytes()); got != float64(ResultVersion) {
+		t.Errorf("SerializeTo() version = %v, want %d", got, ResultVersion)
+	}
+
+	buf.Reset()
+	if err := r.Encode(&buf, scanner.FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	if got := versionOf(t, buf.Bytes()); got != float64(ResultVersion) {
+		t.Errorf("Encode(json) version = %v, want %d", got, ResultVersion)
+	}
+
+	buf.Reset()
+	if err := r.EncodeWithOptions(&buf, scanner.FormatYAML, scanner.EmitOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "version: 1\n") {
+		t.Errorf("EncodeWithOptions(yaml) doesn't start with the version:\n%s", buf.String())
+	}
+}
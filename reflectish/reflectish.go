@@ -0,0 +1,477 @@
+// Package reflectish exposes scanned types through a read-only API shaped like reflect.Type,
+// so code written against reflect can be adapted to types that aren't loaded at runtime.
+//
+// Like reflect, methods only valid for some kinds (Elem, Field, Key...) panic when called on
+// a descriptor of another kind. Types are not loaded, load them (or the whole result) first.
+package reflectish
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// TypeDescriptor is the subset of reflect.Type supported over scanned types
+type TypeDescriptor interface {
+	// Name is the type name within its package, empty for unnamed types
+	Name() string
+	// PkgPath is the import path of a named type, empty for unnamed and predeclared types
+	PkgPath() string
+	// String is a representation of the type (pkg.Name for named types)
+	String() string
+	Kind() reflect.Kind
+
+	// Struct
+	NumField() int
+	Field(i int) StructField
+
+	// Methods, sorted by name
+	NumMethod() int
+	Method(i int) Method
+
+	// Array, Chan, Map, Pointer and Slice
+	Elem() TypeDescriptor
+	// Map
+	Key() TypeDescriptor
+	// Array
+	Len() int
+	// Chan
+	ChanDir() reflect.ChanDir
+
+	// Func
+	NumIn() int
+	In(i int) TypeDescriptor
+	NumOut() int
+	Out(i int) TypeDescriptor
+	IsVariadic() bool
+
+	// Type returns the described scanned type
+	Type() gstypes.Type
+}
+
+// StructField describes a struct field, like reflect.StructField
+type StructField struct {
+	Name      string
+	PkgPath   string // empty for exported fields
+	Type      TypeDescriptor
+	Tag       reflect.StructTag
+	Index     []int
+	Anonymous bool // embedded field
+}
+
+// Method describes a method, like reflect.Method.
+// Type is the method signature without the receiver.
+type Method struct {
+	Name    string
+	PkgPath string // empty for exported methods
+	Type    TypeDescriptor
+	Index   int
+}
+
+// Describe returns the descriptor of t. Aliases are described by their target type.
+func Describe(t gstypes.Type) TypeDescriptor {
+	return describe(t, nil)
+}
+
+// descriptor implements TypeDescriptor over a scanned type
+type descriptor struct {
+	t     gstypes.Type
+	depth int                     // remaining pointer levels when t is a multi level *Pointer
+	subst map[string]gstypes.Type // type arguments of the enclosing instantiation, by param name
+}
+
+func describe(t gstypes.Type, subst map[string]gstypes.Type) *descriptor {
+	for {
+		switch x := t.(type) {
+		case *gstypes.Alias:
+			t = x.UnderlyingType()
+			continue
+		case *gstypes.TypeParameter:
+			if arg, ok := subst[x.Name()]; ok {
+				return describe(arg, nil)
+			}
+		case *gstypes.InstantiatedGeneric:
+			// Fields and methods of the origin are described with the type arguments,
+			// which may be parameters of an enclosing instantiation (Box[T] in List[T])
+			outer := subst
+			subst = make(map[string]gstypes.Type, len(x.TypeArgs()))
+			for _, arg := range x.TypeArgs() {
+				subst[arg.Param] = arg.Type
+				if tp, ok := arg.Type.(*gstypes.TypeParameter); ok && outer[tp.Name()] != nil {
+					subst[arg.Param] = outer[tp.Name()]
+				}
+			}
+		}
+		break
+	}
+	if t == nil {
+		panic("reflectish: Describe of nil type")
+	}
+
+	d := &descriptor{t: t, subst: subst}
+	if p, ok := t.(*gstypes.Pointer); ok {
+		d.depth = max(p.Depth(), 1)
+	}
+	return d
+}
+
+// child describes a type referenced by d, named types start a new scope
+func (d *descriptor) child(t gstypes.Type) TypeDescriptor {
+	if _, ok := t.(*gstypes.TypeParameter); !ok && t != nil && t.IsNamed() && !isInstantiated(t) {
+		return describe(t, nil)
+	}
+	return describe(t, d.subst)
+}
+
+// shape returns the type defining the kind of d: the origin of instantiations and the
+// underlying type of enums
+func (d *descriptor) shape() gstypes.Type {
+	t := d.t
+	for {
+		switch x := t.(type) {
+		case *gstypes.InstantiatedGeneric:
+			t = x.Origin()
+		case *gstypes.Enum:
+			t = x.Underlying()
+		case *gstypes.Alias:
+			t = x.UnderlyingType()
+		default:
+			return t
+		}
+	}
+}
+
+func (d *descriptor) Type() gstypes.Type {
+	return d.t
+}
+
+func (d *descriptor) Name() string {
+	if d.depth > 0 && d.depth < d.t.(*gstypes.Pointer).Depth() {
+		return ""
+	}
+	if ig, ok := d.t.(*gstypes.InstantiatedGeneric); ok {
+		// Strip the package qualifier from the id (pkg.List[int] -> List[int])
+		return ig.Id()[len(d.PkgPath())+1:]
+	}
+	if !d.t.IsNamed() {
+		return ""
+	}
+	return d.t.Name()
+}
+
+func (d *descriptor) PkgPath() string {
+	if (!d.t.IsNamed() && !isInstantiated(d.t)) || d.t.Package() == nil {
+		return ""
+	}
+	if !strings.HasPrefix(d.t.Id(), d.t.Package().Path()+".") {
+		return ""
+	}
+	return d.t.Package().Path()
+}
+
+func (d *descriptor) String() string {
+	if name := d.Name(); name != "" {
+		if d.t.Package() != nil && d.PkgPath() != "" {
+			return d.t.Package().Name() + "." + name
+		}
+		return name
+	}
+	switch d.Kind() {
+	case reflect.Pointer:
+		return "*" + d.Elem().String()
+	case reflect.Slice:
+		return "[]" + d.Elem().String()
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", d.Len(), d.Elem().String())
+	case reflect.Map:
+		return "map[" + d.Key().String() + "]" + d.Elem().String()
+	case reflect.Chan:
+		switch d.ChanDir() {
+		case reflect.SendDir:
+			return "chan<- " + d.Elem().String()
+		case reflect.RecvDir:
+			return "<-chan " + d.Elem().String()
+		}
+		return "chan " + d.Elem().String()
+	case reflect.Func:
+		return "func" + d.signatureString()
+	}
+	if goType := d.t.GoType(); goType != nil {
+		return goType.String()
+	}
+	return d.t.Id()
+}
+
+func (d *descriptor) signatureString() string {
+	in := make([]string, d.NumIn())
+	for i := range in {
+		in[i] = d.In(i).String()
+	}
+	if d.IsVariadic() {
+		in[len(in)-1] = "..." + d.In(len(in)-1).Elem().String()
+	}
+	s := "(" + strings.Join(in, ", ") + ")"
+	switch d.NumOut() {
+	case 0:
+		return s
+	case 1:
+		return s + " " + d.Out(0).String()
+	}
+	out := make([]string, d.NumOut())
+	for i := range out {
+		out[i] = d.Out(i).String()
+	}
+	return s + " (" + strings.Join(out, ", ") + ")"
+}
+
+func (d *descriptor) Kind() reflect.Kind {
+	switch x := d.shape().(type) {
+	case *gstypes.Basic:
+		return basicKind(x)
+	case *gstypes.Pointer:
+		return reflect.Pointer
+	case *gstypes.Slice:
+		if x.IsArray() {
+			return reflect.Array
+		}
+		return reflect.Slice
+	case *gstypes.Map:
+		return reflect.Map
+	case *gstypes.Chan:
+		return reflect.Chan
+	case *gstypes.Function, *gstypes.Method:
+		return reflect.Func
+	case *gstypes.Struct:
+		return reflect.Struct
+	case *gstypes.Interface, *gstypes.TypeParameter, *gstypes.Union:
+		return reflect.Interface
+	}
+	return reflect.Invalid
+}
+
+// basicKinds maps predeclared type names to their reflect kind
+var basicKinds = map[string]reflect.Kind{
+	"bool":           reflect.Bool,
+	"int":            reflect.Int,
+	"int8":           reflect.Int8,
+	"int16":          reflect.Int16,
+	"int32":          reflect.Int32,
+	"rune":           reflect.Int32,
+	"int64":          reflect.Int64,
+	"uint":           reflect.Uint,
+	"uint8":          reflect.Uint8,
+	"byte":           reflect.Uint8,
+	"uint16":         reflect.Uint16,
+	"uint32":         reflect.Uint32,
+	"uint64":         reflect.Uint64,
+	"uintptr":        reflect.Uintptr,
+	"float32":        reflect.Float32,
+	"float64":        reflect.Float64,
+	"complex64":      reflect.Complex64,
+	"complex128":     reflect.Complex128,
+	"string":         reflect.String,
+	"unsafe.Pointer": reflect.UnsafePointer,
+	"Pointer":        reflect.UnsafePointer,
+	"error":          reflect.Interface,
+	"any":            reflect.Interface,
+	"comparable":     reflect.Interface,
+}
+
+func basicKind(b *gstypes.Basic) reflect.Kind {
+	// Named basics (type Role int) point to the predeclared type
+	for b.Underlying() != nil && b.Underlying() != gstypes.Type(b) {
+		u, ok := b.Underlying().(*gstypes.Basic)
+		if !ok {
+			break
+		}
+		b = u
+	}
+	if kind, ok := basicKinds[b.Name()]; ok {
+		return kind
+	}
+	return reflect.Invalid
+}
+
+func (d *descriptor) mustBe(method string, kinds ...reflect.Kind) {
+	kind := d.Kind()
+	for _, k := range kinds {
+		if kind == k {
+			return
+		}
+	}
+	panic(fmt.Sprintf("reflectish: %s of non-%s type %s", method, kinds[0], d.String()))
+}
+
+func (d *descriptor) fields() []StructField {
+	d.mustBe("Field", reflect.Struct)
+	strct := d.shape().(*gstypes.Struct)
+
+	// Embeds are stored apart from fields, they're listed first
+	fields := make([]StructField, 0, len(strct.Fields()))
+	for _, embed := range strct.Embeds() {
+		name := embed.Name()
+		if p, ok := embed.(*gstypes.Pointer); ok && p.Elem() != nil {
+			name = p.Elem().Name()
+		}
+		if i := strings.IndexByte(name, '['); i >= 0 {
+			name = name[:i]
+		}
+		fields = append(fields, StructField{Name: name, PkgPath: pkgPathOf(name, strct), Type: d.child(embed), Anonymous: true})
+	}
+	for _, f := range strct.Fields() {
+		if f.PromotedFrom() != nil {
+			continue
+		}
+		fields = append(fields, StructField{
+			Name:    f.Name(),
+			PkgPath: pkgPathOf(f.Name(), strct),
+			Type:    d.child(f.Type()),
+			Tag:     reflect.StructTag(f.Tag()),
+		})
+	}
+	for i := range fields {
+		fields[i].Index = []int{i}
+	}
+	return fields
+}
+
+// pkgPathOf returns the package path of unexported names, like reflect
+func pkgPathOf(name string, owner gstypes.Type) string {
+	if name == "" || owner.Package() == nil || (name[0] >= 'A' && name[0] <= 'Z') {
+		return ""
+	}
+	return owner.Package().Path()
+}
+
+func (d *descriptor) NumField() int {
+	return len(d.fields())
+}
+
+func (d *descriptor) Field(i int) StructField {
+	fields := d.fields()
+	if i < 0 || i >= len(fields) {
+		panic("reflectish: Field index out of bounds")
+	}
+	return fields[i]
+}
+
+func (d *descriptor) methods() []*gstypes.Method {
+	// Multi level pointers and unnamed types other than interfaces have no methods
+	if d.depth > 0 && d.depth < d.t.(*gstypes.Pointer).Depth() {
+		return nil
+	}
+	var methods []*gstypes.Method
+	if ig, ok := d.t.(*gstypes.InstantiatedGeneric); ok {
+		methods = ig.Origin().Methods()
+	} else {
+		methods = d.t.Methods()
+	}
+	if len(methods) == 0 {
+		if iface, ok := d.shape().(*gstypes.Interface); ok {
+			methods = iface.Methods()
+		}
+	}
+	sorted := append([]*gstypes.Method(nil), methods...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+	return sorted
+}
+
+func (d *descriptor) NumMethod() int {
+	return len(d.methods())
+}
+
+func (d *descriptor) Method(i int) Method {
+	methods := d.methods()
+	if i < 0 || i >= len(methods) {
+		panic("reflectish: Method index out of bounds")
+	}
+	m := methods[i]
+	return Method{Name: m.Name(), PkgPath: pkgPathOf(m.Name(), d.t), Type: &descriptor{t: m, subst: d.subst}, Index: i}
+}
+
+func (d *descriptor) Elem() TypeDescriptor {
+	d.mustBe("Elem", reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan)
+	switch x := d.shape().(type) {
+	case *gstypes.Pointer:
+		if d.t == gstypes.Type(x) && d.depth > 1 {
+			return &descriptor{t: x, depth: d.depth - 1, subst: d.subst}
+		}
+		return d.child(x.Elem())
+	case *gstypes.Slice:
+		return d.child(x.Elem())
+	case *gstypes.Map:
+		return d.child(x.Value())
+	case *gstypes.Chan:
+		return d.child(x.Elem())
+	}
+	return nil
+}
+
+func (d *descriptor) Key() TypeDescriptor {
+	d.mustBe("Key", reflect.Map)
+	return d.child(d.shape().(*gstypes.Map).Key())
+}
+
+func (d *descriptor) Len() int {
+	d.mustBe("Len", reflect.Array)
+	return int(d.shape().(*gstypes.Slice).Len())
+}
+
+func (d *descriptor) ChanDir() reflect.ChanDir {
+	d.mustBe("ChanDir", reflect.Chan)
+	switch d.shape().(*gstypes.Chan).Dir() {
+	case gstypes.ChanDirSend:
+		return reflect.SendDir
+	case gstypes.ChanDirRecv:
+		return reflect.RecvDir
+	}
+	return reflect.BothDir
+}
+
+func (d *descriptor) signature() ([]*gstypes.Parameter, []*gstypes.Result) {
+	d.mustBe("In", reflect.Func)
+	switch x := d.shape().(type) {
+	case *gstypes.Function:
+		return x.Parameters(), x.Results()
+	case *gstypes.Method:
+		return x.Parameters(), x.Results()
+	}
+	return nil, nil
+}
+
+func (d *descriptor) NumIn() int {
+	params, _ := d.signature()
+	return len(params)
+}
+
+// In returns the type of the i'th parameter, a slice for the variadic one like reflect
+func (d *descriptor) In(i int) TypeDescriptor {
+	params, _ := d.signature()
+	if params[i].IsVariadic() {
+		return d.child(params[i].SliceType())
+	}
+	return d.child(params[i].Type())
+}
+
+func (d *descriptor) NumOut() int {
+	_, results := d.signature()
+	return len(results)
+}
+
+func (d *descriptor) Out(i int) TypeDescriptor {
+	_, results := d.signature()
+	return d.child(results[i].Type())
+}
+
+func (d *descriptor) IsVariadic() bool {
+	params, _ := d.signature()
+	return len(params) > 0 && params[len(params)-1].IsVariadic()
+}
+
+func isInstantiated(t gstypes.Type) bool {
+	_, ok := t.(*gstypes.InstantiatedGeneric)
+	return ok
+}
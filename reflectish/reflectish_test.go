@@ -0,0 +1,169 @@
+package reflectish
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pablor21/goscanner/scanner"
+)
+
+// scanSource scans src as the only file of a module
+func scanSource(t *testing.T, src string) *scanner.ScanningResult {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{"go.mod": "module example.com/test\n\ngo 1.22\n", "test.go": src} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := scanner.NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./..."}
+	cfg.ModuleDirs = []string{dir}
+	result, err := scanner.NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+	return result
+}
+
+func TestDescribe(t *testing.T) {
+	src := `
+	package test
+
+	type Role int
+
+	type Base struct {
+		ID int
+	}
+
+	func (b Base) Key() string { return "" }
+
+	type Box[T any] struct {
+		Value T
+		Items []T
+	}
+
+	type User struct {
+		Base
+		Name    string            ` + "`json:\"name\"`" + `
+		secret  string
+		Roles   []Role
+		Meta    map[string]*int
+		Next    **User
+		Grid    [3][2]int
+		Events  <-chan string
+		Box     Box[Role]
+		Handler func(name string, args ...int) (bool, error)
+	}
+
+	func (u *User) Save() error { return nil }
+	`
+
+	result := scanSource(t, src)
+	userType, _ := result.Types.Get("example.com/test.User")
+	user := Describe(userType)
+
+	if user.Kind() != reflect.Struct || user.Name() != "User" || user.PkgPath() != "example.com/test" || user.String() != "test.User" {
+		t.Fatalf("User = (%s, %q, %q, %q)", user.Kind(), user.Name(), user.PkgPath(), user.String())
+	}
+
+	fields := map[string]StructField{}
+	for i := 0; i < user.NumField(); i++ {
+		f := user.Field(i)
+		if len(f.Index) != 1 || f.Index[0] != i {
+			t.Errorf("%s.Index = %v, want [%d]", f.Name, f.Index, i)
+		}
+		fields[f.Name] = f
+	}
+	if user.NumField() != 10 {
+		t.Errorf("NumField() = %d, want 10 (promoted fields excluded)", user.NumField())
+	}
+	if base := fields["Base"]; !base.Anonymous || base.Type.NumMethod() != 1 {
+		t.Errorf("Base = %+v, want an embedded field with 1 method", base)
+	}
+	if got := fields["Name"].Tag.Get("json"); got != "name" {
+		t.Errorf(`Name.Tag.Get("json") = %q`, got)
+	}
+	if fields["secret"].PkgPath != "example.com/test" || fields["Name"].PkgPath != "" {
+		t.Errorf("PkgPath must be set for unexported fields only")
+	}
+
+	tests := []struct {
+		field string
+		kind  reflect.Kind
+		str   string
+		elem  reflect.Kind
+	}{
+		{"Roles", reflect.Slice, "[]test.Role", reflect.Int},
+		{"Meta", reflect.Map, "map[string]*int", reflect.Pointer},
+		{"Next", reflect.Pointer, "**test.User", reflect.Pointer},
+		{"Grid", reflect.Array, "[3][2]int", reflect.Array},
+		{"Events", reflect.Chan, "<-chan string", reflect.String},
+		{"Handler", reflect.Func, "func(string, ...int) (bool, error)", reflect.Invalid},
+	}
+	for _, tt := range tests {
+		d := fields[tt.field].Type
+		if d.Kind() != tt.kind || d.String() != tt.str {
+			t.Errorf("%s = (%s, %q), want (%s, %q)", tt.field, d.Kind(), d.String(), tt.kind, tt.str)
+		}
+		if tt.elem != reflect.Invalid && d.Elem().Kind() != tt.elem {
+			t.Errorf("%s.Elem().Kind() = %s, want %s", tt.field, d.Elem().Kind(), tt.elem)
+		}
+	}
+
+	if next := fields["Next"].Type.Elem().Elem(); next.Name() != "User" {
+		t.Errorf("**User elem elem = %s, want User", next)
+	}
+	if grid := fields["Grid"].Type; grid.Len() != 3 || grid.Elem().Len() != 2 {
+		t.Errorf("Grid lengths = %d, %d", grid.Len(), grid.Elem().Len())
+	}
+	if fields["Events"].Type.ChanDir() != reflect.RecvDir {
+		t.Errorf("Events.ChanDir() = %s", fields["Events"].Type.ChanDir())
+	}
+	if fields["Meta"].Type.Key().Kind() != reflect.String {
+		t.Errorf("Meta key kind = %s", fields["Meta"].Type.Key().Kind())
+	}
+
+	handler := fields["Handler"].Type
+	if handler.NumIn() != 2 || !handler.IsVariadic() || handler.In(1).Kind() != reflect.Slice || handler.NumOut() != 2 {
+		t.Errorf("Handler signature = %s", handler)
+	}
+	if handler.Out(1).Kind() != reflect.Interface {
+		t.Errorf("error result kind = %s, want interface", handler.Out(1).Kind())
+	}
+
+	// Type arguments are substituted in fields of instantiated generics
+	box := fields["Box"].Type
+	if box.Kind() != reflect.Struct || box.Name() != "Box[example.com/test.Role]" {
+		t.Fatalf("Box = (%s, %q)", box.Kind(), box.Name())
+	}
+	if value := box.Field(0).Type; value.Name() != "Role" || value.Kind() != reflect.Int {
+		t.Errorf("Box.Value = (%s, %s), want Role int", value.Name(), value.Kind())
+	}
+	if items := box.Field(1).Type; items.String() != "[]test.Role" {
+		t.Errorf("Box.Items = %s, want []test.Role", items)
+	}
+
+	// Methods are sorted by name and include promoted ones
+	var names []string
+	for i := 0; i < user.NumMethod(); i++ {
+		names = append(names, user.Method(i).Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Key", "Save"}) {
+		t.Errorf("methods = %v, want [Key Save]", names)
+	}
+	if save := user.Method(1).Type; save.Kind() != reflect.Func || save.NumIn() != 0 || save.Out(0).String() != "error" {
+		t.Errorf("Save = %s", save)
+	}
+
+	// Invalid calls panic like reflect
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Elem of a struct must panic")
+		}
+	}()
+	user.Elem()
+}
@@ -0,0 +1,311 @@
+package scanner
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// Types of the parameters of an AnnotationSchema
+const (
+	ParamString = "string"
+	ParamInt    = "int"
+	ParamFloat  = "float"
+	ParamBool   = "bool" // a flag (@cache(enabled)) or true/false
+)
+
+// AnnotationsMetadataKey is the metadata key of the annotations parsed by ParseAnnotations
+const AnnotationsMetadataKey = "annotations"
+
+// RuleInvalidAnnotation is the rule of the diagnostics of ParseAnnotations
+const RuleInvalidAnnotation = "invalid-annotation"
+
+// AnnotationSchema declares a comment annotation and its parameters, as in
+// @route(method="GET", path="/users"), see RegisterAnnotation
+type AnnotationSchema struct {
+	// Name is the annotation with its @ ("@route")
+	Name   string             `json:"name" yaml:"name"`
+	Params []*AnnotationParam `json:"params,omitempty" yaml:"params,omitempty"`
+	// AllowUnknown accepts parameters missing from Params, as strings
+	AllowUnknown bool `json:"allow_unknown,omitempty" yaml:"allow_unknown,omitempty"`
+	// Targets are the kinds of the declarations it can annotate (struct, interface, function,
+	// field, method, constant...), any when empty
+	Targets []gstypes.TypeKind `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
+
+// AnnotationParam declares a parameter of an annotation
+type AnnotationParam struct {
+	Name string `json:"name" yaml:"name"`
+	// Type is ParamString (the default), ParamInt, ParamFloat or ParamBool
+	Type     string `json:"type,omitempty" yaml:"type,omitempty"`
+	Required bool   `json:"required,omitempty" yaml:"required,omitempty"`
+	// Values are the values accepted, any when empty
+	Values []string `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+// Annotation is an annotation parsed with its schema: Args hold its arguments converted to
+// the type of their parameter (string, int64, float64 or bool)
+type Annotation struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// AnnotationError is the error of the scans with Config.StrictAnnotations finding
+// annotations not matching their schema
+type AnnotationError struct {
+	Diagnostics []*Diagnostic
+}
+
+func (e *AnnotationError) Error() string {
+	lines := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		lines[i] = d.String()
+	}
+	return fmt.Sprintf("%d invalid annotations:\n%s", len(e.Diagnostics), strings.Join(lines, "\n"))
+}
+
+// annotationSchemas are the schemas of every scan, by name
+var annotationSchemas = gstypes.NewSyncMap[string, *AnnotationSchema]()
+
+// RegisterAnnotation registers the schema of an annotation parsed by every scan, replacing
+// the one registered with the same name if any. Plugins register their annotations from init.
+func RegisterAnnotation(schema *AnnotationSchema) {
+	annotationSchemas.Set(schema.Name, schema)
+}
+
+// ParseAnnotations parses the annotations of the declarations of the scanned packages (types,
+// functions, values, and the fields and methods the types declare) with the registered
+// schemas and the given ones, which take precedence. The annotations of a declaration are
+// attached to it as a []*Annotation under AnnotationsMetadataKey. The ones not matching their
+// schema (unknown or missing parameters, values of the wrong type or not allowed, a kind of
+// declaration it doesn't apply to) are returned as diagnostics, and kept in Diagnostics.
+func (s *ScanningResult) ParseAnnotations(schemas ...*AnnotationSchema) []*Diagnostic {
+	bySchema := annotationSchemas.Snapshot()
+	for _, schema := range schemas {
+		bySchema[schema.Name] = schema
+	}
+	if len(bySchema) == 0 {
+		s.annotationDiagnostics = nil
+		return nil
+	}
+	names := make([]string, 0, len(bySchema))
+	for name := range bySchema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var diags []*Diagnostic
+	parse := func(owner gstypes.Type, decl gstypes.Type, member string) {
+		lines := DocLines(decl)
+		if len(lines) == 0 {
+			return
+		}
+		var parsed []*Annotation
+		for _, name := range names {
+			args := annotationArgs(lines, name)
+			if args == nil {
+				continue
+			}
+			annotation, problems := bySchema[name].parse(decl.Kind(), args)
+			for _, problem := range problems {
+				diags = append(diags, &Diagnostic{
+					Severity: SeverityError,
+					Rule:     RuleInvalidAnnotation,
+					Type:     owner.Id(),
+					Field:    member,
+					Key:      name,
+					Message:  problem,
+					Position: decl.Position(),
+				})
+			}
+			parsed = append(parsed, annotation)
+		}
+		if len(parsed) > 0 {
+			decl.SetMetadata(AnnotationsMetadataKey, parsed)
+		}
+	}
+
+	for _, t := range s.Types.Values() {
+		if t.Distance() != 0 || !t.IsNamed() || t.Opaque() {
+			continue
+		}
+		if _, ok := t.(*gstypes.InstantiatedGeneric); ok {
+			continue
+		}
+		if err := t.Load(); err != nil {
+			continue
+		}
+		parse(t, t, "")
+		for _, f := range fieldsOf(t) {
+			if f.PromotedFrom() == nil && f.Load() == nil {
+				parse(t, f, f.Name())
+			}
+		}
+		for _, m := range t.Methods() {
+			if m.PromotedFrom() == nil && m.Load() == nil {
+				parse(t, m, m.Name())
+			}
+		}
+	}
+	for _, v := range s.Values.Values() {
+		if v.Distance() == 0 && v.Load() == nil {
+			parse(v, v, "")
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Type != diags[j].Type {
+			return diags[i].Type < diags[j].Type
+		}
+		return diags[i].Field < diags[j].Field
+	})
+	s.annotationDiagnostics = diags
+	return diags
+}
+
+// parse converts the arguments of the annotation of a declaration of the given kind,
+// returning the problems found
+func (schema *AnnotationSchema) parse(kind gstypes.TypeKind, args map[string]string) (*Annotation, []string) {
+	var problems []string
+	if len(schema.Targets) > 0 && !slices.Contains(schema.Targets, kind) {
+		problems = append(problems, fmt.Sprintf("%s doesn't apply to a %s", schema.Name, kind))
+	}
+
+	annotation := &Annotation{Name: schema.Name, Args: make(map[string]any, len(args))}
+	declared := map[string]bool{}
+	for _, param := range schema.Params {
+		declared[param.Name] = true
+		raw, ok := args[param.Name]
+		if !ok {
+			if param.Required {
+				problems = append(problems, fmt.Sprintf("missing parameter %s", param.Name))
+			}
+			continue
+		}
+		value, err := param.convert(raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("parameter %s: %v", param.Name, err))
+			continue
+		}
+		annotation.Args[param.Name] = value
+	}
+
+	unknown := make([]string, 0, len(args))
+	for name := range args {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		if !schema.AllowUnknown {
+			problems = append(problems, fmt.Sprintf("unknown parameter %s", name))
+			continue
+		}
+		annotation.Args[name] = args[name]
+	}
+	return annotation, problems
+}
+
+// convert returns the value of an argument of the parameter, raw being its unquoted text
+// (empty for flags)
+func (param *AnnotationParam) convert(raw string) (any, error) {
+	if len(param.Values) > 0 && !slices.Contains(param.Values, raw) {
+		return nil, fmt.Errorf("%q is not one of %s", raw, strings.Join(param.Values, ", "))
+	}
+	switch param.Type {
+	case "", ParamString:
+		return raw, nil
+	case ParamInt:
+		n, err := strconv.ParseInt(raw, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return n, nil
+	case ParamFloat:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+		return f, nil
+	case ParamBool:
+		if raw == "" {
+			return true, nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("unknown parameter type %s", param.Type)
+}
+
+// annotationArgs returns the arguments of the first annotation called name in the comment
+// lines, as in @name(key="value", flag): quoted values are unquoted and flags have an empty
+// value. It returns nil when there is no such annotation.
+func annotationArgs(lines []string, name string) map[string]string {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		rest, ok := strings.CutPrefix(line, name)
+		if !ok {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		if !strings.HasPrefix(rest, "(") {
+			if rest == "" {
+				return map[string]string{}
+			}
+			continue
+		}
+		list, ok := splitAnnotationArgs(rest[1:])
+		if !ok {
+			continue
+		}
+		args := map[string]string{}
+		for _, arg := range list {
+			key, value, _ := strings.Cut(arg, "=")
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			if key != "" {
+				args[key] = value
+			}
+		}
+		return args
+	}
+	return nil
+}
+
+// splitAnnotationArgs splits the arguments of an annotation up to its closing parenthesis on
+// the commas out of quoted values ("..." with escapes, or `...`). It reports false when the
+// parenthesis is not closed.
+func splitAnnotationArgs(s string) ([]string, bool) {
+	var args []string
+	start := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`':
+			quote = c
+		case c == ',':
+			args = append(args, s[start:i])
+			start = i + 1
+		case c == ')':
+			return append(args, s[start:i]), true
+		}
+	}
+	return nil, false
+}
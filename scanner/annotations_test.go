@@ -0,0 +1,134 @@
+package scanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+func TestScanningResult_ParseAnnotations(t *testing.T) {
+	src := `
+	package test
+
+	// Users handles users
+	// @controller(prefix="/users", note="says \"hi, there\"")
+	type Users struct {
+		// @column(size=32, nullable)
+		Name string
+		// @column(size=big)
+		Bio string
+		// @column(size="8,16")
+		Tags string
+	}
+
+	// Search searches the users
+	// @route(method="GET", path="/search?fields=name,bio", cached)
+	func (Users) Search() {}
+
+	// List lists the users
+	// @route(method="GET", path="/", cached)
+	func (Users) List() {}
+
+	// Create creates a user
+	// @route(method=FETCH, weight=0.5)
+	func (Users) Create() {}
+
+	// @route(method="POST", path="/")
+	type Route string
+	`
+	schemas := []*AnnotationSchema{
+		{Name: "@route", Targets: []gstypes.TypeKind{gstypes.TypeKindMethod, gstypes.TypeKindFunction}, Params: []*AnnotationParam{
+			{Name: "method", Required: true, Values: []string{"GET", "POST"}},
+			{Name: "path", Required: true},
+			{Name: "cached", Type: ParamBool},
+		}},
+		{Name: "@column", Params: []*AnnotationParam{
+			{Name: "size", Type: ParamInt},
+			{Name: "nullable", Type: ParamBool},
+		}},
+		{Name: "@controller", AllowUnknown: true},
+	}
+
+	result := scanTestSource(t, src)
+	diags := result.ParseAnnotations(schemas...)
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.String())
+	}
+	want := []string{
+		`test.Route: @route doesn't apply to a basic (invalid-annotation)`,
+		`test.Users.Bio: parameter size: "big" is not an integer (invalid-annotation)`,
+		`test.Users.Create: parameter method: "FETCH" is not one of GET, POST (invalid-annotation)`,
+		`test.Users.Create: missing parameter path (invalid-annotation)`,
+		`test.Users.Create: unknown parameter weight (invalid-annotation)`,
+		`test.Users.Tags: parameter size: "8,16" is not an integer (invalid-annotation)`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAnnotations() =\n%v\nwant\n%v", got, want)
+	}
+	if !reflect.DeepEqual(result.Diagnostics(), diags) {
+		t.Errorf("Diagnostics() = %v, want the annotation diagnostics", result.Diagnostics())
+	}
+
+	users, _ := result.Types.Get("test.Users")
+	annotations := func(decl gstypes.Type) []*Annotation {
+		list, _ := decl.Metadata()[AnnotationsMetadataKey].([]*Annotation)
+		return list
+	}
+	if got := annotations(users); len(got) != 1 || !reflect.DeepEqual(got[0].Args, map[string]any{"prefix": "/users", "note": `says "hi, there"`}) {
+		t.Errorf("Users annotations = %v, want @controller(prefix=/users, note=...)", got)
+	}
+	for _, f := range users.(*gstypes.Struct).Fields() {
+		if f.Name() != "Name" {
+			continue
+		}
+		if got := annotations(f); len(got) != 1 || !reflect.DeepEqual(got[0].Args, map[string]any{"size": int64(32), "nullable": true}) {
+			t.Errorf("Name annotations = %v, want @column(size=32, nullable)", got)
+		}
+	}
+	for _, m := range users.Methods() {
+		switch m.Name() {
+		case "List":
+			if got := annotations(m); len(got) != 1 || !reflect.DeepEqual(got[0].Args, map[string]any{"method": "GET", "path": "/", "cached": true}) {
+				t.Errorf("List annotations = %v, want @route(method=GET, path=/, cached)", got)
+			}
+		case "Search":
+			if got := annotations(m); len(got) != 1 || !reflect.DeepEqual(got[0].Args, map[string]any{"method": "GET", "path": "/search?fields=name,bio", "cached": true}) {
+				t.Errorf("Search annotations = %v, want @route(method=GET, path=/search?fields=name,bio, cached)", got)
+			}
+		}
+	}
+
+	// Strict scans fail on invalid annotations
+	cfg := NewDefaultConfig()
+	cfg.Annotations = schemas
+	cfg.StrictAnnotations = true
+	var annErr *AnnotationError
+	if err := analyzeResult(cfg, result); !errors.As(err, &annErr) || len(annErr.Diagnostics) != len(want) {
+		t.Errorf("analyzeResult() error = %v, want an AnnotationError with %d diagnostics", err, len(want))
+	}
+}
+
+func TestAnnotationArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want map[string]string
+	}{
+		{`@route`, map[string]string{}},
+		{`@route(method="GET", cached)`, map[string]string{"method": "GET", "cached": ""}},
+		{`@route(path="/a,b", method=GET)`, map[string]string{"path": "/a,b", "method": "GET"}},
+		{`@route(desc="say \"hi, there\"", n=1)`, map[string]string{"desc": `say "hi, there"`, "n": "1"}},
+		{"@route(desc=`a, (b)`, n=1)", map[string]string{"desc": "a, (b)", "n": "1"}},
+		{`@route(path="/a)") trailing, text`, map[string]string{"path": "/a)"}},
+		{`@route(path="/a"`, nil},
+		{`@router(path="/a")`, nil},
+	}
+	for _, tt := range tests {
+		if got := annotationArgs([]string{tt.line}, "@route"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("annotationArgs(%s) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
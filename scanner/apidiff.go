@@ -0,0 +1,86 @@
+package scanner
+
+import gstypes "github.com/pablor21/goscanner/types"
+
+// Version bumps suggested by a CompatibilityReport
+const (
+	BumpMajor = "major" // breaking changes
+	BumpMinor = "minor" // compatible additions or changes only
+	BumpNone  = "none"  // no API change
+)
+
+// CompatibilityReport classifies the changes of a ResultDiff by whether they break the code
+// using the previous API, with the semantic version bump they call for
+type CompatibilityReport struct {
+	Bump       string       `json:"bump"`
+	Breaking   []*APIChange `json:"breaking,omitempty"`
+	Compatible []*APIChange `json:"compatible,omitempty"`
+}
+
+// APIChange is a classified change: an added or removed declaration (Change is nil), or a
+// change of a declaration found in both results
+type APIChange struct {
+	ID     string        `json:"id"`
+	Change *MemberChange `json:"change,omitempty"`
+	Reason string        `json:"reason"`
+}
+
+// IsBreaking reports whether the report has breaking changes
+func (r *CompatibilityReport) IsBreaking() bool {
+	return len(r.Breaking) > 0
+}
+
+// Compatibility classifies the changes of the diff like apidiff does: removing a declaration
+// or a member, changing a type, a struct tag, a signature or a constant value, and adding a
+// method to an interface (its implementations no longer satisfy it) are breaking; additions
+// are compatible.
+func (d *ResultDiff) Compatibility() *CompatibilityReport {
+	report := &CompatibilityReport{}
+	for _, id := range d.Removed {
+		report.Breaking = append(report.Breaking, &APIChange{ID: id, Reason: "declaration removed"})
+	}
+	for _, decl := range d.Changed {
+		for _, c := range decl.Changes {
+			change := &APIChange{ID: decl.ID, Change: c, Reason: changeReason(c)}
+			if isBreakingChange(decl.Kind, c) {
+				report.Breaking = append(report.Breaking, change)
+			} else {
+				report.Compatible = append(report.Compatible, change)
+			}
+		}
+	}
+	for _, id := range d.Added {
+		report.Compatible = append(report.Compatible, &APIChange{ID: id, Reason: "declaration added"})
+	}
+
+	switch {
+	case len(report.Breaking) > 0:
+		report.Bump = BumpMajor
+	case len(report.Compatible) > 0:
+		report.Bump = BumpMinor
+	default:
+		report.Bump = BumpNone
+	}
+	return report
+}
+
+// isBreakingChange reports whether a change of a declaration of the given kind breaks its users
+func isBreakingChange(declKind string, c *MemberChange) bool {
+	if c.Change != ChangeAdded {
+		// Removed members and changed types, signatures, tags and values
+		return true
+	}
+	// Adding a method to an interface narrows the types implementing it
+	return c.Kind == "method" && gstypes.TypeKind(declKind) == gstypes.TypeKindInterface
+}
+
+// changeReason describes a change in a few words ("field removed", "method signature changed")
+func changeReason(c *MemberChange) string {
+	switch {
+	case c.Kind == "method" && c.Change == ChangeChanged:
+		return "method signature changed"
+	case c.Kind == "field" && c.Change == ChangeChanged:
+		return "field type or tag changed"
+	}
+	return c.Kind + " " + c.Change
+}
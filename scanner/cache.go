@@ -4,8 +4,10 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"go/doc"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	gstypes "github.com/pablor21/goscanner/types"
@@ -17,21 +19,41 @@ type CacheHeader struct {
 	Version   uint8  `json:"version"`
 	Timestamp int64  `json:"timestamp"`
 	Checksum  uint32 `json:"checksum"`
+	// Partial is set on the caches of cancelled scans, IsCacheValid rejects them
+	Partial bool `json:"partial,omitempty"`
 }
 
 // CacheFile is a gzip-compressed JSON file containing the serialized scanning result
 type CacheFile struct {
 	Header CacheHeader            `json:"header"`
 	Result map[string]interface{} `json:"result"` // The complete result from ScanningResult.Serialize()
+	// Sources are the stamps of the source files of the scanned packages, by package path and
+	// file, written by ScanIncremental
+	Sources map[string]map[string]FileStamp `json:"sources,omitempty"`
+}
+
+// FileStamp identifies the content of a source file when the cache was written
+type FileStamp struct {
+	ModTime int64  `json:"modTime"` // Unix nanoseconds
+	Size    int64  `json:"size"`
+	Hash    string `json:"hash"` // hex SHA-256 of the content
 }
 
 const (
 	CacheMagic   = "GSCAN"
-	CacheVersion = 1
+	CacheVersion = 2
 )
 
-// WriteCache writes the scanning result to a gzip-compressed JSON cache file
+// WriteCache writes the scanning result to a gzip-compressed JSON cache file.
+// Failures are reported as a *CacheError.
 func WriteCache(filename string, result *ScanningResult) error {
+	if err := writeCache(filename, result); err != nil {
+		return &CacheError{Path: filename, Op: "write", Err: err}
+	}
+	return nil
+}
+
+func writeCache(filename string, result *ScanningResult) error {
 	if filename == "" {
 		return fmt.Errorf("cache filename cannot be empty")
 	}
@@ -40,6 +62,16 @@ func WriteCache(filename string, result *ScanningResult) error {
 		return fmt.Errorf("scanning result cannot be nil")
 	}
 
+	// Serialize the result
+	serialized, ok := result.Serialize().(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected serialization format")
+	}
+	return writeCacheFile(filename, serialized, nil, result.partial)
+}
+
+// writeCacheFile writes a serialized result and the stamps of its sources to filename
+func writeCacheFile(filename string, serialized map[string]interface{}, sources map[string]map[string]FileStamp, partial bool) error {
 	// Ensure directory exists
 	dir := filepath.Dir(filename)
 	if dir != "" && dir != "." {
@@ -54,14 +86,10 @@ func WriteCache(filename string, result *ScanningResult) error {
 			Magic:     CacheMagic,
 			Version:   CacheVersion,
 			Timestamp: time.Now().Unix(),
+			Partial:   partial,
 		},
-	}
-
-	// Serialize the result
-	if serialized, ok := result.Serialize().(map[string]interface{}); ok {
-		cache.Result = serialized
-	} else {
-		return fmt.Errorf("unexpected serialization format")
+		Result:  serialized,
+		Sources: sources,
 	}
 
 	// Calculate checksum on the result data
@@ -97,8 +125,33 @@ func WriteCache(filename string, result *ScanningResult) error {
 	return nil
 }
 
-// ReadCache reads a scanning result from a gzip-compressed JSON cache file
+// ReadCache reads a scanning result from a gzip-compressed JSON cache file.
+// Failures are reported as a *CacheError.
 func ReadCache(filename string) (*ScanningResult, error) {
+	cache, err := readCacheFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reconstruct ScanningResult from JSON data
+	result, err := reconstructFromCache(cache.Result)
+	if err != nil {
+		return nil, &CacheError{Path: filename, Op: "read", Err: err}
+	}
+	result.partial = cache.Header.Partial
+	return result, nil
+}
+
+// readCacheFile reads and validates a cache file without reconstructing the result
+func readCacheFile(filename string) (*CacheFile, error) {
+	cache, err := decodeCacheFile(filename)
+	if err != nil {
+		return nil, &CacheError{Path: filename, Op: "read", Err: err}
+	}
+	return cache, nil
+}
+
+func decodeCacheFile(filename string) (*CacheFile, error) {
 	if filename == "" {
 		return nil, fmt.Errorf("cache filename cannot be empty")
 	}
@@ -140,8 +193,7 @@ func ReadCache(filename string) (*ScanningResult, error) {
 		return nil, fmt.Errorf("incompatible cache version: expected %d, got %d", CacheVersion, cache.Header.Version)
 	}
 
-	// Reconstruct ScanningResult from JSON data
-	return reconstructFromCache(cache.Result)
+	return &cache, nil
 }
 
 // reconstructFromCache rebuilds a ScanningResult from the cached JSON data
@@ -149,9 +201,22 @@ func ReadCache(filename string) (*ScanningResult, error) {
 func reconstructFromCache(data map[string]interface{}) (*ScanningResult, error) {
 	result := NewScanningResult()
 
-	// First pass: reconstruct all types with basic information
+	// Reconstruct packages first so types and values can link to them
+	if packagesData, ok := data["packages"].(map[string]interface{}); ok {
+		for path, pkgData := range packagesData {
+			if pkgBytes, err := json.Marshal(pkgData); err == nil {
+				p, err := deserializePackage(string(pkgBytes), result)
+				if err == nil && p != nil {
+					result.Packages.Set(path, p)
+				}
+			}
+		}
+	}
+
+	// Reconstruct all types with basic information. Instantiated generics go last, so their
+	// origin is reconstructed first.
 	if typesData, ok := data["types"].(map[string]interface{}); ok {
-		for id, typeData := range typesData {
+		restore := func(id string, typeData any) {
 			if typeBytes, err := json.Marshal(typeData); err == nil {
 				t, err := deserializeType(string(typeBytes), result)
 				if err == nil && t != nil {
@@ -159,6 +224,18 @@ func reconstructFromCache(data map[string]interface{}) (*ScanningResult, error)
 				}
 			}
 		}
+		instances := map[string]any{}
+		for id, typeData := range typesData {
+			if m, ok := typeData.(map[string]interface{}); ok && m["kind"] == string(gstypes.TypeKindInstantiated) {
+				instances[id] = typeData
+				continue
+			}
+			restore(id, typeData)
+		}
+		for id, typeData := range instances {
+			restore(id, typeData)
+		}
+		linkPromotions(result)
 	}
 
 	// Reconstruct values
@@ -173,19 +250,49 @@ func reconstructFromCache(data map[string]interface{}) (*ScanningResult, error)
 		}
 	}
 
-	// Reconstruct packages
-	if packagesData, ok := data["packages"].(map[string]interface{}); ok {
-		for path, pkgData := range packagesData {
-			if pkgBytes, err := json.Marshal(pkgData); err == nil {
-				p, err := deserializePackage(string(pkgBytes), result)
-				if err == nil && p != nil {
-					result.Packages.Set(path, p)
-				}
+	result.linkImplementations()
+	markFromCache(result)
+	return result, nil
+}
+
+// linkPromotions points the promoted fields and methods of the restored types at the
+// embedded types they come from, which got a placeholder when restored after them
+func linkPromotions(result *ScanningResult) {
+	link := func(from gstypes.Type) gstypes.Type {
+		if t, ok := result.Types.Get(from.Id()); ok {
+			return t
+		}
+		return from
+	}
+	for _, t := range result.Types.Values() {
+		for _, f := range fieldsOf(t) {
+			if from := f.PromotedFrom(); from != nil {
+				f.SetPromotedFrom(link(from))
+			}
+		}
+		for _, m := range t.Methods() {
+			if from := m.PromotedFrom(); from != nil {
+				m.SetPromotedFrom(link(from))
 			}
 		}
 	}
+}
 
-	return result, nil
+// markFromCache flags the types and values of a restored result, and the types they
+// reference, as coming from the cache
+func markFromCache(result *ScanningResult) {
+	mark := func(t gstypes.Type) {
+		t.SetFromCache(true)
+		gstypes.WalkReferences(t, func(ref gstypes.Type, _ gstypes.RefRole) {
+			ref.SetFromCache(true)
+		})
+	}
+	for _, t := range result.Types.Values() {
+		mark(t)
+	}
+	for _, v := range result.Values.Values() {
+		mark(v)
+	}
 }
 
 // deserializeType reconstructs a Type from JSON bytes
@@ -208,6 +315,21 @@ func deserializeType(jsonStr string, result *ScanningResult) (gstypes.Type, erro
 			}
 		}
 
+	case gstypes.TypeKindEnum:
+		var se gstypes.SerializedEnum
+		if err := json.Unmarshal([]byte(jsonStr), &se); err != nil {
+			return nil, err
+		}
+		enum := gstypes.NewEnum(se.ID, se.Name, reconstructTypeRef(se.Underlying, result))
+		enum.SetIotaExpr(se.IotaExpr)
+		for _, sv := range se.Values {
+			v := gstypes.NewConstant(sv.ID, sv.Name, enum, sv.Value)
+			restoreValue(v, sv, result)
+			enum.AddValue(v)
+		}
+		enum.AddMethods(deserializeMethods(se.Methods, enum, result)...)
+		t = enum
+
 	case gstypes.TypeKindPointer:
 		var sp gstypes.SerializedPointer
 		_ = json.Unmarshal([]byte(jsonStr), &sp)
@@ -224,7 +346,7 @@ func deserializeType(jsonStr string, result *ScanningResult) (gstypes.Type, erro
 		var sa gstypes.SerializedSlice
 		_ = json.Unmarshal([]byte(jsonStr), &sa)
 		elem := reconstructTypeRef(sa.Element, result)
-		t = gstypes.NewSlice(sa.ID, sa.Name, elem)
+		t = gstypes.NewArray(sa.ID, sa.Name, elem, sa.Length)
 
 	case gstypes.TypeKindMap:
 		var sm gstypes.SerializedMap
@@ -249,21 +371,24 @@ func deserializeType(jsonStr string, result *ScanningResult) (gstypes.Type, erro
 		var sf gstypes.SerializedFunction
 		_ = json.Unmarshal([]byte(jsonStr), &sf)
 		fn := gstypes.NewFunction(sf.ID, sf.Name)
+		fn.SetBodyFlags(sf.BodyFlags)
+		fn.SetLinkage(sf.Linkage)
 		// Add parameters
 		for _, param := range sf.Parameters {
-			paramType := reconstructTypeRef(param.Type, result)
-			fn.AddParameter(gstypes.NewParameter(param.Name, paramType, param.IsVariadic))
+			fn.AddParameter(deserializeParameter(param, result))
 		}
 		// Add results
 		for _, res := range sf.Results {
-			resType := reconstructTypeRef(res.Type, result)
-			fn.AddResult(gstypes.NewResult(res.Name, resType))
+			fn.AddResult(deserializeResult(res, result))
 		}
 		t = fn
 
 	case gstypes.TypeKindInterface:
 		var si gstypes.SerializedInterface
 		_ = json.Unmarshal([]byte(jsonStr), &si)
+		if si.Predeclared && si.ID == gstypes.AnyID {
+			return result.sharedAny(), nil
+		}
 		iface := gstypes.NewInterface(si.ID, si.Name)
 		// Add embeds
 		for _, embed := range si.Embeds {
@@ -275,26 +400,38 @@ func deserializeType(jsonStr string, result *ScanningResult) (gstypes.Type, erro
 		methods := make([]*gstypes.Method, 0)
 		for _, method := range si.Methods {
 			m := gstypes.NewMethod(method.ID, method.Name, iface, false)
+			m.SetPosition(method.Position)
 			// Add parameters
 			for _, param := range method.Parameters {
-				paramType := reconstructTypeRef(param.Type, result)
-				m.AddParameter(gstypes.NewParameter(param.Name, paramType, param.IsVariadic))
+				m.AddParameter(deserializeParameter(param, result))
 			}
 			// Add results
 			for _, res := range method.Results {
-				resType := reconstructTypeRef(res.Type, result)
-				m.AddResult(gstypes.NewResult(res.Name, resType))
+				m.AddResult(deserializeResult(res, result))
 			}
 			m.SetExported(method.Exported)
 			methods = append(methods, m)
 		}
 		iface.AddMethods(methods...)
+		iface.SetConstraintOnly(si.ConstraintOnly)
+		iface.SetImplementers(si.Implementers)
+		if si.TypeSet != nil {
+			typeSet := &gstypes.TypeSet{Methods: si.TypeSet.Methods}
+			for _, term := range si.TypeSet.Terms {
+				if termType := reconstructTypeRef(term.Type, result); termType != nil {
+					typeSet.Terms = append(typeSet.Terms, gstypes.NewUnionTerm(termType, term.Approximation))
+				}
+			}
+			iface.SetTypeSet(typeSet)
+		}
 		t = iface
 
 	case gstypes.TypeKindStruct:
 		var ss gstypes.SerializedStruct
 		_ = json.Unmarshal([]byte(jsonStr), &ss)
 		str := gstypes.NewStruct(ss.ID, ss.Name)
+		str.SetImplements(ss.Implements)
+		str.SetConstructors(ss.Constructors)
 		// Add embeds
 		for _, embed := range ss.Embeds {
 			if embedType := reconstructTypeRef(embed, result); embedType != nil {
@@ -302,29 +439,11 @@ func deserializeType(jsonStr string, result *ScanningResult) (gstypes.Type, erro
 			}
 		}
 		// Add fields
-		for _, field := range ss.Fields {
-			fieldType := reconstructTypeRef(field.Type, result)
-			f := gstypes.NewField(field.ID, field.Name, fieldType, field.Tag, field.IsEmbedded, str)
+		for _, f := range deserializeFields(ss.Fields, str, result) {
 			str.AddField(f)
 		}
 		// Add methods
-		methods := make([]*gstypes.Method, 0)
-		for _, method := range ss.Methods {
-			m := gstypes.NewMethod(method.ID, method.Name, str, method.IsPointerReceiver)
-			// Add parameters
-			for _, param := range method.Parameters {
-				paramType := reconstructTypeRef(param.Type, result)
-				m.AddParameter(gstypes.NewParameter(param.Name, paramType, param.IsVariadic))
-			}
-			// Add results
-			for _, res := range method.Results {
-				resType := reconstructTypeRef(res.Type, result)
-				m.AddResult(gstypes.NewResult(res.Name, resType))
-			}
-			m.SetExported(method.Exported)
-			methods = append(methods, m)
-		}
-		str.AddMethods(methods...)
+		str.AddMethods(deserializeMethods(ss.Methods, str, result)...)
 		t = str
 
 	case gstypes.TypeKindMethod:
@@ -332,15 +451,17 @@ func deserializeType(jsonStr string, result *ScanningResult) (gstypes.Type, erro
 		_ = json.Unmarshal([]byte(jsonStr), &sm)
 		receiver := reconstructTypeRef(sm.Receiver, result)
 		m := gstypes.NewMethod(sm.ID, sm.Name, receiver, sm.IsPointerReceiver)
+		m.SetPosition(sm.Position)
+		m.SetBodyFlags(sm.BodyFlags)
+		m.SetLinkage(sm.Linkage)
+		m.SetSatisfies(sm.Satisfies)
 		// Add parameters
 		for _, param := range sm.Parameters {
-			paramType := reconstructTypeRef(param.Type, result)
-			m.AddParameter(gstypes.NewParameter(param.Name, paramType, param.IsVariadic))
+			m.AddParameter(deserializeParameter(param, result))
 		}
 		// Add results
 		for _, res := range sm.Results {
-			resType := reconstructTypeRef(res.Type, result)
-			m.AddResult(gstypes.NewResult(res.Name, resType))
+			m.AddResult(deserializeResult(res, result))
 		}
 		m.SetExported(sm.Exported)
 		t = m
@@ -351,6 +472,9 @@ func deserializeType(jsonStr string, result *ScanningResult) (gstypes.Type, erro
 		parent := reconstructTypeRef(sf.Parent, result)
 		fieldType := reconstructTypeRef(sf.Type, result)
 		f := gstypes.NewField(sf.ID, sf.Name, fieldType, sf.Tag, sf.IsEmbedded, parent)
+		f.SetPosition(sf.Position)
+		f.SetBitWidth(sf.BitWidth)
+		f.SetIndex(sf.Index)
 		f.SetExported(sf.Exported)
 		t = f
 
@@ -361,14 +485,35 @@ func deserializeType(jsonStr string, result *ScanningResult) (gstypes.Type, erro
 		// Create instantiated generic type with type arguments
 		typeArgs := make([]gstypes.TypeArgument, 0)
 		for _, arg := range sig.TypeArgs {
-			argType := reconstructTypeRef(arg, result)
+			// Args are {param, index, type}, nested instantiations are serialized in full
+			argMap, ok := arg.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			argType := reconstructTypeRef(argMap["type"], result)
 			if argType != nil {
+				param, _ := argMap["param"].(string)
+				index, _ := argMap["index"].(float64)
 				typeArgs = append(typeArgs, gstypes.TypeArgument{
-					Type: argType,
+					Param: param,
+					Index: int(index),
+					Type:  argType,
 				})
 			}
 		}
-		t = gstypes.NewInstantiatedGeneric(sig.ID, sig.Name, origin, typeArgs)
+		ig := gstypes.NewInstantiatedGeneric(sig.ID, sig.Name, origin, typeArgs)
+		if sig.Fields != nil || sig.Methods != nil || sig.Embeds != nil || sig.Underlying != nil {
+			var embeds []gstypes.Type
+			for _, embed := range sig.Embeds {
+				if embedType := reconstructTypeRef(embed, result); embedType != nil {
+					embeds = append(embeds, embedType)
+				}
+			}
+			ig.SetEmbeds(embeds)
+			ig.SetUnderlying(reconstructTypeRef(sig.Underlying, result))
+			ig.SetMembers(deserializeFields(sig.Fields, ig, result), deserializeMethods(sig.Methods, ig, result))
+		}
+		t = ig
 
 	case gstypes.TypeKindTypeParameter:
 		var stp gstypes.SerializedTypeParameter
@@ -402,13 +547,86 @@ func deserializeType(jsonStr string, result *ScanningResult) (gstypes.Type, erro
 	if t != nil {
 		// Set common fields
 		t.SetExported(st.Exported)
+		t.SetDefined(st.Defined)
 		t.SetDistance(st.Distance)
+		t.SetFiles(st.Files)
+		t.SetPosition(st.Position)
+		t.SetUsedBy(st.UsedBy)
+		t.SetOpaque(st.Opaque)
+		for key, value := range st.Metadata {
+			t.SetMetadata(key, value)
+		}
+		t.SetMethodSets(st.ValueMethods, st.PointerMethods)
 		// Note: comments are not restored from cache to reduce cache size
 	}
 
 	return t, nil
 }
 
+// deserializeMethods reconstructs the methods of receiver
+func deserializeMethods(methods []*gstypes.SerializedMethod, receiver gstypes.Type, result *ScanningResult) []*gstypes.Method {
+	res := make([]*gstypes.Method, 0, len(methods))
+	for _, method := range methods {
+		m := gstypes.NewMethod(method.ID, method.Name, receiver, method.IsPointerReceiver)
+		m.SetPosition(method.Position)
+		m.SetBodyFlags(method.BodyFlags)
+		m.SetLinkage(method.Linkage)
+		m.SetSatisfies(method.Satisfies)
+		if method.PromotedFrom != "" {
+			m.SetPromotedFrom(reconstructTypeRef(method.PromotedFrom, result))
+		}
+		for _, param := range method.Parameters {
+			m.AddParameter(deserializeParameter(param, result))
+		}
+		for _, r := range method.Results {
+			m.AddResult(deserializeResult(r, result))
+		}
+		m.SetExported(method.Exported)
+		res = append(res, m)
+	}
+	return res
+}
+
+// deserializeFields reconstructs the fields of a struct or instantiated struct
+func deserializeFields(fields []*gstypes.SerializedField, parent gstypes.Type, result *ScanningResult) []*gstypes.Field {
+	res := make([]*gstypes.Field, 0, len(fields))
+	for _, field := range fields {
+		fieldType := reconstructTypeRef(field.Type, result)
+		f := gstypes.NewField(field.ID, field.Name, fieldType, field.Tag, field.IsEmbedded, parent)
+		f.SetPosition(field.Position)
+		f.SetBitWidth(field.BitWidth)
+		f.SetIndex(field.Index)
+		if field.PromotedFrom != "" {
+			f.SetPromotedFrom(reconstructTypeRef(field.PromotedFrom, result))
+		}
+		res = append(res, f)
+	}
+	return res
+}
+
+// deserializeParameter reconstructs a parameter of a function or method
+func deserializeParameter(sp *gstypes.SerializedParameter, result *ScanningResult) *gstypes.Parameter {
+	p := gstypes.NewParameter(sp.Name, reconstructTypeRef(sp.Type, result), sp.IsVariadic)
+	p.SetPosition(sp.Position)
+	return p
+}
+
+// deserializeResult reconstructs a result of a function or method
+func deserializeResult(sr *gstypes.SerializedResult, result *ScanningResult) *gstypes.Result {
+	r := gstypes.NewResult(sr.Name, reconstructTypeRef(sr.Type, result))
+	r.SetPosition(sr.Position)
+	return r
+}
+
+// joinComments joins serialized comment texts back into a doc string
+func joinComments(comments []gstypes.Comment) string {
+	texts := make([]string, 0, len(comments))
+	for _, c := range comments {
+		texts = append(texts, c.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
 // reconstructTypeRef reconstructs a type reference from serialized data
 func reconstructTypeRef(data interface{}, result *ScanningResult) gstypes.Type {
 	if data == nil {
@@ -454,18 +672,47 @@ func deserializeValue(jsonStr string, result *ScanningResult) (*gstypes.Value, e
 
 	valueType := reconstructTypeRef(sv.ValueType, result)
 	v := gstypes.NewVariable(sv.ID, sv.Name, valueType)
-	v.SetExported(sv.Exported)
+	v.SetValue(sv.Value)
+	if sv.Kind == gstypes.TypeKindConstant {
+		v = gstypes.NewConstant(sv.ID, sv.Name, valueType, sv.Value)
+	}
+	restoreValue(v, &sv, result)
+	if sv.Parent != "" {
+		v.SetParent(reconstructTypeRef(sv.Parent, result))
+	}
 
 	return v, nil
 }
 
+// restoreValue sets the visibility, label, position, package and comments of a cached value
+func restoreValue(v *gstypes.Value, sv *gstypes.SerializedValue, result *ScanningResult) {
+	v.SetExported(sv.Exported)
+	v.SetLabel(sv.Label)
+	if sv.Iota != nil {
+		v.SetExpr(sv.Expr, *sv.Iota)
+	} else {
+		v.SetExpr(sv.Expr, -1)
+	}
+	v.SetPosition(sv.Position)
+	if pkg, ok := result.Packages.Get(sv.Package); ok {
+		v.SetPackage(pkg)
+	}
+	if len(sv.Comments) > 0 {
+		// Same doc.Type wrapper the resolver uses for values
+		v.SetDoc(&doc.Type{Doc: joinComments(sv.Comments)})
+	}
+}
+
 // deserializePackage reconstructs a Package from JSON bytes
 func deserializePackage(jsonStr string, result *ScanningResult) (*gstypes.Package, error) {
 	var pkgData struct {
-		Path string   `json:"path"`
-		Name string   `json:"name"`
-		Doc  string   `json:"doc,omitempty"`
-		Docs []string `json:"docs,omitempty"`
+		Path      string                    `json:"path"`
+		Name      string                    `json:"name"`
+		Doc       string                    `json:"doc,omitempty"`
+		Docs      []string                  `json:"docs,omitempty"`
+		Distance  int                       `json:"distance,omitempty"`
+		Module    *gstypes.SerializedModule `json:"module,omitempty"`
+		InitFuncs []*gstypes.Position       `json:"initFuncs,omitempty"`
 	}
 
 	if err := json.Unmarshal([]byte(jsonStr), &pkgData); err != nil {
@@ -473,6 +720,13 @@ func deserializePackage(jsonStr string, result *ScanningResult) (*gstypes.Packag
 	}
 
 	pkg := gstypes.NewPackage(pkgData.Path, pkgData.Name, nil)
+	pkg.SetDistance(pkgData.Distance)
+	if pkgData.Module != nil {
+		pkg.SetModule(gstypes.NewModule(pkgData.Module.Path, pkgData.Module.Version))
+	}
+	for _, pos := range pkgData.InitFuncs {
+		pkg.AddInitFunc(pos)
+	}
 	return pkg, nil
 }
 
@@ -491,9 +745,9 @@ func IsCacheValid(filename string) bool {
 		return false
 	}
 
-	// Try to actually validate the cache format
-	_, err = ReadCache(filename)
-	return err == nil
+	// Try to actually validate the cache format, the caches of cancelled scans are incomplete
+	result, err := ReadCache(filename)
+	return err == nil && !result.Partial()
 }
 
 // CacheAge returns the age of a cache file in seconds
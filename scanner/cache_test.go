@@ -1,9 +1,16 @@
 package scanner
 
 import (
+	"encoding/json"
+	"fmt"
+	"go/constant"
+	"go/doc"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	gstypes "github.com/pablor21/goscanner/types"
 )
 
 // TestCacheRoundtrip tests that we can write and read cache without losing data
@@ -351,3 +358,220 @@ func BenchmarkCacheRead(b *testing.B) {
 		}
 	}
 }
+
+// TestCacheEnumRoundtrip tests that enums keep their values, methods and iota expression
+func TestCacheEnumRoundtrip(t *testing.T) {
+	result := NewScanningResult()
+	pkg := gstypes.NewPackage("test", "test", nil)
+	result.Packages.Set("test", pkg)
+
+	intType := gstypes.NewBasic("int", "int")
+	enum := gstypes.NewEnum("test.Kind", "Kind", intType)
+	enum.SetPackage(pkg)
+	enum.SetIotaExpr("iota + 1")
+	for i, name := range []string{"KindA", "KindB"} {
+		v := gstypes.NewConstant("test."+name, name, enum, constant.MakeInt64(int64(i+1)))
+		v.SetPackage(pkg)
+		v.SetDoc(&doc.Type{Doc: name + " docs"})
+		if err := v.Load(); err != nil {
+			t.Fatal(err)
+		}
+		enum.AddValue(v)
+	}
+	method := gstypes.NewMethod("test.Kind.String", "String", enum, false)
+	method.AddResult(gstypes.NewResult("", gstypes.NewBasic("string", "string")))
+	enum.AddMethods(method)
+	result.Types.Set(enum.Id(), enum)
+
+	cacheFile := filepath.Join(t.TempDir(), "enum.cache")
+	if err := result.ToCache(cacheFile); err != nil {
+		t.Fatalf("Failed to write cache: %v", err)
+	}
+	cachedResult, err := ReadCache(cacheFile)
+	if err != nil {
+		t.Fatalf("Failed to read cache: %v", err)
+	}
+
+	cached, ok := cachedResult.Types.Get("test.Kind")
+	if !ok {
+		t.Fatal("enum not found in cached result")
+	}
+	cachedEnum, ok := cached.(*gstypes.Enum)
+	if !ok {
+		t.Fatalf("expected *Enum, got %T", cached)
+	}
+	if cachedEnum.IotaExpr() != "iota + 1" {
+		t.Errorf("IotaExpr() = %q, want %q", cachedEnum.IotaExpr(), "iota + 1")
+	}
+	if cachedEnum.Underlying() == nil || cachedEnum.Underlying().Id() != "int" {
+		t.Errorf("underlying type not restored: %v", cachedEnum.Underlying())
+	}
+	if methods := cachedEnum.Methods(); len(methods) != 1 || methods[0].Name() != "String" || len(methods[0].Results()) != 1 {
+		t.Errorf("methods not restored: %v", methods)
+	}
+
+	values := cachedEnum.Values()
+	if len(values) != 2 {
+		t.Fatalf("got %d values, want 2", len(values))
+	}
+	for i, name := range []string{"KindA", "KindB"} {
+		v := values[i]
+		if v.Name() != name || v.Parent() != cachedEnum {
+			t.Errorf("value %d = %s (parent %v), want %s", i, v.Name(), v.Parent(), name)
+		}
+		if v.Package() == nil || v.Package().Path() != "test" {
+			t.Errorf("value %s package not restored", name)
+		}
+		if err := v.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if comments := v.Comments(); len(comments) != 1 || comments[0].Text != name+" docs" {
+			t.Errorf("value %s comments = %v, want %q", name, comments, name+" docs")
+		}
+	}
+}
+
+// TestEnumValueShape tests duplicate and gap detection of integer enums, scanned and cached
+func TestEnumValueShape(t *testing.T) {
+	newEnum := func(name string, values ...constant.Value) *gstypes.Enum {
+		enum := gstypes.NewEnum("test."+name, name, gstypes.NewBasic("int", "int"))
+		for i, value := range values {
+			id := fmt.Sprintf("test.%s%d", name, i)
+			enum.AddValue(gstypes.NewConstant(id, id, enum, value))
+		}
+		return enum
+	}
+
+	tests := []struct {
+		enum       *gstypes.Enum
+		duplicates bool
+		contiguous bool
+	}{
+		{newEnum("Iota", constant.MakeInt64(0), constant.MakeInt64(1), constant.MakeInt64(2)), false, true},
+		{newEnum("Shifted", constant.MakeInt64(7), constant.MakeInt64(5), constant.MakeInt64(6)), false, true},
+		{newEnum("Alias", constant.MakeInt64(0), constant.MakeInt64(1), constant.MakeInt64(1)), true, true},
+		{newEnum("Gap", constant.MakeInt64(1), constant.MakeInt64(2), constant.MakeInt64(4)), false, false},
+		{newEnum("Flags", constant.MakeInt64(1), constant.MakeInt64(2), constant.MakeInt64(4), constant.MakeInt64(4)), true, false},
+		{newEnum("Strings", constant.MakeString("a"), constant.MakeString("a")), false, false},
+		{newEnum("Empty"), false, false},
+	}
+
+	result := NewScanningResult()
+	for _, tt := range tests {
+		if got := tt.enum.HasDuplicateValues(); got != tt.duplicates {
+			t.Errorf("%s.HasDuplicateValues() = %v, want %v", tt.enum.Name(), got, tt.duplicates)
+		}
+		if got := tt.enum.IsContiguous(); got != tt.contiguous {
+			t.Errorf("%s.IsContiguous() = %v, want %v", tt.enum.Name(), got, tt.contiguous)
+		}
+		result.Types.Set(tt.enum.Id(), tt.enum)
+	}
+
+	// Values read from a cache are JSON numbers, the shape must not change
+	cacheFile := filepath.Join(t.TempDir(), "enums.cache")
+	if err := result.ToCache(cacheFile); err != nil {
+		t.Fatalf("Failed to write cache: %v", err)
+	}
+	cachedResult, err := ReadCache(cacheFile)
+	if err != nil {
+		t.Fatalf("Failed to read cache: %v", err)
+	}
+	for _, tt := range tests[:5] {
+		cached, _ := cachedResult.Types.Get(tt.enum.Id())
+		enum, ok := cached.(*gstypes.Enum)
+		if !ok {
+			t.Fatalf("expected cached *Enum for %s, got %T", tt.enum.Id(), cached)
+		}
+		if enum.HasDuplicateValues() != tt.duplicates || enum.IsContiguous() != tt.contiguous {
+			t.Errorf("cached %s: duplicates %v contiguous %v, want %v %v",
+				tt.enum.Name(), enum.HasDuplicateValues(), enum.IsContiguous(), tt.duplicates, tt.contiguous)
+		}
+	}
+}
+
+func TestEnumFromIota(t *testing.T) {
+	kind := gstypes.NewEnum("test.Kind", "Kind", gstypes.NewBasic("int", "int"))
+	kind.SetIotaExpr("iota + 1")
+	kind.AddValue(gstypes.NewConstant("test.KindA", "KindA", kind, constant.MakeInt64(1)))
+
+	// String constants sharing the type, without iota
+	mode := gstypes.NewEnum("test.Mode", "Mode", gstypes.NewBasic("string", "string"))
+	mode.AddValue(gstypes.NewConstant("test.ModeRead", "ModeRead", mode, constant.MakeString("r")))
+	mode.AddValue(gstypes.NewConstant("test.ModeWrite", "ModeWrite", mode, constant.MakeString("w")))
+
+	result := NewScanningResult()
+	result.Types.Set(kind.Id(), kind)
+	result.Types.Set(mode.Id(), mode)
+
+	cacheFile := filepath.Join(t.TempDir(), "enums.cache")
+	if err := result.ToCache(cacheFile); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := ReadCache(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]bool{"test.Kind": true, "test.Mode": false} {
+		for _, col := range []*ScanningResult{result, cached} {
+			typ, _ := col.Types.Get(id)
+			enum := typ.(*gstypes.Enum)
+			if enum.FromIota() != want || enum.Serialize().(*gstypes.SerializedEnum).FromIota != want {
+				t.Errorf("%s.FromIota() = %v, want %v", id, enum.FromIota(), want)
+			}
+		}
+	}
+}
+
+// TestCacheFromCache tests that restored types are told apart from scanned ones
+func TestCacheFromCache(t *testing.T) {
+	result := scanTestSource(t, `
+	package test
+
+	type User struct {
+		Friends []*User
+	}
+
+	const Admin = "admin"
+	`)
+	if err := result.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+	cacheFile := filepath.Join(t.TempDir(), "test.cache")
+	if err := WriteCache(cacheFile, result); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := ReadCache(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, typ := range result.Types.Values() {
+		if typ.FromCache() {
+			t.Errorf("scanned type %s is flagged as from cache", typ.Id())
+		}
+	}
+	for _, typ := range cached.Types.Values() {
+		if !typ.FromCache() {
+			t.Errorf("restored type %s isn't flagged as from cache", typ.Id())
+		}
+	}
+	if v, ok := cached.Values.Get("test.Admin"); !ok || !v.FromCache() {
+		t.Errorf("restored value Admin isn't flagged as from cache")
+	}
+
+	user, ok := cached.Types.Get("test.User")
+	if !ok {
+		t.Fatal("expected test.User in the cached result")
+	}
+	friends := user.(*gstypes.Struct).Fields()[0].Type()
+	if !friends.FromCache() || !friends.(*gstypes.Slice).Elem().FromCache() {
+		t.Errorf("types nested in a restored type aren't flagged as from cache")
+	}
+	data, err := json.Marshal(user.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"fromCache":true`) {
+		t.Errorf("serialized restored type doesn't have fromCache: %s", data)
+	}
+}
@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Changes lists the ids of the types and values that differ between two scans
+type Changes struct {
+	Added    []string `json:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+	Modified []string `json:"modified,omitempty"`
+}
+
+// IsEmpty reports whether nothing changed
+func (c Changes) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// ScanChangedSince scans with cfg and compares the result with the one cached at cachePath.
+// It returns the new full result and the types and values added, removed or modified since
+// the cache was written. A missing cache reports everything as added. The cache is not
+// updated, call ToCache on the result to move the baseline.
+func ScanChangedSince(cachePath string, cfg *Config) (*ScanningResult, Changes, error) {
+	previous := map[string]any{}
+	if _, err := os.Stat(cachePath); err == nil {
+		cache, err := readCacheFile(cachePath)
+		if err != nil {
+			return nil, Changes{}, err
+		}
+		previous = cache.Result
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, Changes{}, &CacheError{Path: cachePath, Op: "read", Err: err}
+	}
+
+	result, err := NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		return nil, Changes{}, err
+	}
+	if err := result.EnsureFullyLoaded(); err != nil {
+		return nil, Changes{}, err
+	}
+
+	// Compare the same representation: the serialized JSON written to caches
+	current, err := serializeGeneric(result)
+	if err != nil {
+		return nil, Changes{}, err
+	}
+
+	return result, diffSerialized(previous, current), nil
+}
+
+// diffSerialized compares the "types" and "values" sections of two serialized results
+func diffSerialized(previous, current map[string]any) Changes {
+	var changes Changes
+	for _, section := range []string{"types", "values"} {
+		prev, _ := previous[section].(map[string]any)
+		curr, _ := current[section].(map[string]any)
+
+		for id, entry := range curr {
+			old, ok := prev[id]
+			if !ok {
+				changes.Added = append(changes.Added, id)
+				continue
+			}
+			if !bytes.Equal(canonicalJSON(old), canonicalJSON(entry)) {
+				changes.Modified = append(changes.Modified, id)
+			}
+		}
+		for id := range prev {
+			if _, ok := curr[id]; !ok {
+				changes.Removed = append(changes.Removed, id)
+			}
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Modified)
+	return changes
+}
+
+// canonicalJSON encodes a serialized entry with the generated ids of unnamed types blanked,
+// they depend on the resolution order and would report unchanged types as modified
+func canonicalJSON(entry any) []byte {
+	data, _ := json.Marshal(blankUnnamedIDs(entry))
+	return data
+}
+
+func blankUnnamedIDs(node any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for key, child := range v {
+			res[key] = blankUnnamedIDs(child)
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, child := range v {
+			res[i] = blankUnnamedIDs(child)
+		}
+		return res
+	case string:
+		if strings.HasPrefix(v, "__unnamed_") {
+			return ""
+		}
+	}
+	return node
+}
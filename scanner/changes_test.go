@@ -0,0 +1,291 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScanChangedSince(t *testing.T) {
+	dir := t.TempDir()
+	write := func(src string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "shop.go"), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n\ngo 1.22\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	write(`package shop
+
+type Item struct {
+	Name  string
+	Price int
+}
+
+type Cart struct {
+	Items []Item
+}
+
+type Coupon struct {
+	Code string
+}
+`)
+
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./..."}
+	cfg.ModuleDirs = []string{dir}
+	cachePath := filepath.Join(t.TempDir(), "cache.json.gz")
+
+	// Without a cache everything is new
+	result, changes, err := ScanChangedSince(cachePath, cfg)
+	if err != nil {
+		t.Fatalf("ScanChangedSince() error = %v", err)
+	}
+	if len(changes.Added) == 0 || len(changes.Removed) != 0 || len(changes.Modified) != 0 {
+		t.Fatalf("first scan changes = %+v, want only additions", changes)
+	}
+	if err := result.ToCache(cachePath); err != nil {
+		t.Fatalf("ToCache() error = %v", err)
+	}
+
+	// Rescanning the same sources reports no change
+	if _, changes, err = ScanChangedSince(cachePath, cfg); err != nil {
+		t.Fatalf("ScanChangedSince() error = %v", err)
+	}
+	if !changes.IsEmpty() {
+		t.Fatalf("unchanged sources reported %+v", changes)
+	}
+
+	write(`package shop
+
+type Item struct {
+	Name  string
+	Price float64
+}
+
+type Cart struct {
+	Items []Item
+}
+
+type Order struct {
+	Cart Cart
+}
+`)
+	if _, changes, err = ScanChangedSince(cachePath, cfg); err != nil {
+		t.Fatalf("ScanChangedSince() error = %v", err)
+	}
+	want := Changes{
+		Added:    []string{"example.com/shop.Order"},
+		Removed:  []string{"example.com/shop.Coupon"},
+		Modified: []string{"example.com/shop.Item"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	serialize := func(src string) map[string]any {
+		t.Helper()
+		tree, err := serializeGeneric(scanTestSource(t, src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tree
+	}
+	old := serialize(`package testpkg
+
+// User is documented
+type User struct {
+	ID    int
+	Name  string ` + "`json:\"name\"`" + `
+	Email string
+	notes string
+}
+
+func (u *User) Save() error { return nil }
+func (u User) Label() string { return u.Name }
+
+type Status int
+
+const Active Status = 1
+
+func Find(id int) *User { return nil }
+
+type Legacy struct{}
+`)
+	current := serialize(`package testpkg
+
+// User has another doc, and moved
+type User struct {
+	ID    int64
+	Name  string ` + "`json:\"fullName\"`" + `
+	Phone string
+	other bool
+}
+
+func (u *User) Save(force bool) error { return nil }
+func (u User) Label() string { return u.Name }
+
+type Status int
+
+const Active Status = 2
+
+func Find(id int) *User { return nil }
+
+type Account struct{}
+`)
+
+	diff := DiffResults(old, current, DiffOptions{})
+	if !reflect.DeepEqual(diff.Added, []string{"test.Account"}) {
+		t.Errorf("Added = %v", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"test.Legacy"}) {
+		t.Errorf("Removed = %v", diff.Removed)
+	}
+
+	changed := map[string][]MemberChange{}
+	for _, decl := range diff.Changed {
+		for _, c := range decl.Changes {
+			changed[decl.ID] = append(changed[decl.ID], *c)
+		}
+	}
+	want := map[string][]MemberChange{
+		"test.User": {
+			{Kind: "field", Name: "Email", Change: ChangeRemoved, Old: "string"},
+			{Kind: "field", Name: "ID", Change: ChangeChanged, Old: "int", New: "int64"},
+			{Kind: "field", Name: "Name", Change: ChangeChanged, Old: "string `json:\"name\"`", New: "string `json:\"fullName\"`"},
+			{Kind: "field", Name: "Phone", Change: ChangeAdded, New: "string"},
+			{Kind: "method", Name: "Save", Change: ChangeChanged, Old: "(*User) Save() error", New: "(*User) Save(force bool) error"},
+		},
+		"test.Active": {
+			{Kind: "value", Change: ChangeChanged, Old: "1", New: "2"},
+		},
+	}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("Changed = %+v\nwant %+v", changed, want)
+	}
+
+	// Unexported members are compared on request
+	diff = DiffResults(old, current, DiffOptions{IncludeUnexported: true})
+	members := map[string]bool{}
+	for _, decl := range diff.Changed {
+		if decl.ID == "test.User" {
+			for _, c := range decl.Changes {
+				members[c.Change+" "+c.Name] = true
+			}
+		}
+	}
+	if !members["added other"] || !members["removed notes"] {
+		t.Errorf("unexported changes = %v", members)
+	}
+
+	if diff := DiffResults(old, old, DiffOptions{}); !diff.IsEmpty() {
+		t.Errorf("a result compared with itself differs: %+v", diff)
+	}
+}
+
+func TestResultDiff_Compatibility(t *testing.T) {
+	serialize := func(src string) map[string]any {
+		t.Helper()
+		tree, err := serializeGeneric(scanTestSource(t, src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tree
+	}
+	base := serialize(`package testpkg
+
+type User struct {
+	ID   int
+	Name string
+}
+
+type Store interface {
+	Get(id int) User
+}
+
+func Find(id int) User { return User{} }
+`)
+
+	tests := []struct {
+		name     string
+		src      string
+		bump     string
+		breaking []string
+	}{
+		{"unchanged", `package testpkg
+
+type User struct {
+	ID   int
+	Name string
+}
+
+type Store interface {
+	Get(id int) User
+}
+
+func Find(id int) User { return User{} }
+`, BumpNone, nil},
+		{"additions", `package testpkg
+
+type User struct {
+	ID    int
+	Name  string
+	Email string
+}
+
+func (u User) Valid() bool { return true }
+
+type Store interface {
+	Get(id int) User
+}
+
+func Find(id int) User { return User{} }
+
+func Count() int { return 0 }
+`, BumpMinor, nil},
+		{"breaking", `package testpkg
+
+type User struct {
+	ID int64
+}
+
+type Store interface {
+	Get(id int) User
+	Put(u User) error
+}
+`, BumpMajor, []string{
+			"test.Find: declaration removed",
+			"test.Store.Put: method added",
+			"test.User.ID: field type or tag changed",
+			"test.User.Name: field removed",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := DiffResults(base, serialize(tt.src), DiffOptions{}).Compatibility()
+			if report.Bump != tt.bump {
+				t.Errorf("Bump = %s, want %s", report.Bump, tt.bump)
+			}
+			var breaking []string
+			for _, c := range report.Breaking {
+				id := c.ID
+				if c.Change != nil && c.Change.Name != "" {
+					id += "." + c.Change.Name
+				}
+				breaking = append(breaking, id+": "+c.Reason)
+			}
+			if !reflect.DeepEqual(breaking, tt.breaking) {
+				t.Errorf("Breaking = %v, want %v", breaking, tt.breaking)
+			}
+			if report.IsBreaking() != (tt.bump == BumpMajor) {
+				t.Errorf("IsBreaking() = %v with bump %s", report.IsBreaking(), report.Bump)
+			}
+		})
+	}
+}
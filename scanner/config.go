@@ -8,20 +8,24 @@ import (
 	"strings"
 
 	"github.com/pablor21/goscanner/logger"
+	gstypes "github.com/pablor21/goscanner/types"
 )
 
 type ScanMode uint16
 
 const (
-	ScanModeNone      ScanMode = 0
-	ScanModeTypes     ScanMode = 1 << iota // Basic type information
-	ScanModeMethods                        // Include methods
-	ScanModeFields                         // Include struct fields
-	ScanModeFunctions                      // Include standalone functions
-	ScanModeDocs                           // Include documentation
-	ScanModeComments                       // Parse and extract comments
-	ScanModeConsts                         // Include constants
-	ScanModeVariables                      // Include variables
+	ScanModeNone           ScanMode = 0
+	ScanModeTypes          ScanMode = 1 << iota // Basic type information
+	ScanModeMethods                             // Include methods
+	ScanModeFields                              // Include struct fields
+	ScanModeFunctions                           // Include standalone functions
+	ScanModeDocs                                // Include documentation
+	ScanModeComments                            // Parse and extract comments
+	ScanModeConsts                              // Include constants
+	ScanModeVariables                           // Include variables
+	ScanModeFunctionBodies                      // Inspect function and method bodies (opt-in, not part of full)
+	ScanModeUsages                              // Record where named types are referenced (opt-in, not part of full)
+	ScanModeEnums                               // Make enums of the named basic types with constants (opt-in, not part of full), their values need ScanModeConsts
 
 	// Predefined combinations
 	ScanModeBasic   = ScanModeTypes | ScanModeDocs
@@ -66,6 +70,13 @@ func (m ScanMode) FromString(str string) ScanMode {
 			m |= ScanModeConsts
 		case "variables", "vars":
 			m |= ScanModeVariables
+		case "function_bodies", "bodies":
+			m |= ScanModeFunctionBodies
+		case "usages", "used_by":
+			m |= ScanModeUsages
+		case "enums":
+			// Enum values are the constants of their type
+			m |= ScanModeEnums | ScanModeConsts
 		default:
 			panic("unknown scan mode " + v)
 		}
@@ -105,6 +116,15 @@ func (m ScanMode) MarshalJSON() ([]byte, error) {
 	if m.Has(ScanModeVariables) {
 		parts = append(parts, "variables")
 	}
+	if m.Has(ScanModeFunctionBodies) {
+		parts = append(parts, "function_bodies")
+	}
+	if m.Has(ScanModeUsages) {
+		parts = append(parts, "usages")
+	}
+	if m.Has(ScanModeEnums) {
+		parts = append(parts, "enums")
+	}
 	str := strings.Join(parts, ",")
 	return []byte(`"` + str + `"`), nil
 }
@@ -180,21 +200,110 @@ const (
 )
 
 type ExternalPackagesOptions struct {
-	ScanMode    ScanMode           `json:"scan_mode" yaml:"scan_mode"`
-	ParseFiles  bool               `json:"parse_files" yaml:"parse_files"`
-	Visibility  VisibilityLevel    `json:"visibility" yaml:"visibility"`
-	Packages    []string           `json:"packages" yaml:"packages"`
-	MaxDistance int                `json:"max_distance" yaml:"max_distance"`
+	ScanMode   ScanMode        `json:"scan_mode" yaml:"scan_mode"`
+	ParseFiles bool            `json:"parse_files" yaml:"parse_files"`
+	Visibility VisibilityLevel `json:"visibility" yaml:"visibility"`
+	Packages   []string        `json:"packages" yaml:"packages"`
+	// Deprecated: use Config.MaxDistance. It's still applied when Config.MaxDistance isn't set.
+	MaxDistance int                `json:"max_distance,omitempty" yaml:"max_distance,omitempty"`
 	OutOfScope  OutOfScopeHandling `json:"out_of_scope" yaml:"out_of_scope"`
 }
 
 type Config struct {
+	// Packages are the package patterns to scan: paths, directories and go list wildcards
+	// ("./...", "github.com/foo/bar/..."). Patterns prefixed with "!" leave out the packages
+	// they match ("!./internal/..."), see GlobScanner.ScanPackages.
 	Packages                []string                 `json:"packages" yaml:"packages"`
 	ScanMode                ScanMode                 `json:"scan_mode" yaml:"scan_mode"`
 	Visibility              VisibilityLevel          `json:"visibility" yaml:"visibility"`
 	ExternalPackagesOptions *ExternalPackagesOptions `json:"external_packages_options,omitempty" yaml:"external_packages_options,omitempty"`
 	LogLevel                logger.LogLevel          `json:"log_level" yaml:"log_level"`
 	MaxConcurrency          int                      `json:"max_concurrency" yaml:"max_concurrency"`
+	// ModuleDirs are module roots loaded each in its own module context, results are merged.
+	// Packages patterns are resolved relative to each of them. Empty means the working directory.
+	ModuleDirs []string `json:"module_dirs,omitempty" yaml:"module_dirs,omitempty"`
+	// ExcludeMethods are regular expressions dropping methods of concrete types (interface
+	// methods are kept). Each must fully match the method name ("String"), "Type.Method"
+	// or the qualified "pkg/path.Type.Method".
+	ExcludeMethods []string `json:"exclude_methods,omitempty" yaml:"exclude_methods,omitempty"`
+	// IncludeMethods are regular expressions like ExcludeMethods keeping only the methods of
+	// concrete types matching one of them. Empty keeps every method.
+	IncludeMethods []string `json:"include_methods,omitempty" yaml:"include_methods,omitempty"`
+	// IncludeTypes and ExcludeTypes are regular expressions selecting the named types of the
+	// result. Each must fully match the type name ("User") or its id ("pkg/path.User"). A type
+	// is kept when it matches no exclude pattern and, if there are include patterns, one of
+	// them. The declarations of the other types (and their constants) aren't processed, and
+	// the ones resolved as references are left out of ScanningResult.Types like with
+	// TypeFilter. Functions aren't filtered.
+	IncludeTypes []string `json:"include_types,omitempty" yaml:"include_types,omitempty"`
+	ExcludeTypes []string `json:"exclude_types,omitempty" yaml:"exclude_types,omitempty"`
+	// IncludePackages and ExcludePackages select the packages by path the same way: the
+	// declarations of the other packages aren't processed and their named types are left out
+	// of ScanningResult.Types.
+	IncludePackages []string `json:"include_packages,omitempty" yaml:"include_packages,omitempty"`
+	ExcludePackages []string `json:"exclude_packages,omitempty" yaml:"exclude_packages,omitempty"`
+	// IncludeFields and ExcludeFields select the struct fields the same way, matching the
+	// field name ("ID"), "Type.Field" or "pkg/path.Type.Field". They apply before FieldFilter.
+	IncludeFields []string `json:"include_fields,omitempty" yaml:"include_fields,omitempty"`
+	ExcludeFields []string `json:"exclude_fields,omitempty" yaml:"exclude_fields,omitempty"`
+	// IncludePositions captures the line, column and end line of the declarations of types,
+	// fields, methods, parameters, results, constants and variables, and the position of
+	// comments. Off by default, computing positions has a cost that batch scans not needing
+	// source locations can skip.
+	IncludePositions bool `json:"include_positions,omitempty" yaml:"include_positions,omitempty"`
+	// IncludeInitializers captures the initializer expressions of package level variables
+	// (Value.Expr), and their value when it's constant (var Timeout = 30 * time.Second), so
+	// the default values of configuration variables can be read.
+	IncludeInitializers bool `json:"include_initializers,omitempty" yaml:"include_initializers,omitempty"`
+	// MaxDistance is the number of package hops from the scanned packages up to which named
+	// types are fully resolved. The ones of farther packages are emitted as opaque references:
+	// id, kind, package and distance, without fields, methods or embeds, and their package files
+	// aren't parsed. 0 means no limit. It replaces ExternalPackagesOptions.MaxDistance, still
+	// read when this one is 0 so existing configurations keep working.
+	MaxDistance int `json:"max_distance,omitempty" yaml:"max_distance,omitempty"`
+	// ComputeImplements records interface satisfaction after the scan, see
+	// ScanningResult.ComputeImplements
+	ComputeImplements bool `json:"compute_implements,omitempty" yaml:"compute_implements,omitempty"`
+	// MarkConstraintOnly flags the interfaces only used as type parameter constraints after
+	// the scan, see ScanningResult.MarkConstraintOnly
+	MarkConstraintOnly bool `json:"mark_constraint_only,omitempty" yaml:"mark_constraint_only,omitempty"`
+	// LintTags checks the struct tags of the scanned packages once the scan is done, see
+	// ScanningResult.LintTags and Diagnostics
+	LintTags bool `json:"lint_tags,omitempty" yaml:"lint_tags,omitempty"`
+	// Annotations declare comment annotations parsed after the scan along with the registered
+	// ones (RegisterAnnotation), see ScanningResult.ParseAnnotations. StrictAnnotations fails
+	// the scan with an *AnnotationError when some don't match their schema.
+	Annotations       []*AnnotationSchema `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	StrictAnnotations bool                `json:"strict_annotations,omitempty" yaml:"strict_annotations,omitempty"`
+	// Deterministic processes packages and loads types sequentially in sorted order, so the
+	// counter based ids of unnamed types (__unnamed_slice__3__) are stable across scans of
+	// unchanged code. It trades the parallelism of the scan for reproducible output.
+	Deterministic bool `json:"deterministic,omitempty" yaml:"deterministic,omitempty"`
+	// FailOnPackageErrors fails the scan with a *PackageLoadError when a scanned package has
+	// errors (it doesn't compile). By default they're recorded as warnings of the result and
+	// the package is scanned as far as it could be type checked. ResolveSingle always fails.
+	FailOnPackageErrors bool `json:"fail_on_package_errors,omitempty" yaml:"fail_on_package_errors,omitempty"`
+	// SkipGenerated excludes the declarations of generated files (with the standard
+	// "// Code generated ... DO NOT EDIT." header). Generated types referenced by other
+	// declarations are still resolved.
+	SkipGenerated bool `json:"skip_generated,omitempty" yaml:"skip_generated,omitempty"`
+	// AuxiliaryPackages are package patterns scanned for reference only: they're loaded (test
+	// packages included) and processed like the scanned packages, but their declarations are
+	// left out of the result unless referenced from a scanned package.
+	AuxiliaryPackages []string `json:"auxiliary_packages,omitempty" yaml:"auxiliary_packages,omitempty"`
+	// BitWidthTag is the struct tag key holding the number of bits a field is packed in
+	// (bits:"4" with "bits"), recorded as Field.BitWidth. Empty disables it.
+	BitWidthTag string `json:"bit_width_tag,omitempty" yaml:"bit_width_tag,omitempty"`
+	// FieldFilter is called for every struct field passing the visibility checks, fields it
+	// returns false for are dropped (e.g. the ones tagged json:"-"). The field is complete
+	// but its type may not be loaded yet. It may be called concurrently. Nil keeps every field.
+	FieldFilter func(f *gstypes.Field) bool `json:"-" yaml:"-"`
+	// TypeFilter is called once the scan is done for every resolved and loaded type, the ones
+	// it returns false for are left out of ScanningResult.Types. Unlike the options matching
+	// ids it sees the complete type: kind, package, comments, fields... Dropped types are still
+	// resolved and referenced by the kept ones (PreloadAll adds the referenced ones back).
+	// Nil keeps every type.
+	TypeFilter func(t gstypes.Type) bool `json:"-" yaml:"-"`
 }
 
 func NewDefaultConfig() *Config {
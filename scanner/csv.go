@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// csvHeader are the columns written by EmitCSV, in order. New columns are only appended.
+var csvHeader = []string{"kind", "id", "package", "name", "fieldCount", "methodCount", "exported", "distance"}
+
+// EmitCSV writes a row per type of the result, sorted by id, after a header row with the
+// columns: kind, id, package, name, fieldCount, methodCount, exported (true/false) and
+// distance. The counts are the fields and methods declared by the type, promoted ones
+// excluded. Types are loaded to count their members.
+func (s *ScanningResult) EmitCSV(w io.Writer) error {
+	ids := s.Types.Keys()
+	sort.Strings(ids)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		t, ok := s.Types.Get(id)
+		if !ok {
+			continue
+		}
+		if err := t.Load(); err != nil {
+			return err
+		}
+		pkg := ""
+		if t.Package() != nil {
+			pkg = t.Package().Path()
+		}
+		fields := 0
+		for _, f := range fieldsOf(t) {
+			if f.PromotedFrom() == nil {
+				fields++
+			}
+		}
+		methods := 0
+		for _, m := range t.Methods() {
+			if m.PromotedFrom() == nil {
+				methods++
+			}
+		}
+		row := []string{
+			string(t.Kind()),
+			id,
+			pkg,
+			t.Name(),
+			strconv.Itoa(fields),
+			strconv.Itoa(methods),
+			strconv.FormatBool(t.Exported()),
+			strconv.Itoa(t.Distance()),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,292 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// Kinds of change of a declaration or member
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// ResultDiff is the structural difference between two scanning results: the declarations
+// (types, functions and values) only in one of them, and the members and properties that
+// changed in the ones found in both
+type ResultDiff struct {
+	Added   []string      `json:"added,omitempty"`
+	Removed []string      `json:"removed,omitempty"`
+	Changed []*DeclChange `json:"changed,omitempty"`
+}
+
+// DeclChange lists the differences of a declaration found in both results
+type DeclChange struct {
+	ID      string          `json:"id"`
+	Kind    string          `json:"kind"` // kind of the declaration in the current result
+	Changes []*MemberChange `json:"changes"`
+}
+
+// MemberChange is a difference of a declaration. Kind is "field" or "method" for its
+// members (Name is the member name), otherwise the property that changed: "kind",
+// "signature" (functions), "type" (the definition of other types, the type of values),
+// "typeParams" or "value" (constants). Old and New describe both sides (a field type, a
+// method signature...), empty on the missing side.
+type MemberChange struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name,omitempty"`
+	Change string `json:"change"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// DiffOptions selects the declarations DiffResults compares
+type DiffOptions struct {
+	// IncludeUnexported compares the unexported declarations and members too
+	IncludeUnexported bool
+	// IncludeExternal compares the declarations of the dependencies too, not only the ones of
+	// the scanned packages (distance 0)
+	IncludeExternal bool
+}
+
+// IsEmpty reports whether the results are the same
+func (d *ResultDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// ReadSerializedResult reads a scanning result written as JSON (the json format of
+// SerializeTo, without InternRefs nor a KeyStyle other than camel), to compare it with
+// DiffResults
+func ReadSerializedResult(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("%s is not a serialized scanning result: %w", path, err)
+	}
+	return tree, nil
+}
+
+// DiffResults compares two serialized scanning results (ScanningResult.Serialize or
+// ReadSerializedResult) structurally: fields, methods, signatures and definitions. Docs,
+// positions and other metadata are ignored.
+func DiffResults(previous, current map[string]any, opts DiffOptions) *ResultDiff {
+	prev := map[string]any{}
+	curr := map[string]any{}
+	for _, section := range []string{"types", "values"} {
+		prev[section] = diffDecls(previous[section], opts)
+		curr[section] = diffDecls(current[section], opts)
+	}
+
+	// The declarations whose serialized form changed are compared member by member, the
+	// ones differing only in docs or positions report no changes
+	changes := diffSerialized(prev, curr)
+	diff := &ResultDiff{Added: changes.Added, Removed: changes.Removed}
+	for _, id := range changes.Modified {
+		for _, section := range []string{"types", "values"} {
+			entry, ok := curr[section].(map[string]any)[id].(map[string]any)
+			if !ok {
+				continue
+			}
+			old, _ := prev[section].(map[string]any)[id].(map[string]any)
+			if declChanges := diffDecl(old, entry, opts); len(declChanges) > 0 {
+				diff.Changed = append(diff.Changed, &DeclChange{ID: id, Kind: jsonString(entry["kind"]), Changes: declChanges})
+			}
+			break
+		}
+	}
+	return diff
+}
+
+// diffDecls returns the entries of a section of a serialized result selected by opts
+func diffDecls(section any, opts DiffOptions) map[string]any {
+	entries, _ := section.(map[string]any)
+	decls := make(map[string]any, len(entries))
+	for id, entry := range entries {
+		decl, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		if !opts.IncludeExternal && jsonInt(decl["distance"]) != 0 {
+			continue
+		}
+		if !opts.IncludeUnexported && !isExportedName(jsonString(decl["name"])) {
+			continue
+		}
+		decls[id] = decl
+	}
+	return decls
+}
+
+// diffDecl compares two serialized declarations with the same id
+func diffDecl(old, curr map[string]any, opts DiffOptions) []*MemberChange {
+	var changes []*MemberChange
+	property := func(kind, oldDesc, newDesc string) {
+		if oldDesc != newDesc {
+			changes = append(changes, &MemberChange{Kind: kind, Change: ChangeChanged, Old: oldDesc, New: newDesc})
+		}
+	}
+
+	kind := gstypes.TypeKind(jsonString(curr["kind"]))
+	property("kind", jsonString(old["kind"]), string(kind))
+	property("typeParams", typeParamsDesc(old["typeParams"]), typeParamsDesc(curr["typeParams"]))
+
+	switch kind {
+	case gstypes.TypeKindFunction:
+		property("signature", jsonString(old["structure"]), jsonString(curr["structure"]))
+	case gstypes.TypeKindStruct, gstypes.TypeKindInterface:
+	case gstypes.TypeKindConstant, gstypes.TypeKindVariable:
+		property("type", typeDesc(old["valueType"]), typeDesc(curr["valueType"]))
+		if kind == gstypes.TypeKindConstant {
+			property("value", fmt.Sprint(old["value"]), fmt.Sprint(curr["value"]))
+		}
+	default:
+		property("type", definitionDesc(old), definitionDesc(curr))
+	}
+
+	changes = append(changes, diffMembers("field", old["fields"], curr["fields"], fieldDesc, opts)...)
+	changes = append(changes, diffMembers("method", old["methods"], curr["methods"], methodDesc, opts)...)
+	return changes
+}
+
+// diffMembers compares two serialized member lists by name
+func diffMembers(kind string, old, curr any, describe func(map[string]any) string, opts DiffOptions) []*MemberChange {
+	byName := func(list any) map[string]string {
+		items, _ := list.([]any)
+		members := make(map[string]string, len(items))
+		for _, item := range items {
+			member, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			name := jsonString(member["name"])
+			if !opts.IncludeUnexported && !isExportedName(name) {
+				continue
+			}
+			members[name] = describe(member)
+		}
+		return members
+	}
+	prev, next := byName(old), byName(curr)
+
+	var changes []*MemberChange
+	for name, desc := range next {
+		oldDesc, ok := prev[name]
+		switch {
+		case !ok:
+			changes = append(changes, &MemberChange{Kind: kind, Name: name, Change: ChangeAdded, New: desc})
+		case oldDesc != desc:
+			changes = append(changes, &MemberChange{Kind: kind, Name: name, Change: ChangeChanged, Old: oldDesc, New: desc})
+		}
+	}
+	for name, desc := range prev {
+		if _, ok := next[name]; !ok {
+			changes = append(changes, &MemberChange{Kind: kind, Name: name, Change: ChangeRemoved, Old: desc})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// fieldDesc describes a serialized field by its type and tag
+func fieldDesc(field map[string]any) string {
+	desc := typeDesc(field["type"])
+	if jsonBool(field["isEmbedded"]) {
+		desc = "embedded " + desc
+	}
+	if tag := jsonString(field["tag"]); tag != "" {
+		desc += " `" + tag + "`"
+	}
+	return desc
+}
+
+// methodDesc describes a serialized method by its signature, prefixed with the receiver
+// when it's a pointer ("(*User) Save() error")
+func methodDesc(method map[string]any) string {
+	desc := jsonString(method["name"]) + strings.TrimPrefix(jsonString(method["structure"]), "func")
+	if jsonBool(method["isPointerReceiver"]) {
+		receiver := jsonString(method["receiver"])
+		desc = "(*" + receiver[strings.LastIndex(receiver, ".")+1:] + ") " + desc
+	}
+	return desc
+}
+
+// definitionDesc describes the definition of a serialized type other than a struct,
+// interface or function: its structure or underlying type
+func definitionDesc(decl map[string]any) string {
+	if structure := jsonString(decl["structure"]); structure != "" {
+		return structure
+	}
+	return typeDesc(decl["underlying"])
+}
+
+// typeParamsDesc describes serialized type parameters as they're declared ("[K comparable, V any]")
+func typeParamsDesc(params any) string {
+	items, _ := params.([]any)
+	if len(items) == 0 {
+		return ""
+	}
+	descs := make([]string, 0, len(items))
+	for _, item := range items {
+		param, _ := item.(map[string]any)
+		desc := jsonString(param["name"])
+		if constraint := typeDesc(param["constraint"]); constraint != "" {
+			desc += " " + constraint
+		}
+		descs = append(descs, desc)
+	}
+	return "[" + strings.Join(descs, ", ") + "]"
+}
+
+// typeDesc describes a serialized type reference: the id of named types, the structure of
+// unnamed ones (their ids are counters that differ between scans)
+func typeDesc(ref any) string {
+	node, ok := ref.(map[string]any)
+	if !ok {
+		return jsonString(ref)
+	}
+	id := jsonString(node["id"])
+	if strings.HasPrefix(id, "__unnamed_") {
+		if structure := jsonString(node["structure"]); structure != "" {
+			return structure
+		}
+		return jsonString(node["kind"])
+	}
+	return id
+}
+
+func jsonString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func jsonBool(v any) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+func jsonInt(v any) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
+
+func isExportedName(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
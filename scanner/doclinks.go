@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"go/doc/comment"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// extractDocLinks resolves the doc links of every comment recorded in pkgInfo.
+// Links to the package itself are checked against its scope, links to other packages are
+// resolved through its imports (or the standard library).
+func (r *defaultTypeResolver) extractDocLinks(pkgInfo *gstypes.Package, pkg *packages.Package) {
+	if pkg.Types == nil {
+		return
+	}
+
+	imports := map[string]string{pkg.Types.Name(): pkg.PkgPath}
+	for _, imp := range pkg.Types.Imports() {
+		imports[imp.Name()] = imp.Path()
+	}
+
+	parser := &comment.Parser{
+		LookupPackage: func(name string) (string, bool) {
+			path, ok := imports[name]
+			return path, ok
+		},
+		LookupSym: func(recv, name string) bool {
+			return lookupSym(pkg.Types, recv, name)
+		},
+	}
+
+	pkgInfo.WalkComments(func(c *gstypes.Comment) {
+		// Avoid parsing comments without links
+		if !strings.Contains(c.Text, "[") {
+			return
+		}
+		c.DocLinks = nil
+		for _, link := range docLinks(parser.Parse(c.Text).Content) {
+			importPath := link.ImportPath
+			if importPath == "" {
+				importPath = pkg.PkgPath
+			}
+			dl := gstypes.DocLink{
+				Text:       plainText(link.Text),
+				ImportPath: importPath,
+				Recv:       link.Recv,
+				Name:       link.Name,
+			}
+			switch {
+			case link.Recv != "":
+				dl.TypeID = importPath + "." + link.Recv + "#" + link.Name
+			case link.Name != "":
+				dl.TypeID = importPath + "." + link.Name
+			}
+			c.DocLinks = append(c.DocLinks, dl)
+		}
+	})
+}
+
+// lookupSym reports whether name (or recv.name) is declared in pkg
+func lookupSym(pkg *types.Package, recv, name string) bool {
+	if recv == "" {
+		return pkg.Scope().Lookup(name) != nil
+	}
+	tn, ok := pkg.Scope().Lookup(recv).(*types.TypeName)
+	if !ok {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(tn.Type(), true, pkg, name)
+	return obj != nil
+}
+
+// docLinks collects the doc links of the blocks in order
+func docLinks(blocks []comment.Block) []*comment.DocLink {
+	var links []*comment.DocLink
+	var walkText func(text []comment.Text)
+	walkText = func(text []comment.Text) {
+		for _, t := range text {
+			switch t := t.(type) {
+			case *comment.DocLink:
+				links = append(links, t)
+			case *comment.Link:
+				walkText(t.Text)
+			}
+		}
+	}
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *comment.Paragraph:
+			walkText(b.Text)
+		case *comment.Heading:
+			walkText(b.Text)
+		case *comment.List:
+			for _, item := range b.Items {
+				links = append(links, docLinks(item.Content)...)
+			}
+		}
+	}
+	return links
+}
+
+func plainText(text []comment.Text) string {
+	var sb strings.Builder
+	for _, t := range text {
+		switch t := t.(type) {
+		case comment.Plain:
+			sb.WriteString(string(t))
+		case comment.Italic:
+			sb.WriteString(string(t))
+		}
+	}
+	return sb.String()
+}
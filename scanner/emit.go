@@ -0,0 +1,488 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// structureEllipsis marks a truncated structure string, followed by the hash of the full string
+const structureEllipsis = "..."
+
+// EmitOptions controls how a result is rendered for output
+type EmitOptions struct {
+	// MaxStructureLen truncates "structure" strings (full go/types strings) longer than this
+	// many bytes. The cut string ends with an ellipsis and a hash of the full string so
+	// distinct structures stay distinct. Zero means no limit.
+	MaxStructureLen int `json:"max_structure_len,omitempty" yaml:"max_structure_len,omitempty"`
+	// InternRefs replaces every reference to a named type ({"id", "kind"}) and every basic
+	// type with a {"$ref": id} pointer into a "$defs" table at the root of the output, which
+	// holds each of them once.
+	InternRefs bool `json:"intern_refs,omitempty" yaml:"intern_refs,omitempty"`
+	// NameTransform computes the name emitters use for struct fields from the Go name and the
+	// parsed struct tags (see SnakeCase, CamelCase and TagName). When set, serialized fields
+	// get an "emitName" with the result. Nil keeps the Go names.
+	NameTransform func(goName string, tags map[string]string) string `json:"-" yaml:"-"`
+	// TreatPointerScalarAsOptional renders fields of a pointer to a basic type (*int, *string)
+	// as the basic type marked "optional", the usual meaning of the idiom in schemas, instead
+	// of a pointer. Pointers to pointers are kept.
+	TreatPointerScalarAsOptional bool `json:"treat_pointer_scalar_as_optional,omitempty" yaml:"treat_pointer_scalar_as_optional,omitempty"`
+	// KeyStyle is the style of the keys of the output schema (KeyStyleCamel, the default, or
+	// KeyStyleSnake). Keys holding ids, as in the types, values, packages and $defs tables,
+	// are never renamed.
+	KeyStyle KeyStyle `json:"key_style,omitempty" yaml:"key_style,omitempty"`
+	// RootFields are extra entries of the root of the output, written before the tables and
+	// never renamed (goscanner.Result adds its version)
+	RootFields map[string]any `json:"-" yaml:"-"`
+}
+
+// KeyStyle is the naming style of the keys of the output schema
+type KeyStyle string
+
+const (
+	KeyStyleCamel KeyStyle = "camel" // isPointerReceiver
+	KeyStyleSnake KeyStyle = "snake" // is_pointer_receiver
+)
+
+// urlKey holds the link of references resolved by the result's ref resolver
+const urlKey = "url"
+
+// emitNameKey holds the transformed name of serialized fields
+const emitNameKey = "emitName"
+
+// optionalKey marks the fields rendered as optional scalars
+const optionalKey = "optional"
+
+// defsKey is the root key of the interned references table
+const defsKey = "$defs"
+
+// SerializeWithOptions serializes the result like Serialize and applies opts to the output.
+// The returned value is a generic JSON tree (maps, slices and scalars).
+func (s *ScanningResult) SerializeWithOptions(opts EmitOptions) (any, error) {
+	data, err := json.Marshal(s.Serialize())
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	tree = opts.apply(tree)
+	if s.refResolver != nil {
+		s.linkRefs(tree)
+	}
+	if opts.InternRefs {
+		internResult(tree.(map[string]any))
+	}
+	switch opts.KeyStyle {
+	case "", KeyStyleCamel:
+	case KeyStyleSnake:
+		styleResult(tree.(map[string]any), snakeKey)
+	default:
+		return nil, fmt.Errorf("unknown key style %q", opts.KeyStyle)
+	}
+	for key, value := range opts.RootFields {
+		tree.(map[string]any)[key] = value
+	}
+	return tree, nil
+}
+
+// Format is an encoding of the serialized result
+type Format string
+
+const (
+	FormatJSON Format = "json" // indented JSON, as SerializeTo
+	FormatYAML Format = "yaml" // block style YAML
+)
+
+// SerializeTo writes the result to w as indented JSON, with the same content as
+// SerializeWithOptions. The entries of the packages, types and values tables are serialized
+// and encoded one at a time, in id order, so memory stays bounded by the largest entry
+// instead of the whole result. With InternRefs the $defs table is written last.
+func (s *ScanningResult) SerializeTo(w io.Writer, opts EmitOptions) error {
+	return s.EncodeWithOptions(w, FormatJSON, opts)
+}
+
+// Encode writes the serialized result to w in format
+func (s *ScanningResult) Encode(w io.Writer, format Format) error {
+	return s.EncodeWithOptions(w, format, EmitOptions{})
+}
+
+// EncodeWithOptions writes the result to w in format with the content of SerializeWithOptions,
+// streaming it entry by entry as SerializeTo
+func (s *ScanningResult) EncodeWithOptions(w io.Writer, format Format, opts EmitOptions) error {
+	var sw tableWriter
+	switch format {
+	case FormatJSON:
+		sw = newStreamWriter(w)
+	case FormatYAML:
+		sw = newYAMLWriter(w)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	var rename func(string) string
+	switch opts.KeyStyle {
+	case "", KeyStyleCamel:
+	case KeyStyleSnake:
+		rename = snakeKey
+	default:
+		return fmt.Errorf("unknown key style %q", opts.KeyStyle)
+	}
+	var defs map[string]any
+	if opts.InternRefs {
+		defs = map[string]any{}
+	}
+
+	tables := []struct {
+		key   string
+		ids   []string
+		entry func(id string) (gstypes.Serializable, bool)
+	}{
+		{"packages", s.Packages.Keys(), func(id string) (gstypes.Serializable, bool) { return s.Packages.Get(id) }},
+		{"types", s.Types.Keys(), func(id string) (gstypes.Serializable, bool) { return s.Types.Get(id) }},
+		{"values", s.Values.Keys(), func(id string) (gstypes.Serializable, bool) { return s.Values.Get(id) }},
+	}
+
+	sw.begin()
+	fields := make([]string, 0, len(opts.RootFields))
+	for key := range opts.RootFields {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+	for i, key := range fields {
+		sw.writeField(key, opts.RootFields[key], i == 0)
+	}
+	for i, table := range tables {
+		sort.Strings(table.ids)
+		sw.openTable(table.key, i == 0 && len(fields) == 0)
+		first := true
+		for _, id := range table.ids {
+			entry, ok := table.entry(id)
+			if !ok {
+				continue
+			}
+			node, err := s.emitEntry(entry.Serialize(), opts, defs, rename)
+			if err != nil {
+				return err
+			}
+			sw.writeEntry(id, node, first)
+			first = false
+		}
+		sw.closeTable(first)
+	}
+	if defs != nil {
+		ids := make([]string, 0, len(defs))
+		for id := range defs {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		sw.openTable(defsKey, false)
+		for i, id := range ids {
+			def := defs[id]
+			if rename != nil {
+				def = styleKeys(def, rename)
+			}
+			sw.writeEntry(id, def, i == 0)
+		}
+		sw.closeTable(len(ids) == 0)
+	}
+	return sw.end()
+}
+
+// emitEntry converts a serialized entry of one of the result tables to a generic JSON tree and
+// applies opts to it, as SerializeWithOptions does on the whole result. The references
+// interned are added to defs when not nil.
+func (s *ScanningResult) emitEntry(entry any, opts EmitOptions, defs map[string]any, rename func(string) string) (any, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	var node any
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	node = opts.apply(node)
+	if s.refResolver != nil {
+		s.linkRefs(node)
+	}
+	if def, ok := node.(map[string]any); ok && defs != nil {
+		for k, child := range def {
+			def[k] = internRefs(child, defs)
+		}
+	}
+	if rename != nil {
+		node = styleKeys(node, rename)
+	}
+	return node, nil
+}
+
+// tableWriter writes the root object of EncodeWithOptions, an object of id keyed tables, in a
+// format. Write errors are kept and returned by end.
+type tableWriter interface {
+	begin()
+	writeField(key string, value any, first bool)
+	openTable(key string, first bool)
+	writeEntry(id string, node any, first bool)
+	closeTable(empty bool)
+	end() error
+}
+
+// streamWriter writes the root object as JSON laid out as json.MarshalIndent with tabs
+type streamWriter struct {
+	w   *bufio.Writer
+	buf bytes.Buffer
+	enc *json.Encoder
+	err error
+}
+
+func newStreamWriter(w io.Writer) *streamWriter {
+	sw := &streamWriter{w: bufio.NewWriter(w)}
+	sw.enc = json.NewEncoder(&sw.buf)
+	sw.enc.SetIndent("\t\t", "\t")
+	return sw
+}
+
+func (sw *streamWriter) begin() {
+	sw.writeString("{")
+}
+
+func (sw *streamWriter) writeString(s string) {
+	if sw.err == nil {
+		_, sw.err = sw.w.WriteString(s)
+	}
+}
+
+func (sw *streamWriter) writeJSON(v any) {
+	if sw.err != nil {
+		return
+	}
+	sw.buf.Reset()
+	if sw.err = sw.enc.Encode(v); sw.err != nil {
+		return
+	}
+	_, sw.err = sw.w.Write(bytes.TrimSuffix(sw.buf.Bytes(), []byte("\n")))
+}
+
+func (sw *streamWriter) writeField(key string, value any, first bool) {
+	if !first {
+		sw.writeString(",")
+	}
+	sw.writeString("\n\t")
+	sw.writeJSON(key)
+	sw.writeString(": ")
+	sw.writeJSON(value)
+}
+
+func (sw *streamWriter) openTable(key string, first bool) {
+	if !first {
+		sw.writeString(",")
+	}
+	sw.writeString("\n\t")
+	sw.writeJSON(key)
+	sw.writeString(": {")
+}
+
+func (sw *streamWriter) closeTable(empty bool) {
+	if empty {
+		sw.writeString("}")
+		return
+	}
+	sw.writeString("\n\t}")
+}
+
+func (sw *streamWriter) writeEntry(id string, node any, first bool) {
+	if !first {
+		sw.writeString(",")
+	}
+	sw.writeString("\n\t\t")
+	sw.writeJSON(id)
+	sw.writeString(": ")
+	sw.writeJSON(node)
+}
+
+func (sw *streamWriter) end() error {
+	sw.writeString("\n}\n")
+	if sw.err != nil {
+		return sw.err
+	}
+	return sw.w.Flush()
+}
+
+// styleResult renames the keys of the entries of the id keyed tables of root, the table
+// names themselves are single words
+func styleResult(root map[string]any, rename func(string) string) {
+	for _, key := range []string{"types", "values", "packages", defsKey} {
+		col, _ := root[key].(map[string]any)
+		for id, entry := range col {
+			col[id] = styleKeys(entry, rename)
+		}
+	}
+}
+
+// styleKeys renames the keys of the maps under node
+func styleKeys(node any, rename func(string) string) any {
+	switch v := node.(type) {
+	case map[string]any:
+		styled := make(map[string]any, len(v))
+		for key, child := range v {
+			styled[rename(key)] = styleKeys(child, rename)
+		}
+		return styled
+	case []any:
+		for i, child := range v {
+			v[i] = styleKeys(child, rename)
+		}
+	}
+	return node
+}
+
+// snakeKey converts a camelCase key to snake_case, keys such as "$ref" are kept
+func snakeKey(key string) string {
+	if strings.HasPrefix(key, "$") {
+		return key
+	}
+	return SnakeCase(key, nil)
+}
+
+// linkRefs adds the url given by the ref resolver to the references to named types
+func (s *ScanningResult) linkRefs(node any) {
+	switch v := node.(type) {
+	case map[string]any:
+		if id, ok := v["id"].(string); ok && len(v) == 2 && v["kind"] != nil {
+			if url, ok := s.ResolveRef(id); ok {
+				v[urlKey] = url
+			}
+			return
+		}
+		for _, child := range v {
+			s.linkRefs(child)
+		}
+	case []any:
+		for _, child := range v {
+			s.linkRefs(child)
+		}
+	}
+}
+
+// internResult interns the references found in the definitions of the types, values and
+// packages collections of root, and adds the $defs table
+func internResult(root map[string]any) {
+	defs := map[string]any{}
+	for _, key := range []string{"types", "values", "packages"} {
+		col, _ := root[key].(map[string]any)
+		for _, entry := range col {
+			def, ok := entry.(map[string]any)
+			if !ok {
+				continue
+			}
+			for k, child := range def {
+				def[k] = internRefs(child, defs)
+			}
+		}
+	}
+	root[defsKey] = defs
+}
+
+// internRefs replaces the references under node with pointers, moving them to defs
+func internRefs(node any, defs map[string]any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		if id, ok := refID(v); ok {
+			if _, seen := defs[id]; !seen {
+				defs[id] = v
+			}
+			return map[string]any{"$ref": id}
+		}
+		for key, child := range v {
+			v[key] = internRefs(child, defs)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = internRefs(child, defs)
+		}
+	}
+	return node
+}
+
+// refID reports whether m is a reference to intern: a named type reference or a basic type
+func refID(m map[string]any) (string, bool) {
+	id, _ := m["id"].(string)
+	if id == "" {
+		return "", false
+	}
+	if (len(m) == 2 || len(m) == 3 && m[urlKey] != nil) && m["kind"] != nil {
+		return id, true
+	}
+	if m["kind"] == "basic" && m["named"] == nil && m["underlying"] == nil {
+		return id, true
+	}
+	return "", false
+}
+
+// apply walks the generic tree applying the options
+func (o EmitOptions) apply(node any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		if o.NameTransform != nil && v["kind"] == "field" {
+			name, _ := v["name"].(string)
+			tag, _ := v["tag"].(string)
+			v[emitNameKey] = o.FieldName(name, tag)
+		}
+		if o.TreatPointerScalarAsOptional && v["kind"] == "field" {
+			if ptr, ok := v["type"].(map[string]any); ok && ptr["kind"] == "pointer" && ptr["depth"] == float64(1) {
+				if elem, ok := ptr["element"].(map[string]any); ok && elem["kind"] == "basic" {
+					v["type"] = elem
+					v[optionalKey] = true
+				}
+			}
+		}
+		for key, child := range v {
+			if str, ok := child.(string); ok && key == "structure" && o.MaxStructureLen > 0 {
+				v[key] = truncateStructure(str, o.MaxStructureLen)
+				continue
+			}
+			v[key] = o.apply(child)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = o.apply(child)
+		}
+	}
+	return node
+}
+
+// OptionalScalar returns the basic type t points to when t renders as an optional scalar
+// under TreatPointerScalarAsOptional, for emitters working on the types
+func (o EmitOptions) OptionalScalar(t gstypes.Type) (*gstypes.Basic, bool) {
+	if !o.TreatPointerScalarAsOptional {
+		return nil, false
+	}
+	ptr, ok := t.(*gstypes.Pointer)
+	if !ok || ptr.Depth() != 1 {
+		return nil, false
+	}
+	basic, ok := ptr.Elem().(*gstypes.Basic)
+	return basic, ok
+}
+
+// truncateStructure cuts s to max bytes (on a rune boundary) adding the ellipsis and a hash
+func truncateStructure(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	sum := sha256.Sum256([]byte(s))
+	return s[:cut] + structureEllipsis + "#" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,1080 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+func TestSerializeWithOptions_maxStructureLen(t *testing.T) {
+	src := `
+	package test
+
+	type Nested map[string]map[string]map[string][]int
+	type Other map[string]map[string]map[string][]string
+	type Short []int
+	`
+
+	result := scanTestSource(t, src)
+
+	tree, err := result.SerializeWithOptions(EmitOptions{MaxStructureLen: 20})
+	if err != nil {
+		t.Fatalf("SerializeWithOptions() error = %v", err)
+	}
+	typesTree := tree.(map[string]any)["types"].(map[string]any)
+	structure := func(id string) string {
+		return typesTree[id].(map[string]any)["structure"].(string)
+	}
+
+	nested, other := structure("test.Nested"), structure("test.Other")
+	if !strings.HasPrefix(nested, "map[string]map[strin...#") {
+		t.Errorf("structure not truncated: %q", nested)
+	}
+	if nested == other {
+		t.Errorf("truncated structures with the same prefix must keep distinct hashes: %q", nested)
+	}
+	if got := structure("test.Short"); got != "[]int" {
+		t.Errorf("short structure changed: %q", got)
+	}
+
+	// Without options the output is untouched
+	tree, err = result.SerializeWithOptions(EmitOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := tree.(map[string]any)["types"].(map[string]any)["test.Nested"].(map[string]any)["structure"]
+	if full != "map[string]map[string]map[string][]int" {
+		t.Errorf("unexpected structure without limit: %v", full)
+	}
+}
+
+func TestSerializeWithOptions_internRefs(t *testing.T) {
+	src := `
+	package test
+
+	type Role int
+
+	type User struct {
+		ID    int
+		Name  string
+		Email string
+		Role  Role
+	}
+
+	type Team struct {
+		Name    string
+		Owner   User
+		Members []User
+	}
+	`
+
+	result := scanTestSource(t, src)
+
+	plain, err := result.SerializeWithOptions(EmitOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := result.SerializeWithOptions(EmitOptions{InternRefs: true})
+	if err != nil {
+		t.Fatalf("SerializeWithOptions() error = %v", err)
+	}
+	root := tree.(map[string]any)
+
+	defs, ok := root["$defs"].(map[string]any)
+	if !ok {
+		t.Fatalf("missing $defs table")
+	}
+	for _, id := range []string{"string", "int", "test.Role", "test.User"} {
+		if defs[id] == nil {
+			t.Errorf("expected %s in $defs", id)
+		}
+	}
+	if defs["test.Team"] != nil {
+		t.Errorf("unreferenced test.Team must not be in $defs")
+	}
+
+	// Definitions stay in place, references inside them become pointers
+	team := root["types"].(map[string]any)["test.Team"].(map[string]any)
+	if team["id"] != "test.Team" {
+		t.Fatalf("definition replaced: %v", team)
+	}
+	fields := team["fields"].([]any)
+	if ref := fields[1].(map[string]any)["type"]; !reflect.DeepEqual(ref, map[string]any{"$ref": "test.User"}) {
+		t.Errorf("Owner type = %v, want a pointer to test.User", ref)
+	}
+	elem := fields[2].(map[string]any)["type"].(map[string]any)["element"]
+	if !reflect.DeepEqual(elem, map[string]any{"$ref": "test.User"}) {
+		t.Errorf("Members element = %v, want a pointer to test.User", elem)
+	}
+
+	// Nothing else changes: resolving the pointers gives back the plain output
+	delete(root, "$defs")
+	if !reflect.DeepEqual(resolveRefs(tree, defs), plain) {
+		t.Errorf("resolving the pointers does not give back the plain output")
+	}
+}
+
+func resolveRefs(node any, defs map[string]any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		if id, ok := v["$ref"].(string); ok && len(v) == 1 {
+			return defs[id]
+		}
+		for key, child := range v {
+			v[key] = resolveRefs(child, defs)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = resolveRefs(child, defs)
+		}
+	}
+	return node
+}
+
+func TestSerializeTo(t *testing.T) {
+	src := `
+	package test
+
+	type Role int
+
+	const Admin Role = 1
+
+	type User struct {
+		Name  string
+		Email *string
+		Role  Role
+	}
+
+	type Team struct {
+		Owner   User
+		Members []User
+	}
+	`
+
+	result := scanTestSource(t, src)
+
+	// Without options the stream is the indented Serialize output
+	var buf bytes.Buffer
+	if err := result.SerializeTo(&buf, EmitOptions{}); err != nil {
+		t.Fatalf("SerializeTo() error = %v", err)
+	}
+	tree, err := result.SerializeWithOptions(EmitOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := json.MarshalIndent(tree, "", "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != string(want)+"\n" {
+		t.Errorf("SerializeTo() output differs from the indented Serialize output:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The options give the same content as SerializeWithOptions
+	for _, opts := range []EmitOptions{
+		{InternRefs: true},
+		{KeyStyle: KeyStyleSnake, TreatPointerScalarAsOptional: true, MaxStructureLen: 8},
+		{InternRefs: true, KeyStyle: KeyStyleSnake},
+	} {
+		buf.Reset()
+		if err := result.SerializeTo(&buf, opts); err != nil {
+			t.Fatalf("SerializeTo(%+v) error = %v", opts, err)
+		}
+		var got any
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("SerializeTo(%+v) wrote invalid JSON: %v", opts, err)
+		}
+		want, err := result.SerializeWithOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("SerializeTo(%+v) content differs from SerializeWithOptions", opts)
+		}
+	}
+
+	if err := result.SerializeTo(&buf, EmitOptions{KeyStyle: "kebab"}); err == nil {
+		t.Error("expected an error for an unknown key style")
+	}
+}
+
+func TestEncode_yaml(t *testing.T) {
+	src := `
+	package test
+
+	// User is a user
+	// of the system
+	type User struct {
+		Name  string ` + "`json:\"name\"`" + `
+		Tags  []string
+		Extra struct{}
+	}
+
+	const On = true
+	`
+
+	result := scanTestSource(t, src)
+
+	var buf bytes.Buffer
+	if err := result.Encode(&buf, FormatYAML); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"packages:\n  test:\n",
+		"types:\n  test.User:\n",
+		"    comments:\n      - placement: above\n        text: \"User is a user\\nof the system\"\n",
+		"    fields:\n      - id: \"test.User#Name\"\n        index: 0\n",
+		"        tag: \"json:\\\"name\\\"\"\n",
+		"          structure: \"[]string\"\n",
+		"values:\n  test.On:\n",
+		"    name: \"On\"\n",
+		"    value: true\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("YAML output misses %q:\n%s", want, out)
+		}
+	}
+
+	// JSON is the SerializeTo output
+	var jsonOut, streamed bytes.Buffer
+	if err := result.Encode(&jsonOut, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := result.SerializeTo(&streamed, EmitOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if jsonOut.String() != streamed.String() {
+		t.Error("Encode(FormatJSON) differs from SerializeTo")
+	}
+
+	if err := result.Encode(&buf, "toml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestEmitManifest(t *testing.T) {
+	src := `
+	package test
+
+	type User struct {
+		ID   int
+		Name string
+	}
+
+	type Store interface {
+		Get(id int) (*User, error)
+	}
+	`
+
+	result := scanTestSource(t, src)
+
+	var sb strings.Builder
+	if err := result.EmitManifest(&sb); err != nil {
+		t.Fatalf("EmitManifest() error = %v", err)
+	}
+	var manifest []map[string]any
+	if err := json.Unmarshal([]byte(sb.String()), &manifest); err != nil {
+		t.Fatalf("invalid manifest JSON: %v", err)
+	}
+
+	entries := map[string]map[string]any{}
+	for i, entry := range manifest {
+		id := entry["id"].(string)
+		if i > 0 && manifest[i-1]["id"].(string) > id {
+			t.Errorf("manifest not sorted by id: %s after %s", id, manifest[i-1]["id"])
+		}
+		entries[id] = entry
+	}
+
+	if len(manifest) != result.Types.Len() {
+		t.Errorf("manifest has %d entries, want %d", len(manifest), result.Types.Len())
+	}
+	for id, kind := range map[string]string{"test.User": "struct", "test.Store": "interface"} {
+		entry, ok := entries[id]
+		if !ok {
+			t.Fatalf("%s missing from manifest", id)
+		}
+		if entry["kind"] != kind || entry["package"] != "test" {
+			t.Errorf("%s = %v, want kind %s in package test", id, entry, kind)
+		}
+		if _, ok := entry["fields"]; ok {
+			t.Errorf("%s: manifest entries must not include details", id)
+		}
+	}
+}
+
+func TestSerializeWithOptions_nameTransform(t *testing.T) {
+	src := `
+	package test
+
+	type User struct {
+		UserID     int
+		HTTPServer string ` + "`json:\"server,omitempty\"`" + `
+		Ignored    bool   ` + "`json:\"-\" db:\"ignored_flag\"`" + `
+	}
+	`
+
+	result := scanTestSource(t, src)
+	emitNames := func(opts EmitOptions) map[string]any {
+		t.Helper()
+		tree, err := result.SerializeWithOptions(opts)
+		if err != nil {
+			t.Fatalf("SerializeWithOptions() error = %v", err)
+		}
+		names := map[string]any{}
+		user := tree.(map[string]any)["types"].(map[string]any)["test.User"].(map[string]any)
+		for _, f := range user["fields"].([]any) {
+			field := f.(map[string]any)
+			names[field["name"].(string)] = field[emitNameKey]
+		}
+		return names
+	}
+
+	tests := []struct {
+		name      string
+		transform func(string, map[string]string) string
+		want      map[string]any
+	}{
+		{"none", nil, map[string]any{"UserID": nil, "HTTPServer": nil, "Ignored": nil}},
+		{"snake", SnakeCase, map[string]any{"UserID": "user_id", "HTTPServer": "http_server", "Ignored": "ignored"}},
+		{"camel", CamelCase, map[string]any{"UserID": "userID", "HTTPServer": "httpServer", "Ignored": "ignored"}},
+		{"json tag", TagName("json", SnakeCase), map[string]any{"UserID": "user_id", "HTTPServer": "server", "Ignored": "ignored"}},
+		{"db tag", TagName("db", nil), map[string]any{"UserID": "UserID", "HTTPServer": "HTTPServer", "Ignored": "ignored_flag"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := emitNames(EmitOptions{NameTransform: tt.transform}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("emit names = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	got := ParseTags(`json:"name,omitempty" db:"col \"x\""  yaml:"-"`)
+	want := map[string]string{"json": "name,omitempty", "db": `col "x"`, "yaml": "-"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTags() = %v, want %v", got, want)
+	}
+	if got := ParseTags(`json:name`); len(got) != 0 {
+		t.Errorf("ParseTags() of a malformed tag = %v, want empty", got)
+	}
+}
+
+func TestSerializeWithOptions_refResolver(t *testing.T) {
+	src := `
+	package test
+
+	type Role int
+
+	type Team struct {
+		Name string
+	}
+
+	type User struct {
+		Role  Role
+		Teams []Team
+	}
+	`
+
+	result := scanTestSource(t, src)
+	if _, ok := result.ResolveRef("test.Role"); ok {
+		t.Errorf("ResolveRef() without resolver must not resolve")
+	}
+	result.SetRefResolver(func(id string) (string, bool) {
+		if id == "test.Role" {
+			return "https://docs.example.com/test#Role", true
+		}
+		return "", false
+	})
+
+	fieldType := func(tree any, name string) map[string]any {
+		t.Helper()
+		user := tree.(map[string]any)["types"].(map[string]any)["test.User"].(map[string]any)
+		for _, f := range user["fields"].([]any) {
+			if field := f.(map[string]any); field["name"] == name {
+				return field["type"].(map[string]any)
+			}
+		}
+		t.Fatalf("field %s not found", name)
+		return nil
+	}
+
+	tree, err := result.SerializeWithOptions(EmitOptions{})
+	if err != nil {
+		t.Fatalf("SerializeWithOptions() error = %v", err)
+	}
+	if role := fieldType(tree, "Role"); role[urlKey] != "https://docs.example.com/test#Role" {
+		t.Errorf("Role reference = %v, want a url", role)
+	}
+	elem := fieldType(tree, "Teams")["element"].(map[string]any)
+	if _, ok := elem[urlKey]; ok || elem["id"] != "test.Team" {
+		t.Errorf("unresolved Team reference = %v, want no url", elem)
+	}
+
+	// Interned references keep the url in the definitions table
+	tree, err = result.SerializeWithOptions(EmitOptions{InternRefs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref := fieldType(tree, "Role"); ref["$ref"] != "test.Role" {
+		t.Fatalf("Role reference not interned: %v", ref)
+	}
+	def := tree.(map[string]any)[defsKey].(map[string]any)["test.Role"].(map[string]any)
+	if def[urlKey] != "https://docs.example.com/test#Role" {
+		t.Errorf("interned Role definition = %v, want a url", def)
+	}
+}
+
+func TestSerializeWithOptions_optionalPointers(t *testing.T) {
+	src := `
+	package test
+
+	type Celsius float64
+
+	type Address struct {
+		City string
+	}
+
+	type User struct {
+		Age     *int
+		Temp    *Celsius
+		Name    string
+		Home    *Address
+		Nick    **string
+	}
+	`
+
+	result := scanTestSource(t, src)
+	fields := func(opts EmitOptions) map[string]map[string]any {
+		t.Helper()
+		tree, err := result.SerializeWithOptions(opts)
+		if err != nil {
+			t.Fatalf("SerializeWithOptions() error = %v", err)
+		}
+		out := map[string]map[string]any{}
+		user := tree.(map[string]any)["types"].(map[string]any)["test.User"].(map[string]any)
+		for _, f := range user["fields"].([]any) {
+			field := f.(map[string]any)
+			out[field["name"].(string)] = field
+		}
+		return out
+	}
+
+	for name, field := range fields(EmitOptions{}) {
+		if _, ok := field[optionalKey]; ok {
+			t.Errorf("%s marked optional without the option", name)
+		}
+	}
+
+	got := fields(EmitOptions{TreatPointerScalarAsOptional: true})
+	for name, wantID := range map[string]string{"Age": "int", "Temp": "test.Celsius"} {
+		field := got[name]
+		typ := field["type"].(map[string]any)
+		if field[optionalKey] != true || typ["kind"] != "basic" || typ["id"] != wantID {
+			t.Errorf("%s = %v, want an optional %s", name, field, wantID)
+		}
+	}
+	for _, name := range []string{"Name", "Home", "Nick"} {
+		if _, ok := got[name][optionalKey]; ok {
+			t.Errorf("%s must not be optional: %v", name, got[name])
+		}
+	}
+
+	// The same decision on the types
+	opts := EmitOptions{TreatPointerScalarAsOptional: true}
+	user, _ := result.Types.Get("test.User")
+	for _, f := range user.(*gstypes.Struct).Fields() {
+		basic, ok := opts.OptionalScalar(f.Type())
+		if want := f.Name() == "Age" || f.Name() == "Temp"; ok != want {
+			t.Errorf("OptionalScalar(%s) = %v, want %v", f.Name(), ok, want)
+		} else if ok && basic == nil {
+			t.Errorf("OptionalScalar(%s) returned no basic type", f.Name())
+		}
+	}
+}
+
+func TestSerializeWithOptions_keyStyle(t *testing.T) {
+	src := `
+	package test
+
+	type UserID int
+
+	type User struct {
+		ID   UserID
+		Tags map[string]UserID
+	}
+
+	func (u *User) Rename(names ...string) {}
+	`
+
+	result := scanTestSource(t, src)
+	user := func(opts EmitOptions) (map[string]any, map[string]any) {
+		t.Helper()
+		tree, err := result.SerializeWithOptions(opts)
+		if err != nil {
+			t.Fatalf("SerializeWithOptions() error = %v", err)
+		}
+		root := tree.(map[string]any)
+		u, ok := root["types"].(map[string]any)["test.User"].(map[string]any)
+		if !ok {
+			t.Fatalf("test.User missing from the types table, ids must not be renamed")
+		}
+		return root, u
+	}
+
+	_, camel := user(EmitOptions{})
+	method := camel["methods"].([]any)[0].(map[string]any)
+	if method["isPointerReceiver"] != true || camel["pointerMethods"] == nil {
+		t.Fatalf("camel method = %v, want isPointerReceiver", method)
+	}
+
+	root, snake := user(EmitOptions{KeyStyle: KeyStyleSnake, InternRefs: true})
+	if _, ok := snake["pointerMethods"]; ok {
+		t.Errorf("snake output kept pointerMethods: %v", snake)
+	}
+	if ids, _ := snake["pointer_methods"].([]any); len(ids) != 1 || ids[0] != "test.User#Rename" {
+		t.Errorf("snake pointer_methods = %v, want the method id", snake["pointer_methods"])
+	}
+	method = snake["methods"].([]any)[0].(map[string]any)
+	if method["is_pointer_receiver"] != true || method["is_variadic"] != true {
+		t.Errorf("snake method = %v, want is_pointer_receiver and is_variadic", method)
+	}
+	field := snake["fields"].([]any)[0].(map[string]any)
+	if ref, ok := field["type"].(map[string]any)["$ref"]; !ok || ref != "test.UserID" {
+		t.Errorf("snake field type = %v, want a $ref to test.UserID", field["type"])
+	}
+	if _, ok := root[defsKey].(map[string]any)["test.UserID"]; !ok {
+		t.Errorf("$defs = %v, want test.UserID", root[defsKey])
+	}
+
+	if _, err := result.SerializeWithOptions(EmitOptions{KeyStyle: "kebab"}); err == nil {
+		t.Error("SerializeWithOptions() with an unknown key style succeeded")
+	}
+}
+
+func TestEmitSQL(t *testing.T) {
+	src := `
+	package test
+
+	type Status string
+
+	type User struct {
+		_         struct{} ` + "`table:\"users\"`" + `
+		ID        int64    ` + "`db:\"id,primaryKey\"`" + `
+		Email     string   ` + "`db:\"email,unique\"`" + `
+		Nickname  *string  ` + "`db:\"nickname\"`" + `
+		Status    Status   ` + "`db:\"status,index\"`" + `
+		Avatar    []byte
+		Secret    string   ` + "`db:\"-\"`" + `
+		Tags      []string
+		internal  int
+	}
+
+	type Membership struct {
+		UserID  int64  ` + "`gorm:\"primaryKey\"`" + `
+		GroupID int64  ` + "`gorm:\"primaryKey\"`" + `
+		Role    string ` + "`gorm:\"column:role_name;type:varchar(32);index\"`" + `
+		Score   *float32
+	}
+
+	type Plain struct {
+		Name string ` + "`json:\"name\"`" + `
+	}
+	`
+
+	result := scanTestSource(t, src)
+	var buf bytes.Buffer
+	if err := EmitSQL(result, &buf, SQLOptions{}); err != nil {
+		t.Fatalf("EmitSQL() error = %v", err)
+	}
+
+	want := `CREATE TABLE membership (
+	user_id BIGINT NOT NULL,
+	group_id BIGINT NOT NULL,
+	role_name varchar(32) NOT NULL,
+	score REAL,
+	PRIMARY KEY (user_id, group_id)
+);
+CREATE INDEX idx_membership_role_name ON membership (role_name);
+
+CREATE TABLE users (
+	id BIGINT NOT NULL PRIMARY KEY,
+	email TEXT NOT NULL,
+	nickname TEXT,
+	status TEXT NOT NULL,
+	avatar BLOB
+);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_status ON users (status);
+`
+	if got := buf.String(); got != want {
+		t.Errorf("EmitSQL() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Custom column types
+	buf.Reset()
+	opts := SQLOptions{ColumnType: func(f *gstypes.Field) (string, bool) {
+		return "JSONB", f.Name() == "Tags"
+	}}
+	if err := EmitSQL(result, &buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\ttags JSONB NOT NULL\n") {
+		t.Errorf("EmitSQL() with ColumnType =\n%s\nwant a tags JSONB column", buf.String())
+	}
+
+	// The shared name transform names the untagged columns
+	buf.Reset()
+	if err := EmitSQL(result, &buf, SQLOptions{EmitOptions: EmitOptions{NameTransform: CamelCase}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.Contains(got, "\tuserID BIGINT NOT NULL,\n") || !strings.Contains(got, "\trole_name varchar(32) NOT NULL,\n") {
+		t.Errorf("EmitSQL() with CamelCase names =\n%s", got)
+	}
+}
+
+func TestEmitGraphQL(t *testing.T) {
+	src := `
+	package test
+
+	// Episode of the saga
+	type Episode int
+
+	const (
+		NewHope Episode = iota + 4
+		// @graphql(name="EMPIRE")
+		EmpireStrikesBack
+		ReturnOfTheJedi
+	)
+
+	// Character in the films
+	type Character interface {
+		GetID() string
+		// @graphql(nullable)
+		GetName() string
+		Friends(first int) []Character
+	}
+
+	type Page[T any] struct {
+		Items []T
+		Total int
+	}
+
+	// Human is a person
+	// @graphql(name="Person")
+	type Human struct {
+		ID        string ` + "`json:\"id\"`" + `
+		Name      string
+		// Height in meters
+		Height    *float64
+		AppearsIn []Episode
+		// @graphql(name="buddies", nullable=false)
+		Mates     []*Human
+		Related   Page[Human]
+		Meta      map[string]any
+		// @graphql(skip)
+		Secret    string
+		internal  int
+	}
+
+	func (h Human) GetID() string { return h.ID }
+	func (h Human) GetName() string { return h.Name }
+	func (h Human) Friends(first int) []Character { return nil }
+
+	`
+
+	result := scanTestSource(t, src)
+	result.ComputeImplements()
+	var buf bytes.Buffer
+	if err := EmitGraphQL(result, &buf, GraphQLOptions{}); err != nil {
+		t.Fatalf("EmitGraphQL() error = %v", err)
+	}
+
+	want := `scalar JSON
+
+"Character in the films"
+interface Character {
+	friends(first: Int!): [Character!]!
+	id: String!
+	name: String
+}
+
+"Episode of the saga"
+enum Episode {
+	NEW_HOPE
+	EMPIRE
+	RETURN_OF_THE_JEDI
+}
+
+"Human is a person"
+type Person implements Character {
+	id: String!
+	name: String!
+	"Height in meters"
+	height: Float
+	appearsIn: [Episode!]!
+	buddies: [Person]!
+	related: PagePerson!
+	meta: JSON!
+}
+
+type PagePerson {
+	items: [Person!]!
+	total: Int!
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("EmitGraphQL() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	src := `
+	package test
+
+	type Priority int
+
+	const (
+		Low Priority = iota
+		High
+	)
+
+	type Audit struct {
+		CreatedBy string ` + "`json:\"created_by\"`" + `
+	}
+
+	type Line struct {
+		SKU string ` + "`json:\"sku\"`" + `
+	}
+
+	type Order struct {
+		Audit
+		Lines    []Line            ` + "`json:\"lines\"`" + `
+		Priority Priority          ` + "`json:\"priority\"`" + `
+		Note     *string           ` + "`json:\"note,omitempty\"`" + `
+		Labels   map[string]string ` + "`json:\"labels,omitempty\"`" + `
+	}
+	`
+
+	result := scanTestSource(t, src)
+	doc, err := result.JSONSchema("test.Order", JSONSchemaOptions{ID: "https://example.com/order.json"})
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "https://example.com/order.json",
+		"$ref": "#/$defs/Order",
+		"$defs": {
+			"Order": {
+				"type": "object",
+				"properties": {
+					"created_by": {"type": "string"},
+					"lines": {"type": "array", "items": {"$ref": "#/$defs/Line"}},
+					"priority": {"$ref": "#/$defs/Priority"},
+					"note": {"type": ["string", "null"]},
+					"labels": {"type": "object", "additionalProperties": {"type": "string"}}
+				},
+				"required": ["created_by", "lines", "priority"]
+			},
+			"Line": {
+				"type": "object",
+				"properties": {"sku": {"type": "string"}},
+				"required": ["sku"]
+			},
+			"Priority": {"type": "integer", "format": "int64", "enum": [0, 1]}
+		}
+	}`), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSONSchema() =\n%s", data)
+	}
+
+	if _, err := result.JSONSchema("test.Missing", JSONSchemaOptions{}); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+
+	// The whole result: every scanned struct is a root
+	var buf bytes.Buffer
+	if err := EmitJSONSchema(result, &buf, JSONSchemaOptions{}); err != nil {
+		t.Fatalf("EmitJSONSchema() error = %v", err)
+	}
+	var all map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &all); err != nil {
+		t.Fatalf("EmitJSONSchema() wrote invalid JSON: %v", err)
+	}
+	roots := []any{
+		map[string]any{"$ref": "#/$defs/Audit"},
+		map[string]any{"$ref": "#/$defs/Line"},
+		map[string]any{"$ref": "#/$defs/Order"},
+	}
+	if !reflect.DeepEqual(all["anyOf"], roots) {
+		t.Errorf("anyOf = %v, want %v", all["anyOf"], roots)
+	}
+	if defs := all["$defs"].(map[string]any); len(defs) != 4 {
+		t.Errorf("$defs = %v, want Audit, Line, Order and Priority", defs)
+	}
+}
+
+func TestEmitCSV(t *testing.T) {
+	src := `
+	package test
+
+	type Base struct {
+		ID int
+	}
+
+	func (b Base) Key() int { return b.ID }
+
+	type User struct {
+		Base
+		Name  string
+		email string
+	}
+
+	func (u *User) Rename(name string) {}
+
+	type Store interface {
+		Get(id int) (*User, error)
+	}
+	`
+	result := scanTestSource(t, src)
+
+	var buf bytes.Buffer
+	if err := result.EmitCSV(&buf); err != nil {
+		t.Fatalf("EmitCSV() error = %v", err)
+	}
+	want := `kind,id,package,name,fieldCount,methodCount,exported,distance
+struct,test.Base,test,Base,1,1,true,0
+interface,test.Store,test,Store,0,1,true,0
+struct,test.User,test,User,2,1,true,0
+`
+	if got := buf.String(); got != want {
+		t.Errorf("EmitCSV() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Promoted members are still told apart when read from a cache
+	cacheFile := filepath.Join(t.TempDir(), "csv.cache")
+	if err := result.ToCache(cacheFile); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := ReadCache(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := cached.EmitCSV(&buf); err != nil {
+		t.Fatalf("EmitCSV() error = %v", err)
+	}
+	// The test package isn't in the packages table, so it isn't restored
+	if got, want := buf.String(), strings.ReplaceAll(want, ",test,", ",,"); got != want {
+		t.Errorf("cached EmitCSV() =\n%s\nwant\n%s", got, want)
+	}
+	user, _ := cached.Types.Get("test.User")
+	base, _ := cached.Types.Get("test.Base")
+	for _, f := range user.(*gstypes.Struct).Fields() {
+		if f.Name() == "ID" && f.PromotedFrom() != base {
+			t.Errorf("cached ID promoted from %v, want the cached Base", f.PromotedFrom())
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src := `
+	package test
+
+	// User is a user
+	// @gen(table="users")
+	type User struct {
+		Name      string ` + "`json:\"name\" db:\"user_name\"`" + `
+		Tags      map[string][]*Tag
+		Notify    chan<- func(int) bool
+	}
+
+	// @gen
+	type Page[T any] struct {
+		Items []T
+	}
+
+	type Tag struct{}
+
+	type Store interface {
+		Get(ids ...string) (*User, error)
+	}
+	`
+	result := scanTestSource(t, src)
+
+	files, err := Generate(result, GenerateOptions{
+		Template: `package {{.Package.Name}}
+
+{{imports}}
+{{range .Types}}{{$t := .}}{{if hasAnnotation . "@gen"}}{{$gen := annotation . "@gen"}}
+type {{$t.Name}}Row{{typeParams $t}} struct {
+{{- range fields $t}}
+	{{.Name}} {{goType .Type}} {{with tag . "db"}}{{quote .}}{{end}}
+{{- end}}
+}
+
+func ({{$t.Name}}Row{{typeArgs $t}}) Table() string { return {{quote (or $gen.table (snake $t.Name))}} }
+{{end}}{{end}}
+{{- range .Types}}{{if eq .Kind "interface"}}{{range methods .}}
+type {{.Name}}Func func{{signature .}}
+{{end}}{{end}}{{end}}`,
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "test_gen.go" {
+		t.Fatalf("Generate() files = %v, want test_gen.go", files)
+	}
+	want := `package test
+
+type PageRow[T any] struct {
+	Items []T
+}
+
+func (PageRow[T]) Table() string { return "page" }
+
+type UserRow struct {
+	Name   string "user_name"
+	Tags   map[string][]*Tag
+	Notify chan<- func(int) bool
+}
+
+func (UserRow) Table() string { return "users" }
+
+type GetFunc func(ids ...string) (*User, error)
+`
+	if got := string(files[0].Content); got != want {
+		t.Errorf("Generate() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Per type, into another package: the scanned types are imported
+	files, err = Generate(result, GenerateOptions{
+		Template:    "package gen\n{{imports}}\nvar _ {{goType .Type}}\n",
+		PerType:     true,
+		PackagePath: "example.com/gen",
+		Output:      "gen/{{snake .Type.Name}}.go",
+		Filter:      func(t gstypes.Type) bool { return t.Kind() == gstypes.TypeKindStruct && t.Name() != "Page" },
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	if want := []string{"gen/tag.go", "gen/user.go"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Generate() paths = %v, want %v", paths, want)
+	}
+	if got, want := string(files[1].Content), "package gen\n\nimport (\n\t\"test\"\n)\n\nvar _ test.User\n"; got != want {
+		t.Errorf("Generate() =\n%s\nwant\n%s", got, want)
+	}
+
+	dir := t.TempDir()
+	if err := WriteGenerated(dir, files); err != nil {
+		t.Fatalf("WriteGenerated() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gen", "tag.go")); err != nil {
+		t.Errorf("WriteGenerated() did not write gen/tag.go: %v", err)
+	}
+}
+
+func TestGenerateMocks(t *testing.T) {
+	src := `
+	package test
+
+	type User struct{}
+
+	type Closer interface {
+		Close() error
+	}
+
+	// Store keeps users
+	// @mock
+	type Store interface {
+		Closer
+		Get(id string, fields ...string) (*User, error)
+		Put(*User)
+	}
+
+	// @mock(name="FakeCache")
+	type Cache[K comparable, V any] interface {
+		Load(key K) (V, bool)
+	}
+
+	type Number interface {
+		~int | ~float64
+	}
+	`
+	result := scanTestSource(t, src)
+
+	files, err := GenerateMocks(result, MockOptions{})
+	if err != nil {
+		t.Fatalf("GenerateMocks() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "test_mock_test.go" {
+		t.Fatalf("GenerateMocks() files = %v, want test_mock_test.go", files)
+	}
+	testify := string(files[0].Content)
+	for _, want := range []string{
+		"package test\n\nimport (\n\t\"github.com/stretchr/testify/mock\"\n)\n",
+		"type FakeCache[K comparable, V any] struct {\n\tmock.Mock\n}",
+		"func (m *FakeCache[K, V]) Load(p0 K) (V, bool) {",
+		"func (m *MockStore) Close() error {",
+		"func (m *MockStore) Get(p0 string, p1 ...string) (*User, error) {\n\tcallArgs := []any{p0}\n\tfor _, a := range p1 {\n\t\tcallArgs = append(callArgs, a)\n\t}\n\tret := m.Called(callArgs...)\n\tr0, _ := ret.Get(0).(*User)\n\tr1, _ := ret.Get(1).(error)\n\treturn r0, r1\n}",
+		"func (m *MockStore) Put(p0 *User) {\n\tcallArgs := []any{p0}\n\tm.Called(callArgs...)\n}",
+	} {
+		if !strings.Contains(testify, want) {
+			t.Errorf("GenerateMocks() testify mocks miss %q:\n%s", want, testify)
+		}
+	}
+	for _, unwanted := range []string{"MockCloser", "Number"} {
+		if strings.Contains(testify, unwanted) {
+			t.Errorf("GenerateMocks() testify mocks hold %s:\n%s", unwanted, testify)
+		}
+	}
+
+	// Every interface but the constraint, in a package of their own
+	files, err = GenerateMocks(result, MockOptions{Style: MockStyleGomock, All: true, PackagePath: "example.com/mocks", Output: "mocks.go"})
+	if err != nil {
+		t.Fatalf("GenerateMocks() error = %v", err)
+	}
+	gomock := string(files[0].Content)
+	for _, want := range []string{
+		"package mocks\n\nimport (\n\t\"reflect\"\n\t\"test\"\n\n\t\"go.uber.org/mock/gomock\"\n)\n",
+		"func NewMockCloser(ctrl *gomock.Controller) *MockCloser {",
+		"func NewFakeCache[K comparable, V any](ctrl *gomock.Controller) *FakeCache[K, V] {",
+		"func (m *MockStore) Get(p0 string, p1 ...string) (*test.User, error) {",
+		"func (mr *MockStoreMockRecorder) Get(p0 any, p1 ...any) *gomock.Call {",
+		`return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStore)(nil).Get), callArgs...)`,
+	} {
+		if !strings.Contains(gomock, want) {
+			t.Errorf("GenerateMocks() gomock mocks miss %q:\n%s", want, gomock)
+		}
+	}
+	if strings.Contains(gomock, "Number") {
+		t.Errorf("GenerateMocks() mocked the constraint Number:\n%s", gomock)
+	}
+
+	if _, err := GenerateMocks(result, MockOptions{Style: "fake"}); err == nil {
+		t.Errorf("GenerateMocks() with an unknown style succeeded")
+	}
+}
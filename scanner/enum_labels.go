@@ -0,0 +1,245 @@
+package scanner
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// extractEnumLabels records the display strings of the constants of the types of pkg with a
+// String() method, from the body of the method. The forms written by hand and generated by
+// stringer are understood:
+//
+//	switch c { case Red: return "red" ... }
+//	return names[c] // names is an array, slice or map literal of strings
+//	return _Color_name[_Color_index[c]:_Color_index[c+1]] // after an optional c -= offset
+//
+// Labels are keyed by the exact string of the constant value.
+func (r *defaultTypeResolver) extractEnumLabels(pkg *packages.Package) {
+	if pkg.TypesInfo == nil {
+		return
+	}
+	var inits map[*types.Var]ast.Expr
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body == nil || fd.Name.Name != "String" || fd.Recv == nil || len(fd.Recv.List[0].Names) == 0 {
+				continue
+			}
+			fn, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok || fn.Signature().Params().Len() != 0 || fn.Signature().Results().Len() != 1 {
+				continue
+			}
+			recvType := fn.Signature().Recv().Type()
+			if ptr, ok := recvType.(*types.Pointer); ok {
+				recvType = ptr.Elem()
+			}
+			named, ok := recvType.(*types.Named)
+			if !ok {
+				continue
+			}
+			if basic, ok := named.Underlying().(*types.Basic); !ok || basic.Info()&types.IsConstType == 0 {
+				continue
+			}
+			recv, ok := pkg.TypesInfo.Defs[fd.Recv.List[0].Names[0]].(*types.Var)
+			if !ok {
+				continue
+			}
+
+			if inits == nil {
+				inits = varInitializers(pkg)
+			}
+			l := &labeler{info: pkg.TypesInfo, recv: recv, inits: inits, labels: map[string]string{}}
+			l.body(fd.Body)
+			if len(l.labels) > 0 {
+				r.enumLabels.Set(named.Obj(), l.labels)
+			}
+		}
+	}
+}
+
+// varInitializers maps the package level variables of pkg declared with a single value to it
+func varInitializers(pkg *packages.Package) map[*types.Var]ast.Expr {
+	inits := map[*types.Var]ast.Expr{}
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if len(vs.Names) != len(vs.Values) {
+					continue
+				}
+				for i, name := range vs.Names {
+					if v, ok := pkg.TypesInfo.Defs[name].(*types.Var); ok {
+						inits[v] = vs.Values[i]
+					}
+				}
+			}
+		}
+	}
+	return inits
+}
+
+// labeler collects the labels of a String() method body
+type labeler struct {
+	info   *types.Info
+	recv   *types.Var
+	inits  map[*types.Var]ast.Expr
+	offset int64 // subtracted from the receiver before indexing (stringer's c -= 1)
+	labels map[string]string
+}
+
+func (l *labeler) body(body *ast.BlockStmt) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.SUB_ASSIGN && len(n.Lhs) == 1 && l.isRecv(n.Lhs[0]) {
+				if offset, ok := l.intConst(n.Rhs[0]); ok {
+					l.offset = offset
+				}
+			}
+		case *ast.SwitchStmt:
+			if n.Tag != nil && l.isRecv(n.Tag) {
+				l.switchCases(n)
+			}
+		case *ast.IndexExpr:
+			if l.isRecv(n.Index) {
+				l.lookupTable(n.X)
+			}
+		case *ast.SliceExpr:
+			l.nameIndex(n)
+		}
+		return true
+	})
+}
+
+// switchCases labels the values of the cases returning a constant string
+func (l *labeler) switchCases(sw *ast.SwitchStmt) {
+	for _, stmt := range sw.Body.List {
+		clause := stmt.(*ast.CaseClause)
+		if len(clause.Body) == 0 {
+			continue
+		}
+		ret, ok := clause.Body[0].(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			continue
+		}
+		label, ok := l.stringConst(ret.Results[0])
+		if !ok {
+			continue
+		}
+		for _, expr := range clause.List {
+			if tv, ok := l.info.Types[expr]; ok && tv.Value != nil {
+				l.set(tv.Value, label)
+			}
+		}
+	}
+}
+
+// lookupTable labels the values from the literal initializing the array, slice or map x
+func (l *labeler) lookupTable(x ast.Expr) {
+	lit, ok := l.initializer(x).(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	index := int64(0)
+	for _, elt := range lit.Elts {
+		value := elt
+		key := constant.MakeInt64(index + l.offset)
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			tv, ok := l.info.Types[kv.Key]
+			if !ok || tv.Value == nil {
+				return
+			}
+			key, value = tv.Value, kv.Value
+			if n, ok := constant.Int64Val(constant.ToInt(tv.Value)); ok {
+				index = n - l.offset
+			}
+		}
+		if label, ok := l.stringConst(value); ok {
+			l.set(key, label)
+		}
+		index++
+	}
+}
+
+// nameIndex labels the values of stringer's name[index[c]:index[c+1]] form
+func (l *labeler) nameIndex(expr *ast.SliceExpr) {
+	lowIdx, ok := expr.Low.(*ast.IndexExpr)
+	if !ok || !l.isRecv(lowIdx.Index) {
+		return
+	}
+	name, ok := l.stringConst(expr.X)
+	if !ok {
+		return
+	}
+	lit, ok := l.initializer(lowIdx.X).(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	bounds := make([]int64, 0, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		n, ok := l.intConst(elt)
+		if !ok || n < 0 || n > int64(len(name)) {
+			return
+		}
+		bounds = append(bounds, n)
+	}
+	for i := 0; i+1 < len(bounds); i++ {
+		if bounds[i] <= bounds[i+1] {
+			l.set(constant.MakeInt64(int64(i)+l.offset), name[bounds[i]:bounds[i+1]])
+		}
+	}
+}
+
+// initializer returns the literal initializing the package level variable x refers to
+func (l *labeler) initializer(x ast.Expr) ast.Expr {
+	ident, ok := ast.Unparen(x).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, ok := l.info.Uses[ident].(*types.Var)
+	if !ok {
+		return nil
+	}
+	return l.inits[v]
+}
+
+func (l *labeler) set(value constant.Value, label string) {
+	key := value.ExactString()
+	if _, exists := l.labels[key]; !exists {
+		l.labels[key] = label
+	}
+}
+
+// isRecv reports whether expr is the receiver, dereferenced for pointer receivers
+func (l *labeler) isRecv(expr ast.Expr) bool {
+	expr = ast.Unparen(expr)
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = ast.Unparen(star.X)
+	}
+	ident, ok := expr.(*ast.Ident)
+	return ok && l.info.Uses[ident] == l.recv
+}
+
+func (l *labeler) stringConst(expr ast.Expr) (string, bool) {
+	tv, ok := l.info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+func (l *labeler) intConst(expr ast.Expr) (int64, bool) {
+	tv, ok := l.info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return 0, false
+	}
+	return constant.Int64Val(tv.Value)
+}
@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// PackageLoadError reports a package that could not be loaded or does not compile
+type PackageLoadError struct {
+	Package string   // Import path or pattern of the package
+	Errors  []string // Errors reported by go/packages (list, parse and type errors)
+	Err     error    // Underlying cause when loading itself failed
+}
+
+func (e *PackageLoadError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("failed to load package %s: %v", e.Package, e.Err)
+	}
+	return fmt.Sprintf("package %s has errors:\n%s", e.Package, strings.Join(e.Errors, "\n"))
+}
+
+func (e *PackageLoadError) Unwrap() error {
+	return e.Err
+}
+
+// ResolveError reports a failure of the scanner processing a package or resolving a type
+// from a package that loaded fine
+type ResolveError struct {
+	Package string // Import path of the package being processed
+	TypeID  string // Id of the type being resolved, empty when processing the whole package
+	Err     error
+}
+
+func (e *ResolveError) Error() string {
+	if e.TypeID != "" {
+		return fmt.Sprintf("failed to resolve %s: %v", e.TypeID, e.Err)
+	}
+	return fmt.Sprintf("failed to process package %s: %v", e.Package, e.Err)
+}
+
+func (e *ResolveError) Unwrap() error {
+	return e.Err
+}
+
+// CacheError reports a failure reading or writing a cache file
+type CacheError struct {
+	Path string // Cache file path
+	Op   string // "read" or "write"
+	Err  error
+}
+
+func (e *CacheError) Error() string {
+	return fmt.Sprintf("cache %s %s: %v", e.Op, e.Path, e.Err)
+}
+
+func (e *CacheError) Unwrap() error {
+	return e.Err
+}
+
+// CycleError reports the dependency cycles broken by TopoSort. Each cycle lists type ids
+// in dependency order, the last one depending on the first.
+type CycleError struct {
+	Cycles [][]string
+}
+
+func (e *CycleError) Error() string {
+	cycles := make([]string, len(e.Cycles))
+	for i, cycle := range e.Cycles {
+		cycles[i] = strings.Join(cycle, " -> ") + " -> " + cycle[0]
+	}
+	return fmt.Sprintf("dependency cycles: %s", strings.Join(cycles, "; "))
+}
+
+// packageErrors returns the load error of the first package in pkgs with errors, if any
+func packageErrors(pkgs []*packages.Package) error {
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) == 0 {
+			continue
+		}
+		msgs := make([]string, 0, len(pkg.Errors))
+		for _, e := range pkg.Errors {
+			msgs = append(msgs, e.Error())
+		}
+		return &PackageLoadError{Package: pkg.PkgPath, Errors: msgs}
+	}
+	return nil
+}
+
+// checkPackageErrors fails with the load error of the first package in pkgs with errors
+// when Config.FailOnPackageErrors is set, otherwise it records them as warnings
+func checkPackageErrors(cfg *Config, resolver *defaultTypeResolver, pkgs []*packages.Package) error {
+	if cfg.FailOnPackageErrors {
+		return packageErrors(pkgs)
+	}
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			resolver.warnf("package %s: %v", pkg.PkgPath, e)
+		}
+	}
+	return nil
+}
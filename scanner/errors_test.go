@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanErrors(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+
+	// A package that doesn't compile is scanned with its errors as warnings
+	cfg.Packages = []string{"./testdata/broken"}
+	result, err := NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+	if warnings := result.Warnings(); len(warnings) == 0 || !strings.Contains(warnings[0], "testdata/broken") {
+		t.Errorf("Warnings() = %v, want the errors of the broken package", warnings)
+	}
+
+	// Unless they fail the scan
+	cfg.FailOnPackageErrors = true
+	_, err = NewScanner().ScanWithConfig(cfg)
+	var loadErr *PackageLoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("ScanWithConfig() error = %v (%T), want *PackageLoadError", err, err)
+	}
+	if loadErr.Package != "github.com/pablor21/goscanner/scanner/testdata/broken" || len(loadErr.Errors) == 0 {
+		t.Errorf("unexpected load error: package %q, errors %v", loadErr.Package, loadErr.Errors)
+	}
+
+	// The same from ResolveSingle
+	if _, _, err := ResolveSingle("./testdata/broken", "Broken", cfg); !errors.As(err, &loadErr) {
+		t.Errorf("ResolveSingle() error = %v (%T), want *PackageLoadError", err, err)
+	}
+
+	// A type that doesn't exist in a package that compiles
+	_, _, err = ResolveSingle("../examples/starwars/models", "Missing", cfg)
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) {
+		t.Fatalf("ResolveSingle() error = %v (%T), want *ResolveError", err, err)
+	}
+	if resolveErr.TypeID != "github.com/pablor21/goscanner/examples/starwars/models.Missing" {
+		t.Errorf("ResolveError.TypeID = %q", resolveErr.TypeID)
+	}
+
+	// Cache failures
+	path := filepath.Join(t.TempDir(), "cache.gz")
+	if err := os.WriteFile(path, []byte("not a cache"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadCache(path)
+	var cacheErr *CacheError
+	if !errors.As(err, &cacheErr) || cacheErr.Path != path || cacheErr.Op != "read" {
+		t.Errorf("ReadCache() error = %v (%T), want a read *CacheError for %s", err, err, path)
+	}
+	if err := WriteCache("", NewScanningResult()); !errors.As(err, &cacheErr) || cacheErr.Op != "write" {
+		t.Errorf("WriteCache() error = %v (%T), want a write *CacheError", err, err)
+	}
+}
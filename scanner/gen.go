@@ -0,0 +1,556 @@
+package scanner
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// GenerateOptions configures the files rendered by Generate
+type GenerateOptions struct {
+	// Template is the source of the template, TemplateFiles the files parsed along with it
+	// (at least one of them is required). Name selects the template executed, by default
+	// Template or the first file.
+	Template      string
+	TemplateFiles []string
+	Name          string
+
+	// PerType renders a file per named type of the scanned packages, instead of a file per
+	// package
+	PerType bool
+
+	// Output is a template of the path of each file, executed with its GenData. The default
+	// is "{{.Package.Name}}_gen.go" per package and "{{snake .Type.Name}}_gen.go" per type.
+	Output string
+
+	// PackagePath is the import path of the generated files, whose types are written
+	// unqualified. The default is the path of the package the file is rendered for, for files
+	// generated next to its sources.
+	PackagePath string
+
+	// Filter selects the types rendered (all the named types when nil). Packages left without
+	// types nor functions get no file.
+	Filter func(t gstypes.Type) bool
+
+	// Funcs are added to the helpers of the templates, overriding them on conflicts
+	Funcs template.FuncMap
+
+	// NoFormat keeps the .go files as rendered, they're gofmt'ed otherwise
+	NoFormat bool
+}
+
+// GenData is the data the templates of Generate are executed with
+type GenData struct {
+	// Package is the package the file is rendered for
+	Package *gstypes.Package
+	// Type is the type the file is rendered for, nil per package
+	Type gstypes.Type
+	// Types are the named types of the file in name order (Type alone per type), Functions
+	// and Values the functions and values of the package (nil per type)
+	Types     []gstypes.Type
+	Functions []*gstypes.Function
+	Values    []*gstypes.Value
+	// Result is the scanning result rendered
+	Result *ScanningResult
+}
+
+// GeneratedFile is a file rendered by Generate
+type GeneratedFile struct {
+	Path    string
+	Content []byte
+}
+
+// genImportsPlaceholder marks where the imports helper writes the import declaration, only
+// known once the whole file is rendered
+const genImportsPlaceholder = "\x00goscanner:imports\x00"
+
+// Generate renders the template for each package (or type, see PerType) of the scanned
+// packages. Besides the text/template builtins, templates can call:
+//
+//   - goType T: the Go source of a type reference, qualified and imported as needed
+//   - imports: the import declaration of the packages referenced, in place of the call
+//   - import "path": imports a package, returning the name it's referenced by
+//   - params M, results M, signature M: the parameters "(a int, b ...string)", results
+//     "(int, error)" and both of a method or function
+//   - typeParams T, typeArgs T: the type parameters of a generic type or function as
+//     declared ("[K comparable, V any]") and as passed ("[K, V]"), empty for the others
+//   - fields T, methods T: the fields of a struct and the methods of a type
+//   - tags F, tag F "json": the tags of a field, and the name of one (before the comma)
+//   - doc T, annotation T "@name", hasAnnotation T "@name": the comment lines above a
+//     type, function, field or method and the arguments of an annotation in them
+//   - dir P: the directory of the sources of a package, for output paths next to them
+//     (empty for results read from a cache)
+//   - snake, camel, pascal, lower, upper, join, quote: string helpers
+//
+// The files are returned in path order, see WriteGenerated to write them.
+func Generate(result *ScanningResult, opts GenerateOptions) ([]*GeneratedFile, error) {
+	tmpl, err := opts.parse()
+	if err != nil {
+		return nil, err
+	}
+	output := opts.Output
+	if output == "" {
+		output = "{{.Package.Name}}_gen.go"
+		if opts.PerType {
+			output = "{{snake .Type.Name}}_gen.go"
+		}
+	}
+	pathTmpl, err := template.New("output").Funcs(genFuncs(newGenImports(""))).Parse(output)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output path template: %w", err)
+	}
+
+	units, err := genUnits(result, opts)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*GeneratedFile, 0, len(units))
+	for _, data := range units {
+		var path bytes.Buffer
+		if err := pathTmpl.Execute(&path, data); err != nil {
+			return nil, fmt.Errorf("rendering the output path: %w", err)
+		}
+
+		self := opts.PackagePath
+		if self == "" {
+			self = data.Package.Path()
+		}
+		imports := newGenImports(self)
+		fileTmpl, err := tmpl.Clone()
+		if err != nil {
+			return nil, err
+		}
+		fileTmpl.Funcs(genFuncs(imports)).Funcs(opts.Funcs)
+
+		var buf bytes.Buffer
+		if err := fileTmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", path.String(), err)
+		}
+		content := bytes.Replace(buf.Bytes(), []byte(genImportsPlaceholder), []byte(imports.decl()), 1)
+		if !opts.NoFormat && strings.HasSuffix(path.String(), ".go") {
+			formatted, err := format.Source(content)
+			if err != nil {
+				return nil, fmt.Errorf("%s is not valid Go: %w", path.String(), err)
+			}
+			content = formatted
+		}
+		files = append(files, &GeneratedFile{Path: path.String(), Content: content})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// WriteGenerated writes the files under dir (absolute paths as they are), creating the
+// directories of their paths
+func WriteGenerated(dir string, files []*GeneratedFile) error {
+	for _, f := range files {
+		path := f.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, f.Content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasAnnotation reports whether the comments above t hold the annotation name ("@gen",
+// with or without arguments), for the Filter of GenerateOptions
+func HasAnnotation(t gstypes.Type, name string) bool {
+	return annotationArgs(DocLines(t), name) != nil
+}
+
+// parse parses the templates of the options with the helpers of Generate
+func (o GenerateOptions) parse() (*template.Template, error) {
+	if o.Template == "" && len(o.TemplateFiles) == 0 {
+		return nil, fmt.Errorf("no template to generate from")
+	}
+	name := "gen"
+	if o.Template == "" {
+		name = filepath.Base(o.TemplateFiles[0])
+	}
+	tmpl := template.New(name).Funcs(genFuncs(newGenImports(""))).Funcs(o.Funcs)
+	var err error
+	if o.Template != "" {
+		if tmpl, err = tmpl.Parse(o.Template); err != nil {
+			return nil, err
+		}
+	}
+	if len(o.TemplateFiles) > 0 {
+		if tmpl, err = tmpl.ParseFiles(o.TemplateFiles...); err != nil {
+			return nil, err
+		}
+	}
+	if o.Name != "" {
+		if tmpl = tmpl.Lookup(o.Name); tmpl == nil {
+			return nil, fmt.Errorf("no template named %q", o.Name)
+		}
+	}
+	return tmpl, nil
+}
+
+// genUnits returns the data of the files to render, in package path order
+func genUnits(result *ScanningResult, opts GenerateOptions) ([]*GenData, error) {
+	byPkg := map[string]*GenData{}
+	for _, pkg := range result.Packages.Values() {
+		if pkg.Distance() == 0 {
+			byPkg[pkg.Path()] = &GenData{Package: pkg, Result: result}
+		}
+	}
+
+	for _, t := range result.Types.Values() {
+		if !t.IsNamed() || t.Package() == nil {
+			continue
+		}
+		data := byPkg[t.Package().Path()]
+		if data == nil {
+			continue
+		}
+		if _, ok := t.(*gstypes.InstantiatedGeneric); ok {
+			continue
+		}
+		if opts.Filter != nil && !opts.Filter(t) {
+			continue
+		}
+		if err := t.Load(); err != nil {
+			return nil, err
+		}
+		if fn, ok := t.(*gstypes.Function); ok {
+			data.Functions = append(data.Functions, fn)
+		} else {
+			data.Types = append(data.Types, t)
+		}
+	}
+	for _, v := range result.Values.Values() {
+		if v.Package() == nil {
+			continue
+		}
+		if data := byPkg[v.Package().Path()]; data != nil {
+			data.Values = append(data.Values, v)
+		}
+	}
+
+	paths := make([]string, 0, len(byPkg))
+	for path := range byPkg {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var units []*GenData
+	for _, path := range paths {
+		data := byPkg[path]
+		if len(data.Types) == 0 && len(data.Functions) == 0 {
+			continue
+		}
+		sort.Slice(data.Types, func(i, j int) bool { return data.Types[i].Name() < data.Types[j].Name() })
+		sort.Slice(data.Functions, func(i, j int) bool { return data.Functions[i].Name() < data.Functions[j].Name() })
+		sort.Slice(data.Values, func(i, j int) bool { return data.Values[i].Name() < data.Values[j].Name() })
+		if !opts.PerType {
+			units = append(units, data)
+			continue
+		}
+		for _, t := range data.Types {
+			units = append(units, &GenData{Package: data.Package, Type: t, Types: []gstypes.Type{t}, Result: result})
+		}
+	}
+	return units, nil
+}
+
+// genImports are the packages referenced by a generated file
+type genImports struct {
+	self   string            // import path of the generated file
+	byPath map[string]string // names the packages are referenced by
+	names  UniqueNames
+}
+
+func newGenImports(self string) *genImports {
+	return &genImports{self: self, byPath: map[string]string{}, names: UniqueNames{}}
+}
+
+// add imports the package at path, returning the name it's referenced by (empty for the
+// package of the file). name is the package name, the last element of path when empty.
+func (im *genImports) add(path, name string) string {
+	if path == "" || path == im.self {
+		return ""
+	}
+	if ref, ok := im.byPath[path]; ok {
+		return ref
+	}
+	if name == "" {
+		name = path[strings.LastIndex(path, "/")+1:]
+	}
+	ref := im.names.Reserve(name)
+	im.byPath[path] = ref
+	return ref
+}
+
+// qualify returns the prefix of the names declared in pkg ("pkg."), importing it
+func (im *genImports) qualify(pkg *gstypes.Package) string {
+	if pkg == nil {
+		return ""
+	}
+	if ref := im.add(pkg.Path(), pkg.Name()); ref != "" {
+		return ref + "."
+	}
+	return ""
+}
+
+// decl returns the import declaration of the packages added, empty when there are none
+func (im *genImports) decl() string {
+	if len(im.byPath) == 0 {
+		return ""
+	}
+	paths := make([]string, 0, len(im.byPath))
+	for path := range im.byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// The standard library first, as goimports groups them
+	std := func(path string) bool { return !strings.Contains(strings.Split(path, "/")[0], ".") }
+	sort.SliceStable(paths, func(i, j int) bool { return std(paths[i]) && !std(paths[j]) })
+
+	var b strings.Builder
+	b.WriteString("import (\n")
+	for i, path := range paths {
+		if i > 0 && std(paths[i-1]) && !std(path) {
+			b.WriteString("\n")
+		}
+		b.WriteString("\t")
+		if ref := im.byPath[path]; ref != path[strings.LastIndex(path, "/")+1:] {
+			b.WriteString(ref + " ")
+		}
+		b.WriteString(strconv.Quote(path) + "\n")
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// genFuncs returns the helpers of the templates of Generate, importing into imports
+func genFuncs(imports *genImports) template.FuncMap {
+	return template.FuncMap{
+		"goType": func(t gstypes.Type) string { return goTypeExpr(t, imports) },
+		"imports": func() string {
+			return genImportsPlaceholder
+		},
+		"import": func(path string) string { return imports.add(path, "") },
+		"params": func(fn any) string {
+			params, _ := signatureOf(fn)
+			return goParams(params, imports)
+		},
+		"results": func(fn any) string {
+			_, results := signatureOf(fn)
+			return goResults(results, imports)
+		},
+		"signature": func(fn any) string {
+			params, results := signatureOf(fn)
+			if res := goResults(results, imports); res != "" {
+				return goParams(params, imports) + " " + res
+			}
+			return goParams(params, imports)
+		},
+		"typeParams": func(t any) string { return goTypeParams(t, imports, true) },
+		"typeArgs":   func(t any) string { return goTypeParams(t, imports, false) },
+		"fields":     fieldsOf,
+		"methods": func(t gstypes.Type) []*gstypes.Method {
+			if t == nil {
+				return nil
+			}
+			return t.Methods()
+		},
+		"tags": func(f *gstypes.Field) map[string]string { return ParseTags(f.Tag()) },
+		"tag": func(f *gstypes.Field, key string) string {
+			name, _, _ := strings.Cut(ParseTags(f.Tag())[key], ",")
+			return name
+		},
+		"doc": DocLines,
+		"annotation": func(t gstypes.Type, name string) map[string]string {
+			return annotationArgs(DocLines(t), name)
+		},
+		"hasAnnotation": HasAnnotation,
+		"dir": func(pkg *gstypes.Package) string {
+			if goPkg := pkg.GoPackage(); goPkg != nil {
+				if goPkg.Dir != "" {
+					return goPkg.Dir
+				}
+				if len(goPkg.GoFiles) > 0 {
+					return filepath.Dir(goPkg.GoFiles[0])
+				}
+			}
+			return ""
+		},
+		"snake":  func(s string) string { return SnakeCase(s, nil) },
+		"camel":  func(s string) string { return CamelCase(s, nil) },
+		"pascal": func(s string) string { return ExportedName(CamelCase(s, nil)) },
+		"lower":  strings.ToLower,
+		"upper":  strings.ToUpper,
+		"join":   func(sep string, items []string) string { return strings.Join(items, sep) },
+		"quote":  strconv.Quote,
+	}
+}
+
+// signatureOf returns the parameters and results of a method or function
+func signatureOf(fn any) ([]*gstypes.Parameter, []*gstypes.Result) {
+	switch f := fn.(type) {
+	case *gstypes.Method:
+		return f.Parameters(), f.Results()
+	case *gstypes.Function:
+		return f.Parameters(), f.Results()
+	}
+	return nil, nil
+}
+
+// goParams returns the Go source of a parameter list ("(a int, b ...string)")
+func goParams(params []*gstypes.Parameter, imports *genImports) string {
+	list := make([]string, len(params))
+	for i, p := range params {
+		typ := goTypeExpr(p.Type(), imports)
+		if p.IsVariadic() {
+			typ = "..." + typ
+		}
+		list[i] = strings.TrimSpace(p.Name() + " " + typ)
+	}
+	return "(" + strings.Join(list, ", ") + ")"
+}
+
+// goResults returns the Go source of a result list: empty, a single unnamed type or a
+// parenthesized list
+func goResults(results []*gstypes.Result, imports *genImports) string {
+	if len(results) == 1 && results[0].Name() == "" {
+		return goTypeExpr(results[0].Type(), imports)
+	}
+	if len(results) == 0 {
+		return ""
+	}
+	list := make([]string, len(results))
+	for i, r := range results {
+		list[i] = strings.TrimSpace(r.Name() + " " + goTypeExpr(r.Type(), imports))
+	}
+	return "(" + strings.Join(list, ", ") + ")"
+}
+
+// goTypeParams returns the type parameters of a generic type or function, with their
+// constraints or as type arguments, empty when it has none
+func goTypeParams(t any, imports *genImports, constraints bool) string {
+	generic, ok := t.(interface {
+		TypeParams() []*gstypes.TypeParameter
+	})
+	if !ok || len(generic.TypeParams()) == 0 {
+		return ""
+	}
+	list := make([]string, len(generic.TypeParams()))
+	for i, tp := range generic.TypeParams() {
+		list[i] = tp.Name()
+		if constraints {
+			list[i] += " " + goTypeExpr(tp.Constraint(), imports)
+		}
+	}
+	return "[" + strings.Join(list, ", ") + "]"
+}
+
+// goTypeExpr returns the Go source of a reference to t, qualifying (and importing) the named
+// types of other packages than the one of the generated file
+func goTypeExpr(t gstypes.Type, imports *genImports) string {
+	if t == nil {
+		return "any"
+	}
+	switch typed := t.(type) {
+	case *gstypes.TypeParameter:
+		return typed.Name()
+	case *gstypes.InstantiatedGeneric:
+		origin := typed.Origin()
+		if origin == nil {
+			break
+		}
+		args := make([]string, len(typed.TypeArgs()))
+		for i, arg := range typed.TypeArgs() {
+			args[i] = goTypeExpr(arg.Type, imports)
+		}
+		return goTypeExpr(origin, imports) + "[" + strings.Join(args, ", ") + "]"
+	}
+	if t.IsNamed() {
+		return imports.qualify(t.Package()) + t.Name()
+	}
+
+	switch typed := t.(type) {
+	case *gstypes.Basic:
+		return typed.Name()
+	case *gstypes.Pointer:
+		return strings.Repeat("*", max(typed.Depth(), 1)) + goTypeExpr(typed.Elem(), imports)
+	case *gstypes.Slice:
+		if typed.IsArray() {
+			return "[" + strconv.FormatInt(typed.Len(), 10) + "]" + goTypeExpr(typed.Elem(), imports)
+		}
+		return "[]" + goTypeExpr(typed.Elem(), imports)
+	case *gstypes.Map:
+		return "map[" + goTypeExpr(typed.Key(), imports) + "]" + goTypeExpr(typed.Value(), imports)
+	case *gstypes.Chan:
+		switch typed.Dir() {
+		case gstypes.ChanDirSend:
+			return "chan<- " + goTypeExpr(typed.Elem(), imports)
+		case gstypes.ChanDirRecv:
+			return "<-chan " + goTypeExpr(typed.Elem(), imports)
+		}
+		return "chan " + goTypeExpr(typed.Elem(), imports)
+	case *gstypes.Function:
+		sig := "func" + goParams(typed.Parameters(), imports)
+		if res := goResults(typed.Results(), imports); res != "" {
+			sig += " " + res
+		}
+		return sig
+	case *gstypes.Struct:
+		fields := make([]string, 0, len(typed.Fields()))
+		for _, f := range typed.Fields() {
+			if f.PromotedFrom() != nil {
+				continue
+			}
+			field := goTypeExpr(f.Type(), imports)
+			if !f.IsEmbedded() {
+				field = f.Name() + " " + field
+			}
+			if f.Tag() != "" {
+				field += " " + strconv.Quote(f.Tag())
+			}
+			fields = append(fields, field)
+		}
+		return "struct{ " + strings.Join(fields, "; ") + " }"
+	case *gstypes.Union:
+		terms := make([]string, len(typed.Terms()))
+		for i, term := range typed.Terms() {
+			terms[i] = goTypeExpr(term.Type(), imports)
+			if term.Approximation() {
+				terms[i] = "~" + terms[i]
+			}
+		}
+		return strings.Join(terms, " | ")
+	case *gstypes.Interface:
+		if typed.IsPredeclared() || len(typed.Methods()) == 0 && len(typed.Embeds()) == 0 {
+			return "any"
+		}
+		var elems []string
+		for _, embed := range typed.Embeds() {
+			elems = append(elems, goTypeExpr(embed, imports))
+		}
+		for _, m := range typed.Methods() {
+			sig := m.Name() + goParams(m.Parameters(), imports)
+			if res := goResults(m.Results(), imports); res != "" {
+				sig += " " + res
+			}
+			elems = append(elems, sig)
+		}
+		return "interface{ " + strings.Join(elems, "; ") + " }"
+	}
+	return t.Name()
+}
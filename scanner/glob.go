@@ -1,6 +1,10 @@
 package scanner
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
@@ -12,6 +16,10 @@ type PackageGlob struct {
 	Recursive bool
 	ModPath   string
 	PkgPath   string
+	Dir       string // directory the pattern is loaded from (its module context), empty means the working directory
+	Tests     bool   // also load the test packages (package x_test) of the matched packages
+	// Context cancels the go list and parsing of the packages, nil means no cancellation
+	Context context.Context
 }
 
 // ParseGlob parses a glob pattern and returns a PackageGlob
@@ -111,8 +119,8 @@ func (g *PackageGlob) LoadPackages(mode ScanMode) ([]*packages.Package, error) {
 
 	var loadMode packages.LoadMode
 
-	// Always need basic package info
-	loadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports
+	// Always need basic package info, and the module of each package for provenance
+	loadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedModule
 
 	// Add modes based on ScanMode flags
 	if mode.Has(ScanModeTypes) {
@@ -129,32 +137,130 @@ func (g *PackageGlob) LoadPackages(mode ScanMode) ([]*packages.Package, error) {
 	}
 
 	config := &packages.Config{
-		Mode: loadMode,
-		// Tests: true, // Uncomment if you want to include test files
+		Context: g.Context,
+		Mode:    loadMode,
+		Dir:     g.Dir,
+		Tests:   g.Tests,
 	}
 
-	return packages.Load(config, patterns...)
+	pkgs, err := packages.Load(config, patterns...)
+	if err != nil || !g.Tests {
+		return pkgs, err
+	}
+	return withoutTestVariants(pkgs), nil
+}
+
+// withoutTestVariants drops the packages a test load adds besides the external test packages:
+// the generated test binaries (x.test) and the copies of x recompiled with its test files
+// ("x [x.test]"), keeping the plain packages
+func withoutTestVariants(pkgs []*packages.Package) []*packages.Package {
+	kept := pkgs[:0]
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.PkgPath, ".test") {
+			continue
+		}
+		if strings.Contains(pkg.ID, " [") && !strings.HasSuffix(pkg.PkgPath, "_test") {
+			continue
+		}
+		kept = append(kept, pkg)
+	}
+	return kept
 }
 
 // GlobScanner handles package discovery
-type GlobScanner struct{}
+type GlobScanner struct {
+	Dir   string // directory patterns are loaded from, empty means the working directory
+	Tests bool   // also load the test packages (package x_test) of the matched packages
+	// Context cancels the loading of the packages, nil means no cancellation
+	Context context.Context
+}
 
 func NewGlobScanner() *GlobScanner {
 	return &GlobScanner{}
 }
 
-// ScanPackages scans packages matching the provided patterns
+// ScanPackages scans packages matching the provided patterns. Patterns prefixed with "!"
+// leave out the packages they match, e.g. "./..." and "!./internal/...": relative and
+// absolute patterns match the package directories, the others the package paths, and a
+// trailing "/..." matches the directory or path and everything below it, like go list.
+// Packages matched by several patterns are loaded once.
 func (s *GlobScanner) ScanPackages(mode ScanMode, patterns ...string) ([]*packages.Package, error) {
 	var allPackages []*packages.Package
+	var excludes []string
+	seen := map[string]bool{}
 
 	for _, pattern := range patterns {
+		if excluded, ok := strings.CutPrefix(pattern, "!"); ok {
+			expanded, _ := ParseGlob(excluded).ExpandGlob()
+			excludes = append(excludes, expanded...)
+			continue
+		}
 		glob := ParseGlob(pattern)
+		glob.Dir = s.Dir
+		glob.Tests = s.Tests
+		glob.Context = s.Context
 		pkgs, err := glob.LoadPackages(mode)
 		if err != nil {
-			return nil, err
+			if s.Context != nil && s.Context.Err() != nil {
+				// Cancelled, not a load failure of the package
+				return nil, s.Context.Err()
+			}
+			return nil, &PackageLoadError{Package: pattern, Err: err}
+		}
+		for _, pkg := range pkgs {
+			if !seen[pkg.ID] {
+				seen[pkg.ID] = true
+				allPackages = append(allPackages, pkg)
+			}
 		}
-		allPackages = append(allPackages, pkgs...)
 	}
 
-	return allPackages, nil
+	if len(excludes) == 0 {
+		return allPackages, nil
+	}
+	kept := allPackages[:0]
+	for _, pkg := range allPackages {
+		if !s.excluded(pkg, excludes) {
+			kept = append(kept, pkg)
+		}
+	}
+	return kept, nil
+}
+
+// excluded reports whether pkg matches one of the exclusion patterns of ScanPackages
+func (s *GlobScanner) excluded(pkg *packages.Package, patterns []string) bool {
+	for _, pattern := range patterns {
+		if !isFilesystemPattern(pattern) {
+			if matchPackagePattern(pattern, pkg.PkgPath) {
+				return true
+			}
+			continue
+		}
+		if pkg.Dir == "" {
+			continue
+		}
+		dir := pattern
+		if !filepath.IsAbs(dir) {
+			base := s.Dir
+			if base == "" {
+				base, _ = os.Getwd()
+			}
+			dir = filepath.Join(base, dir)
+		}
+		if matchPackagePattern(filepath.ToSlash(filepath.Clean(dir)), filepath.ToSlash(pkg.Dir)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPackagePattern reports whether path matches the go list pattern: "..." matches any
+// string and a trailing "/..." the path without it as well ("net/..." matches "net")
+func matchPackagePattern(pattern, path string) bool {
+	expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\.\.\.`, `.*`)
+	if before, ok := strings.CutSuffix(expr, `/.*`); ok {
+		expr = before + `(/.*)?`
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	return err == nil && re.MatchString(path)
 }
@@ -0,0 +1,483 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// GraphQLOptions controls the schema generated by EmitGraphQL
+type GraphQLOptions struct {
+	// TagKey is the tag naming the fields when there is no annotation (default "json")
+	TagKey string `json:"tag_key,omitempty" yaml:"tag_key,omitempty"`
+}
+
+// graphqlAnnotation is the comment annotation overriding the generated names and nullability
+const graphqlAnnotation = "@graphql"
+
+// graphqlScalars maps the predeclared types to the GraphQL scalars
+var graphqlScalars = map[string]string{
+	"bool":    "Boolean",
+	"string":  "String",
+	"int":     "Int",
+	"int8":    "Int",
+	"int16":   "Int",
+	"int32":   "Int",
+	"int64":   "Int",
+	"rune":    "Int",
+	"uint":    "Int",
+	"uint8":   "Int",
+	"byte":    "Int",
+	"uint16":  "Int",
+	"uint32":  "Int",
+	"uint64":  "Int",
+	"float32": "Float",
+	"float64": "Float",
+}
+
+// graphqlCustomScalars are the custom scalars standing for well known types, maps and any
+var graphqlCustomScalars = map[string]string{
+	"time.Time":                   "Time",
+	"time.Duration":               "Int",
+	"encoding/json.RawMessage":    "JSON",
+	"github.com/google/uuid.UUID": "ID",
+}
+
+// gqlFile builds the schema of EmitGraphQL
+type gqlFile struct {
+	result    *ScanningResult
+	tagKey    string
+	names     map[string]string // declared names, by type id
+	used      UniqueNames
+	queue     []gstypes.Type // named types referenced, waiting for their declaration
+	scalars   map[string]bool
+	constants map[string][]*gstypes.Value
+}
+
+// EmitGraphQL writes a GraphQL SDL schema with the structs, interfaces and enums of the
+// scanned packages and the named types they reference, in id order. Structs become object
+// types implementing the interfaces they satisfy (Config.ComputeImplements), interfaces
+// become interfaces with a field per exported method (its parameters as arguments, its first
+// result, not an error, as type, constraint-only ones left out with Config.MarkConstraintOnly)
+// and named types with constants enums.
+//
+// Fields are named by their json tag, or the camelCased Go name. Pointers are nullable, the
+// other types non-null. Integers are Int, floats Float, time.Time the Time scalar and maps
+// and any the JSON scalar; instances of generic types are object types named after the
+// origin and the type arguments (Page[User] is PageUser). Fields tagged "-", unexported
+// fields, channels and functions are skipped.
+//
+// A @graphql annotation in the comments of a type, field, method or constant overrides the
+// generated schema: @graphql(name="node") renames it, @graphql(nullable) and
+// @graphql(nullable=false) force the nullability of a field or method, and @graphql(skip)
+// leaves it out. Annotations are removed from the descriptions.
+func EmitGraphQL(result *ScanningResult, w io.Writer, opts GraphQLOptions) error {
+	if opts.TagKey == "" {
+		opts.TagKey = "json"
+	}
+	f := &gqlFile{
+		result:    result,
+		tagKey:    opts.TagKey,
+		names:     map[string]string{},
+		used:      UniqueNames{},
+		scalars:   map[string]bool{},
+		constants: result.ConstantsByType(),
+	}
+
+	ids := result.Types.Keys()
+	sort.Strings(ids)
+	for _, id := range ids {
+		t, _ := result.Types.Get(id)
+		if t == nil || t.Distance() != 0 || !t.IsNamed() {
+			continue
+		}
+		if err := t.Load(); err != nil {
+			return err
+		}
+		if _, skip := annotationArgs(DocLines(t), graphqlAnnotation)["skip"]; skip {
+			continue
+		}
+		switch typed := t.(type) {
+		case *gstypes.Struct:
+			if len(typed.TypeParams()) == 0 {
+				f.ref(t)
+			}
+		case *gstypes.Interface:
+			if len(typed.TypeParams()) == 0 && !typed.ConstraintOnly() && len(typed.Methods()) > 0 {
+				f.ref(t)
+			}
+		case *gstypes.Basic, *gstypes.Enum:
+			if len(EnumValues(t, f.constants)) > 0 {
+				f.ref(t)
+			}
+		}
+	}
+
+	var decls strings.Builder
+	bw := bufio.NewWriter(&decls)
+	for len(f.queue) > 0 {
+		t := f.queue[0]
+		f.queue = f.queue[1:]
+		if err := t.Load(); err != nil {
+			return err
+		}
+		bw.WriteString("\n")
+		writeGraphQLDescription(bw, "", DocLines(t))
+		f.declare(bw, t)
+	}
+	bw.Flush()
+
+	out := bufio.NewWriter(w)
+	scalars := make([]string, 0, len(f.scalars))
+	for name := range f.scalars {
+		scalars = append(scalars, name)
+	}
+	sort.Strings(scalars)
+	for _, name := range scalars {
+		fmt.Fprintf(out, "scalar %s\n", name)
+	}
+	body := decls.String()
+	if len(scalars) == 0 {
+		body = strings.TrimPrefix(body, "\n")
+	}
+	out.WriteString(body)
+	return out.Flush()
+}
+
+// ref returns the name of the declaration of t, queueing it on first use
+func (f *gqlFile) ref(t gstypes.Type) string {
+	name, ok := f.names[t.Id()]
+	if !ok {
+		name = f.typeName(t)
+		if f.used[name] && t.Package() != nil {
+			name = ExportedName(t.Package().Name()) + name
+		}
+		name = f.used.Reserve(name)
+		f.names[t.Id()] = name
+		f.queue = append(f.queue, t)
+	}
+	return name
+}
+
+// typeName names the declaration of t: the annotated name, its Go name, or for instances the
+// origin name followed by the names of the type arguments
+func (f *gqlFile) typeName(t gstypes.Type) string {
+	if name := annotationArgs(DocLines(t), graphqlAnnotation)["name"]; name != "" {
+		return name
+	}
+	switch typed := t.(type) {
+	case *gstypes.InstantiatedGeneric:
+		name := typed.Name()
+		if typed.Origin() != nil {
+			name = typed.Origin().Name()
+		}
+		for _, arg := range typed.TypeArgs() {
+			name += ExportedName(strings.Trim(f.typeOf(arg.Type, false), "[]!"))
+		}
+		return name
+	}
+	return t.Name()
+}
+
+// declare writes the declaration of the named type t
+func (f *gqlFile) declare(w *bufio.Writer, t gstypes.Type) {
+	name := f.names[t.Id()]
+	if values := f.enumNames(t); len(values) > 0 {
+		fmt.Fprintf(w, "enum %s {\n", name)
+		for _, v := range values {
+			fmt.Fprintf(w, "\t%s\n", v)
+		}
+		w.WriteString("}\n")
+		return
+	}
+	switch typed := t.(type) {
+	case *gstypes.Interface:
+		fmt.Fprintf(w, "interface %s {\n", name)
+		f.writeMethods(w, typed.Methods())
+		w.WriteString("}\n")
+		return
+	case *gstypes.Struct:
+		var ifaces []string
+		for _, id := range typed.Implements() {
+			if iface, ok := f.result.Types.Get(id); ok {
+				if _, isIface := iface.(*gstypes.Interface); isIface {
+					ifaces = append(ifaces, f.ref(iface))
+				}
+			}
+		}
+		if len(ifaces) > 0 {
+			sort.Strings(ifaces)
+			name += " implements " + strings.Join(ifaces, " & ")
+		}
+	}
+	fmt.Fprintf(w, "type %s {\n", name)
+	f.writeFields(w, fieldsOf(t))
+	w.WriteString("}\n")
+}
+
+// writeFields writes the fields of an object type
+func (f *gqlFile) writeFields(w *bufio.Writer, fields []*gstypes.Field) {
+	for _, field := range fields {
+		if field.Name() == "_" || !token.IsExported(field.Name()) {
+			continue
+		}
+		if err := field.Load(); err != nil {
+			continue
+		}
+		lines := DocLines(field)
+		args := annotationArgs(lines, graphqlAnnotation)
+		if _, skip := args["skip"]; skip {
+			continue
+		}
+		tagName, _, _ := strings.Cut(ParseTags(field.Tag())[f.tagKey], ",")
+		if tagName == "-" {
+			continue
+		}
+		typ, ok := f.fieldType(field.Type(), args)
+		if !ok {
+			continue
+		}
+		name := args["name"]
+		if name == "" {
+			name = tagName
+		}
+		if name == "" {
+			name = CamelCase(field.Name(), nil)
+		}
+		writeGraphQLDescription(w, "\t", lines)
+		fmt.Fprintf(w, "\t%s: %s\n", name, typ)
+	}
+}
+
+// writeMethods writes the fields of an interface, one per exported method
+func (f *gqlFile) writeMethods(w *bufio.Writer, methods []*gstypes.Method) {
+	for _, m := range methods {
+		if !token.IsExported(m.Name()) {
+			continue
+		}
+		if err := m.Load(); err != nil {
+			continue
+		}
+		lines := DocLines(m)
+		args := annotationArgs(lines, graphqlAnnotation)
+		if _, skip := args["skip"]; skip {
+			continue
+		}
+		var result gstypes.Type
+		if results := m.Results(); len(results) > 0 && (results[0].Type() == nil || results[0].Type().Id() != "error") {
+			result = results[0].Type()
+		}
+		if result == nil {
+			continue
+		}
+		typ, ok := f.fieldType(result, args)
+		if !ok {
+			continue
+		}
+		var params []string
+		for i, p := range m.Parameters() {
+			pt := p.Type()
+			if p.IsVariadic() {
+				pt = p.SliceType()
+			}
+			if i == 0 && pt != nil && pt.Id() == "context.Context" {
+				continue
+			}
+			paramType, ok := f.fieldType(pt, nil)
+			if !ok {
+				params = nil
+				break
+			}
+			paramName := p.Name()
+			if paramName == "" || paramName == "_" {
+				paramName = "arg" + strconv.Itoa(i)
+			}
+			params = append(params, paramName+": "+paramType)
+		}
+		name := args["name"]
+		if name == "" {
+			name = CamelCase(strings.TrimPrefix(m.Name(), "Get"), nil)
+			if name == "" {
+				name = CamelCase(m.Name(), nil)
+			}
+		}
+		if len(params) > 0 {
+			name += "(" + strings.Join(params, ", ") + ")"
+		}
+		writeGraphQLDescription(w, "\t", lines)
+		fmt.Fprintf(w, "\t%s: %s\n", name, typ)
+	}
+}
+
+// fieldType returns the GraphQL type of a field of type t with the annotation args, ok is
+// false for channels and functions
+func (f *gqlFile) fieldType(t gstypes.Type, args map[string]string) (string, bool) {
+	typ, ok := f.nullableType(t)
+	if !ok {
+		return "", false
+	}
+	nullable := false
+	if ptr, isPtr := t.(*gstypes.Pointer); isPtr && ptr.Depth() > 0 {
+		nullable = true
+	}
+	if value, set := args["nullable"]; set {
+		nullable = value != "false"
+	}
+	if !nullable {
+		typ += "!"
+	}
+	return typ, true
+}
+
+// typeOf returns the GraphQL type of t, non-null unless nullable, JSON for the types without
+// a GraphQL form
+func (f *gqlFile) typeOf(t gstypes.Type, nonNull bool) string {
+	typ, ok := f.nullableType(t)
+	if !ok {
+		typ = f.scalar("JSON")
+	}
+	if nonNull {
+		typ += "!"
+	}
+	return typ
+}
+
+// nullableType returns the GraphQL type of a value of type t, without the non-null marker
+func (f *gqlFile) nullableType(t gstypes.Type) (string, bool) {
+	if t == nil {
+		return f.scalar("JSON"), true
+	}
+	if scalar, found := graphqlCustomScalars[t.Id()]; found {
+		return f.scalar(scalar), true
+	}
+	switch typed := t.(type) {
+	case *gstypes.Pointer:
+		return f.nullableType(typed.Elem())
+	case *gstypes.Basic:
+		if len(EnumValues(t, f.constants)) > 0 {
+			return f.ref(t), true
+		}
+		id := t.Id()
+		if typed.Underlying() != nil {
+			id = typed.Underlying().Id()
+		}
+		if scalar, found := graphqlScalars[id]; found {
+			return scalar, true
+		}
+		return "", false
+	case *gstypes.Enum:
+		return f.ref(t), true
+	case *gstypes.Slice:
+		if elem := typed.Elem(); !typed.IsArray() && elem != nil && (elem.Id() == "uint8" || elem.Id() == "byte") {
+			return "String", true
+		}
+		elemNullable := false
+		if ptr, isPtr := typed.Elem().(*gstypes.Pointer); isPtr && ptr.Depth() > 0 {
+			elemNullable = true
+		}
+		elem, ok := f.nullableType(typed.Elem())
+		if !ok {
+			return "", false
+		}
+		if !elemNullable {
+			elem += "!"
+		}
+		return "[" + elem + "]", true
+	case *gstypes.Struct:
+		if !typed.IsNamed() {
+			return f.scalar("JSON"), true
+		}
+		return f.ref(t), true
+	case *gstypes.InstantiatedGeneric:
+		return f.ref(t), true
+	case *gstypes.Interface:
+		if typed.IsNamed() && typed.Distance() == 0 && len(typed.Methods()) > 0 {
+			return f.ref(t), true
+		}
+		return f.scalar("JSON"), true
+	case *gstypes.Map, *gstypes.TypeParameter:
+		return f.scalar("JSON"), true
+	case *gstypes.Alias:
+		return f.nullableType(typed.UnderlyingType())
+	}
+	return "", false
+}
+
+// scalar declares the custom scalar name
+func (f *gqlFile) scalar(name string) string {
+	if _, builtin := map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}[name]; !builtin {
+		f.scalars[name] = true
+	}
+	return name
+}
+
+// enumNames returns the values of the enum of the named type t: the UPPER_SNAKE names of its
+// constants, or their annotated names, in value order for integers
+func (f *gqlFile) enumNames(t gstypes.Type) []string {
+	if len(EnumValues(t, f.constants)) == 0 {
+		return nil
+	}
+	var constants []*gstypes.Value
+	if enum, ok := t.(*gstypes.Enum); ok {
+		constants = enum.Values()
+	} else {
+		constants = f.constants[t.Id()]
+	}
+	constants = append([]*gstypes.Value(nil), constants...)
+	sort.SliceStable(constants, func(i, j int) bool {
+		vi, iok := constantValue(constants[i].Value()).(int64)
+		vj, jok := constantValue(constants[j].Value()).(int64)
+		return iok && jok && vi < vj
+	})
+	var names []string
+	seen := map[string]bool{}
+	for _, c := range constants {
+		if err := c.Load(); err != nil {
+			continue
+		}
+		args := annotationArgs(DocLines(c), graphqlAnnotation)
+		if _, skip := args["skip"]; skip {
+			continue
+		}
+		name := args["name"]
+		if name == "" {
+			name = strings.ToUpper(SnakeCase(c.Name(), nil))
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// writeGraphQLDescription writes the comment lines, without the annotations, as a description
+func writeGraphQLDescription(w *bufio.Writer, indent string, lines []string) {
+	var text []string
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "@") {
+			text = append(text, line)
+		}
+	}
+	for len(text) > 0 && strings.TrimSpace(text[len(text)-1]) == "" {
+		text = text[:len(text)-1]
+	}
+	switch len(text) {
+	case 0:
+		return
+	case 1:
+		fmt.Fprintf(w, "%s%s\n", indent, strconv.Quote(text[0]))
+		return
+	}
+	fmt.Fprintf(w, "%s\"\"\"\n", indent)
+	for _, line := range text {
+		fmt.Fprintf(w, "%s%s\n", indent, strings.ReplaceAll(line, `"""`, `\"""`))
+	}
+	fmt.Fprintf(w, "%s\"\"\"\n", indent)
+}
@@ -0,0 +1,170 @@
+package scanner
+
+import (
+	"go/types"
+	"sort"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// ComputeImplements records which named types implement which interfaces of the result, by
+// value or by pointer (Interface.Implementers and Implementations, Struct.Implements), and
+// which interface methods each of their methods satisfies (Method.Satisfies).
+// It needs the go/types objects, so it only works on scanned (not cached) results. Generic
+// types, empty interfaces and constraint interfaces (with type sets) are skipped.
+func (s *ScanningResult) ComputeImplements() {
+	type candidate struct {
+		t     gstypes.Type
+		named *types.Named
+	}
+
+	var ifaces []*gstypes.Interface
+	var candidates []candidate
+	for _, id := range s.Types.Keys() {
+		t, _ := s.Types.Get(id)
+		named := goNamed(t)
+		if named == nil || (named.TypeParams() != nil && named.TypeParams().Len() > 0) {
+			continue
+		}
+		if goIface, ok := named.Underlying().(*types.Interface); ok {
+			if iface, ok := t.(*gstypes.Interface); ok && goIface.IsMethodSet() && goIface.NumMethods() > 0 {
+				ifaces = append(ifaces, iface)
+			}
+			continue
+		}
+		candidates = append(candidates, candidate{t: t, named: named})
+	}
+
+	implements := map[string][]string{}
+	satisfies := map[*gstypes.Method][]string{}
+	for _, iface := range ifaces {
+		goIface := goNamed(iface).Underlying().(*types.Interface)
+		var implementers []gstypes.Type
+		for _, c := range candidates {
+			if types.Implements(c.named, goIface) || types.Implements(types.NewPointer(c.named), goIface) {
+				implementers = append(implementers, c.t)
+				implements[c.t.Id()] = append(implements[c.t.Id()], iface.Id())
+				// The type implements the interface, so its methods named after the interface
+				// methods have identical signatures
+				for _, m := range c.t.Methods() {
+					if hasMethod(goIface, m.Name()) {
+						satisfies[m] = append(satisfies[m], iface.Id()+"#"+m.Name())
+					}
+				}
+			}
+		}
+		sort.Slice(implementers, func(i, j int) bool { return implementers[i].Id() < implementers[j].Id() })
+		var ids []string
+		var structs []*gstypes.Struct
+		for _, t := range implementers {
+			ids = append(ids, t.Id())
+			if strct, ok := t.(*gstypes.Struct); ok {
+				structs = append(structs, strct)
+			}
+		}
+		iface.SetImplementers(ids)
+		iface.SetImplementations(structs)
+	}
+
+	for _, c := range candidates {
+		if strct, ok := c.t.(*gstypes.Struct); ok {
+			ids := implements[strct.Id()]
+			sort.Strings(ids)
+			strct.SetImplements(ids)
+		}
+		for _, m := range c.t.Methods() {
+			ids := satisfies[m]
+			sort.Strings(ids)
+			m.SetSatisfies(ids)
+		}
+	}
+}
+
+// linkImplementations sets Interface.Implementations from the implementer ids, resolving
+// the structs among them, for results restored from a cache
+func (s *ScanningResult) linkImplementations() {
+	for _, t := range s.Types.Values() {
+		iface, ok := t.(*gstypes.Interface)
+		if !ok {
+			continue
+		}
+		var structs []*gstypes.Struct
+		for _, id := range iface.Implementers() {
+			if strct, ok := s.Types.Get(id); ok {
+				if strct, ok := strct.(*gstypes.Struct); ok {
+					structs = append(structs, strct)
+				}
+			}
+		}
+		iface.SetImplementations(structs)
+	}
+}
+
+// hasMethod reports whether the method set of iface has a method with the given name
+func hasMethod(iface *types.Interface, name string) bool {
+	for i := 0; i < iface.NumMethods(); i++ {
+		if iface.Method(i).Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// goNamed returns the go/types named type declared by t, nil if t isn't a declared type
+func goNamed(t gstypes.Type) *types.Named {
+	obj, ok := t.Object().(*types.TypeName)
+	if !ok || obj.IsAlias() {
+		return nil
+	}
+	named, _ := obj.Type().(*types.Named)
+	return named
+}
+
+// FatInterface is an interface with more methods than the InterfaceSegregationReport limit
+type FatInterface struct {
+	ID      string `json:"id"`
+	Methods int    `json:"methods"`
+	// ErrorMethods are the methods whose last result is an error, sorted. Fallible
+	// operations (I/O, validation...) are often a group of their own.
+	ErrorMethods []string `json:"errorMethods,omitempty"`
+	// Implementers are the types implementing the interface, see ComputeImplements
+	Implementers []string `json:"implementers,omitempty"`
+}
+
+// InterfaceSegregationReport lists the interfaces of the scanned packages with more than
+// maxMethods methods (embedded ones included), the most methods first, to flag interfaces
+// violating the interface segregation principle. Constraint interfaces are skipped.
+// Types are not loaded and implementers not computed: call EnsureFullyLoaded and
+// ComputeImplements (or set Config.ComputeImplements) first for complete figures.
+func (s *ScanningResult) InterfaceSegregationReport(maxMethods int) []FatInterface {
+	var report []FatInterface
+	for _, t := range s.Types.Values() {
+		iface, ok := t.(*gstypes.Interface)
+		if !ok || iface.Distance() != 0 || iface.TypeSet() != nil || len(iface.Methods()) <= maxMethods {
+			continue
+		}
+		fat := FatInterface{ID: iface.Id(), Methods: len(iface.Methods()), Implementers: iface.Implementers()}
+		for _, m := range iface.Methods() {
+			if returnsError(m) {
+				fat.ErrorMethods = append(fat.ErrorMethods, m.Name())
+			}
+		}
+		sort.Strings(fat.ErrorMethods)
+		report = append(report, fat)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		return a.Methods > b.Methods || (a.Methods == b.Methods && a.ID < b.ID)
+	})
+	return report
+}
+
+// returnsError reports whether the last result of m is an error
+func returnsError(m *gstypes.Method) bool {
+	results := m.Results()
+	if len(results) == 0 {
+		return false
+	}
+	last := results[len(results)-1].Type()
+	return last != nil && last.Id() == "error"
+}
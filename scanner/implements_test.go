@@ -0,0 +1,243 @@
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+func TestComputeImplements(t *testing.T) {
+	src := `
+	package test
+
+	type Reader interface {
+		Read(p []byte) (int, error)
+	}
+
+	type Closer interface {
+		Close() error
+	}
+
+	type ReadCloser interface {
+		Reader
+		Closer
+	}
+
+	type Any interface{}
+
+	type Number interface {
+		~int | ~float64
+	}
+
+	type File struct{}
+
+	func (f *File) Read(p []byte) (int, error) { return 0, nil }
+	func (f *File) Close() error               { return nil }
+
+	type Conn struct{}
+
+	func (c Conn) Close() error { return nil }
+
+	type CloseFunc func() error
+
+	func (f CloseFunc) Close() error { return f() }
+
+	type Box[T any] struct{ v T }
+
+	func (b Box[T]) Close() error { return nil }
+	`
+
+	result := scanTestSource(t, src)
+	result.ComputeImplements()
+
+	get := func(id string) gstypes.Type {
+		typ, ok := result.Types.Get(id)
+		if !ok {
+			t.Fatalf("%s not found", id)
+		}
+		return typ
+	}
+
+	for id, want := range map[string][]string{
+		"test.Reader":     {"test.File"},
+		"test.Closer":     {"test.CloseFunc", "test.Conn", "test.File"},
+		"test.ReadCloser": {"test.File"},
+		"test.Any":        nil,
+		"test.Number":     nil,
+	} {
+		if got := get(id).(*gstypes.Interface).Implementers(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s.Implementers() = %v, want %v", id, got, want)
+		}
+	}
+
+	for id, want := range map[string][]string{
+		"test.File": {"test.Closer", "test.ReadCloser", "test.Reader"},
+		"test.Conn": {"test.Closer"},
+		"test.Box":  nil,
+	} {
+		if got := get(id).(*gstypes.Struct).Implements(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s.Implements() = %v, want %v", id, got, want)
+		}
+	}
+
+	serialized := get("test.File").Serialize().(*gstypes.SerializedStruct)
+	if len(serialized.Implements) != 3 {
+		t.Errorf("serialized implements = %v", serialized.Implements)
+	}
+
+	// Implementations resolves the structs among the implementers, CloseFunc isn't one
+	implementations := func(result *ScanningResult, id string) []string {
+		typ, _ := result.Types.Get(id)
+		var ids []string
+		for _, s := range typ.(*gstypes.Interface).Implementations() {
+			ids = append(ids, s.Id())
+		}
+		return ids
+	}
+	if got, want := implementations(result, "test.Closer"), []string{"test.Conn", "test.File"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Closer.Implementations() = %v, want %v", got, want)
+	}
+
+	// The relationship survives a cache round trip
+	cache := filepath.Join(t.TempDir(), "cache.json")
+	if err := WriteCache(cache, result); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := ReadCache(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := implementations(cached, "test.Reader"), []string{"test.File"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cached Reader.Implementations() = %v, want %v", got, want)
+	}
+}
+
+func TestComputeImplements_methodSatisfies(t *testing.T) {
+	src := `
+	package test
+
+	type Reader interface {
+		Read(p []byte) (int, error)
+	}
+
+	type Closer interface {
+		Close() error
+	}
+
+	type ReadCloser interface {
+		Reader
+		Closer
+	}
+
+	type File struct{}
+
+	func (f *File) Read(p []byte) (int, error) { return 0, nil }
+	func (f *File) Close() error               { return nil }
+	func (f *File) Name() string               { return "" }
+
+	type CloseFunc func() error
+
+	func (f CloseFunc) Close() error { return f() }
+
+	// Read has the name of Reader.Read, not its signature
+	type Other struct{}
+
+	func (Other) Read() error { return nil }
+	`
+
+	result := scanTestSource(t, src)
+	result.ComputeImplements()
+
+	satisfies := func(id string) map[string][]string {
+		t.Helper()
+		typ, ok := result.Types.Get(id)
+		if !ok {
+			t.Fatalf("%s not found", id)
+		}
+		got := map[string][]string{}
+		for _, m := range typ.Methods() {
+			got[m.Name()] = m.Satisfies()
+		}
+		return got
+	}
+
+	tests := map[string]map[string][]string{
+		"test.File": {
+			"Read":  {"test.ReadCloser#Read", "test.Reader#Read"},
+			"Close": {"test.Closer#Close", "test.ReadCloser#Close"},
+			"Name":  nil,
+		},
+		"test.CloseFunc": {"Close": {"test.Closer#Close"}},
+		"test.Other":     {"Read": nil},
+	}
+	for id, want := range tests {
+		if got := satisfies(id); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s methods satisfy %v, want %v", id, got, want)
+		}
+	}
+
+	file, _ := result.Types.Get("test.File")
+	for _, m := range file.Serialize().(*gstypes.SerializedStruct).Methods {
+		if m.Name == "Read" && len(m.Satisfies) != 2 {
+			t.Errorf("serialized Read satisfies = %v", m.Satisfies)
+		}
+	}
+}
+
+func TestScanningResult_InterfaceSegregationReport(t *testing.T) {
+	src := `
+	package test
+
+	type Reader interface {
+		Read(p []byte) (int, error)
+	}
+
+	type Store interface {
+		Reader
+		Get(id string) (string, error)
+		Put(id, value string) error
+		Len() int
+	}
+
+	type Cache interface {
+		Get(id string) string
+		Put(id, value string)
+	}
+
+	type Number interface {
+		~int | ~float64
+		String() string
+		Sign() int
+	}
+
+	type Memory struct{}
+
+	func (m *Memory) Read(p []byte) (int, error)    { return 0, nil }
+	func (m *Memory) Get(id string) (string, error) { return "", nil }
+	func (m *Memory) Put(id, value string) error    { return nil }
+	func (m *Memory) Len() int                      { return 0 }
+	`
+	result := scanTestSource(t, src)
+	if err := result.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+	result.ComputeImplements()
+
+	want := []FatInterface{{
+		ID:           "test.Store",
+		Methods:      4,
+		ErrorMethods: []string{"Get", "Put", "Read"},
+		Implementers: []string{"test.Memory"},
+	}, {
+		ID:      "test.Cache",
+		Methods: 2,
+	}}
+	if got := result.InterfaceSegregationReport(1); !reflect.DeepEqual(got, want) {
+		t.Errorf("InterfaceSegregationReport(1) = %+v, want %+v", got, want)
+	}
+	if got := result.InterfaceSegregationReport(4); len(got) != 0 {
+		t.Errorf("InterfaceSegregationReport(4) = %+v, want none", got)
+	}
+}
@@ -0,0 +1,237 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ScanIncremental scans with cfg reusing the result cached at cachePath by a previous
+// ScanIncremental: only the packages with a source file added, removed or modified since
+// then, and the scanned packages importing them, are scanned again and merged into the
+// cached result. Files are compared by size and modification time, then by content hash.
+// A missing cache, or one written by WriteCache (without file stamps), scans everything.
+// The cache is updated and the paths of the packages scanned again are returned.
+//
+// After a full scan the result is the scanned one, otherwise it's restored from the merged
+// cache as ReadCache does. Only the packages matched by cfg.Packages are tracked, changes to
+// their dependencies and to Config.AuxiliaryPackages go unnoticed.
+func ScanIncremental(cachePath string, cfg *Config) (*ScanningResult, []string, error) {
+	if cfg == nil {
+		cfg = NewDefaultConfig()
+	}
+	var cache *CacheFile
+	if _, err := os.Stat(cachePath); err == nil {
+		if cache, err = readCacheFile(cachePath); err != nil {
+			return nil, nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, &CacheError{Path: cachePath, Op: "read", Err: err}
+	}
+	// The cache of a cancelled scan misses declarations of unchanged packages
+	full := cache == nil || cache.Sources == nil || cache.Header.Partial
+	var previous map[string]map[string]FileStamp
+	if !full {
+		previous = cache.Sources
+	}
+
+	// Stamp the sources of the tracked packages, by module dir
+	dirs := cfg.ModuleDirs
+	if len(dirs) == 0 {
+		dirs = []string{""}
+	}
+	listed := map[string][]*packages.Package{}
+	sources := map[string]map[string]FileStamp{}
+	changed := map[string]bool{}
+	for _, dir := range dirs {
+		lister := NewGlobScanner()
+		lister.Dir = dir
+		pkgs, err := lister.ScanPackages(0, cfg.Packages...)
+		if err != nil {
+			return nil, nil, err
+		}
+		listed[dir] = pkgs
+		for _, pkg := range pkgs {
+			stamps, modified, err := stampFiles(pkg.GoFiles, previous[pkg.PkgPath])
+			if err != nil {
+				return nil, nil, err
+			}
+			sources[pkg.PkgPath] = stamps
+			if full || modified {
+				changed[pkg.PkgPath] = true
+			}
+		}
+	}
+	var stale []string
+	for path := range previous {
+		if _, ok := sources[path]; !ok {
+			stale = append(stale, path)
+		}
+	}
+
+	// The importers of a changed package may embed or promote its types
+	for grown := true; grown; {
+		grown = false
+		for _, pkgs := range listed {
+			for _, pkg := range pkgs {
+				if changed[pkg.PkgPath] {
+					continue
+				}
+				for path := range pkg.Imports {
+					if changed[path] {
+						changed[pkg.PkgPath] = true
+						grown = true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	var rescanned []string
+	var results []*ScanningResult
+	for _, dir := range dirs {
+		var patterns []string
+		for _, pkg := range listed[dir] {
+			if changed[pkg.PkgPath] {
+				patterns = append(patterns, pkg.PkgPath)
+			}
+		}
+		if len(patterns) == 0 {
+			continue
+		}
+		dirCfg := *cfg
+		dirCfg.Packages = patterns
+		dirCfg.ModuleDirs = nil
+		if dir != "" {
+			dirCfg.ModuleDirs = []string{dir}
+		}
+		result, err := NewScanner().ScanWithConfig(&dirCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := result.EnsureFullyLoaded(); err != nil {
+			return nil, nil, err
+		}
+		results = append(results, result)
+		rescanned = append(rescanned, patterns...)
+	}
+	sort.Strings(rescanned)
+
+	if full {
+		result := MergeResults(results...)
+		serialized, err := serializeGeneric(result)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeCacheFile(cachePath, serialized, sources, false); err != nil {
+			return nil, nil, &CacheError{Path: cachePath, Op: "write", Err: err}
+		}
+		return result, rescanned, nil
+	}
+
+	// Replace the entries of the changed and removed packages with the new ones, the entries
+	// left are the unchanged packages, also found as dependencies of the new scans
+	merged := cache.Result
+	stale = append(stale, rescanned...)
+	dropPackages(merged, stale)
+	serialized, err := serializeGeneric(MergeResults(results...))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, section := range []string{"types", "values", "packages"} {
+		entries, _ := serialized[section].(map[string]any)
+		target, ok := merged[section].(map[string]any)
+		if !ok {
+			target = map[string]any{}
+			merged[section] = target
+		}
+		for id, entry := range entries {
+			if _, ok := target[id]; !ok {
+				target[id] = entry
+			}
+		}
+	}
+
+	if len(stale) > 0 || !reflect.DeepEqual(sources, cache.Sources) {
+		if err := writeCacheFile(cachePath, merged, sources, false); err != nil {
+			return nil, nil, &CacheError{Path: cachePath, Op: "write", Err: err}
+		}
+	}
+	result, err := reconstructFromCache(merged)
+	if err != nil {
+		return nil, nil, &CacheError{Path: cachePath, Op: "read", Err: err}
+	}
+	return result, rescanned, nil
+}
+
+// stampFiles stamps files, reporting whether they differ from the previous stamps. The
+// content is only hashed again when the size or the modification time changed.
+func stampFiles(files []string, previous map[string]FileStamp) (map[string]FileStamp, bool, error) {
+	stamps := make(map[string]FileStamp, len(files))
+	modified := len(files) != len(previous)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, false, err
+		}
+		stamp := FileStamp{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+		old, ok := previous[file]
+		if ok && old.ModTime == stamp.ModTime && old.Size == stamp.Size {
+			stamps[file] = old
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, false, err
+		}
+		sum := sha256.Sum256(data)
+		stamp.Hash = hex.EncodeToString(sum[:])
+		stamps[file] = stamp
+		modified = modified || !ok || old.Hash != stamp.Hash
+	}
+	return stamps, modified, nil
+}
+
+// dropPackages removes the types, values and packages of the given package paths from a
+// serialized result
+func dropPackages(serialized map[string]any, paths []string) {
+	drop := map[string]bool{}
+	for _, path := range paths {
+		drop[path] = true
+	}
+	for _, section := range []string{"types", "values"} {
+		entries, _ := serialized[section].(map[string]any)
+		for id, entry := range entries {
+			if fields, ok := entry.(map[string]any); ok {
+				if pkg, _ := fields["package"].(string); drop[pkg] {
+					delete(entries, id)
+				}
+			}
+		}
+	}
+	if pkgs, ok := serialized["packages"].(map[string]any); ok {
+		for path := range drop {
+			delete(pkgs, path)
+		}
+	}
+}
+
+// serializeGeneric serializes result as the generic JSON maps read back from caches
+func serializeGeneric(result *ScanningResult) (map[string]any, error) {
+	data, err := json.Marshal(result.Serialize())
+	if err != nil {
+		return nil, err
+	}
+	var serialized map[string]any
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
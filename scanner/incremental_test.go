@@ -0,0 +1,118 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+func TestScanIncremental(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/shop\n\ngo 1.22\n")
+	write("catalog/item.go", `package catalog
+
+type Item struct {
+	Name string
+}
+`)
+	write("orders/order.go", `package orders
+
+import "example.com/shop/catalog"
+
+type Order struct {
+	Items []catalog.Item
+}
+`)
+	write("users/user.go", `package users
+
+type User struct {
+	Email string
+}
+`)
+
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./..."}
+	cfg.ModuleDirs = []string{dir}
+	cachePath := filepath.Join(t.TempDir(), "cache.json.gz")
+
+	scan := func(wantRescanned ...string) *ScanningResult {
+		t.Helper()
+		result, rescanned, err := ScanIncremental(cachePath, cfg)
+		if err != nil {
+			t.Fatalf("ScanIncremental() error = %v", err)
+		}
+		if !reflect.DeepEqual(rescanned, wantRescanned) {
+			t.Fatalf("rescanned = %v, want %v", rescanned, wantRescanned)
+		}
+		return result
+	}
+	fieldNames := func(result *ScanningResult, id string) []string {
+		t.Helper()
+		typ, ok := result.Types.Get(id)
+		if !ok {
+			t.Fatalf("expected %s", id)
+		}
+		var names []string
+		for _, f := range typ.(*gstypes.Struct).Fields() {
+			names = append(names, f.Name())
+		}
+		return names
+	}
+
+	// Without a cache everything is scanned
+	scan("example.com/shop/catalog", "example.com/shop/orders", "example.com/shop/users")
+
+	// Unchanged and touched files are served from the cache
+	result := scan()
+	if got := fieldNames(result, "example.com/shop/users.User"); !reflect.DeepEqual(got, []string{"Email"}) {
+		t.Errorf("cached User fields = %v", got)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "users/user.go"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	scan()
+
+	// A changed package is scanned again with its importers
+	write("catalog/item.go", `package catalog
+
+type Item struct {
+	Name  string
+	Price int
+}
+`)
+	result = scan("example.com/shop/catalog", "example.com/shop/orders")
+	if got := fieldNames(result, "example.com/shop/catalog.Item"); !reflect.DeepEqual(got, []string{"Name", "Price"}) {
+		t.Errorf("Item fields = %v, want Name and Price", got)
+	}
+	if _, ok := result.Types.Get("example.com/shop/users.User"); !ok {
+		t.Error("unchanged User was dropped")
+	}
+
+	// Removed packages are dropped
+	if err := os.RemoveAll(filepath.Join(dir, "users")); err != nil {
+		t.Fatal(err)
+	}
+	result = scan()
+	if _, ok := result.Types.Get("example.com/shop/users.User"); ok {
+		t.Error("User of the removed package is still cached")
+	}
+	if _, ok := result.Types.Get("example.com/shop/orders.Order"); !ok {
+		t.Error("expected Order")
+	}
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// JSONSchemaOptions controls the documents generated by JSONSchema and EmitJSONSchema
+type JSONSchemaOptions struct {
+	// ID is the $id of the documents, omitted when empty
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+	// Format is the encoding of EmitJSONSchema, FormatJSON (default) or FormatYAML
+	Format Format `json:"format,omitempty" yaml:"format,omitempty"`
+	// TagKey is the tag holding the property names and the omitempty option (default "json")
+	TagKey string `json:"tag_key,omitempty" yaml:"tag_key,omitempty"`
+}
+
+// jsonSchemaDialect is the $schema of the generated documents
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaRefPrefix is the prefix of the references to the definitions of a document
+const jsonSchemaRefPrefix = "#/$defs/"
+
+// JSONSchema returns a JSON Schema (draft 2020-12) document validating the JSON encoding of
+// the type id: a reference to its definition, with the definitions of the named types it
+// references under $defs. Schemas are built as by Schemas: structs are objects with a
+// property per field (embedded fields promoted), pointers are nullable, slices arrays, maps
+// objects and named types with constants enums of their values.
+func (s *ScanningResult) JSONSchema(id string, opts JSONSchemaOptions) (map[string]any, error) {
+	t, ok := s.Types.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("type %q not found", id)
+	}
+	b := newSchemaBuilder(s, jsonSchemaRefPrefix, opts.TagKey)
+	root := b.ref(t)
+	if err := b.define(); err != nil {
+		return nil, err
+	}
+	return jsonSchemaDocument(root, b.schemas, opts), nil
+}
+
+// EmitJSONSchema writes a JSON Schema (draft 2020-12) document with the definitions of the
+// structs and enums of the scanned packages, and of the named types they reference, under
+// $defs. Its root accepts any of the scanned structs.
+func EmitJSONSchema(result *ScanningResult, w io.Writer, opts JSONSchemaOptions) error {
+	b := newSchemaBuilder(result, jsonSchemaRefPrefix, opts.TagKey)
+	if err := b.build(); err != nil {
+		return err
+	}
+	var roots []any
+	ids := result.Types.Keys()
+	sort.Strings(ids)
+	for _, id := range ids {
+		t, _ := result.Types.Get(id)
+		if strct, ok := t.(*gstypes.Struct); ok && strct.Distance() == 0 && strct.IsNamed() && len(strct.TypeParams()) == 0 {
+			roots = append(roots, b.ref(t))
+		}
+	}
+	root := map[string]any{"anyOf": roots}
+	if len(roots) == 0 {
+		root = map[string]any{}
+	}
+	return EncodeTree(w, jsonSchemaDocument(root, b.schemas, opts), opts.Format)
+}
+
+// jsonSchemaDocument returns the document with the root schema and the definitions
+func jsonSchemaDocument(root map[string]any, defs map[string]any, opts JSONSchemaOptions) map[string]any {
+	doc := map[string]any{"$schema": jsonSchemaDialect}
+	if opts.ID != "" {
+		doc["$id"] = opts.ID
+	}
+	for k, v := range root {
+		doc[k] = v
+	}
+	if len(defs) > 0 {
+		doc["$defs"] = defs
+	}
+	return doc
+}
@@ -0,0 +1,284 @@
+package scanner
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// Severities of a Diagnostic
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Rules of the diagnostics of LintTags
+const (
+	RuleMalformedTag      = "malformed-tag"      // the tag doesn't follow the key:"value" convention
+	RuleDuplicateKey      = "duplicate-key"      // a key appears twice in a tag
+	RuleUnknownKey        = "unknown-key"        // no schema is registered for the key
+	RuleUnknownOption     = "unknown-option"     // an option the schema of the key doesn't accept
+	RuleDuplicateName     = "duplicate-name"     // two fields of a struct have the same name in a key
+	RuleOmitemptyConflict = "omitempty-conflict" // omitempty has no effect or contradicts the tag
+	RuleTypeMismatch      = "type-mismatch"      // the tag doesn't apply to the type of the field
+	RuleDashName          = "dash-name"          // json:"-," names the field "-" instead of skipping it
+)
+
+// Diagnostic is a problem found in the struct tags of a field or in an annotation
+type Diagnostic struct {
+	Severity string            `json:"severity"`
+	Rule     string            `json:"rule"`
+	Type     string            `json:"type"`            // id of the struct
+	Field    string            `json:"field,omitempty"` // field or method, empty for the declaration itself
+	Key      string            `json:"key,omitempty"`   // tag key, empty for the whole tag
+	Message  string            `json:"message"`
+	Position *gstypes.Position `json:"position,omitempty"` // with Config.IncludePositions
+}
+
+// String formats the diagnostic as "file:line: Type.Field: message (rule)"
+func (d *Diagnostic) String() string {
+	decl := d.Type
+	if d.Field != "" {
+		decl += "." + d.Field
+	}
+	msg := fmt.Sprintf("%s: %s (%s)", decl, d.Message, d.Rule)
+	if pos := d.Position.String(); pos != "" {
+		msg = pos + ": " + msg
+	}
+	return msg
+}
+
+// TagSchema describes the values a struct tag key accepts, see RegisterTagSchema
+type TagSchema struct {
+	Key string
+	// Named keys hold a name before the options (json:"name,omitempty"), two fields of a
+	// struct can't have the same one. Untagged fields are not compared.
+	Named bool
+	// Options are the options accepted after the name, nil accepts any
+	Options []string
+	// Validate returns the problems of the value of the tag of field, nil when there's none
+	Validate func(field *gstypes.Field, value string) []*Diagnostic
+}
+
+// tagSchemas are the schemas LintTags checks the tags with, by key
+var tagSchemas = gstypes.NewSyncMap[string, *TagSchema]()
+
+// RegisterTagSchema registers the schema of a tag key checked by LintTags, replacing the
+// built-in one of the key if any (json, yaml, xml, db, validate)
+func RegisterTagSchema(schema *TagSchema) {
+	tagSchemas.Set(schema.Key, schema)
+}
+
+func init() {
+	RegisterTagSchema(&TagSchema{Key: "json", Named: true, Options: []string{"omitempty", "omitzero", "string"}, Validate: validateJSONTag})
+	RegisterTagSchema(&TagSchema{Key: "yaml", Named: true, Options: []string{"omitempty", "flow", "inline"}, Validate: validateYAMLTag})
+	RegisterTagSchema(&TagSchema{Key: "xml", Named: true, Options: []string{"attr", "chardata", "cdata", "innerxml", "comment", "any", "omitempty"}})
+	RegisterTagSchema(&TagSchema{Key: "db", Named: true, Options: []string{}})
+	RegisterTagSchema(&TagSchema{Key: "validate", Validate: validateValidateTag})
+}
+
+// Diagnostics returns the problems found by LintTags and ParseAnnotations (which the scans
+// run with Config.LintTags and with annotation schemas)
+func (s *ScanningResult) Diagnostics() []*Diagnostic {
+	return append(slices.Clip(s.tagDiagnostics), s.annotationDiagnostics...)
+}
+
+// LintTags checks the struct tags of the fields of the structs of the scanned packages
+// against the registered schemas (RegisterTagSchema): malformed tags, duplicated and unknown
+// keys, unknown options, fields with the same name in a key, omitempty without effect and
+// tags not applying to the type of the field. Promoted fields are checked in the struct
+// declaring them. The diagnostics are sorted by type and field, and kept as Diagnostics.
+func (s *ScanningResult) LintTags() []*Diagnostic {
+	var diags []*Diagnostic
+	for _, t := range s.Types.Values() {
+		strct, ok := t.(*gstypes.Struct)
+		if !ok || t.Distance() != 0 || !t.IsNamed() || t.Opaque() {
+			continue
+		}
+		if err := t.Load(); err != nil {
+			continue
+		}
+		diags = append(diags, lintStruct(strct)...)
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Type != diags[j].Type {
+			return diags[i].Type < diags[j].Type
+		}
+		return diags[i].Field < diags[j].Field
+	})
+	s.tagDiagnostics = diags
+	return diags
+}
+
+// lintStruct checks the tags of the fields declared by strct
+func lintStruct(strct *gstypes.Struct) []*Diagnostic {
+	var diags []*Diagnostic
+	names := map[string]map[string]string{} // field by name, by key
+	for _, f := range strct.Fields() {
+		if f.PromotedFrom() != nil || f.Tag() == "" {
+			continue
+		}
+		report := func(severity, rule, key, format string, args ...any) {
+			diags = append(diags, &Diagnostic{
+				Severity: severity,
+				Rule:     rule,
+				Type:     strct.Id(),
+				Field:    f.Name(),
+				Key:      key,
+				Message:  fmt.Sprintf(format, args...),
+				Position: f.Position(),
+			})
+		}
+
+		pairs, ok := parseTagPairs(f.Tag())
+		if !ok {
+			report(SeverityError, RuleMalformedTag, "", "malformed tag %q, pairs must be key:\"value\" separated by spaces", f.Tag())
+		}
+		seen := map[string]bool{}
+		for _, p := range pairs {
+			if seen[p.key] {
+				report(SeverityError, RuleDuplicateKey, p.key, "key %s appears more than once, only the first one is used", p.key)
+				continue
+			}
+			seen[p.key] = true
+
+			schema, ok := tagSchemas.Get(p.key)
+			if !ok {
+				report(SeverityWarning, RuleUnknownKey, p.key, "unknown tag key %s", p.key)
+				continue
+			}
+			name, options := p.value, []string(nil)
+			if schema.Named || schema.Options != nil {
+				parts := strings.Split(p.value, ",")
+				name, options = parts[0], parts[1:]
+			}
+			if schema.Options != nil {
+				for _, opt := range options {
+					if opt != "" && !slices.Contains(schema.Options, opt) {
+						report(SeverityError, RuleUnknownOption, p.key, "unknown %s option %q", p.key, opt)
+					}
+				}
+			}
+			if schema.Named && name != "" && name != "-" {
+				if names[p.key] == nil {
+					names[p.key] = map[string]string{}
+				}
+				if other, dup := names[p.key][name]; dup {
+					report(SeverityError, RuleDuplicateName, p.key, "%s name %q is also the one of field %s", p.key, name, other)
+				} else {
+					names[p.key][name] = f.Name()
+				}
+			}
+			if schema.Validate != nil {
+				for _, d := range schema.Validate(f, p.value) {
+					if d.Key == "" {
+						d.Key = p.key
+					}
+					d.Type, d.Field, d.Position = strct.Id(), f.Name(), f.Position()
+					diags = append(diags, d)
+				}
+			}
+		}
+	}
+	return diags
+}
+
+// validateJSONTag checks the options of a json tag against the type of the field, as
+// encoding/json applies them
+func validateJSONTag(f *gstypes.Field, value string) []*Diagnostic {
+	var diags []*Diagnostic
+	name, opts, hasOpts := strings.Cut(value, ",")
+	options := strings.Split(opts, ",")
+	kind := tagTargetKind(f.Type(), true)
+	if name == "-" && hasOpts {
+		diags = append(diags, &Diagnostic{Severity: SeverityWarning, Rule: RuleDashName,
+			Message: `json:"-," names the field "-", use json:"-" to skip it`})
+	}
+	if slices.Contains(options, "omitempty") && tagTargetKind(f.Type(), false) == gstypes.TypeKindStruct {
+		diags = append(diags, &Diagnostic{Severity: SeverityWarning, Rule: RuleOmitemptyConflict,
+			Message: "omitempty has no effect on struct fields, use omitzero"})
+	}
+	if slices.Contains(options, "string") && !isScalarKind(kind) {
+		diags = append(diags, &Diagnostic{Severity: SeverityError, Rule: RuleTypeMismatch,
+			Message: fmt.Sprintf("the string option applies to strings, numbers and booleans, not %s", kind)})
+	}
+	return diags
+}
+
+// validateYAMLTag checks the options of a yaml tag against the type of the field
+func validateYAMLTag(f *gstypes.Field, value string) []*Diagnostic {
+	var diags []*Diagnostic
+	_, opts, _ := strings.Cut(value, ",")
+	options := strings.Split(opts, ",")
+	kind := tagTargetKind(f.Type(), true)
+	if slices.Contains(options, "inline") && kind != gstypes.TypeKindStruct && kind != gstypes.TypeKindMap {
+		diags = append(diags, &Diagnostic{Severity: SeverityError, Rule: RuleTypeMismatch,
+			Message: fmt.Sprintf("inline applies to structs and maps, not %s", kind)})
+	}
+	if slices.Contains(options, "flow") && kind != gstypes.TypeKindStruct && kind != gstypes.TypeKindMap &&
+		kind != gstypes.TypeKindSlice && kind != gstypes.TypeKindArray {
+		diags = append(diags, &Diagnostic{Severity: SeverityError, Rule: RuleTypeMismatch,
+			Message: fmt.Sprintf("flow applies to structs, maps and sequences, not %s", kind)})
+	}
+	return diags
+}
+
+// validateValidateTag checks a go-playground/validator tag: empty rules, and omitempty
+// contradicting required
+func validateValidateTag(f *gstypes.Field, value string) []*Diagnostic {
+	var diags []*Diagnostic
+	rules := strings.Split(value, ",")
+	for _, rule := range rules {
+		if strings.TrimSpace(rule) == "" {
+			diags = append(diags, &Diagnostic{Severity: SeverityError, Rule: RuleMalformedTag,
+				Message: fmt.Sprintf("empty rule in %q", value)})
+			break
+		}
+	}
+	if slices.Contains(rules, "omitempty") && slices.Contains(rules, "required") {
+		diags = append(diags, &Diagnostic{Severity: SeverityError, Rule: RuleOmitemptyConflict,
+			Message: "omitempty and required contradict each other"})
+	}
+	return diags
+}
+
+// tagTargetKind returns the kind of the values a field of type t holds: aliases and named
+// basic types are followed, and pointers with derefPointers
+func tagTargetKind(t gstypes.Type, derefPointers bool) gstypes.TypeKind {
+	for range 16 {
+		switch typed := t.(type) {
+		case nil:
+			return ""
+		case *gstypes.Pointer:
+			if !derefPointers {
+				return t.Kind()
+			}
+			t = typed.Elem()
+		case *gstypes.Alias:
+			t = typed.UnderlyingType()
+		case *gstypes.Enum:
+			t = typed.Underlying()
+		case *gstypes.Basic:
+			if t.Id() == "error" {
+				return gstypes.TypeKindInterface
+			}
+			if typed.Underlying() == nil {
+				return t.Kind()
+			}
+			t = typed.Underlying()
+		case *gstypes.InstantiatedGeneric:
+			t = typed.Origin()
+		default:
+			return t.Kind()
+		}
+	}
+	return t.Kind()
+}
+
+// isScalarKind reports whether values of kind are strings, numbers or booleans
+func isScalarKind(kind gstypes.TypeKind) bool {
+	return kind == gstypes.TypeKindBasic
+}
@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+func TestScanningResult_LintTags(t *testing.T) {
+	src := "package test\n" +
+		"type Address struct{ City string }\n" +
+		"type Clean struct {\n" +
+		"	ID      int      `json:\"id,string\" db:\"id\" validate:\"required\"`\n" +
+		"	Home    *Address `json:\"home,omitempty\" yaml:\"home,inline\"`\n" +
+		"	Skipped string   `json:\"-\"`\n" +
+		"}\n" +
+		"type User struct {\n" +
+		"	ID      int      `json:\"id\" json:\"uid\"`\n" +
+		"	Name    string   `json:\"id,omitempty\" validate:\"required,omitempty\"`\n" +
+		"	Addr    Address  `json:\"addr,omitempty,inline\"`\n" +
+		"	Tags    []string `json:\"tags,string\" yaml:\"tags,inline\"`\n" +
+		"	Secret  string   `json:\"-,\"`\n" +
+		"	Broken  string   `json:\"broken`\n" +
+		"	Custom  string   `acme:\"x\" validate:\"min=1,,max=3\"`\n" +
+		"}\n"
+
+	result := scanTestSource(t, src)
+	diags := result.LintTags()
+	if !reflect.DeepEqual(result.Diagnostics(), diags) {
+		t.Errorf("Diagnostics() differs from the LintTags() result")
+	}
+
+	var got []string
+	for _, d := range diags {
+		if d.Type != "test.User" {
+			t.Errorf("unexpected diagnostic of %s: %s", d.Type, d)
+			continue
+		}
+		got = append(got, d.Field+" "+d.Severity+" "+d.Rule+" "+d.Key)
+	}
+	sort.Strings(got)
+	want := []string{
+		"Addr error unknown-option json",
+		"Addr warning omitempty-conflict json",
+		"Broken error malformed-tag ",
+		"Custom error malformed-tag validate",
+		"Custom warning unknown-key acme",
+		"ID error duplicate-key json",
+		"Name error duplicate-name json",
+		"Name error omitempty-conflict validate",
+		"Secret warning dash-name json",
+		"Tags error type-mismatch json",
+		"Tags error type-mismatch yaml",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LintTags() =\n%v\nwant\n%v", got, want)
+	}
+
+	// Custom schemas
+	RegisterTagSchema(&TagSchema{Key: "acme", Options: []string{}, Validate: func(f *gstypes.Field, value string) []*Diagnostic {
+		if value != "y" {
+			return []*Diagnostic{{Severity: SeverityError, Rule: "acme-value", Message: "acme must be y"}}
+		}
+		return nil
+	}})
+	defer tagSchemas.Delete("acme")
+	for _, d := range result.LintTags() {
+		if d.Key == "acme" && (d.Rule != "acme-value" || d.Field != "Custom") {
+			t.Errorf("unexpected acme diagnostic: %s", d)
+		}
+	}
+}
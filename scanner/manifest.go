@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// ManifestEntry is the lightweight description of a type in the manifest
+type ManifestEntry struct {
+	ID      string           `json:"id"`
+	Kind    gstypes.TypeKind `json:"kind"`
+	Package string           `json:"package,omitempty"`
+	File    string           `json:"file,omitempty"`
+}
+
+// Manifest returns an index of every type of the result (no fields, methods...) sorted by id.
+// It's meant to be loaded before the full output, to navigate types and fetch details on demand.
+func (s *ScanningResult) Manifest() []ManifestEntry {
+	keys := s.Types.Keys()
+	sort.Strings(keys)
+
+	manifest := make([]ManifestEntry, 0, len(keys))
+	for _, id := range keys {
+		t, ok := s.Types.Get(id)
+		if !ok {
+			continue
+		}
+		entry := ManifestEntry{ID: id, Kind: t.Kind()}
+		if pkg := t.Package(); pkg != nil {
+			entry.Package = pkg.Path()
+		}
+		if files := t.Files(); len(files) > 0 {
+			entry.File = files[0]
+		}
+		manifest = append(manifest, entry)
+	}
+	return manifest
+}
+
+// EmitManifest writes the manifest to w as an indented JSON array
+func (s *ScanningResult) EmitManifest(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(s.Manifest())
+}
@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// FieldName returns the name emitters use for a field with the given Go name and raw struct
+// tag: the result of NameTransform, or the Go name when no transform is set
+func (o EmitOptions) FieldName(goName string, tag string) string {
+	if o.NameTransform == nil {
+		return goName
+	}
+	return o.NameTransform(goName, ParseTags(tag))
+}
+
+// WithDefaultNames returns o naming the fields with transform when it has no NameTransform,
+// emitters with a naming convention of their own (snake_case columns) use it as the default
+func (o EmitOptions) WithDefaultNames(transform func(goName string, tags map[string]string) string) EmitOptions {
+	if o.NameTransform == nil {
+		o.NameTransform = transform
+	}
+	return o
+}
+
+// ParseTags parses a raw struct tag (`json:"name,omitempty" db:"name"`) into a key/value map,
+// malformed pairs end the parsing like in reflect.StructTag
+func ParseTags(tag string) map[string]string {
+	pairs, _ := parseTagPairs(tag)
+	tags := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		tags[p.key] = p.value
+	}
+	return tags
+}
+
+// tagPair is a key:"value" pair of a struct tag
+type tagPair struct {
+	key, value string
+}
+
+// parseTagPairs parses the pairs of a struct tag in order, ok is false when it stopped at a
+// malformed pair
+func parseTagPairs(tag string) (pairs []tagPair, ok bool) {
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			break
+		}
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return pairs, false
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		// Scan the quoted value
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return pairs, false
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return pairs, false
+		}
+		pairs = append(pairs, tagPair{key: key, value: value})
+		tag = tag[i+1:]
+	}
+	return pairs, true
+}
+
+// TagName returns a name transform using the name of the given tag key (the part before the
+// first comma, as in `json:"name,omitempty"`), falling back to fallback (or the Go name when
+// nil) for untagged, unnamed or ignored ("-") fields
+func TagName(key string, fallback func(goName string, tags map[string]string) string) func(string, map[string]string) string {
+	return func(goName string, tags map[string]string) string {
+		name, _, _ := strings.Cut(tags[key], ",")
+		if name != "" && name != "-" {
+			return name
+		}
+		if fallback != nil {
+			return fallback(goName, tags)
+		}
+		return goName
+	}
+}
+
+// SnakeCase is a name transform converting Go names to snake_case, keeping acronyms
+// together (UserID -> user_id, HTTPServer -> http_server)
+func SnakeCase(goName string, _ map[string]string) string {
+	words := splitWords(goName)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "_")
+}
+
+// CamelCase is a name transform converting Go names to camelCase by lowering the leading
+// word (UserID -> userID, HTTPServer -> httpServer)
+func CamelCase(goName string, _ map[string]string) string {
+	words := splitWords(goName)
+	if len(words) == 0 {
+		return goName
+	}
+	words[0] = strings.ToLower(words[0])
+	return strings.Join(words, "")
+}
+
+// splitWords splits a Go identifier in words at case changes and underscores, an uppercase
+// run is one word except for its last letter when followed by a lowercase one
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		prev := runes[i-1]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if !unicode.IsUpper(prev) || nextLower {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return words
+}
+
+// UniqueNames are the names taken in a generated schema
+type UniqueNames map[string]bool
+
+// Reserve marks name as taken, numbering it when it already is
+func (u UniqueNames) Reserve(name string) string {
+	for i, base := 2, name; u[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	u[name] = true
+	return name
+}
+
+// ExportedName upper cases the first letter of name
+func ExportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// DocLines returns the lines of the comments above t, for the descriptions of generated schemas
+func DocLines(t gstypes.Type) []string {
+	var lines []string
+	for _, c := range t.Comments() {
+		if c.Place == gstypes.CommentPlacementAbove && c.Text != "" {
+			lines = append(lines, strings.Split(strings.TrimRight(c.Text, "\n"), "\n")...)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"regexp"
+
+	"github.com/pablor21/goscanner/logger"
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// namePatterns are the compiled include and exclude patterns of a Config option pair
+type namePatterns struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// newNamePatterns compiles the include and exclude patterns of option, each must fully match
+// a name. Invalid patterns are logged and ignored.
+func newNamePatterns(log logger.Logger, option string, include, exclude []string) namePatterns {
+	return namePatterns{
+		include: compilePatterns(log, "include_"+option, include),
+		exclude: compilePatterns(log, "exclude_"+option, exclude),
+	}
+}
+
+func compilePatterns(log logger.Logger, option string, patterns []string) []*regexp.Regexp {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			log.Warnf("Ignoring invalid %s pattern %q: %v", option, pattern, err)
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled
+}
+
+// empty reports whether there are no patterns, everything is kept
+func (p namePatterns) empty() bool {
+	return len(p.include) == 0 && len(p.exclude) == 0
+}
+
+// keep reports whether a name, given in its different forms, passes the patterns: no form
+// matches an exclude pattern and, when there are include patterns, one form matches one
+func (p namePatterns) keep(forms ...string) bool {
+	if matchAny(p.exclude, forms) {
+		return false
+	}
+	return len(p.include) == 0 || matchAny(p.include, forms)
+}
+
+func matchAny(patterns []*regexp.Regexp, forms []string) bool {
+	for _, re := range patterns {
+		for _, form := range forms {
+			if re.MatchString(form) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keepPackage reports whether the package with the given path passes
+// Config.IncludePackages and Config.ExcludePackages
+func (r *defaultTypeResolver) keepPackage(path string) bool {
+	return r.packagePatterns.keep(path)
+}
+
+// keepTypeName reports whether the declared type name of pkgPath passes
+// Config.IncludeTypes and Config.ExcludeTypes
+func (r *defaultTypeResolver) keepTypeName(pkgPath, name string) bool {
+	return r.typePatterns.keep(name, pkgPath+"."+name)
+}
+
+// resultFilter returns the filter of the types of the result: the named types passing the
+// package and type patterns and Config.TypeFilter, and not vetoed by a processor. Nil when
+// nothing is filtered.
+func (r *defaultTypeResolver) resultFilter() func(gstypes.Type) bool {
+	if r.packagePatterns.empty() && r.typePatterns.empty() && len(r.processors) == 0 {
+		return r.config.TypeFilter
+	}
+	return func(t gstypes.Type) bool {
+		if r.vetoed.Has(t.Id()) {
+			return false
+		}
+		if t.IsNamed() && t.Package() != nil {
+			if !r.keepPackage(t.Package().Path()) {
+				return false
+			}
+			if t.Kind() != gstypes.TypeKindFunction && !r.keepTypeName(t.Package().Path(), t.Name()) {
+				return false
+			}
+		}
+		return r.config.TypeFilter == nil || r.config.TypeFilter(t)
+	}
+}
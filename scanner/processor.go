@@ -1,6 +1,10 @@
 package scanner
 
-import "go/types"
+import (
+	"go/types"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
 
 // Processor interface for custom type processing during scanning
 type Processor interface {
@@ -11,6 +15,26 @@ type Processor interface {
 	ProcessType(t types.Type) (continueProcessing bool, err error)
 }
 
+// TypeProcessor is implemented by the processors receiving the resolved types. Each named
+// type is passed to ProcessResolvedType before it's cached, to annotate it (SetMetadata),
+// modify it through its setters, or veto it by returning false: a vetoed type is still
+// resolved where it's referenced but left out of ScanningResult.Types. Members load lazily
+// afterwards, don't Load the type. It may be called concurrently. Errors are recorded as
+// warnings of the result and the type kept.
+type TypeProcessor interface {
+	ProcessResolvedType(t gstypes.Type) (keep bool, err error)
+}
+
+// registeredProcessors are the processors of every scan, see RegisterProcessor
+var registeredProcessors = gstypes.NewSyncSlice[Processor]()
+
+// RegisterProcessor registers a processor applied by every scan, before the ones added to
+// each scanner (DefaultScanner.AddProcessor). Plugins register themselves from init.
+// Processors also implementing TypeProcessor receive the resolved types.
+func RegisterProcessor(p Processor) {
+	registeredProcessors.Append(p)
+}
+
 // NoOpProcessor is a no-operation processor that always continues processing
 type NoOpProcessor struct {
 	scanMode ScanMode
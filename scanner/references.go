@@ -0,0 +1,268 @@
+package scanner
+
+import (
+	"sort"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// Reference is a use of a named type by another type or value
+type Reference struct {
+	From string          `json:"from"` // id of the referencing type or value
+	Role gstypes.RefRole `json:"role"` // position of the reference in From
+}
+
+// ReferenceIndex maps named type ids to the references made to them
+type ReferenceIndex map[string][]Reference
+
+// BuildReferenceIndex walks every type and value of the result and indexes the named types
+// they reference. Self references are skipped and references are sorted by From and Role.
+func (s *ScanningResult) BuildReferenceIndex() ReferenceIndex {
+	index := ReferenceIndex{}
+	add := func(from gstypes.Type) {
+		seen := map[string]bool{}
+		gstypes.WalkReferences(from, func(ref gstypes.Type, role gstypes.RefRole) {
+			if !ref.IsNamed() || ref.Id() == from.Id() {
+				return
+			}
+			key := ref.Id() + "|" + string(role)
+			if seen[key] {
+				return
+			}
+			seen[key] = true
+			r := Reference{From: from.Id(), Role: role}
+			index[ref.Id()] = append(index[ref.Id()], r)
+		})
+	}
+
+	for _, t := range s.Types.Values() {
+		add(t)
+	}
+	for _, v := range s.Values.Values() {
+		add(v)
+	}
+
+	for _, refs := range index {
+		sort.Slice(refs, func(i, j int) bool {
+			if refs[i].From != refs[j].From {
+				return refs[i].From < refs[j].From
+			}
+			return refs[i].Role < refs[j].Role
+		})
+	}
+	return index
+}
+
+// ComputeUsages records on every named type of the result the places it is referenced
+// from (Type.UsedBy): the fields and methods of the types, the types themselves (embeds,
+// underlying types, type arguments...), the functions and the constants and variables.
+// Promoted fields and methods belong to the type they're declared in, self references are
+// skipped and usages are sorted by From, Member and Role. Types are loaded first (see
+// EnsureFullyLoaded). Set ScanModeUsages to run it after the scan.
+func (s *ScanningResult) ComputeUsages() error {
+	if err := s.EnsureFullyLoaded(); err != nil {
+		return err
+	}
+
+	type key struct {
+		ref   string
+		usage gstypes.Usage
+	}
+	usages := map[string][]gstypes.Usage{}
+	seen := map[key]bool{}
+	// record adds the named types referenced by walked, a member of from or from itself
+	record := func(walked gstypes.Type, from, member string, skip func(gstypes.RefRole) bool) {
+		gstypes.WalkReferences(walked, func(ref gstypes.Type, role gstypes.RefRole) {
+			if !ref.IsNamed() || ref.Id() == from || (skip != nil && skip(role)) {
+				return
+			}
+			k := key{ref: ref.Id(), usage: gstypes.Usage{From: from, Member: member, Role: role}}
+			if seen[k] {
+				return
+			}
+			seen[k] = true
+			usages[k.ref] = append(usages[k.ref], k.usage)
+		})
+	}
+
+	for _, t := range s.Types.Values() {
+		// Fields and method signatures are recorded by member below, the signature of a
+		// function type belongs to the type itself
+		_, function := t.(*gstypes.Function)
+		record(t, t.Id(), "", func(role gstypes.RefRole) bool {
+			return role == gstypes.RefRoleField || (!function && (role == gstypes.RefRoleParam || role == gstypes.RefRoleResult))
+		})
+		if _, ok := t.(*gstypes.InstantiatedGeneric); ok {
+			// Members of instantiations belong to the origin
+			continue
+		}
+		if strct, ok := t.(*gstypes.Struct); ok {
+			for _, f := range strct.Fields() {
+				if f.PromotedFrom() == nil && !f.IsEmbedded() {
+					record(f, t.Id(), f.Name(), nil)
+				}
+			}
+		}
+		for _, m := range t.Methods() {
+			if m.PromotedFrom() == nil {
+				record(m, t.Id(), m.Name(), nil)
+			}
+		}
+	}
+	for _, v := range s.Values.Values() {
+		record(v, v.Id(), "", nil)
+	}
+
+	for _, t := range s.Types.Values() {
+		list := usages[t.Id()]
+		sort.Slice(list, func(i, j int) bool {
+			a, b := list[i], list[j]
+			if a.From != b.From {
+				return a.From < b.From
+			}
+			if a.Member != b.Member {
+				return a.Member < b.Member
+			}
+			return a.Role < b.Role
+		})
+		t.SetUsedBy(list)
+	}
+	return nil
+}
+
+// Usages returns the places the named type with the given id is referenced from, nil when
+// it isn't found or usages weren't computed (see ComputeUsages)
+func (s *ScanningResult) Usages(typeID string) []gstypes.Usage {
+	t, ok := s.Types.Get(typeID)
+	if !ok {
+		return nil
+	}
+	return t.UsedBy()
+}
+
+// MarkConstraintOnly flags the interfaces that are referenced, but only as type parameter
+// constraints (directly, in union terms, or embedded in other constraint-only interfaces).
+// Interfaces that are never referenced are left unmarked.
+func (s *ScanningResult) MarkConstraintOnly() {
+	index := s.BuildReferenceIndex()
+
+	constraintOnly := map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for _, t := range s.Types.Values() {
+			iface, ok := t.(*gstypes.Interface)
+			if !ok || constraintOnly[iface.Id()] {
+				continue
+			}
+			refs := index[iface.Id()]
+			if len(refs) == 0 {
+				continue
+			}
+			only := true
+			for _, r := range refs {
+				if r.Role == gstypes.RefRoleConstraint || (r.Role == gstypes.RefRoleEmbed && constraintOnly[r.From]) {
+					continue
+				}
+				only = false
+				break
+			}
+			if only {
+				constraintOnly[iface.Id()] = true
+				changed = true
+			}
+		}
+	}
+
+	for _, t := range s.Types.Values() {
+		if iface, ok := t.(*gstypes.Interface); ok {
+			iface.SetConstraintOnly(constraintOnly[iface.Id()])
+		}
+	}
+}
+
+// TopoSort returns the types of the result filter keeps (all of them when nil) ordered so
+// that every type comes after the types it depends on: field, element and key types, embeds,
+// alias targets and underlying types, generic origins, type arguments and constraints, and
+// the signatures of function types. Method signatures aren't dependencies. Types are loaded
+// first (see EnsureFullyLoaded) and ties are broken by id, so the order is stable.
+// Cycles are broken at the reference closing them: the order is still complete, and the
+// cycles are reported in a *CycleError for emitters to insert forward declarations.
+func (s *ScanningResult) TopoSort(filter func(gstypes.Type) bool) ([]gstypes.Type, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if err := s.EnsureFullyLoaded(); err != nil {
+		return nil, err
+	}
+
+	nodes := map[string]gstypes.Type{}
+	var ids []string
+	for _, t := range s.Types.Values() {
+		if filter == nil || filter(t) {
+			nodes[t.Id()] = t
+			ids = append(ids, t.Id())
+		}
+	}
+	sort.Strings(ids)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	var path []string // ids being visited, each depending on the next
+	var sorted []gstypes.Type
+	var cycles [][]string
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range dependencies(nodes[id], nodes) {
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == dep {
+						cycles = append(cycles, append([]string(nil), path[i:]...))
+						break
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		sorted = append(sorted, nodes[id])
+	}
+	for _, id := range ids {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+
+	if len(cycles) > 0 {
+		return sorted, &CycleError{Cycles: cycles}
+	}
+	return sorted, nil
+}
+
+// dependencies returns the sorted ids of the types among nodes t depends on, see TopoSort
+func dependencies(t gstypes.Type, nodes map[string]gstypes.Type) []string {
+	_, function := t.(*gstypes.Function)
+	seen := map[string]bool{}
+	var deps []string
+	gstypes.WalkReferences(t, func(ref gstypes.Type, role gstypes.RefRole) {
+		if !function && (role == gstypes.RefRoleParam || role == gstypes.RefRoleResult) {
+			return
+		}
+		id := ref.Id()
+		if _, ok := nodes[id]; !ok || id == t.Id() || seen[id] {
+			return
+		}
+		seen[id] = true
+		deps = append(deps, id)
+	})
+	sort.Strings(deps)
+	return deps
+}
@@ -0,0 +1,210 @@
+package scanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+func TestScanningResult_MarkConstraintOnly(t *testing.T) {
+	src := `
+	package test
+
+	type Integer interface{ ~int | ~int64 }
+
+	type Number interface {
+		Integer
+		~float64
+	}
+
+	type Stringer interface{ String() string }
+
+	type Unused interface{ Foo() }
+
+	type Box[T Number] struct{ Value T }
+
+	func Sum[T Number](values ...T) T { var s T; return s }
+
+	type Printer[T Stringer] struct {
+		Fallback Stringer
+	}
+	`
+
+	result := scanTestSource(t, src)
+	result.MarkConstraintOnly()
+
+	tests := map[string]bool{
+		"test.Integer":  true,  // embedded in a constraint-only interface
+		"test.Number":   true,  // only used as constraint
+		"test.Stringer": false, // also used as a field type
+		"test.Unused":   false, // never referenced
+	}
+	for id, want := range tests {
+		typ, ok := result.Types.Get(id)
+		if !ok {
+			t.Fatalf("type %s not found", id)
+		}
+		if got := typ.(*gstypes.Interface).ConstraintOnly(); got != want {
+			t.Errorf("%s.ConstraintOnly() = %v, want %v (refs: %v)", id, got, want, result.BuildReferenceIndex()[id])
+		}
+	}
+}
+
+func TestScanningResult_ComputeUsages(t *testing.T) {
+	src := `
+	package test
+
+	type User struct {
+		Name string
+	}
+
+	type Audit struct {
+		By *User
+	}
+
+	type Post struct {
+		Audit
+		Author  User
+		Readers []*User
+		Meta    struct{ Editor User }
+	}
+
+	type Store interface {
+		Save(u *User) error
+		Find(id string) (User, bool)
+	}
+
+	type Users []User
+
+	type Handler func(u User)
+
+	var Admin = &User{}
+
+	func NewPost(author User) *Post { return nil }
+	`
+	cfg := NewDefaultConfig()
+	cfg.ScanMode = ScanModeFull
+	result := scanTestSourceWithConfig(t, src, cfg)
+	if err := result.ComputeUsages(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []gstypes.Usage{
+		{From: "test.Admin", Role: gstypes.RefRoleValue},
+		{From: "test.Audit", Member: "By", Role: gstypes.RefRoleField},
+		{From: "test.Handler", Role: gstypes.RefRoleParam},
+		{From: "test.NewPost", Role: gstypes.RefRoleParam},
+		{From: "test.Post", Member: "Author", Role: gstypes.RefRoleField},
+		{From: "test.Post", Member: "Meta", Role: gstypes.RefRoleField},
+		{From: "test.Post", Member: "Readers", Role: gstypes.RefRoleField},
+		{From: "test.Store", Member: "Find", Role: gstypes.RefRoleResult},
+		{From: "test.Store", Member: "Save", Role: gstypes.RefRoleParam},
+		{From: "test.Users", Role: gstypes.RefRoleElem},
+	}
+	if got := result.Usages("test.User"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Usages(User) =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Promoted fields belong to the embedded struct
+	wantAudit := []gstypes.Usage{{From: "test.Post", Role: gstypes.RefRoleEmbed}}
+	if got := result.Usages("test.Audit"); !reflect.DeepEqual(got, wantAudit) {
+		t.Errorf("Usages(Audit) = %+v, want %+v", got, wantAudit)
+	}
+	if got := result.Usages("test.Missing"); got != nil {
+		t.Errorf("Usages(Missing) = %+v, want nil", got)
+	}
+
+	post, _ := result.Types.Get("test.Post")
+	serialized := post.Serialize().(*gstypes.SerializedStruct)
+	if len(serialized.UsedBy) != 1 || serialized.UsedBy[0].From != "test.NewPost" {
+		t.Errorf("serialized usedBy = %+v", serialized.UsedBy)
+	}
+}
+
+func TestScanningResult_TopoSort(t *testing.T) {
+	src := `
+	package test
+
+	type City string
+
+	type Address struct {
+		City City
+	}
+
+	type Label string
+
+	type Tag = Label
+
+	type User struct {
+		Address Address
+		Tags    []Tag
+		Friends map[string]*User
+		Best    *Friend
+	}
+
+	type Friend struct {
+		User *User
+	}
+
+	type Account struct{}
+
+	type Handler func(a Account) error
+
+	type Service struct{}
+
+	func (Service) Get() User { return User{} }
+	`
+	result := scanTestSource(t, src)
+
+	sorted, err := result.TopoSort(nil)
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("TopoSort() error = %v, want a *CycleError", err)
+	}
+	if want := [][]string{{"test.Friend", "test.User"}}; !reflect.DeepEqual(cycleErr.Cycles, want) {
+		t.Errorf("cycles = %v, want %v", cycleErr.Cycles, want)
+	}
+
+	position := map[string]int{}
+	for i, typ := range sorted {
+		position[typ.Id()] = i
+	}
+	if len(position) != result.Types.Len() {
+		t.Errorf("TopoSort() returned %d types, want all %d", len(position), result.Types.Len())
+	}
+	for _, edge := range [][2]string{
+		{"test.City", "test.Address"},
+		{"test.Address", "test.User"},
+		{"test.Label", "test.Tag"},
+		{"test.Tag", "test.User"},
+		{"test.Account", "test.Handler"},
+	} {
+		if position[edge[0]] > position[edge[1]] {
+			t.Errorf("%s sorted after %s, which depends on it", edge[0], edge[1])
+		}
+	}
+
+	// The order is stable
+	again, _ := result.TopoSort(nil)
+	if !reflect.DeepEqual(sorted, again) {
+		t.Errorf("TopoSort() order changed between calls")
+	}
+
+	// Filtered types are neither sorted nor followed, dropping Friend breaks the cycle
+	structs, err := result.TopoSort(func(typ gstypes.Type) bool {
+		_, ok := typ.(*gstypes.Struct)
+		return ok && typ.Id() != "test.Friend"
+	})
+	if err != nil {
+		t.Fatalf("TopoSort(structs) error = %v", err)
+	}
+	var ids []string
+	for _, typ := range structs {
+		ids = append(ids, typ.Id())
+	}
+	if want := []string{"test.Account", "test.Address", "test.Service", "test.User"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("TopoSort(structs) = %v, want %v", ids, want)
+	}
+}
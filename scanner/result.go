@@ -1,6 +1,8 @@
 package scanner
 
 import (
+	"sort"
+
 	gstypes "github.com/pablor21/goscanner/types"
 )
 
@@ -9,6 +11,50 @@ type ScanningResult struct {
 	Types    *gstypes.TypesCol[gstypes.Type]     `json:"types,omitempty"`
 	Values   *gstypes.TypesCol[*gstypes.Value]   `json:"values,omitempty"`
 	Packages *gstypes.TypesCol[*gstypes.Package] `json:"packages,omitempty"`
+
+	refResolver func(id string) (url string, ok bool)
+	warnings    *warningLog
+	anyType     *gstypes.Interface // predeclared any shared by the types restored from a cache
+	partial     bool               // the scan was cancelled before completing
+	// problems found by LintTags and ParseAnnotations
+	tagDiagnostics        []*Diagnostic
+	annotationDiagnostics []*Diagnostic
+}
+
+// sharedAny returns the predeclared any of a result restored from a cache
+func (s *ScanningResult) sharedAny() *gstypes.Interface {
+	if s.anyType == nil {
+		s.anyType = gstypes.NewAny()
+	}
+	return s.anyType
+}
+
+// Warnings returns the problems found resolving the types of the result (an unresolved
+// element type, a typed nil...), the affected declarations are incomplete. Types load lazily,
+// call EnsureFullyLoaded first to get them all. Results read from a cache have none.
+func (s *ScanningResult) Warnings() []string {
+	return s.warnings.list()
+}
+
+// Partial returns true for the results of a cancelled scan, and the ones restored from the
+// cache written from them: they hold the declarations resolved until the cancellation
+func (s *ScanningResult) Partial() bool {
+	return s.partial
+}
+
+// SetRefResolver sets the function emitters use to turn the id of a referenced type into a
+// link (another generated file, an external documentation site...)
+func (s *ScanningResult) SetRefResolver(resolver func(id string) (url string, ok bool)) {
+	s.refResolver = resolver
+}
+
+// ResolveRef returns the link of the type with the given id, ok is false when there is no
+// resolver or it doesn't know the type
+func (s *ScanningResult) ResolveRef(id string) (string, bool) {
+	if s == nil || s.refResolver == nil {
+		return "", false
+	}
+	return s.refResolver(id)
 }
 
 func (s *ScanningResult) Serialize() any {
@@ -21,38 +67,197 @@ func (s *ScanningResult) Serialize() any {
 }
 
 // EnsureFullyLoaded materializes all lazy-loaded type details
-// This must be called before caching to ensure all type data is available
+// This must be called before caching to ensure all type data is available.
+// Load doesn't load the types a type refers to (it would deadlock on recursive types), so
+// they're driven from here: every registered type and value, the types they reference and
+// their fields and methods are loaded once each, until no new type is discovered.
 func (s *ScanningResult) EnsureFullyLoaded() error {
+	return s.loadAll(nil)
+}
+
+// loadAll drives the loading of EnsureFullyLoaded, calling visit (if not nil) with every
+// type once it and its members are loaded
+func (s *ScanningResult) loadAll(visit func(gstypes.Type)) error {
 	if s == nil {
 		return nil
 	}
 
-	// Load all types
-	for _, id := range s.Types.Keys() {
-		if t, exists := s.Types.Get(id); exists {
-			if loadable, ok := t.(gstypes.Loadable); ok {
-				if err := loadable.Load(); err != nil {
-					return err
-				}
-			}
+	visited := map[gstypes.Type]bool{}
+	var queue []gstypes.Type
+	enqueue := func(t gstypes.Type) {
+		if t != nil && !visited[t] {
+			visited[t] = true
+			queue = append(queue, t)
 		}
 	}
 
-	// Load all values
-	for _, id := range s.Values.Keys() {
-		if v, exists := s.Values.Get(id); exists {
-			if err := v.Load(); err != nil {
+	for {
+		// Registered types and values first, in id order so that unnamed types resolved while
+		// loading are numbered the same way on every run. Loading can register new types.
+		typeIDs := s.Types.Keys()
+		sort.Strings(typeIDs)
+		for _, id := range typeIDs {
+			if t, exists := s.Types.Get(id); exists {
+				enqueue(t)
+			}
+		}
+		valueIDs := s.Values.Keys()
+		sort.Strings(valueIDs)
+		for _, id := range valueIDs {
+			if v, exists := s.Values.Get(id); exists {
+				enqueue(v)
+			}
+		}
+		if len(queue) == 0 {
+			// Packages don't have a Load method, their files are populated during scanning
+			return nil
+		}
+
+		for len(queue) > 0 {
+			t := queue[0]
+			queue = queue[1:]
+			if err := t.Load(); err != nil {
+				return err
+			}
+			if err := loadMembers(t); err != nil {
 				return err
 			}
+			if visit != nil {
+				visit(t)
+			}
+			gstypes.WalkReferences(t, func(ref gstypes.Type, _ gstypes.RefRole) {
+				enqueue(ref)
+			})
+		}
+	}
+}
+
+// Close loads every type (see EnsureFullyLoaded) and then drops the go/types, go/doc and
+// go/packages data the result holds, so a long lived process keeping the model doesn't keep
+// the whole type checker graph (and the resolver) alive. Serialization, caching, the
+// emitters and comments keep working. Afterwards:
+//   - Object, GoType and Doc of the types and GoPackage of the packages return nil
+//   - Load does nothing
+//   - ComputeImplements finds nothing, it needs the go/types objects
+//   - the reflectish descriptors print unnamed types by id
+//
+// Close is for scanned results, results read from a cache hold no go/types data.
+func (s *ScanningResult) Close() error {
+	if s == nil {
+		return nil
+	}
+	var loaded []gstypes.Type
+	if err := s.loadAll(func(t gstypes.Type) { loaded = append(loaded, t) }); err != nil {
+		return err
+	}
+	for _, t := range loaded {
+		t.Detach()
+		for _, f := range fieldsOf(t) {
+			f.Detach()
+		}
+		for _, m := range t.Methods() {
+			m.Detach()
 		}
 	}
+	for _, pkg := range s.Packages.Values() {
+		pkg.Detach()
+	}
+	return nil
+}
 
-	// Packages don't have a Load method, but we ensure their files are populated
-	// (they're loaded during scanning)
+// loadMembers loads the fields and methods of t, not their types
+func loadMembers(t gstypes.Type) error {
+	for _, f := range fieldsOf(t) {
+		if err := f.Load(); err != nil {
+			return err
+		}
+	}
+	for _, m := range t.Methods() {
+		if err := m.Load(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// fieldsOf returns the fields of the structs and instantiated generics, nil for other types
+func fieldsOf(t gstypes.Type) []*gstypes.Field {
+	switch v := t.(type) {
+	case *gstypes.Struct:
+		return v.Fields()
+	case *gstypes.InstantiatedGeneric:
+		return v.Fields()
+	}
 	return nil
 }
 
+// PreloadAll loads every type and value and closes the graph: loading a type can resolve
+// new types (field types, method signatures...), which are loaded in turn, and named types
+// referenced but missing from the result are added to it, so every reference in the
+// serialized output has a definition. Type parameters are scoped to their parent and are
+// not added. Packages beyond the scanning distance are never resolved, so they're not added.
+func (s *ScanningResult) PreloadAll() error {
+	if s == nil {
+		return nil
+	}
+
+	loaded := map[string]bool{}
+	for {
+		var pending []gstypes.Type
+		for _, t := range s.Types.Values() {
+			if !loaded[t.Id()] {
+				pending = append(pending, t)
+			}
+		}
+		for _, v := range s.Values.Values() {
+			if !loaded["value:"+v.Id()] {
+				pending = append(pending, v)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		sort.Slice(pending, func(i, j int) bool { return pending[i].Id() < pending[j].Id() })
+
+		for _, t := range pending {
+			key := t.Id()
+			if _, ok := t.(*gstypes.Value); ok {
+				key = "value:" + key
+			}
+			loaded[key] = true
+			if err := t.Load(); err != nil {
+				return err
+			}
+
+			gstypes.WalkReferences(t, func(ref gstypes.Type, _ gstypes.RefRole) {
+				if _, ok := ref.(*gstypes.TypeParameter); ok || !ref.IsNamed() || ref.Package() == nil {
+					return
+				}
+				if !s.Types.Has(ref.Id()) {
+					s.Types.Set(ref.Id(), ref)
+				}
+			})
+		}
+	}
+}
+
+// ExternalPackages returns the packages referenced by the scanned ones without being scanned
+// themselves (distance > 0), sorted by path. The distance of each package is the shortest
+// path from a scanned package in the dependency graph.
+func (s *ScanningResult) ExternalPackages() []*gstypes.Package {
+	if s == nil {
+		return nil
+	}
+	var external []*gstypes.Package
+	for _, p := range s.Packages.Values() {
+		if p.Distance() > 0 {
+			external = append(external, p)
+		}
+	}
+	sort.Slice(external, func(i, j int) bool { return external[i].Path() < external[j].Path() })
+	return external
+}
+
 // ToCache serializes the result to a gzip-compressed JSON cache file
 func (s *ScanningResult) ToCache(filename string) error {
 	return WriteCache(filename, s)
@@ -79,3 +284,53 @@ func NewScanningResult() *ScanningResult {
 		Packages: gstypes.NewTypesCol[*gstypes.Package](),
 	}
 }
+
+// MergeResults combines several results into a new one. When the same id is present in more
+// than one result, the entry closest to the scanned packages (lowest distance) wins, ties keep
+// the first one.
+func MergeResults(results ...*ScanningResult) *ScanningResult {
+	merged := NewScanningResult()
+	merged.warnings = &warningLog{}
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		merged.partial = merged.partial || r.partial
+		for _, msg := range r.Warnings() {
+			merged.warnings.add(msg)
+		}
+		for _, id := range r.Types.Keys() {
+			t, _ := r.Types.Get(id)
+			if existing, ok := merged.Types.Get(id); !ok || t.Distance() < existing.Distance() {
+				merged.Types.Set(id, t)
+			}
+		}
+		for _, id := range r.Values.Keys() {
+			v, _ := r.Values.Get(id)
+			if existing, ok := merged.Values.Get(id); !ok || v.Distance() < existing.Distance() {
+				merged.Values.Set(id, v)
+			}
+		}
+		for _, path := range r.Packages.Keys() {
+			p, _ := r.Packages.Get(path)
+			if existing, ok := merged.Packages.Get(path); !ok || p.Distance() < existing.Distance() {
+				merged.Packages.Set(path, p)
+			}
+		}
+	}
+	return merged
+}
+
+// ConstantsByType returns the constants of the result by the id of their type, in id order
+func (s *ScanningResult) ConstantsByType() map[string][]*gstypes.Value {
+	constants := map[string][]*gstypes.Value{}
+	ids := s.Values.Keys()
+	sort.Strings(ids)
+	for _, id := range ids {
+		v, _ := s.Values.Get(id)
+		if v != nil && v.Kind() == gstypes.TypeKindConstant && v.ValueType() != nil {
+			constants[v.ValueType().Id()] = append(constants[v.ValueType().Id()], v)
+		}
+	}
+	return constants
+}
@@ -2,13 +2,18 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"golang.org/x/tools/go/packages"
+
+	gstypes "github.com/pablor21/goscanner/types"
 )
 
 type Scanner interface {
@@ -21,9 +26,12 @@ type Scanner interface {
 }
 
 type DefaultScanner struct {
-	Processors   []Processor
-	Context      *ScanningContext
-	TypeResolver TypeResolver
+	Processors []Processor
+	Context    *ScanningContext
+	// TypeResolver is the resolver of the last scan. Scans of several Config.ModuleDirs load
+	// each module with its own resolver, they're in ModuleResolvers by dir and this one is nil.
+	TypeResolver    TypeResolver
+	ModuleResolvers map[string]TypeResolver
 }
 
 func NewScanner() *DefaultScanner {
@@ -53,6 +61,10 @@ func (s *DefaultScanner) ScanWithConfig(config *Config) (*ScanningResult, error)
 	return s.ScanWithContext(ctx)
 }
 
+// ScanWithContext scans with the configuration of ctx. Cancelling ctx stops the loading of
+// the packages, their processing and the loading of the types: once packages were processed
+// the result resolved until then is returned along with the error, flagged as Partial.
+// The lazy loaders of the types keep ctx, loads after its cancellation fail.
 func (s *DefaultScanner) ScanWithContext(ctx *ScanningContext) (*ScanningResult, error) {
 	// start timer and log start message
 	ctx.Logger.Infof("Starting scan with mode  %s on packages: %v", ctx.ScanMode.String(), ctx.Config.Packages)
@@ -74,7 +86,15 @@ func (s *DefaultScanner) ScanWithContext(ctx *ScanningContext) (*ScanningResult,
 		runtime.GC()
 		runtime.ReadMemStats(&m2)
 		memoryUsage = (m2.Alloc - m1.Alloc) / 1024 // in KB
-		ctx.Logger.Infof("Scan completed in %v, found %d types, across %d packages, memory usage: %dKB", time.Since(now), s.TypeResolver.GetTypes().Len(), totalPackages, memoryUsage)
+		// The resolvers are not created when packages fail to load
+		numTypes := 0
+		if s.TypeResolver != nil {
+			numTypes = s.TypeResolver.GetTypes().Len()
+		}
+		for _, resolver := range s.ModuleResolvers {
+			numTypes += resolver.GetTypes().Len()
+		}
+		ctx.Logger.Infof("Scan completed in %v, found %d types, across %d packages, memory usage: %dKB", time.Since(now), numTypes, totalPackages, memoryUsage)
 	}()
 
 	if ctx == nil || ctx.Config == nil {
@@ -82,52 +102,126 @@ func (s *DefaultScanner) ScanWithContext(ctx *ScanningContext) (*ScanningResult,
 	}
 	// Initialize the scanning result
 	s.Context = ctx
+	s.TypeResolver = nil
+	s.ModuleResolvers = nil
 
 	// determine the scanning mode based on the provided configuration (get the maximum depth of the scan)
-	for _, processor := range s.Processors {
+	for _, processor := range append(registeredProcessors.Slice(), s.Processors...) {
 		if processor.ScanMode() > ctx.ScanMode {
 			ctx.ScanMode = processor.ScanMode()
 		}
 	}
+
+	// Each module dir is loaded in its own module context and the results merged
+	if len(ctx.Config.ModuleDirs) > 0 {
+		results := make([]*ScanningResult, 0, len(ctx.Config.ModuleDirs))
+		s.ModuleResolvers = make(map[string]TypeResolver, len(ctx.Config.ModuleDirs))
+		for _, dir := range ctx.Config.ModuleDirs {
+			result, resolver, n, err := s.scanDir(ctx, dir)
+			if resolver != nil {
+				s.ModuleResolvers[dir] = resolver
+			}
+			if err != nil && ctx.Err() != nil && (result != nil || len(results) > 0) {
+				// Cancelled, the modules scanned so far make the partial result
+				if result != nil {
+					results = append(results, result)
+				}
+				merged := MergeResults(results...)
+				merged.partial = true
+				return merged, err
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan module %s: %w", dir, err)
+			}
+			totalPackages += n
+			results = append(results, result)
+		}
+		merged := MergeResults(results...)
+		if err := analyzeResult(ctx.Config, merged); err != nil {
+			return nil, err
+		}
+		return merged, nil
+	}
+
+	result, resolver, n, err := s.scanDir(ctx, "")
+	if resolver != nil {
+		s.TypeResolver = resolver
+	}
+	totalPackages = n
+	if result != nil && result.partial {
+		return result, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := analyzeResult(ctx.Config, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// analyzeResult runs the analyses that need the whole result
+func analyzeResult(cfg *Config, result *ScanningResult) error {
+	if cfg.MarkConstraintOnly {
+		result.MarkConstraintOnly()
+	}
+	if cfg.ComputeImplements {
+		result.ComputeImplements()
+	}
+	if cfg.LintTags {
+		result.LintTags()
+	}
+	if diags := result.ParseAnnotations(cfg.Annotations...); cfg.StrictAnnotations && len(diags) > 0 {
+		return &AnnotationError{Diagnostics: diags}
+	}
+	if cfg.ScanMode.Has(ScanModeUsages) {
+		return result.ComputeUsages()
+	}
+	return nil
+}
+
+// scanDir scans the configured packages from dir (empty means the working directory) with
+// a new type resolver, and returns the result, the resolver and the number of packages
+// processed
+func (s *DefaultScanner) scanDir(ctx *ScanningContext, dir string) (*ScanningResult, *defaultTypeResolver, int, error) {
 	// create the glob pattern based on the provided configuration
 	scanner := NewGlobScanner()
+	scanner.Dir = dir
+	scanner.Context = ctx
 	pkgs, err := scanner.ScanPackages(ctx.ScanMode, ctx.Config.Packages...)
 	if err != nil {
-		return nil, err
+		return nil, nil, 0, err
+	}
+	auxiliary, err := loadAuxiliaryPackages(ctx, dir, pkgs)
+	if err != nil {
+		return nil, nil, 0, err
 	}
 
 	// set the scanmode in the type resolver
-	s.TypeResolver = NewDefaultTypeResolver(ctx.Config, ctx.Logger)
-
-	// Register dependency packages so we can load their docs when needed
-	visited := make(map[string]bool)
-	var registerDeps func(*packages.Package)
-	registerDeps = func(pkg *packages.Package) {
-		if pkg == nil || visited[pkg.PkgPath] {
-			return
-		}
-		visited[pkg.PkgPath] = true
-
-		// Register this package in the type resolver's package map
-		s.TypeResolver.(*defaultTypeResolver).pkgs.Set(pkg.PkgPath, pkg)
-
-		// Recursively register dependencies
-		for _, dep := range pkg.Imports {
-			registerDeps(dep)
-		}
+	resolver := NewDefaultTypeResolver(ctx.Config, ctx.Logger)
+	resolver.processors = append(resolver.processors, s.Processors...)
+	for _, pkg := range auxiliary {
+		resolver.auxiliary.Set(pkg.PkgPath, true)
 	}
-
-	// Register all packages and their dependencies
-	for _, pkg := range pkgs {
-		registerDeps(pkg)
+	pkgs = append(pkgs, auxiliary...)
+	if err := checkPackageErrors(ctx.Config, resolver, pkgs); err != nil {
+		return nil, nil, 0, err
 	}
 
+	// Register dependency packages so we can load their docs when needed
+	resolver.registerPackages(pkgs)
+
 	// Process packages in parallel using worker pool
 	// Number of workers = configured max_concurrency (0 means CPU cores)
 	numWorkers := ctx.Config.MaxConcurrency
 	if numWorkers <= 0 {
 		numWorkers = runtime.NumCPU()
 	}
+	if ctx.Config.Deterministic {
+		// A single worker in import path order assigns unnamed ids in the same order every scan
+		sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].PkgPath < pkgs[j].PkgPath })
+		numWorkers = 1
+	}
 	if len(pkgs) < numWorkers {
 		numWorkers = len(pkgs)
 	}
@@ -142,10 +236,17 @@ func (s *DefaultScanner) ScanWithContext(ctx *ScanningContext) (*ScanningResult,
 		go func(workerID int) {
 			defer wg.Done()
 			for pkg := range pkgChan {
+				// Once cancelled the remaining packages are drained unprocessed
+				if ctx.Err() != nil {
+					continue
+				}
 				// Each worker gets its own context copy with the package
 				workerCtx := ctx.WithPackage(nil) // Reset to clean state for this package
-				if err := s.TypeResolver.ProcessPackage(workerCtx, pkg); err != nil {
-					errChan <- fmt.Errorf("worker %d failed to process %s: %w", workerID, pkg.PkgPath, err)
+				if err := resolver.ProcessPackage(workerCtx, pkg); err != nil {
+					if ctx.Err() != nil {
+						continue
+					}
+					errChan <- &ResolveError{Package: pkg.PkgPath, Err: fmt.Errorf("worker %d: %w", workerID, err)}
 					return
 				}
 			}
@@ -164,18 +265,136 @@ func (s *DefaultScanner) ScanWithContext(ctx *ScanningContext) (*ScanningResult,
 
 	// Check for errors
 	if len(errChan) > 0 {
-		return nil, <-errChan
+		return nil, resolver, 0, <-errChan
 	}
 
-	totalPackages = len(pkgs)
-
 	result := &ScanningResult{
-		Types:    s.TypeResolver.GetTypes(),
-		Values:   s.TypeResolver.GetValues(),
-		Packages: s.TypeResolver.GetPackages(),
+		Types:    resolver.GetTypes(),
+		Values:   resolver.GetValues(),
+		Packages: resolver.GetPackages(),
+		warnings: resolver.warnings,
 	}
 
 	// Trigger lazy loading of all types in parallel
+	loadTypes(ctx, result.Types)
+	result.Types = filterTypes(result.Types, resolver.resultFilter())
+
+	// A cancelled scan returns what was resolved until then
+	if err := ctx.Err(); err != nil {
+		result.partial = true
+		return result, resolver, len(pkgs), fmt.Errorf("scan cancelled: %w", err)
+	}
+
+	// Return the scanning result and any errors encountered
+	return result, resolver, len(pkgs), nil
+}
+
+// loadAuxiliaryPackages loads the packages of Config.AuxiliaryPackages (test packages
+// included) from dir, leaving out the ones already scanned
+func loadAuxiliaryPackages(ctx *ScanningContext, dir string, scanned []*packages.Package) ([]*packages.Package, error) {
+	if len(ctx.Config.AuxiliaryPackages) == 0 {
+		return nil, nil
+	}
+	scanner := NewGlobScanner()
+	scanner.Dir = dir
+	scanner.Tests = true
+	scanner.Context = ctx
+	pkgs, err := scanner.ScanPackages(ctx.ScanMode, ctx.Config.AuxiliaryPackages...)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(scanned)+len(pkgs))
+	for _, pkg := range scanned {
+		seen[pkg.PkgPath] = true
+	}
+	var auxiliary []*packages.Package
+	for _, pkg := range pkgs {
+		if !seen[pkg.PkgPath] {
+			seen[pkg.PkgPath] = true
+			auxiliary = append(auxiliary, pkg)
+		}
+	}
+	return auxiliary, nil
+}
+
+// ResolveSingle resolves a single type by name without scanning the whole package.
+// Only pkgPath (and the dependencies go/packages loads for it) is loaded, and only typeName
+// and the types reachable from it are resolved. The returned partial result holds every
+// type resolved along the way, so references from the returned type can still be followed.
+func ResolveSingle(pkgPath, typeName string, cfg *Config) (gstypes.Type, *ScanningResult, error) {
+	if cfg == nil {
+		cfg = NewDefaultConfig()
+	}
+	ctx := NewScanningContext(context.Background(), cfg)
+
+	pkgs, err := NewGlobScanner().ScanPackages(ctx.ScanMode, pkgPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pkg *packages.Package
+	for _, p := range pkgs {
+		if p.PkgPath == pkgPath {
+			pkg = p
+			break
+		}
+	}
+	// Filesystem patterns (./models) don't match the import path, so accept their single match
+	if pkg == nil && len(pkgs) == 1 && isFilesystemPattern(pkgPath) {
+		pkg = pkgs[0]
+	}
+	if pkg == nil || pkg.Types == nil {
+		return nil, nil, &PackageLoadError{Package: pkgPath, Err: errors.New("package not found")}
+	}
+	if err := packageErrors([]*packages.Package{pkg}); err != nil {
+		return nil, nil, err
+	}
+
+	resolver := NewDefaultTypeResolver(cfg, ctx.Logger)
+	resolver.registerPackages(pkgs)
+
+	t, err := resolver.resolveNamed(ctx.WithPackage(nil), pkg, typeName)
+	if err != nil {
+		return nil, nil, &ResolveError{Package: pkg.PkgPath, TypeID: pkg.PkgPath + "." + typeName, Err: err}
+	}
+
+	result := &ScanningResult{
+		Types:    resolver.GetTypes(),
+		Values:   resolver.GetValues(),
+		Packages: resolver.GetPackages(),
+		warnings: resolver.warnings,
+	}
+	loadTypes(ctx, result.Types)
+	result.Types = filterTypes(result.Types, resolver.resultFilter())
+
+	return t, result, nil
+}
+
+// isFilesystemPattern reports whether pattern is a directory rather than an import path
+func isFilesystemPattern(pattern string) bool {
+	return pattern == "." || pattern == ".." || filepath.IsAbs(pattern) ||
+		strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../")
+}
+
+// filterTypes returns the types of col filter keeps, col itself when filter is nil
+func filterTypes(col *gstypes.TypesCol[gstypes.Type], filter func(gstypes.Type) bool) *gstypes.TypesCol[gstypes.Type] {
+	if filter == nil {
+		return col
+	}
+	kept := gstypes.NewTypesCol[gstypes.Type]()
+	for _, id := range col.Keys() {
+		if t, ok := col.Get(id); ok && filter(t) {
+			kept.Set(id, t)
+		}
+	}
+	return kept
+}
+
+// loadTypes triggers lazy loading of every type in col in parallel.
+// Loading a type can resolve new types (like field types), so it keeps loading
+// until no new types are discovered.
+func loadTypes(ctx *ScanningContext, col *gstypes.TypesCol[gstypes.Type]) {
 	// Keep loading until no new types are discovered
 	// (Loading a type can trigger resolution of new types like field types)
 	// Use worker pool to limit concurrency and handle dynamic type discovery
@@ -185,14 +404,14 @@ func (s *DefaultScanner) ScanWithContext(ctx *ScanningContext) (*ScanningResult,
 	for {
 		// Get all type IDs that haven't been loaded yet
 		var typeIDs []string
-		for _, t := range result.Types.Values() {
+		for _, t := range col.Values() {
 			if _, loaded := loadedTypes.Load(t.Id()); !loaded {
 				typeIDs = append(typeIDs, t.Id())
 			}
 		}
 
-		if len(typeIDs) == 0 {
-			break // No new types to load
+		if len(typeIDs) == 0 || ctx.Err() != nil {
+			break // No new types to load, or cancelled
 		}
 
 		// Sort to ensure deterministic loading order
@@ -203,6 +422,9 @@ func (s *DefaultScanner) ScanWithContext(ctx *ScanningContext) (*ScanningResult,
 		if numWorkers <= 0 {
 			numWorkers = runtime.NumCPU() * 2 // More workers for I/O-bound loading
 		}
+		if ctx.Config.Deterministic {
+			numWorkers = 1
+		}
 		if len(typeIDs) < numWorkers {
 			numWorkers = len(typeIDs)
 		}
@@ -217,10 +439,13 @@ func (s *DefaultScanner) ScanWithContext(ctx *ScanningContext) (*ScanningResult,
 			go func(workerID int) {
 				defer wg.Done()
 				for id := range typeChan {
+					if ctx.Err() != nil {
+						continue
+					}
 					// Retry mechanism for failed loads
 					var loadErr error
 					for attempt := 0; attempt < maxRetries; attempt++ {
-						t, exists := result.Types.Get(id)
+						t, exists := col.Get(id)
 						if !exists {
 							break // Type disappeared, skip it
 						}
@@ -259,10 +484,29 @@ func (s *DefaultScanner) ScanWithContext(ctx *ScanningContext) (*ScanningResult,
 		for err := range errChan {
 			ctx.Logger.Debug(err.Error())
 		}
+
+		// Unnamed types (anonymous interfaces and structs of fields and signatures) are not
+		// in the collection, load the ones referenced by this round so they serialize in full.
+		// Named types they resolve are picked up by the next round.
+		for _, id := range typeIDs {
+			if t, exists := col.Get(id); exists {
+				loadUnnamedReferences(ctx, t)
+			}
+		}
 	}
+}
 
-	// Return the scanning result and any errors encountered
-	return result, nil
+// loadUnnamedReferences loads the unnamed types referenced by t. Loading a type descends
+// into it, so nested unnamed types are loaded too.
+func loadUnnamedReferences(ctx *ScanningContext, t gstypes.Type) {
+	gstypes.WalkReferences(t, func(ref gstypes.Type, _ gstypes.RefRole) {
+		if ref.IsNamed() {
+			return
+		}
+		if err := ref.Load(); err != nil {
+			ctx.Logger.Debug(fmt.Sprintf("Failed to load unnamed type %s: %v", ref.Id(), err))
+		}
+	})
 }
 
 func (s *DefaultScanner) GetTypeResolver() TypeResolver {
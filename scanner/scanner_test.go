@@ -0,0 +1,886 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablor21/goscanner/logger"
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+func TestResolveSingle(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+
+	typ, result, err := ResolveSingle("../examples/starwars/models", "Human", cfg)
+	if err != nil {
+		t.Fatalf("ResolveSingle() error = %v", err)
+	}
+
+	human, ok := typ.(*gstypes.Struct)
+	if !ok {
+		t.Fatalf("expected *Struct, got %T", typ)
+	}
+	if human.Id() != "github.com/pablor21/goscanner/examples/starwars/models.Human" {
+		t.Errorf("unexpected id %s", human.Id())
+	}
+
+	// The embedded struct is reachable from Human and must be part of the partial result
+	embedded := "github.com/pablor21/goscanner/examples/starwars/models.EmbeddedStruct"
+	if names := human.EmbeddedNames(); len(names) != 1 || names[0] != embedded {
+		t.Fatalf("EmbeddedNames() = %v, want [%s]", names, embedded)
+	}
+	if !result.Types.Has(embedded) {
+		t.Errorf("expected %s to be resolved in the partial result", embedded)
+	}
+
+	// Unrelated types of the same package are not resolved
+	if result.Types.Has("github.com/pablor21/goscanner/examples/starwars/models.InterfaceExample") {
+		t.Errorf("did not expect unrelated type InterfaceExample in the partial result")
+	}
+
+	if _, _, err := ResolveSingle("../examples/starwars/models", "Missing", cfg); err == nil {
+		t.Errorf("expected an error for a missing type")
+	}
+}
+
+func TestResolveSingle_errors(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+
+	// Type errors in the package must not resolve silently
+	if _, _, err := ResolveSingle("./testdata/broken", "Broken", cfg); err == nil {
+		t.Errorf("expected an error for a package with type errors")
+	}
+
+	// An import path pattern that matches a different package is not accepted
+	if _, _, err := ResolveSingle("github.com/pablor21/goscanner/examples/starwars/models/...", "Human", cfg); err == nil {
+		t.Errorf("expected an error for a pattern that does not name the package")
+	}
+
+	// The import path itself resolves
+	if _, _, err := ResolveSingle("github.com/pablor21/goscanner/examples/starwars/models", "Human", cfg); err != nil {
+		t.Errorf("ResolveSingle() by import path error = %v", err)
+	}
+}
+
+func TestScanWithConfig_moduleDirs(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./..."}
+	cfg.ModuleDirs = []string{"testdata/modules/alpha", "testdata/modules/beta"}
+
+	s := NewScanner()
+	result, err := s.ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+
+	// Each module is resolved on its own, no resolver holds the merged result
+	if s.TypeResolver != nil {
+		t.Errorf("TypeResolver must be nil for several modules")
+	}
+	for dir, id := range map[string]string{"testdata/modules/alpha": "example.com/alpha.Alpha", "testdata/modules/beta": "example.com/beta.Beta"} {
+		if resolver, ok := s.ModuleResolvers[dir]; !ok || !resolver.GetTypes().Has(id) {
+			t.Errorf("expected the resolver of %s to hold %s", dir, id)
+		}
+	}
+
+	for _, id := range []string{"example.com/alpha.Alpha", "example.com/beta.Beta"} {
+		if !result.Types.Has(id) {
+			t.Errorf("expected %s in the merged result", id)
+		}
+	}
+	for _, path := range []string{"example.com/alpha", "example.com/beta"} {
+		if !result.Packages.Has(path) {
+			t.Errorf("expected package %s in the merged result", path)
+		}
+	}
+}
+
+func TestGlobScanner_ScanPackages_patterns(t *testing.T) {
+	dir := t.TempDir()
+	for file, src := range map[string]string{
+		"go.mod":              "module example.com/app\n\ngo 1.22\n",
+		"app.go":              "package app\n",
+		"api/api.go":          "package api\n",
+		"api/v2/v2.go":        "package v2\n",
+		"internal/db/db.go":   "package db\n",
+		"internal/log/log.go": "package log\n",
+	} {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scan := func(patterns ...string) []string {
+		t.Helper()
+		pkgs, err := (&GlobScanner{Dir: dir}).ScanPackages(ScanModeTypes, patterns...)
+		if err != nil {
+			t.Fatalf("ScanPackages(%v) error = %v", patterns, err)
+		}
+		var paths []string
+		for _, pkg := range pkgs {
+			paths = append(paths, pkg.PkgPath)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	tests := []struct {
+		patterns []string
+		want     []string
+	}{
+		{[]string{"./..."}, []string{"example.com/app", "example.com/app/api", "example.com/app/api/v2", "example.com/app/internal/db", "example.com/app/internal/log"}},
+		{[]string{"example.com/app/api/..."}, []string{"example.com/app/api", "example.com/app/api/v2"}},
+		// Overlapping patterns load each package once
+		{[]string{"./api/...", "./api"}, []string{"example.com/app/api", "example.com/app/api/v2"}},
+		{[]string{"./...", "!./internal/..."}, []string{"example.com/app", "example.com/app/api", "example.com/app/api/v2"}},
+		{[]string{"./...", "!example.com/app/api/...", "!./internal/log"}, []string{"example.com/app", "example.com/app/internal/db"}},
+		{[]string{"./...", "!./api/**"}, []string{"example.com/app", "example.com/app/internal/db", "example.com/app/internal/log"}},
+	}
+	for _, tt := range tests {
+		if got := scan(tt.patterns...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ScanPackages(%v) = %v, want %v", tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestMergeResults(t *testing.T) {
+	near := gstypes.NewStruct("pkg.T", "T")
+	far := gstypes.NewStruct("pkg.T", "T")
+	far.SetDistance(2)
+
+	a := NewScanningResult()
+	a.Types.Set("pkg.T", far)
+	a.Types.Set("pkg.A", gstypes.NewStruct("pkg.A", "A"))
+	b := NewScanningResult()
+	b.Types.Set("pkg.T", near)
+	b.Types.Set("pkg.B", gstypes.NewStruct("pkg.B", "B"))
+
+	merged := MergeResults(a, nil, b)
+	if merged.Types.Len() != 3 {
+		t.Errorf("merged %d types, want 3", merged.Types.Len())
+	}
+	if got, _ := merged.Types.Get("pkg.T"); got != near {
+		t.Errorf("expected the closest pkg.T to win the merge")
+	}
+}
+
+func TestPreloadAll(t *testing.T) {
+	src := `
+	package test
+
+	type Address struct {
+		City string
+	}
+
+	type Profile struct {
+		Home *Address
+	}
+
+	type Box[T any] struct {
+		Value T
+	}
+
+	type User struct {
+		Profile Profile
+		Boxes   []Box[Address]
+	}
+
+	var Current User
+	`
+
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	r := NewDefaultTypeResolver(cfg, logger.NewDefaultLogger())
+	ctx := NewScanningContext(context.Background(), cfg)
+	if err := r.ProcessPackage(ctx, newTestPackage(t, src)); err != nil {
+		t.Fatalf("ProcessPackage() error = %v", err)
+	}
+	// Processed but not loaded, and missing a referenced type
+	result := &ScanningResult{Types: r.GetTypes(), Values: r.GetValues(), Packages: r.GetPackages()}
+	result.Types.Delete("test.Address")
+
+	if err := result.PreloadAll(); err != nil {
+		t.Fatalf("PreloadAll() error = %v", err)
+	}
+
+	if !result.Types.Has("test.Address") {
+		t.Errorf("referenced type test.Address not restored")
+	}
+	user, _ := result.Types.Get("test.User")
+	if len(user.(*gstypes.Struct).Fields()) != 2 {
+		t.Errorf("User not loaded, fields: %v", user.(*gstypes.Struct).Fields())
+	}
+
+	// Every named reference has a definition
+	check := func(from gstypes.Type) {
+		gstypes.WalkReferences(from, func(ref gstypes.Type, _ gstypes.RefRole) {
+			if _, ok := ref.(*gstypes.TypeParameter); ok || !ref.IsNamed() || ref.Package() == nil {
+				return
+			}
+			if !result.Types.Has(ref.Id()) {
+				t.Errorf("%s references %s which has no definition", from.Id(), ref.Id())
+			}
+		})
+	}
+	for _, typ := range result.Types.Values() {
+		check(typ)
+	}
+	for _, v := range result.Values.Values() {
+		check(v)
+	}
+}
+
+func TestEnsureFullyLoaded_recursiveTypes(t *testing.T) {
+	src := `
+	package test
+
+	type A struct {
+		B *B
+	}
+
+	type B struct {
+		A *A
+		Items []struct {
+			Owner *A
+		}
+	}
+	`
+
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	r := NewDefaultTypeResolver(cfg, logger.NewDefaultLogger())
+	ctx := NewScanningContext(context.Background(), cfg)
+	if err := r.ProcessPackage(ctx, newTestPackage(t, src)); err != nil {
+		t.Fatalf("ProcessPackage() error = %v", err)
+	}
+	// Processed but not loaded
+	result := &ScanningResult{Types: r.GetTypes(), Values: r.GetValues(), Packages: r.GetPackages()}
+
+	done := make(chan error, 1)
+	go func() { done <- result.EnsureFullyLoaded() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("EnsureFullyLoaded() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("EnsureFullyLoaded() deadlocked on recursive types")
+	}
+
+	fieldOf := func(s *gstypes.Struct, name string) *gstypes.Field {
+		t.Helper()
+		for _, f := range s.Fields() {
+			if f.Name() == name {
+				return f
+			}
+		}
+		t.Fatalf("%s has no field %s, fields: %v", s.Id(), name, s.Fields())
+		return nil
+	}
+	a, _ := result.Types.Get("test.A")
+	b := fieldOf(a.(*gstypes.Struct), "B").Type().(*gstypes.Pointer).Elem().(*gstypes.Struct)
+	if back := fieldOf(b, "A").Type().(*gstypes.Pointer).Elem(); back != a {
+		t.Errorf("B.A points to %v, want test.A", back)
+	}
+
+	// The anonymous element struct is not registered, it's loaded through the reference
+	item := fieldOf(b, "Items").Type().(*gstypes.Slice).Elem().(*gstypes.Struct)
+	if owner := fieldOf(item, "Owner").Type().(*gstypes.Pointer).Elem(); owner != a {
+		t.Errorf("Items[].Owner points to %v, want test.A", owner)
+	}
+}
+
+func TestScanWithConfig_deterministic(t *testing.T) {
+	scan := func() []byte {
+		t.Helper()
+		cfg := NewDefaultConfig()
+		cfg.LogLevel = "error"
+		// Packages sharing unnamed types, for the parallel scan to interleave
+		cfg.Packages = []string{"./testdata/deterministic/..."}
+		cfg.MaxConcurrency = 8 // overridden by Deterministic
+		cfg.Deterministic = true
+		result, err := NewScanner().ScanWithConfig(cfg)
+		if err != nil {
+			t.Fatalf("ScanWithConfig() error = %v", err)
+		}
+		if err := result.EnsureFullyLoaded(); err != nil {
+			t.Fatalf("EnsureFullyLoaded() error = %v", err)
+		}
+		data, err := json.Marshal(result.Serialize())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	first := scan()
+	if !bytes.Contains(first, []byte("__unnamed_")) {
+		t.Fatalf("expected unnamed types in the scanned packages")
+	}
+	if !bytes.Equal(first, scan()) {
+		t.Errorf("repeated scan of unchanged code produced different output")
+	}
+}
+
+func TestScanWithConfig_skipGenerated(t *testing.T) {
+	const pkg = "github.com/pablor21/goscanner/scanner/testdata/generated."
+	scan := func(skip bool) *ScanningResult {
+		t.Helper()
+		cfg := NewDefaultConfig()
+		cfg.LogLevel = "error"
+		cfg.Packages = []string{"./testdata/generated"}
+		cfg.SkipGenerated = skip
+		result, err := NewScanner().ScanWithConfig(cfg)
+		if err != nil {
+			t.Fatalf("ScanWithConfig() error = %v", err)
+		}
+		return result
+	}
+
+	all := scan(false)
+	for _, id := range []string{"User", "Greet", "AddressPB", "UserPB", "NewUserPB"} {
+		if !all.Types.Has(pkg + id) {
+			t.Errorf("expected %s without SkipGenerated", id)
+		}
+	}
+
+	result := scan(true)
+	for _, id := range []string{"User", "Greet"} {
+		if !result.Types.Has(pkg + id) {
+			t.Errorf("expected hand written %s", id)
+		}
+	}
+	// Referenced from User, so kept
+	if !result.Types.Has(pkg + "AddressPB") {
+		t.Errorf("expected the referenced generated type AddressPB")
+	}
+	for _, id := range []string{"UserPB", "NewUserPB"} {
+		if result.Types.Has(pkg + id) {
+			t.Errorf("generated %s not skipped", id)
+		}
+	}
+	if result.Values.Has(pkg + "DefaultCity") {
+		t.Errorf("generated constant DefaultCity not skipped")
+	}
+}
+
+func TestScanningResult_ExternalPackages(t *testing.T) {
+	const base = "github.com/pablor21/goscanner/scanner/testdata/external/"
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./testdata/external/app"}
+
+	result, err := NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+	if err := result.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]int{}
+	for _, p := range result.ExternalPackages() {
+		got[p.Path()] = p.Distance()
+	}
+	want := map[string]int{base + "dep": 1, base + "deeper": 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExternalPackages() = %v, want %v", got, want)
+	}
+
+	// A scanned package is never external, even when another scan reached it from outside
+	scanned := NewScanningResult()
+	dep, _ := result.Packages.Get(base + "dep")
+	direct := gstypes.NewPackage(dep.Path(), dep.Name(), nil)
+	scanned.Packages.Set(direct.Path(), direct)
+	for _, p := range MergeResults(result, scanned).ExternalPackages() {
+		if p.Path() == direct.Path() {
+			t.Errorf("%s is scanned in one of the merged results", p.Path())
+		}
+	}
+}
+
+func TestScanWithConfig_auxiliaryPackages(t *testing.T) {
+	const base = "github.com/pablor21/goscanner/scanner/testdata/auxiliary/"
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./testdata/auxiliary/app"}
+	cfg.AuxiliaryPackages = []string{"./testdata/auxiliary/fixtures"}
+
+	result, err := NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+
+	for _, id := range []string{base + "app.Order", base + "fixtures.Item"} {
+		if !result.Types.Has(id) {
+			t.Errorf("expected %s in the result", id)
+		}
+	}
+	for _, id := range []string{base + "fixtures.Unused", base + "fixtures.NewItem", base + "fixtures_test.Case"} {
+		if result.Types.Has(id) {
+			t.Errorf("auxiliary declaration %s not referenced by the scanned packages", id)
+		}
+	}
+
+	// Loaded with its test package, processed like a scanned package
+	for _, path := range []string{base + "fixtures", base + "fixtures_test"} {
+		if p, ok := result.Packages.Get(path); !ok || p.Distance() != 0 {
+			t.Errorf("auxiliary package %s not processed", path)
+		}
+	}
+	item, _ := result.Types.Get(base + "fixtures.Item")
+	if comments := item.Comments(); len(comments) == 0 {
+		t.Errorf("expected the docs of the auxiliary package on Item")
+	}
+}
+
+func TestScanWithConfig_linkage(t *testing.T) {
+	const pkg = "github.com/pablor21/goscanner/scanner/testdata/asm."
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./testdata/asm"}
+
+	result, err := NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+
+	for name, want := range map[string]gstypes.Linkage{
+		"Add":    {Assembly: true},
+		"Now":    {Linkname: "runtime.nanotime"},
+		"Double": {Linkname: pkg + "Double"},
+		"Plain":  {},
+	} {
+		typ, ok := result.Types.Get(pkg + name)
+		if !ok {
+			t.Errorf("expected function %s", name)
+			continue
+		}
+		fn := typ.(*gstypes.Function)
+		if got := (gstypes.Linkage{Assembly: fn.IsAssembly(), Linkname: fn.Linkname()}); got != want {
+			t.Errorf("%s linkage = %+v, want %+v", name, got, want)
+		}
+	}
+
+	counter, _ := result.Types.Get(pkg + "Counter")
+	if err := counter.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if methods := counter.Methods(); len(methods) != 1 || !methods[0].IsAssembly() {
+		t.Errorf("expected the assembly method Inc, got %v", methods)
+	}
+}
+
+func TestScanWithConfig_modules(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./testdata/provenance"}
+
+	result, err := NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+	if err := result.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The version of the x/tools module this test is built against
+	var toolsVersion string
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == "golang.org/x/tools" {
+				toolsVersion = dep.Version
+			}
+		}
+	}
+	if toolsVersion == "" {
+		t.Fatal("golang.org/x/tools not found in the build info")
+	}
+
+	for path, want := range map[string]gstypes.SerializedModule{
+		"github.com/pablor21/goscanner/scanner/testdata/provenance": {Path: "github.com/pablor21/goscanner"},
+		"golang.org/x/tools/go/packages":                            {Path: "golang.org/x/tools", Version: toolsVersion},
+	} {
+		pkg, ok := result.Packages.Get(path)
+		if !ok {
+			t.Errorf("expected package %s", path)
+			continue
+		}
+		if pkg.Module() == nil {
+			t.Errorf("%s has no module", path)
+			continue
+		}
+		if got := pkg.Module().Serialize(); got != want {
+			t.Errorf("%s module = %+v, want %+v", path, got, want)
+		}
+	}
+
+	// The module survives the cache
+	data, err := json.Marshal(result.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := reconstructFromCache(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg, ok := restored.Packages.Get("golang.org/x/tools/go/packages"); !ok || pkg.Module() == nil || pkg.Module().Version() != toolsVersion {
+		t.Errorf("restored package lost its module: %v", pkg)
+	}
+}
+
+func TestScanWithConfig_maxDistance(t *testing.T) {
+	const base = "github.com/pablor21/goscanner/scanner/testdata/distance/"
+	scan := func(maxDistance int) *ScanningResult {
+		t.Helper()
+		cfg := NewDefaultConfig()
+		cfg.LogLevel = "error"
+		cfg.Packages = []string{"./testdata/distance/app"}
+		cfg.MaxDistance = maxDistance
+		result, err := NewScanner().ScanWithConfig(cfg)
+		if err != nil {
+			t.Fatalf("ScanWithConfig() error = %v", err)
+		}
+		if err := result.EnsureFullyLoaded(); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// Without a limit the whole chain is resolved
+	result := scan(0)
+	for _, id := range []string{base + "b.B", base + "c.C", base + "d.D"} {
+		if typ, ok := result.Types.Get(id); !ok || typ.Opaque() {
+			t.Errorf("%s should be resolved without a max distance", id)
+		}
+	}
+
+	result = scan(1)
+	b, ok := result.Types.Get(base + "b.B")
+	if !ok || b.Opaque() || len(b.(*gstypes.Struct).Fields()) != 1 || len(b.Methods()) != 1 {
+		t.Fatalf("b.B within the max distance should be resolved, got %v", b)
+	}
+	c, ok := result.Types.Get(base + "c.C")
+	if !ok {
+		t.Fatal("c.C should still be referenced beyond the max distance")
+	}
+	if !c.Opaque() || c.Distance() != 2 || len(c.(*gstypes.Struct).Fields()) != 0 || len(c.Methods()) != 0 {
+		t.Errorf("c.C beyond the max distance should be opaque without members, got opaque=%v distance=%d", c.Opaque(), c.Distance())
+	}
+	if result.Types.Has(base + "d.D") {
+		t.Error("d.D is only reachable through an opaque type and shouldn't be resolved")
+	}
+
+	// ExternalPackagesOptions.MaxDistance applies when Config.MaxDistance isn't set
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./testdata/distance/app"}
+	cfg.ExternalPackagesOptions.MaxDistance = 1
+	result, err := NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := result.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := result.Types.Get(base + "c.C"); !ok || !c.Opaque() {
+		t.Error("c.C should be opaque with ExternalPackagesOptions.MaxDistance")
+	}
+
+	// The flag survives the cache
+	data, err := json.Marshal(result.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := reconstructFromCache(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := restored.Types.Get(base + "c.C"); !ok || !c.Opaque() {
+		t.Error("restored c.C lost its opaque flag")
+	}
+}
+
+func TestScanWithContext_cancelled(t *testing.T) {
+	newCfg := func() *Config {
+		cfg := NewDefaultConfig()
+		cfg.LogLevel = "error"
+		cfg.Packages = []string{"./testdata/distance/app"}
+		return cfg
+	}
+
+	// Cancelled before the packages are loaded there's nothing to return
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := NewScanner().ScanWithContext(NewScanningContext(ctx, newCfg()))
+	if !errors.Is(err, context.Canceled) || result != nil {
+		t.Fatalf("ScanWithContext() = %v, %v, want nil and context.Canceled", result, err)
+	}
+
+	// Cancelled while loading the types the result resolved until then is returned
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	cfg := newCfg()
+	cfg.FieldFilter = func(*gstypes.Field) bool {
+		cancel()
+		return true
+	}
+	result, err = NewScanner().ScanWithContext(NewScanningContext(ctx, cfg))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ScanWithContext() error = %v, want context.Canceled", err)
+	}
+	if result == nil || !result.Partial() {
+		t.Fatalf("expected a partial result, got %v", result)
+	}
+	if !result.Types.Has("github.com/pablor21/goscanner/scanner/testdata/distance/app.App") {
+		t.Error("the partial result should hold the types processed before the cancellation")
+	}
+
+	// Its cache keeps the flag and isn't valid for reuse
+	path := filepath.Join(t.TempDir(), "partial.cache")
+	if err := result.ToCache(path); err != nil {
+		t.Fatal(err)
+	}
+	if IsCacheValid(path) {
+		t.Error("the cache of a partial result shouldn't be valid")
+	}
+	restored, err := ReadCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored.Partial() {
+		t.Error("the restored result lost its partial flag")
+	}
+}
+
+// tagProcessor annotates the types it receives and vetoes the ones named veto
+type tagProcessor struct {
+	NoOpProcessor
+	key  string
+	veto string
+}
+
+func (p *tagProcessor) ProcessResolvedType(t gstypes.Type) (bool, error) {
+	if t.Name() == p.veto {
+		return false, nil
+	}
+	t.SetMetadata(p.key, "seen "+t.Name())
+	return true, nil
+}
+
+func TestScanner_typeProcessors(t *testing.T) {
+	const base = "github.com/pablor21/goscanner/scanner/testdata/distance/"
+	registered := registeredProcessors
+	defer func() { registeredProcessors = registered }()
+	registeredProcessors = gstypes.NewSyncSlice[Processor]()
+	RegisterProcessor(&tagProcessor{key: "plugin"})
+
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./testdata/distance/app"}
+	s := NewScanner()
+	s.AddProcessor(&tagProcessor{key: "owner", veto: "C"})
+	result, err := s.ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+
+	b, ok := result.Types.Get(base + "b.B")
+	if !ok {
+		t.Fatal("b.B missing")
+	}
+	if want := (map[string]any{"plugin": "seen B", "owner": "seen B"}); !reflect.DeepEqual(b.Metadata(), want) {
+		t.Errorf("b.B metadata = %v, want %v", b.Metadata(), want)
+	}
+
+	// The vetoed type is left out, still referenced by the field of B
+	if result.Types.Has(base + "c.C") {
+		t.Error("c.C was vetoed and shouldn't be in the result")
+	}
+	fields := b.(*gstypes.Struct).Fields()
+	if len(fields) != 1 || fields[0].Type().Id() != base+"c.C" {
+		t.Errorf("b.B should keep its field of the vetoed type, got %v", fields)
+	}
+
+	// The metadata survives the cache
+	path := filepath.Join(t.TempDir(), "result.cache")
+	if err := result.ToCache(path); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := ReadCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := restored.Types.Get(base + "b.B"); !ok || b.Metadata()["owner"] != "seen B" {
+		t.Errorf("restored b.B lost its metadata: %v", b)
+	}
+}
+
+func TestScanningResult_warnings(t *testing.T) {
+	clean := scanTestSource(t, `
+	package test
+
+	type User struct {
+		Tags map[string][]int
+	}
+	`)
+	if err := clean.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+	if warnings := clean.Warnings(); len(warnings) != 0 {
+		t.Errorf("Warnings() = %v, want none", warnings)
+	}
+
+	r, ctx, _ := newTestResolver(t, "package test")
+	r.logger.SetLevel("none")
+	result := &ScanningResult{Types: r.GetTypes(), Values: r.GetValues(), Packages: r.GetPackages(), warnings: r.warnings}
+	if got := r.ResolveType(ctx, types.NewTuple()); got != nil {
+		t.Fatalf("ResolveType(tuple) = %v, want nil", got)
+	}
+	warnings := result.Warnings()
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "Unsupported type") {
+		t.Fatalf("Warnings() = %v, want the unsupported type", warnings)
+	}
+
+	// Warnings added after the result is built are visible, and merged results keep them
+	r.warnf("Failed to resolve %s", "late")
+	merged := MergeResults(clean, result)
+	if got := merged.Warnings(); len(got) != 2 || got[1] != "Failed to resolve late" {
+		t.Errorf("merged Warnings() = %v, want both warnings", got)
+	}
+	if got := (&ScanningResult{}).Warnings(); got != nil {
+		t.Errorf("Warnings() of a result without a resolver = %v", got)
+	}
+}
+
+func TestScanningResult_Close(t *testing.T) {
+	result := scanTestSource(t, `
+	package test
+
+	// User is a user
+	//go:generate echo user
+	type User struct {
+		Name    string
+		Manager *User
+		Tags    map[string][]int
+		Events  chan struct{ At int }
+	}
+
+	func (u *User) Rename(name string) error { return nil }
+
+	func NewUser() *User { return nil }
+	`)
+	if err := result.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+	before, err := json.Marshal(result.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := result.Close(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := json.Marshal(result.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Errorf("serialization changed by Close:\nbefore %s\nafter  %s", before, after)
+	}
+
+	for _, typ := range result.Types.Values() {
+		if typ.Object() != nil || typ.GoType() != nil || typ.Doc() != nil {
+			t.Errorf("%s keeps its go/types data after Close", typ.Id())
+		}
+		for _, m := range typ.Methods() {
+			if m.Object() != nil {
+				t.Errorf("method %s keeps its go/types object after Close", m.Id())
+			}
+		}
+	}
+	for _, pkg := range result.Packages.Values() {
+		if pkg.GoPackage() != nil {
+			t.Errorf("package %s keeps its go/packages data after Close", pkg.Path())
+		}
+	}
+	user, _ := result.Types.Get("test.User")
+	if user == nil || !user.IsNamed() || len(user.GenerateDirectives()) != 1 {
+		t.Errorf("User lost its declaration data after Close: %+v", user)
+	}
+}
+
+func TestTypeResolver_initFuncs(t *testing.T) {
+	src := `
+	package test
+
+	var registry = map[string]int{}
+
+	func init() {
+		registry["a"] = 1
+	}
+
+	type Plugin struct{}
+
+	func (Plugin) init() {}
+
+	func init() {
+		registry["b"] = 2
+	}
+	`
+
+	result := scanTestSource(t, src)
+	pkg, ok := result.Packages.Get("test")
+	if !ok {
+		t.Fatal("expected package test")
+	}
+	if !pkg.HasInit() {
+		t.Fatal("HasInit() = false, want true")
+	}
+	var lines []int
+	for _, pos := range pkg.InitFuncs() {
+		if pos.File != "test/test.go" {
+			t.Errorf("init file = %s, want test/test.go", pos.File)
+		}
+		lines = append(lines, pos.Line)
+	}
+	if want := []int{6, 14}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("init lines = %v, want %v", lines, want)
+	}
+	if _, ok := result.Types.Get("test.init"); ok {
+		t.Error("init registered as a function")
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.ScanMode = ScanModeTypes
+	pkg, _ = scanTestSourceWithConfig(t, src, cfg).Packages.Get("test")
+	if pkg.HasInit() {
+		t.Error("init functions detected without ScanModeFunctions")
+	}
+}
@@ -0,0 +1,454 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// schemaWellKnown are the schemas of well known named types
+var schemaWellKnown = map[string]map[string]any{
+	"time.Time":                   {"type": "string", "format": "date-time"},
+	"time.Duration":               {"type": "integer", "format": "int64"},
+	"encoding/json.RawMessage":    {},
+	"github.com/google/uuid.UUID": {"type": "string", "format": "uuid"},
+}
+
+// Schemas returns the JSON schemas (draft 2020-12, as OpenAPI 3.1) of the structs and enums of
+// the scanned packages and of every named type or generic instance they reference, by
+// definition name. Definitions reference each other with refPrefix followed by their name,
+// tagKey is the tag holding the property names and the omitempty option (default "json").
+//
+// Schemas follow encoding/json: properties are named by the tag (the Go name when untagged)
+// and required unless tagged omitempty or omitzero, pointers are nullable, fields promoted
+// from embedded structs are flattened and fields tagged "-", unexported fields, channels and
+// functions are skipped. Named integer and string types with constants are enums of their
+// values. Instances of generic types get a definition of their own named after the origin
+// and the type arguments (Page[User] is Page_User).
+func (s *ScanningResult) Schemas(refPrefix string, tagKey string) (map[string]any, error) {
+	b := newSchemaBuilder(s, refPrefix, tagKey)
+	if err := b.build(); err != nil {
+		return nil, err
+	}
+	return b.schemas, nil
+}
+
+// EncodeTree writes a generic JSON tree in format, JSON indented with tabs
+func EncodeTree(w io.Writer, tree map[string]any, format Format) error {
+	switch format {
+	case "", FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(tree)
+	case FormatYAML:
+		yw := newYAMLWriter(w)
+		yw.writeMap(tree, 0, false)
+		return yw.end()
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// schemaBuilder builds the JSON schemas (draft 2020-12, as OpenAPI 3.1) of the types of a
+// result, named types are definitions referenced with refPrefix
+type schemaBuilder struct {
+	result    *ScanningResult
+	refPrefix string
+	tagKey    string
+	names     map[string]string // definition names, by type id
+	used      UniqueNames
+	queue     []gstypes.Type              // named types referenced, waiting for their definition
+	schemas   map[string]any              // definitions, by name
+	constants map[string][]*gstypes.Value // constants of the scanned values, by type id
+}
+
+func newSchemaBuilder(result *ScanningResult, refPrefix string, tagKey string) *schemaBuilder {
+	if tagKey == "" {
+		tagKey = "json"
+	}
+	return &schemaBuilder{
+		result:    result,
+		refPrefix: refPrefix,
+		tagKey:    tagKey,
+		names:     map[string]string{},
+		used:      UniqueNames{},
+		schemas:   map[string]any{},
+	}
+}
+
+// build defines the structs and enums of the scanned packages, in id order, and the named
+// types they reference
+func (b *schemaBuilder) build() error {
+	ids := b.result.Types.Keys()
+	sort.Strings(ids)
+	for _, id := range ids {
+		t, _ := b.result.Types.Get(id)
+		if t == nil || t.Distance() != 0 || !t.IsNamed() {
+			continue
+		}
+		switch typed := t.(type) {
+		case *gstypes.Struct:
+			if len(typed.TypeParams()) == 0 {
+				b.ref(t)
+			}
+		case *gstypes.Basic, *gstypes.Enum:
+			if len(b.enumValues(t)) > 0 {
+				b.ref(t)
+			}
+		}
+	}
+	return b.define()
+}
+
+// define builds the definitions of the named types queued, and of those they reference
+func (b *schemaBuilder) define() error {
+	for len(b.queue) > 0 {
+		t := b.queue[0]
+		b.queue = b.queue[1:]
+		if err := t.Load(); err != nil {
+			return err
+		}
+		schema := b.definition(t)
+		if lines := DocLines(t); len(lines) > 0 {
+			schema["description"] = strings.Join(lines, "\n")
+		}
+		b.schemas[b.names[t.Id()]] = schema
+	}
+	return nil
+}
+
+// ref returns the reference to the definition of t, queueing it on first use
+func (b *schemaBuilder) ref(t gstypes.Type) map[string]any {
+	name, ok := b.names[t.Id()]
+	if !ok {
+		name = b.defName(t)
+		if b.used[name] && t.Package() != nil {
+			name = ExportedName(t.Package().Name()) + name
+		}
+		name = b.used.Reserve(name)
+		b.names[t.Id()] = name
+		b.queue = append(b.queue, t)
+	}
+	return map[string]any{"$ref": b.refPrefix + name}
+}
+
+// defName names the definition of t: its Go name, or for instances the origin name followed
+// by the names of the type arguments
+func (b *schemaBuilder) defName(t gstypes.Type) string {
+	switch typed := t.(type) {
+	case *gstypes.InstantiatedGeneric:
+		parts := []string{typed.Name()}
+		if typed.Origin() != nil {
+			parts[0] = typed.Origin().Name()
+		}
+		for _, arg := range typed.TypeArgs() {
+			parts = append(parts, b.defName(arg.Type))
+		}
+		return strings.Join(parts, "_")
+	case *gstypes.Pointer:
+		return b.defName(typed.Elem())
+	case *gstypes.Slice:
+		return b.defName(typed.Elem()) + "List"
+	case *gstypes.Map:
+		return "Map_" + b.defName(typed.Key()) + "_" + b.defName(typed.Value())
+	case nil:
+		return "Any"
+	}
+	if t.IsNamed() || t.Kind() == gstypes.TypeKindBasic {
+		return t.Name()
+	}
+	return ExportedName(string(t.Kind()))
+}
+
+// definition returns the schema of the named type t, as the schema of its structure
+func (b *schemaBuilder) definition(t gstypes.Type) map[string]any {
+	if values := b.enumValues(t); len(values) > 0 {
+		schema := b.basicSchema(t)
+		schema["enum"] = values
+		return schema
+	}
+	switch typed := t.(type) {
+	case *gstypes.Struct, *gstypes.InstantiatedGeneric:
+		return b.objectSchema(t)
+	case *gstypes.Slice:
+		if schema := b.sliceSchema(typed); schema != nil {
+			return schema
+		}
+	case *gstypes.Map:
+		if schema := b.mapSchema(typed); schema != nil {
+			return schema
+		}
+	}
+	if schema, ok := b.schema(t); ok && schema != nil {
+		return schema
+	}
+	return map[string]any{}
+}
+
+// schema returns the schema of a value of type t: a reference for the named types with a
+// definition, the inline schema otherwise. ok is false for types encoding/json can't encode.
+func (b *schemaBuilder) schema(t gstypes.Type) (map[string]any, bool) {
+	if t == nil {
+		return map[string]any{}, true
+	}
+	if wk, found := schemaWellKnown[t.Id()]; found {
+		schema := make(map[string]any, len(wk))
+		for k, v := range wk {
+			schema[k] = v
+		}
+		return schema, true
+	}
+	switch typed := t.(type) {
+	case *gstypes.Basic:
+		if len(b.enumValues(t)) > 0 {
+			return b.ref(t), true
+		}
+		schema := b.basicSchema(t)
+		return schema, schema != nil
+	case *gstypes.Enum:
+		return b.ref(t), true
+	case *gstypes.Pointer:
+		elem, ok := b.schema(typed.Elem())
+		if !ok {
+			return nil, false
+		}
+		return nullable(elem), true
+	case *gstypes.Struct:
+		if typed.IsNamed() {
+			return b.ref(t), true
+		}
+		return b.objectSchema(t), true
+	case *gstypes.InstantiatedGeneric:
+		return b.ref(t), true
+	case *gstypes.Slice:
+		if typed.IsNamed() {
+			return b.ref(t), true
+		}
+		schema := b.sliceSchema(typed)
+		return schema, schema != nil
+	case *gstypes.Map:
+		if typed.IsNamed() {
+			return b.ref(t), true
+		}
+		schema := b.mapSchema(typed)
+		return schema, schema != nil
+	case *gstypes.Interface, *gstypes.TypeParameter:
+		return map[string]any{}, true
+	case *gstypes.Alias:
+		return b.schema(typed.UnderlyingType())
+	}
+	return nil, false
+}
+
+// basicSchema returns the schema of a basic type (named ones by their underlying type), nil
+// for complex numbers
+func (b *schemaBuilder) basicSchema(t gstypes.Type) map[string]any {
+	id := t.Id()
+	var underlying gstypes.Type
+	switch typed := t.(type) {
+	case *gstypes.Basic:
+		underlying = typed.Underlying()
+	case *gstypes.Enum:
+		underlying = typed.Underlying()
+	}
+	if underlying != nil {
+		id = underlying.Id()
+	}
+	switch id {
+	case "bool":
+		return map[string]any{"type": "boolean"}
+	case "string":
+		return map[string]any{"type": "string"}
+	case "int8", "int16", "int32", "rune":
+		return map[string]any{"type": "integer", "format": "int32"}
+	case "int", "int64":
+		return map[string]any{"type": "integer", "format": "int64"}
+	case "uint8", "byte", "uint16":
+		return map[string]any{"type": "integer", "format": "int32", "minimum": 0}
+	case "uint", "uint32", "uint64", "uintptr":
+		return map[string]any{"type": "integer", "format": "int64", "minimum": 0}
+	case "float32":
+		return map[string]any{"type": "number", "format": "float"}
+	case "float64":
+		return map[string]any{"type": "number", "format": "double"}
+	}
+	return nil
+}
+
+// sliceSchema returns the array schema of a slice or array, []byte is a base64 string
+func (b *schemaBuilder) sliceSchema(s *gstypes.Slice) map[string]any {
+	elem := s.Elem()
+	if !s.IsArray() && elem != nil && (elem.Id() == "uint8" || elem.Id() == "byte") {
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	}
+	items, ok := b.schema(elem)
+	if !ok {
+		return nil
+	}
+	schema := map[string]any{"type": "array", "items": items}
+	if s.IsArray() {
+		schema["minItems"] = s.Len()
+		schema["maxItems"] = s.Len()
+	}
+	return schema
+}
+
+// mapSchema returns the object schema of a map, its keys are strings in JSON
+func (b *schemaBuilder) mapSchema(m *gstypes.Map) map[string]any {
+	value, ok := b.schema(m.Value())
+	if !ok {
+		return nil
+	}
+	return map[string]any{"type": "object", "additionalProperties": value}
+}
+
+// objectSchema returns the object schema of a struct or an instance of a generic struct
+func (b *schemaBuilder) objectSchema(t gstypes.Type) map[string]any {
+	fields := fieldsOf(t)
+	if ig, ok := t.(*gstypes.InstantiatedGeneric); ok && len(fields) == 0 {
+		fields = fieldsOf(ig.Origin())
+	}
+
+	properties := map[string]any{}
+	var required []any
+	for _, f := range fields {
+		if f.Name() == "_" || !token.IsExported(f.Name()) {
+			continue
+		}
+		name, omit := b.tagName(f)
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name()
+		}
+		schema, ok := b.schema(f.Type())
+		if !ok {
+			continue
+		}
+		if err := f.Load(); err == nil {
+			if lines := DocLines(f); len(lines) > 0 {
+				schema["description"] = strings.Join(lines, "\n")
+			}
+		}
+		properties[name] = schema
+		if !omit {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Slice(required, func(i, j int) bool { return required[i].(string) < required[j].(string) })
+		schema["required"] = required
+	}
+	return schema
+}
+
+// tagName returns the name given to f by the tag ("" when unnamed, "-" when skipped) and
+// whether the field is omitted when empty
+func (b *schemaBuilder) tagName(f *gstypes.Field) (string, bool) {
+	tag, ok := ParseTags(f.Tag())[b.tagKey]
+	if !ok {
+		return "", false
+	}
+	if tag == "-" {
+		return "-", false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	omit := false
+	for _, opt := range strings.Split(options, ",") {
+		omit = omit || opt == "omitempty" || opt == "omitzero"
+	}
+	return name, omit
+}
+
+// enumValues returns the distinct values of the constants of the named basic type t
+func (b *schemaBuilder) enumValues(t gstypes.Type) []any {
+	if b.constants == nil {
+		b.constants = b.result.ConstantsByType()
+	}
+	return EnumValues(t, b.constants)
+}
+
+// EnumValues returns the distinct JSON values of the constants of the named basic type t
+// (from constants, by type id) or of an Enum restored from a cache, integers sorted, nil
+// when it has none
+func EnumValues(t gstypes.Type, constants map[string][]*gstypes.Value) []any {
+	var values []*gstypes.Value
+	switch typed := t.(type) {
+	case *gstypes.Enum:
+		values = typed.Values()
+	case *gstypes.Basic:
+		if typed.Underlying() == nil {
+			return nil
+		}
+		values = constants[t.Id()]
+	default:
+		return nil
+	}
+	var distinct []any
+	seen := map[any]bool{}
+	for _, c := range values {
+		v := constantValue(c.Value())
+		if v == nil || seen[v] {
+			continue
+		}
+		seen[v] = true
+		distinct = append(distinct, v)
+	}
+	sort.SliceStable(distinct, func(i, j int) bool {
+		vi, iok := distinct[i].(int64)
+		vj, jok := distinct[j].(int64)
+		return iok && jok && vi < vj
+	})
+	return distinct
+}
+
+// nullable makes schema accept null
+func nullable(schema map[string]any) map[string]any {
+	if len(schema) == 0 {
+		return schema
+	}
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []any{typ, "null"}
+		return schema
+	}
+	return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+}
+
+// constantValue converts a constant value (go/constant when scanned, a JSON value when read
+// from a cache) to a JSON value, nil when it has no JSON form
+func constantValue(value any) any {
+	switch v := value.(type) {
+	case constant.Value:
+		switch v.Kind() {
+		case constant.Bool:
+			return constant.BoolVal(v)
+		case constant.String:
+			return constant.StringVal(v)
+		case constant.Int:
+			if n, exact := constant.Int64Val(v); exact {
+				return n
+			}
+		case constant.Float:
+			f, _ := constant.Float64Val(v)
+			return f
+		}
+		return nil
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+		return v
+	case string, bool, int64:
+		return v
+	case int:
+		return int64(v)
+	}
+	return nil
+}
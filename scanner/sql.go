@@ -0,0 +1,285 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// SQLOptions controls the SQL schema generated by EmitSQL
+type SQLOptions struct {
+	// EmitOptions names the columns without a name in the tags with its NameTransform
+	// (default SnakeCase)
+	EmitOptions
+	// TagKey is the sqlx style tag holding the column name followed by options, as in
+	// `db:"id,primaryKey"` (default "db"). gorm tags are always understood.
+	TagKey string `json:"tag_key,omitempty" yaml:"tag_key,omitempty"`
+	// ColumnType overrides the SQL type of a field, ok false falls back to the default mapping
+	ColumnType func(f *gstypes.Field) (sqlType string, ok bool) `json:"-" yaml:"-"`
+}
+
+// sqlTableTag names the table of a struct, on any of its fields (usually _ struct{} `table:"users"`)
+const sqlTableTag = "table"
+
+// sqlTypes maps the predeclared types to portable SQL column types
+var sqlTypes = map[string]string{
+	"bool":    "BOOLEAN",
+	"int8":    "SMALLINT",
+	"int16":   "SMALLINT",
+	"uint8":   "SMALLINT",
+	"int32":   "INTEGER",
+	"uint16":  "INTEGER",
+	"rune":    "INTEGER",
+	"int":     "BIGINT",
+	"int64":   "BIGINT",
+	"uint":    "BIGINT",
+	"uint32":  "BIGINT",
+	"uint64":  "BIGINT",
+	"uintptr": "BIGINT",
+	"float32": "REAL",
+	"float64": "DOUBLE PRECISION",
+	"string":  "TEXT",
+}
+
+// sqlNamedTypes maps well known named types to SQL column types
+var sqlNamedTypes = map[string]string{
+	"time.Time":                   "TIMESTAMP",
+	"time.Duration":               "BIGINT",
+	"encoding/json.RawMessage":    "TEXT",
+	"database/sql.NullString":     "TEXT",
+	"database/sql.NullBool":       "BOOLEAN",
+	"database/sql.NullInt16":      "SMALLINT",
+	"database/sql.NullInt32":      "INTEGER",
+	"database/sql.NullInt64":      "BIGINT",
+	"database/sql.NullFloat64":    "DOUBLE PRECISION",
+	"database/sql.NullTime":       "TIMESTAMP",
+	"github.com/google/uuid.UUID": "UUID",
+}
+
+// sqlNullable are the named types holding their own null state
+var sqlNullable = map[string]bool{
+	"encoding/json.RawMessage": true,
+	"database/sql.NullString":  true,
+	"database/sql.NullBool":    true,
+	"database/sql.NullInt16":   true,
+	"database/sql.NullInt32":   true,
+	"database/sql.NullInt64":   true,
+	"database/sql.NullFloat64": true,
+	"database/sql.NullTime":    true,
+}
+
+// sqlColumn is a column of a generated table
+type sqlColumn struct {
+	name       string
+	sqlType    string
+	notNull    bool
+	primaryKey bool
+	index      bool
+	unique     bool
+}
+
+// sqlTable is a table generated from a struct
+type sqlTable struct {
+	name    string
+	columns []sqlColumn
+}
+
+// EmitSQL writes a CREATE TABLE statement for every struct of the scanned packages with db or
+// gorm tags, in id order. Columns are the fields that map to a SQL type: the basic types
+// (named ones by their underlying type), []byte and the well known types of time,
+// database/sql and uuid. Non-pointer fields are NOT NULL. The db tag options "primaryKey"
+// (or "pk"), "index" and "unique" and the gorm keys primaryKey, index, uniqueIndex, unique,
+// not null, column and type are respected. Fields tagged "-" are skipped.
+func EmitSQL(result *ScanningResult, w io.Writer, opts SQLOptions) error {
+	if opts.TagKey == "" {
+		opts.TagKey = "db"
+	}
+	opts.EmitOptions = opts.EmitOptions.WithDefaultNames(SnakeCase)
+
+	ids := result.Types.Keys()
+	sort.Strings(ids)
+	bw := bufio.NewWriter(w)
+	first := true
+	for _, id := range ids {
+		t, _ := result.Types.Get(id)
+		strct, ok := t.(*gstypes.Struct)
+		if !ok || strct.Distance() != 0 || len(strct.TypeParams()) > 0 {
+			continue
+		}
+		if err := strct.Load(); err != nil {
+			return err
+		}
+		table, ok := opts.table(strct)
+		if !ok {
+			continue
+		}
+		if !first {
+			bw.WriteString("\n")
+		}
+		first = false
+		table.write(bw)
+	}
+	return bw.Flush()
+}
+
+// table builds the table of s, ok is false when s has no db or gorm tags
+func (o SQLOptions) table(s *gstypes.Struct) (sqlTable, bool) {
+	table := sqlTable{name: SnakeCase(s.Name(), nil)}
+	tagged := false
+	for _, f := range s.Fields() {
+		tags := ParseTags(f.Tag())
+		if name := tags[sqlTableTag]; name != "" {
+			table.name = name
+		}
+		_, hasDB := tags[o.TagKey]
+		_, hasGorm := tags["gorm"]
+		tagged = tagged || hasDB || hasGorm
+		if f.IsEmbedded() || f.Name() == "_" || !token.IsExported(f.Name()) {
+			continue
+		}
+		if col, ok := o.column(f, tags); ok {
+			table.columns = append(table.columns, col)
+		}
+	}
+	return table, tagged && len(table.columns) > 0
+}
+
+// column builds the column of f, ok is false for skipped fields and unmapped types
+func (o SQLOptions) column(f *gstypes.Field, tags map[string]string) (sqlColumn, bool) {
+	col := sqlColumn{}
+	dbName, dbOpts, _ := strings.Cut(tags[o.TagKey], ",")
+	if dbName == "-" || tags["gorm"] == "-" {
+		return col, false
+	}
+	col.name = dbName
+	for _, opt := range strings.Split(dbOpts, ",") {
+		switch strings.ToLower(strings.TrimSpace(opt)) {
+		case "primarykey", "pk":
+			col.primaryKey = true
+		case "index":
+			col.index = true
+		case "unique":
+			col.unique = true
+		case "notnull", "not null":
+			col.notNull = true
+		}
+	}
+	for _, setting := range strings.Split(tags["gorm"], ";") {
+		key, value, _ := strings.Cut(setting, ":")
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "column":
+			col.name = value
+		case "type":
+			col.sqlType = value
+		case "primarykey", "primary_key":
+			col.primaryKey = true
+		case "index":
+			col.index = true
+		case "uniqueindex", "unique":
+			col.unique = true
+		case "not null":
+			col.notNull = true
+		}
+	}
+	if col.name == "" {
+		col.name = o.FieldName(f.Name(), f.Tag())
+	}
+
+	sqlType, nullable, ok := sqlTypeOf(f.Type())
+	if custom, found := o.customType(f); found {
+		sqlType, ok = custom, true
+	}
+	if col.sqlType == "" {
+		if !ok {
+			return col, false
+		}
+		col.sqlType = sqlType
+	}
+	col.notNull = col.notNull || !nullable || col.primaryKey
+	return col, true
+}
+
+func (o SQLOptions) customType(f *gstypes.Field) (string, bool) {
+	if o.ColumnType == nil {
+		return "", false
+	}
+	return o.ColumnType(f)
+}
+
+// sqlTypeOf returns the SQL type of t and whether the column is nullable (pointers and the
+// sql.Null types), ok is false when t doesn't map to a column
+func sqlTypeOf(t gstypes.Type) (sqlType string, nullable bool, ok bool) {
+	if ptr, isPtr := t.(*gstypes.Pointer); isPtr {
+		if ptr.Depth() != 1 {
+			return "", false, false
+		}
+		sqlType, _, ok = sqlTypeOf(ptr.Elem())
+		return sqlType, true, ok
+	}
+	if sqlType, found := sqlNamedTypes[t.Id()]; found {
+		return sqlType, sqlNullable[t.Id()], true
+	}
+	switch typed := t.(type) {
+	case *gstypes.Basic:
+		id := typed.Id()
+		if typed.Underlying() != nil {
+			id = typed.Underlying().Id()
+		}
+		sqlType, found := sqlTypes[id]
+		return sqlType, false, found
+	case *gstypes.Enum:
+		if typed.Underlying() == nil {
+			return "", false, false
+		}
+		sqlType, found := sqlTypes[typed.Underlying().Id()]
+		return sqlType, false, found
+	case *gstypes.Slice:
+		if elem := typed.Elem(); !typed.IsArray() && elem != nil && (elem.Id() == "uint8" || elem.Id() == "byte") {
+			return "BLOB", true, true
+		}
+	}
+	return "", false, false
+}
+
+// write writes the CREATE TABLE and CREATE INDEX statements of the table
+func (t sqlTable) write(w *bufio.Writer) {
+	var primaryKeys []string
+	for _, col := range t.columns {
+		if col.primaryKey {
+			primaryKeys = append(primaryKeys, col.name)
+		}
+	}
+
+	fmt.Fprintf(w, "CREATE TABLE %s (\n", t.name)
+	for i, col := range t.columns {
+		fmt.Fprintf(w, "\t%s %s", col.name, col.sqlType)
+		if col.notNull {
+			w.WriteString(" NOT NULL")
+		}
+		if col.primaryKey && len(primaryKeys) == 1 {
+			w.WriteString(" PRIMARY KEY")
+		}
+		if i < len(t.columns)-1 || len(primaryKeys) > 1 {
+			w.WriteString(",")
+		}
+		w.WriteString("\n")
+	}
+	if len(primaryKeys) > 1 {
+		fmt.Fprintf(w, "\tPRIMARY KEY (%s)\n", strings.Join(primaryKeys, ", "))
+	}
+	w.WriteString(");\n")
+
+	for _, col := range t.columns {
+		switch {
+		case col.unique:
+			fmt.Fprintf(w, "CREATE UNIQUE INDEX idx_%s_%s ON %s (%s);\n", t.name, col.name, t.name, col.name)
+		case col.index:
+			fmt.Fprintf(w, "CREATE INDEX idx_%s_%s ON %s (%s);\n", t.name, col.name, t.name, col.name)
+		}
+	}
+}
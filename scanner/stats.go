@@ -0,0 +1,191 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// statsTopN is the number of entries kept in each ranking of ScanStats
+const statsTopN = 10
+
+// ScanStats summarizes the shape of a scanning result
+type ScanStats struct {
+	Types    int `json:"types"`
+	Values   int `json:"values"`
+	Packages int `json:"packages"`
+
+	Kinds              map[gstypes.TypeKind]int `json:"kinds"`
+	LargestStructs     []StructSize             `json:"largestStructs"`
+	DeepestPointers    []PointerChain           `json:"deepestPointers"`
+	MostReferenced     []TypeReferences         `json:"mostReferenced"`
+	PackagesByDistance map[int][]string         `json:"packagesByDistance"`
+}
+
+// StructSize is the field count of a struct (promoted fields excluded)
+type StructSize struct {
+	ID     string `json:"id"`
+	Fields int    `json:"fields"`
+}
+
+// PointerChain is a pointer reference found in a type (e.g. a **User field of Owner)
+type PointerChain struct {
+	Owner string `json:"owner"`
+	Elem  string `json:"elem"`
+	Depth int    `json:"depth"`
+}
+
+// TypeReferences is the number of types referencing a named type
+type TypeReferences struct {
+	ID         string `json:"id"`
+	References int    `json:"references"`
+}
+
+// Stats aggregates counts and rankings over the result.
+// Types are not loaded, call EnsureFullyLoaded first for complete figures.
+func (s *ScanningResult) Stats() *ScanStats {
+	stats := &ScanStats{
+		Types:              s.Types.Len(),
+		Values:             s.Values.Len(),
+		Packages:           s.Packages.Len(),
+		Kinds:              map[gstypes.TypeKind]int{},
+		PackagesByDistance: map[int][]string{},
+	}
+
+	refs := map[string]int{}
+	pkgDistance := map[string]int{}
+	for _, id := range s.Types.Keys() {
+		t, ok := s.Types.Get(id)
+		if !ok {
+			continue
+		}
+		stats.Kinds[t.Kind()]++
+
+		if pkg := t.Package(); pkg != nil {
+			if d, ok := pkgDistance[pkg.Path()]; !ok || t.Distance() < d {
+				pkgDistance[pkg.Path()] = t.Distance()
+			}
+		}
+
+		if strct, ok := t.(*gstypes.Struct); ok {
+			fields := 0
+			for _, f := range strct.Fields() {
+				if f.PromotedFrom() == nil {
+					fields++
+				}
+			}
+			stats.LargestStructs = append(stats.LargestStructs, StructSize{ID: id, Fields: fields})
+		}
+
+		// Each referencing type counts once per referenced type
+		referenced := map[string]bool{}
+		gstypes.WalkReferences(t, func(ref gstypes.Type, _ gstypes.RefRole) {
+			if p, ok := ref.(*gstypes.Pointer); ok && p.Depth() > 1 {
+				elem := ""
+				if p.Elem() != nil {
+					elem = p.Elem().Id()
+				}
+				stats.DeepestPointers = append(stats.DeepestPointers, PointerChain{Owner: id, Elem: elem, Depth: p.Depth()})
+			}
+			// Predeclared types have no package and would dominate the ranking
+			if ref.IsNamed() && ref.Package() != nil && ref.Id() != id {
+				referenced[ref.Id()] = true
+			}
+		})
+		for refID := range referenced {
+			refs[refID]++
+		}
+	}
+
+	for path, d := range pkgDistance {
+		stats.PackagesByDistance[d] = append(stats.PackagesByDistance[d], path)
+	}
+	for _, paths := range stats.PackagesByDistance {
+		sort.Strings(paths)
+	}
+
+	sort.Slice(stats.LargestStructs, func(i, j int) bool {
+		a, b := stats.LargestStructs[i], stats.LargestStructs[j]
+		return a.Fields > b.Fields || (a.Fields == b.Fields && a.ID < b.ID)
+	})
+	stats.LargestStructs = topN(stats.LargestStructs)
+
+	sort.Slice(stats.DeepestPointers, func(i, j int) bool {
+		a, b := stats.DeepestPointers[i], stats.DeepestPointers[j]
+		if a.Depth != b.Depth {
+			return a.Depth > b.Depth
+		}
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		return a.Elem < b.Elem
+	})
+	stats.DeepestPointers = topN(stats.DeepestPointers)
+
+	for refID, n := range refs {
+		stats.MostReferenced = append(stats.MostReferenced, TypeReferences{ID: refID, References: n})
+	}
+	sort.Slice(stats.MostReferenced, func(i, j int) bool {
+		a, b := stats.MostReferenced[i], stats.MostReferenced[j]
+		return a.References > b.References || (a.References == b.References && a.ID < b.ID)
+	})
+	stats.MostReferenced = topN(stats.MostReferenced)
+
+	return stats
+}
+
+func topN[T any](items []T) []T {
+	if len(items) > statsTopN {
+		return items[:statsTopN]
+	}
+	return items
+}
+
+// EmitStats writes a human readable report of Stats to w
+func (s *ScanningResult) EmitStats(w io.Writer) error {
+	stats := s.Stats()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Types: %d  Values: %d  Packages: %d\n", stats.Types, stats.Values, stats.Packages)
+
+	sb.WriteString("\nTypes by kind:\n")
+	kinds := make([]string, 0, len(stats.Kinds))
+	for kind := range stats.Kinds {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(&sb, "  %-14s %d\n", kind, stats.Kinds[gstypes.TypeKind(kind)])
+	}
+
+	sb.WriteString("\nLargest structs (by field count):\n")
+	for _, st := range stats.LargestStructs {
+		fmt.Fprintf(&sb, "  %4d  %s\n", st.Fields, st.ID)
+	}
+
+	sb.WriteString("\nDeepest pointer chains:\n")
+	for _, pc := range stats.DeepestPointers {
+		fmt.Fprintf(&sb, "  %s%s in %s\n", strings.Repeat("*", pc.Depth), pc.Elem, pc.Owner)
+	}
+
+	sb.WriteString("\nMost referenced types:\n")
+	for _, tr := range stats.MostReferenced {
+		fmt.Fprintf(&sb, "  %4d  %s\n", tr.References, tr.ID)
+	}
+
+	sb.WriteString("\nPackages by distance:\n")
+	distances := make([]int, 0, len(stats.PackagesByDistance))
+	for d := range stats.PackagesByDistance {
+		distances = append(distances, d)
+	}
+	sort.Ints(distances)
+	for _, d := range distances {
+		fmt.Fprintf(&sb, "  %d: %s\n", d, strings.Join(stats.PackagesByDistance[d], ", "))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+func TestScanningResult_Stats(t *testing.T) {
+	src := `
+	package test
+
+	type User struct {
+		ID   int
+		Name string
+	}
+
+	type Group struct {
+		Owner   *User
+		Members []User
+		Deep    **User
+	}
+
+	type Store interface {
+		Get(id int) (User, error)
+	}
+
+	type Role int
+	`
+
+	result := scanTestSource(t, src)
+	stats := result.Stats()
+
+	if stats.Kinds[gstypes.TypeKindStruct] != 2 || stats.Kinds[gstypes.TypeKindInterface] != 1 {
+		t.Errorf("unexpected kinds: %v", stats.Kinds)
+	}
+
+	if len(stats.LargestStructs) != 2 || stats.LargestStructs[0].ID != "test.Group" || stats.LargestStructs[0].Fields != 3 {
+		t.Errorf("unexpected largest structs: %v", stats.LargestStructs)
+	}
+
+	if len(stats.DeepestPointers) != 1 || stats.DeepestPointers[0] != (PointerChain{Owner: "test.Group", Elem: "test.User", Depth: 2}) {
+		t.Errorf("unexpected pointer chains: %v", stats.DeepestPointers)
+	}
+
+	// User is referenced by Group and Store
+	if len(stats.MostReferenced) == 0 || stats.MostReferenced[0] != (TypeReferences{ID: "test.User", References: 2}) {
+		t.Errorf("unexpected most referenced: %v", stats.MostReferenced)
+	}
+
+	if paths := stats.PackagesByDistance[0]; len(paths) != 1 || paths[0] != "test" {
+		t.Errorf("unexpected packages by distance: %v", stats.PackagesByDistance)
+	}
+
+	var buf bytes.Buffer
+	if err := result.EmitStats(&buf); err != nil {
+		t.Fatalf("EmitStats() error = %v", err)
+	}
+	for _, want := range []string{"Types by kind:", "   3  test.Group", "**test.User in test.Group", "   2  test.User", "  0: test"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, buf.String())
+		}
+	}
+}
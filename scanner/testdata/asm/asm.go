@@ -0,0 +1,31 @@
+// Package asm declares functions without a Go body, it is used to test the capture of
+// assembly and //go:linkname backed functions.
+package asm
+
+import _ "unsafe" // for go:linkname
+
+// Add is implemented in add_amd64.s
+func Add(a, b int) int
+
+// Counter counts in assembly
+type Counter struct {
+	n int
+}
+
+// Inc is implemented in add_amd64.s
+func (c *Counter) Inc()
+
+// Now is pulled from the runtime
+//
+//go:linkname Now runtime.nanotime
+func Now() int64
+
+// Double has a body and is pushed under its own name
+//
+//go:linkname Double
+func Double(n int) int {
+	return n * 2
+}
+
+// Plain is a regular function
+func Plain() {}
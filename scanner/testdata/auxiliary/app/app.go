@@ -0,0 +1,8 @@
+package app
+
+import "github.com/pablor21/goscanner/scanner/testdata/auxiliary/fixtures"
+
+// Order is built from a fixture
+type Order struct {
+	Item fixtures.Item
+}
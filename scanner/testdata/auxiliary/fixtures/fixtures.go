@@ -0,0 +1,14 @@
+package fixtures
+
+// Item is referenced by the app package
+type Item struct {
+	SKU string
+}
+
+// Unused is not referenced by any scanned package
+type Unused struct{}
+
+// NewItem builds an Item
+func NewItem(sku string) Item {
+	return Item{SKU: sku}
+}
@@ -0,0 +1,8 @@
+package fixtures_test
+
+import "github.com/pablor21/goscanner/scanner/testdata/auxiliary/fixtures"
+
+// Case is only declared in the external test package
+type Case struct {
+	Input fixtures.Item
+}
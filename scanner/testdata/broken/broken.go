@@ -0,0 +1,6 @@
+// Package broken does not type check; it is used to test error reporting.
+package broken
+
+type Broken struct {
+	Missing UndefinedType
+}
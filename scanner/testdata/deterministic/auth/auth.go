@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"github.com/pablor21/goscanner/scanner/testdata/deterministic/api"
+	"github.com/pablor21/goscanner/scanner/testdata/deterministic/events"
+)
+
+// User mixes unnamed types with references to other packages
+type User struct {
+	IDs      []int
+	Items    []*api.Order
+	ByKey    map[string][]*events.Item
+	OnChange func(old, new *api.Order) error
+	Updates  chan map[int]events.Item
+	Meta     struct {
+		Tags  [2]string
+		Owner *api.Order
+	}
+}
+
+// Order mixes unnamed types with references to other packages
+type Order struct {
+	IDs      []int
+	Items    []*api.Item
+	ByKey    map[string][]*events.Invoice
+	OnChange func(old, new *api.Item) error
+	Updates  chan map[int]events.Invoice
+	Meta     struct {
+		Tags  [3]string
+		Owner *api.Item
+	}
+}
+
+// Item mixes unnamed types with references to other packages
+type Item struct {
+	IDs      []int
+	Items    []*api.Invoice
+	ByKey    map[string][]*events.Session
+	OnChange func(old, new *api.Invoice) error
+	Updates  chan map[int]events.Session
+	Meta     struct {
+		Tags  [4]string
+		Owner *api.Invoice
+	}
+}
+
+// Invoice mixes unnamed types with references to other packages
+type Invoice struct {
+	IDs      []int
+	Items    []*api.Session
+	ByKey    map[string][]*events.Token
+	OnChange func(old, new *api.Session) error
+	Updates  chan map[int]events.Token
+	Meta     struct {
+		Tags  [5]string
+		Owner *api.Session
+	}
+}
+
+// Session mixes unnamed types with references to other packages
+type Session struct {
+	IDs      []int
+	Items    []*api.Token
+	ByKey    map[string][]*events.Account
+	OnChange func(old, new *api.Token) error
+	Updates  chan map[int]events.Account
+	Meta     struct {
+		Tags  [6]string
+		Owner *api.Token
+	}
+}
+
+// Token mixes unnamed types with references to other packages
+type Token struct {
+	IDs      []int
+	Items    []*api.Account
+	ByKey    map[string][]*events.Report
+	OnChange func(old, new *api.Account) error
+	Updates  chan map[int]events.Report
+	Meta     struct {
+		Tags  [7]string
+		Owner *api.Account
+	}
+}
+
+// Account mixes unnamed types with references to other packages
+type Account struct {
+	IDs      []int
+	Items    []*api.Report
+	ByKey    map[string][]*events.Address
+	OnChange func(old, new *api.Report) error
+	Updates  chan map[int]events.Address
+	Meta     struct {
+		Tags  [8]string
+		Owner *api.Report
+	}
+}
+
+// Report mixes unnamed types with references to other packages
+type Report struct {
+	IDs      []int
+	Items    []*api.Address
+	ByKey    map[string][]*events.Payment
+	OnChange func(old, new *api.Address) error
+	Updates  chan map[int]events.Payment
+	Meta     struct {
+		Tags  [9]string
+		Owner *api.Address
+	}
+}
+
+// Address mixes unnamed types with references to other packages
+type Address struct {
+	IDs      []int
+	Items    []*api.Payment
+	ByKey    map[string][]*events.User
+	OnChange func(old, new *api.Payment) error
+	Updates  chan map[int]events.User
+	Meta     struct {
+		Tags  [10]string
+		Owner *api.Payment
+	}
+}
+
+// Payment mixes unnamed types with references to other packages
+type Payment struct {
+	IDs      []int
+	Items    []*api.User
+	ByKey    map[string][]*events.Order
+	OnChange func(old, new *api.User) error
+	Updates  chan map[int]events.Order
+	Meta     struct {
+		Tags  [11]string
+		Owner *api.User
+	}
+}
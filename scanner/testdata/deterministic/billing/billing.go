@@ -0,0 +1,136 @@
+package billing
+
+import (
+	"github.com/pablor21/goscanner/scanner/testdata/deterministic/auth"
+	"github.com/pablor21/goscanner/scanner/testdata/deterministic/events"
+)
+
+// User mixes unnamed types with references to other packages
+type User struct {
+	IDs      []int
+	Items    []*events.Order
+	ByKey    map[string][]*auth.Item
+	OnChange func(old, new *events.Order) error
+	Updates  chan map[int]auth.Item
+	Meta     struct {
+		Tags  [2]string
+		Owner *events.Order
+	}
+}
+
+// Order mixes unnamed types with references to other packages
+type Order struct {
+	IDs      []int
+	Items    []*events.Item
+	ByKey    map[string][]*auth.Invoice
+	OnChange func(old, new *events.Item) error
+	Updates  chan map[int]auth.Invoice
+	Meta     struct {
+		Tags  [3]string
+		Owner *events.Item
+	}
+}
+
+// Item mixes unnamed types with references to other packages
+type Item struct {
+	IDs      []int
+	Items    []*events.Invoice
+	ByKey    map[string][]*auth.Session
+	OnChange func(old, new *events.Invoice) error
+	Updates  chan map[int]auth.Session
+	Meta     struct {
+		Tags  [4]string
+		Owner *events.Invoice
+	}
+}
+
+// Invoice mixes unnamed types with references to other packages
+type Invoice struct {
+	IDs      []int
+	Items    []*events.Session
+	ByKey    map[string][]*auth.Token
+	OnChange func(old, new *events.Session) error
+	Updates  chan map[int]auth.Token
+	Meta     struct {
+		Tags  [5]string
+		Owner *events.Session
+	}
+}
+
+// Session mixes unnamed types with references to other packages
+type Session struct {
+	IDs      []int
+	Items    []*events.Token
+	ByKey    map[string][]*auth.Account
+	OnChange func(old, new *events.Token) error
+	Updates  chan map[int]auth.Account
+	Meta     struct {
+		Tags  [6]string
+		Owner *events.Token
+	}
+}
+
+// Token mixes unnamed types with references to other packages
+type Token struct {
+	IDs      []int
+	Items    []*events.Account
+	ByKey    map[string][]*auth.Report
+	OnChange func(old, new *events.Account) error
+	Updates  chan map[int]auth.Report
+	Meta     struct {
+		Tags  [7]string
+		Owner *events.Account
+	}
+}
+
+// Account mixes unnamed types with references to other packages
+type Account struct {
+	IDs      []int
+	Items    []*events.Report
+	ByKey    map[string][]*auth.Address
+	OnChange func(old, new *events.Report) error
+	Updates  chan map[int]auth.Address
+	Meta     struct {
+		Tags  [8]string
+		Owner *events.Report
+	}
+}
+
+// Report mixes unnamed types with references to other packages
+type Report struct {
+	IDs      []int
+	Items    []*events.Address
+	ByKey    map[string][]*auth.Payment
+	OnChange func(old, new *events.Address) error
+	Updates  chan map[int]auth.Payment
+	Meta     struct {
+		Tags  [9]string
+		Owner *events.Address
+	}
+}
+
+// Address mixes unnamed types with references to other packages
+type Address struct {
+	IDs      []int
+	Items    []*events.Payment
+	ByKey    map[string][]*auth.User
+	OnChange func(old, new *events.Payment) error
+	Updates  chan map[int]auth.User
+	Meta     struct {
+		Tags  [10]string
+		Owner *events.Payment
+	}
+}
+
+// Payment mixes unnamed types with references to other packages
+type Payment struct {
+	IDs      []int
+	Items    []*events.User
+	ByKey    map[string][]*auth.Order
+	OnChange func(old, new *events.User) error
+	Updates  chan map[int]auth.Order
+	Meta     struct {
+		Tags  [11]string
+		Owner *events.User
+	}
+}
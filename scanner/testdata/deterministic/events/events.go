@@ -0,0 +1,136 @@
+package events
+
+import (
+	"github.com/pablor21/goscanner/scanner/testdata/deterministic/api"
+	"github.com/pablor21/goscanner/scanner/testdata/deterministic/store"
+)
+
+// User mixes unnamed types with references to other packages
+type User struct {
+	IDs      []int
+	Items    []*store.Order
+	ByKey    map[string][]*api.Item
+	OnChange func(old, new *store.Order) error
+	Updates  chan map[int]api.Item
+	Meta     struct {
+		Tags  [2]string
+		Owner *store.Order
+	}
+}
+
+// Order mixes unnamed types with references to other packages
+type Order struct {
+	IDs      []int
+	Items    []*store.Item
+	ByKey    map[string][]*api.Invoice
+	OnChange func(old, new *store.Item) error
+	Updates  chan map[int]api.Invoice
+	Meta     struct {
+		Tags  [3]string
+		Owner *store.Item
+	}
+}
+
+// Item mixes unnamed types with references to other packages
+type Item struct {
+	IDs      []int
+	Items    []*store.Invoice
+	ByKey    map[string][]*api.Session
+	OnChange func(old, new *store.Invoice) error
+	Updates  chan map[int]api.Session
+	Meta     struct {
+		Tags  [4]string
+		Owner *store.Invoice
+	}
+}
+
+// Invoice mixes unnamed types with references to other packages
+type Invoice struct {
+	IDs      []int
+	Items    []*store.Session
+	ByKey    map[string][]*api.Token
+	OnChange func(old, new *store.Session) error
+	Updates  chan map[int]api.Token
+	Meta     struct {
+		Tags  [5]string
+		Owner *store.Session
+	}
+}
+
+// Session mixes unnamed types with references to other packages
+type Session struct {
+	IDs      []int
+	Items    []*store.Token
+	ByKey    map[string][]*api.Account
+	OnChange func(old, new *store.Token) error
+	Updates  chan map[int]api.Account
+	Meta     struct {
+		Tags  [6]string
+		Owner *store.Token
+	}
+}
+
+// Token mixes unnamed types with references to other packages
+type Token struct {
+	IDs      []int
+	Items    []*store.Account
+	ByKey    map[string][]*api.Report
+	OnChange func(old, new *store.Account) error
+	Updates  chan map[int]api.Report
+	Meta     struct {
+		Tags  [7]string
+		Owner *store.Account
+	}
+}
+
+// Account mixes unnamed types with references to other packages
+type Account struct {
+	IDs      []int
+	Items    []*store.Report
+	ByKey    map[string][]*api.Address
+	OnChange func(old, new *store.Report) error
+	Updates  chan map[int]api.Address
+	Meta     struct {
+		Tags  [8]string
+		Owner *store.Report
+	}
+}
+
+// Report mixes unnamed types with references to other packages
+type Report struct {
+	IDs      []int
+	Items    []*store.Address
+	ByKey    map[string][]*api.Payment
+	OnChange func(old, new *store.Address) error
+	Updates  chan map[int]api.Payment
+	Meta     struct {
+		Tags  [9]string
+		Owner *store.Address
+	}
+}
+
+// Address mixes unnamed types with references to other packages
+type Address struct {
+	IDs      []int
+	Items    []*store.Payment
+	ByKey    map[string][]*api.User
+	OnChange func(old, new *store.Payment) error
+	Updates  chan map[int]api.User
+	Meta     struct {
+		Tags  [10]string
+		Owner *store.Payment
+	}
+}
+
+// Payment mixes unnamed types with references to other packages
+type Payment struct {
+	IDs      []int
+	Items    []*store.User
+	ByKey    map[string][]*api.Order
+	OnChange func(old, new *store.User) error
+	Updates  chan map[int]api.Order
+	Meta     struct {
+		Tags  [11]string
+		Owner *store.User
+	}
+}
@@ -0,0 +1,132 @@
+// Package models declares types the other packages reference through unnamed types
+package models
+
+// User mixes unnamed types with references to other types
+type User struct {
+	IDs      []int
+	Items    []*Order
+	ByKey    map[string][]*Order
+	OnChange func(old, new *Order) error
+	Updates  chan map[int]Order
+	Meta     struct {
+		Tags  [2]string
+		Owner *Order
+	}
+}
+
+// Order mixes unnamed types with references to other types
+type Order struct {
+	IDs      []int
+	Items    []*Item
+	ByKey    map[string][]*Item
+	OnChange func(old, new *Item) error
+	Updates  chan map[int]Item
+	Meta     struct {
+		Tags  [3]string
+		Owner *Item
+	}
+}
+
+// Item mixes unnamed types with references to other types
+type Item struct {
+	IDs      []int
+	Items    []*Invoice
+	ByKey    map[string][]*Invoice
+	OnChange func(old, new *Invoice) error
+	Updates  chan map[int]Invoice
+	Meta     struct {
+		Tags  [4]string
+		Owner *Invoice
+	}
+}
+
+// Invoice mixes unnamed types with references to other types
+type Invoice struct {
+	IDs      []int
+	Items    []*Session
+	ByKey    map[string][]*Session
+	OnChange func(old, new *Session) error
+	Updates  chan map[int]Session
+	Meta     struct {
+		Tags  [5]string
+		Owner *Session
+	}
+}
+
+// Session mixes unnamed types with references to other types
+type Session struct {
+	IDs      []int
+	Items    []*Token
+	ByKey    map[string][]*Token
+	OnChange func(old, new *Token) error
+	Updates  chan map[int]Token
+	Meta     struct {
+		Tags  [6]string
+		Owner *Token
+	}
+}
+
+// Token mixes unnamed types with references to other types
+type Token struct {
+	IDs      []int
+	Items    []*Account
+	ByKey    map[string][]*Account
+	OnChange func(old, new *Account) error
+	Updates  chan map[int]Account
+	Meta     struct {
+		Tags  [7]string
+		Owner *Account
+	}
+}
+
+// Account mixes unnamed types with references to other types
+type Account struct {
+	IDs      []int
+	Items    []*Report
+	ByKey    map[string][]*Report
+	OnChange func(old, new *Report) error
+	Updates  chan map[int]Report
+	Meta     struct {
+		Tags  [8]string
+		Owner *Report
+	}
+}
+
+// Report mixes unnamed types with references to other types
+type Report struct {
+	IDs      []int
+	Items    []*Address
+	ByKey    map[string][]*Address
+	OnChange func(old, new *Address) error
+	Updates  chan map[int]Address
+	Meta     struct {
+		Tags  [9]string
+		Owner *Address
+	}
+}
+
+// Address mixes unnamed types with references to other types
+type Address struct {
+	IDs      []int
+	Items    []*Payment
+	ByKey    map[string][]*Payment
+	OnChange func(old, new *Payment) error
+	Updates  chan map[int]Payment
+	Meta     struct {
+		Tags  [10]string
+		Owner *Payment
+	}
+}
+
+// Payment mixes unnamed types with references to other types
+type Payment struct {
+	IDs      []int
+	Items    []*User
+	ByKey    map[string][]*User
+	OnChange func(old, new *User) error
+	Updates  chan map[int]User
+	Meta     struct {
+		Tags  [11]string
+		Owner *User
+	}
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"github.com/pablor21/goscanner/scanner/testdata/deterministic/models"
+)
+
+// User mixes unnamed types with references to other packages
+type User struct {
+	IDs      []int
+	Items    []*models.Order
+	ByKey    map[string][]*models.Order
+	OnChange func(old, new *models.Order) error
+	Updates  chan map[int]models.Order
+	Meta     struct {
+		Tags  [2]string
+		Owner *models.Order
+	}
+}
+
+// Order mixes unnamed types with references to other packages
+type Order struct {
+	IDs      []int
+	Items    []*models.Item
+	ByKey    map[string][]*models.Item
+	OnChange func(old, new *models.Item) error
+	Updates  chan map[int]models.Item
+	Meta     struct {
+		Tags  [3]string
+		Owner *models.Item
+	}
+}
+
+// Item mixes unnamed types with references to other packages
+type Item struct {
+	IDs      []int
+	Items    []*models.Invoice
+	ByKey    map[string][]*models.Invoice
+	OnChange func(old, new *models.Invoice) error
+	Updates  chan map[int]models.Invoice
+	Meta     struct {
+		Tags  [4]string
+		Owner *models.Invoice
+	}
+}
+
+// Invoice mixes unnamed types with references to other packages
+type Invoice struct {
+	IDs      []int
+	Items    []*models.Session
+	ByKey    map[string][]*models.Session
+	OnChange func(old, new *models.Session) error
+	Updates  chan map[int]models.Session
+	Meta     struct {
+		Tags  [5]string
+		Owner *models.Session
+	}
+}
+
+// Session mixes unnamed types with references to other packages
+type Session struct {
+	IDs      []int
+	Items    []*models.Token
+	ByKey    map[string][]*models.Token
+	OnChange func(old, new *models.Token) error
+	Updates  chan map[int]models.Token
+	Meta     struct {
+		Tags  [6]string
+		Owner *models.Token
+	}
+}
+
+// Token mixes unnamed types with references to other packages
+type Token struct {
+	IDs      []int
+	Items    []*models.Account
+	ByKey    map[string][]*models.Account
+	OnChange func(old, new *models.Account) error
+	Updates  chan map[int]models.Account
+	Meta     struct {
+		Tags  [7]string
+		Owner *models.Account
+	}
+}
+
+// Account mixes unnamed types with references to other packages
+type Account struct {
+	IDs      []int
+	Items    []*models.Report
+	ByKey    map[string][]*models.Report
+	OnChange func(old, new *models.Report) error
+	Updates  chan map[int]models.Report
+	Meta     struct {
+		Tags  [8]string
+		Owner *models.Report
+	}
+}
+
+// Report mixes unnamed types with references to other packages
+type Report struct {
+	IDs      []int
+	Items    []*models.Address
+	ByKey    map[string][]*models.Address
+	OnChange func(old, new *models.Address) error
+	Updates  chan map[int]models.Address
+	Meta     struct {
+		Tags  [9]string
+		Owner *models.Address
+	}
+}
+
+// Address mixes unnamed types with references to other packages
+type Address struct {
+	IDs      []int
+	Items    []*models.Payment
+	ByKey    map[string][]*models.Payment
+	OnChange func(old, new *models.Payment) error
+	Updates  chan map[int]models.Payment
+	Meta     struct {
+		Tags  [10]string
+		Owner *models.Payment
+	}
+}
+
+// Payment mixes unnamed types with references to other packages
+type Payment struct {
+	IDs      []int
+	Items    []*models.User
+	ByKey    map[string][]*models.User
+	OnChange func(old, new *models.User) error
+	Updates  chan map[int]models.User
+	Meta     struct {
+		Tags  [11]string
+		Owner *models.User
+	}
+}
@@ -0,0 +1,8 @@
+package app
+
+import "github.com/pablor21/goscanner/scanner/testdata/distance/b"
+
+// App references b directly (distance 1), and c and d through it
+type App struct {
+	B b.B
+}
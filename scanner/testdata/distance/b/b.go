@@ -0,0 +1,9 @@
+package b
+
+import "github.com/pablor21/goscanner/scanner/testdata/distance/c"
+
+type B struct {
+	C c.C
+}
+
+func (B) Name() string { return "b" }
@@ -0,0 +1,9 @@
+package c
+
+import "github.com/pablor21/goscanner/scanner/testdata/distance/d"
+
+type C struct {
+	D d.D
+}
+
+func (C) Name() string { return "c" }
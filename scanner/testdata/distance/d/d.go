@@ -0,0 +1,5 @@
+package d
+
+type D struct {
+	X int
+}
@@ -0,0 +1,11 @@
+package embedded
+
+import "context"
+
+// Job runs in a context, its Deadline, Done, Err and Value methods are promoted from
+// context.Context
+type Job interface {
+	context.Context
+	// Run runs the job
+	Run() error
+}
@@ -0,0 +1,8 @@
+package app
+
+import "github.com/pablor21/goscanner/scanner/testdata/external/dep"
+
+// Service uses a client from a dependency
+type Service struct {
+	Client dep.Client
+}
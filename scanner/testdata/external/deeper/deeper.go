@@ -0,0 +1,6 @@
+package deeper
+
+// Options is referenced by the dep package only
+type Options struct {
+	Retries int
+}
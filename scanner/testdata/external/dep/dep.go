@@ -0,0 +1,8 @@
+package dep
+
+import "github.com/pablor21/goscanner/scanner/testdata/external/deeper"
+
+// Client is referenced by the app package
+type Client struct {
+	Options deeper.Options
+}
@@ -0,0 +1,15 @@
+// Package generated mixes hand written and generated files, it is used to test
+// Config.SkipGenerated.
+package generated
+
+// User is written by hand
+type User struct {
+	Name string
+	// Address is declared in a generated file but referenced from here
+	Address AddressPB
+}
+
+// Greet is written by hand
+func Greet(u User) string {
+	return "hello " + u.Name
+}
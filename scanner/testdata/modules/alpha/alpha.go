@@ -0,0 +1,6 @@
+// Package alpha is a standalone module used to test multi-module scans.
+package alpha
+
+type Alpha struct {
+	Name string
+}
@@ -0,0 +1,6 @@
+// Package beta is a standalone module used to test multi-module scans.
+package beta
+
+type Beta struct {
+	Count int
+}
@@ -0,0 +1,9 @@
+// Package provenance references a type of a dependency module
+package provenance
+
+import "golang.org/x/tools/go/packages"
+
+// Loaded wraps a package loaded by go/packages
+type Loaded struct {
+	Pkg *packages.Package
+}
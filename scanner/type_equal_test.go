@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypeEqual(t *testing.T) {
+	base := `
+	package test
+
+	type Role int
+
+	type User struct {
+		ID    int    ` + "`json:\"id\"`" + `
+		Roles []Role
+		Meta  struct{ Tags map[string][]string }
+		Next  *User
+	}
+
+	func (u *User) Name(prefix string, parts ...string) (string, error) { return "", nil }
+
+	type Box[T any] struct{ Value T }
+
+	type Store interface {
+		Get(id int) (*User, error)
+		Boxes() []Box[User]
+	}
+	`
+
+	changes := map[string]string{
+		"tag":        "`json:\"id\"`",
+		"field type": "Roles []Role",
+		"method":     "parts ...string",
+		"iface":      "Get(id int)",
+	}
+	replacements := map[string]string{
+		"tag":        "`json:\"user_id\"`",
+		"field type": "Roles []int",
+		"method":     "parts []string",
+		"iface":      "Get(id string)",
+	}
+
+	a := scanTestSource(t, base)
+	b := scanTestSource(t, base)
+
+	// Identical sources produce equal types even though they are different pointers
+	for _, id := range []string{"test.User", "test.Store", "test.Box", "test.Role"} {
+		ta, _ := a.Types.Get(id)
+		tb, _ := b.Types.Get(id)
+		if ta == tb {
+			t.Fatalf("%s: expected distinct instances", id)
+		}
+		if !ta.Equal(tb) {
+			t.Errorf("%s: expected structurally equal types", id)
+		}
+	}
+
+	// Different types are not equal
+	user, _ := a.Types.Get("test.User")
+	role, _ := a.Types.Get("test.Role")
+	if user.Equal(role) || user.Equal(nil) {
+		t.Errorf("User must not equal Role or nil")
+	}
+
+	for name, from := range changes {
+		t.Run(name, func(t *testing.T) {
+			changed := scanTestSource(t, strings.Replace(base, from, replacements[name], 1))
+			id := "test.User"
+			if name == "iface" {
+				id = "test.Store"
+			}
+			ta, _ := a.Types.Get(id)
+			tc, _ := changed.Types.Get(id)
+			if ta.Equal(tc) {
+				t.Errorf("%s: expected types to differ after changing %q", id, from)
+			}
+		})
+	}
+}
@@ -1,11 +1,16 @@
 package scanner
 
 import (
+	"cmp"
 	"fmt"
 	"go/ast"
 	"go/doc"
+	"go/format"
+	"go/token"
 	"go/types"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/pablor21/goscanner/logger"
@@ -42,8 +47,25 @@ type defaultTypeResolver struct {
 	pkgs             *gstypes.SyncMap[string, *packages.Package] // Raw go/packages (thread-safe)
 	loadedPkgs       *gstypes.SyncMap[string, bool]              // Track processed packages (thread-safe)
 	packageDistances *gstypes.SyncMap[string, int]               // Track distance for each package (thread-safe)
+	packagePatterns  namePatterns                                // Compiled Config.IncludePackages and ExcludePackages
+	typePatterns     namePatterns                                // Compiled Config.IncludeTypes and ExcludeTypes
+	fieldPatterns    namePatterns                                // Compiled Config.IncludeFields and ExcludeFields
+	methodPatterns   namePatterns                                // Compiled Config.IncludeMethods and ExcludeMethods
 	unnamedCounter   *gstypes.SyncCounter                        // Counter for unnamed types per kind (thread-safe)
 
+	bodyFlags      *gstypes.SyncMap[*types.Func, gstypes.BodyFlags]           // Body facts of scanned functions (ScanModeFunctionBodies)
+	linkage        *gstypes.SyncMap[*types.Func, gstypes.Linkage]             // Assembly and linkname of bodyless scanned functions
+	enumLabels     *gstypes.SyncMap[*types.TypeName, map[string]string]       // Constant labels from String() methods (ScanModeFunctionBodies)
+	enumTypes      *gstypes.SyncMap[*types.Package, map[*types.TypeName]bool] // Named types with constants, per package (ScanModeEnums)
+	declEnds       *gstypes.SyncMap[string, map[token.Pos]token.Pos]          // End of the declarations by name position, per package (Config.IncludePositions)
+	generatedFiles *gstypes.SyncMap[string, bool]                             // Generated files of scanned packages (Config.SkipGenerated)
+	auxiliary      *gstypes.SyncMap[string, bool]                             // Packages scanned for reference only (Config.AuxiliaryPackages)
+	warnings       *warningLog                                                // Resolution warnings, shared with the results
+	anonStructs    *gstypes.SyncMap[string, *gstypes.Struct]                  // Anonymous structs by structural key (see structKey)
+	anyType        *gstypes.Interface                                         // The predeclared any, shared by every any and interface{}
+	processors     []Processor                                                // Registered and scanner processors, see TypeProcessor
+	vetoed         *gstypes.SyncMap[string, bool]                             // Types vetoed by processors, left out of the result
+
 	ignoredTypes   map[string]struct{}                    // Types to ignore
 	basicTypes     *gstypes.SyncMap[string, gstypes.Type] // Cache of basic types (thread-safe)
 	stringInterner *StringInterner                        // String interning pool to reduce allocations (thread-safe)
@@ -68,6 +90,18 @@ func NewDefaultTypeResolver(config *Config, log logger.Logger) *defaultTypeResol
 		pkgs:             gstypes.NewSyncMap[string, *packages.Package](),
 		loadedPkgs:       gstypes.NewSyncMap[string, bool](),
 		packageDistances: gstypes.NewSyncMap[string, int](),
+		bodyFlags:        gstypes.NewSyncMap[*types.Func, gstypes.BodyFlags](),
+		linkage:          gstypes.NewSyncMap[*types.Func, gstypes.Linkage](),
+		enumLabels:       gstypes.NewSyncMap[*types.TypeName, map[string]string](),
+		enumTypes:        gstypes.NewSyncMap[*types.Package, map[*types.TypeName]bool](),
+		declEnds:         gstypes.NewSyncMap[string, map[token.Pos]token.Pos](),
+		generatedFiles:   gstypes.NewSyncMap[string, bool](),
+		auxiliary:        gstypes.NewSyncMap[string, bool](),
+		processors:       registeredProcessors.Slice(),
+		vetoed:           gstypes.NewSyncMap[string, bool](),
+		warnings:         &warningLog{},
+		anonStructs:      gstypes.NewSyncMap[string, *gstypes.Struct](),
+		anyType:          gstypes.NewAny(),
 		unnamedCounter:   gstypes.NewSyncCounter(),
 		ignoredTypes:     make(map[string]struct{}),
 		basicTypes:       gstypes.NewSyncMap[string, gstypes.Type](),
@@ -81,6 +115,11 @@ func NewDefaultTypeResolver(config *Config, log logger.Logger) *defaultTypeResol
 
 	tr.logger.SetTag("TypeResolver")
 
+	tr.packagePatterns = newNamePatterns(tr.logger, "packages", config.IncludePackages, config.ExcludePackages)
+	tr.typePatterns = newNamePatterns(tr.logger, "types", config.IncludeTypes, config.ExcludeTypes)
+	tr.fieldPatterns = newNamePatterns(tr.logger, "fields", config.IncludeFields, config.ExcludeFields)
+	tr.methodPatterns = newNamePatterns(tr.logger, "methods", config.IncludeMethods, config.ExcludeMethods)
+
 	// Initialize basic types cache
 	tr.initBasicTypes()
 
@@ -159,23 +198,6 @@ func (r *defaultTypeResolver) getPackageInfo(ctx *ScanningContext, obj types.Obj
 			return pkgInfo
 		}
 
-		// Check if this is an external package and if we should parse its files
-		isExternal := ctx.CurrentPackage() != nil && pkgPath != ctx.CurrentPackage().Path()
-		shouldParseFiles := isExternal &&
-			r.config.ExternalPackagesOptions != nil &&
-			r.config.ExternalPackagesOptions.ParseFiles
-
-		var rawPkg *packages.Package
-		if shouldParseFiles {
-			// Load the external package with AST to extract comments and files
-			rawPkg = r.loadExternalPackage(pkgPath)
-		}
-
-		// Create package info
-		pkgInfo := gstypes.NewPackage(pkgPath, obj.Pkg().Name(), rawPkg)
-		pkgInfo.SetLogger(r.logger)
-		r.packages.Set(pkgPath, pkgInfo)
-
 		// Calculate distance for this package (use minimum distance if already exists)
 		refPkg := ctx.ResolvingPackage()
 		if refPkg == "" && ctx.CurrentPackage() != nil {
@@ -194,6 +216,33 @@ func (r *defaultTypeResolver) getPackageInfo(ctx *ScanningContext, obj types.Obj
 		if existingDist, exists := r.packageDistances.Get(pkgPath); !exists || newDistance < existingDist {
 			r.packageDistances.Set(pkgPath, newDistance)
 		}
+		distance, _ := r.packageDistances.Get(pkgPath)
+
+		// Check if this is an external package and if we should parse its files.
+		// Packages beyond the max distance only provide opaque references.
+		isExternal := ctx.CurrentPackage() != nil && pkgPath != ctx.CurrentPackage().Path()
+		shouldParseFiles := isExternal &&
+			r.config.ExternalPackagesOptions != nil &&
+			r.config.ExternalPackagesOptions.ParseFiles &&
+			!r.beyondMaxDistance(distance)
+
+		var rawPkg *packages.Package
+		if shouldParseFiles {
+			// Load the external package with AST to extract comments and files
+			rawPkg = r.loadExternalPackage(pkgPath)
+		}
+
+		// Create package info
+		pkgInfo := gstypes.NewPackage(pkgPath, obj.Pkg().Name(), rawPkg)
+		pkgInfo.SetLogger(r.logger)
+		if rawPkg == nil {
+			// Dependencies registered with the scanned packages know their module too
+			rawPkg, _ = r.pkgs.Get(pkgPath)
+		}
+		pkgInfo.SetModule(moduleOf(rawPkg))
+		r.packages.Set(pkgPath, pkgInfo)
+
+		pkgInfo.SetDistance(distance)
 
 		// Extract comments and files if we loaded the AST
 		if rawPkg != nil && len(rawPkg.Syntax) > 0 {
@@ -209,6 +258,16 @@ func (r *defaultTypeResolver) getPackageInfo(ctx *ScanningContext, obj types.Obj
 	return ctx.CurrentPackage()
 }
 
+// beyondMaxDistance reports whether a package at the given distance is farther than
+// Config.MaxDistance (or the deprecated ExternalPackagesOptions.MaxDistance when it's not set)
+func (r *defaultTypeResolver) beyondMaxDistance(distance int) bool {
+	limit := r.config.MaxDistance
+	if limit <= 0 && r.config.ExternalPackagesOptions != nil {
+		limit = r.config.ExternalPackagesOptions.MaxDistance
+	}
+	return limit > 0 && distance > limit
+}
+
 // getPackageForObj returns the raw packages.Package for the given object
 func (r *defaultTypeResolver) getPackageForObj(obj types.Object) *packages.Package {
 	if obj != nil && obj.Pkg() != nil {
@@ -240,6 +299,19 @@ func (r *defaultTypeResolver) getModuleRelativePath(osPath string, pkgPath strin
 	return sb.String()
 }
 
+// moduleOf returns the module pkg was loaded from (the replacement when the module is
+// replaced), nil for packages outside modules or loaded without packages.NeedModule
+func moduleOf(pkg *packages.Package) *gstypes.Module {
+	if pkg == nil || pkg.Module == nil {
+		return nil
+	}
+	mod := pkg.Module
+	if mod.Replace != nil {
+		mod = mod.Replace
+	}
+	return gstypes.NewModule(mod.Path, mod.Version)
+}
+
 // loadExternalPackage loads an external package with its AST for comment extraction
 func (r *defaultTypeResolver) loadExternalPackage(pkgPath string) *packages.Package {
 	// Check if already loaded
@@ -252,7 +324,7 @@ func (r *defaultTypeResolver) loadExternalPackage(pkgPath string) *packages.Pack
 	// Load package with AST (NeedSyntax includes NeedTypes and NeedImports)
 	cfg := &packages.Config{
 		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
-			packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+			packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedModule,
 	}
 
 	pkgs, err := packages.Load(cfg, pkgPath)
@@ -342,11 +414,38 @@ func (r *defaultTypeResolver) loadExternalPackageDoc(pkgPath string, obj types.O
 	return nil
 }
 
-// ProcessPackage processes a package to extract type information
-func (r *defaultTypeResolver) ProcessPackage(ctx *ScanningContext, pkg *packages.Package) error {
+// registerPackages registers the given packages and all their dependencies
+// so their docs can be loaded when needed
+func (r *defaultTypeResolver) registerPackages(pkgs []*packages.Package) {
+	visited := make(map[string]bool)
+	var registerDeps func(*packages.Package)
+	registerDeps = func(pkg *packages.Package) {
+		if pkg == nil || visited[pkg.PkgPath] {
+			return
+		}
+		visited[pkg.PkgPath] = true
+
+		// Register this package in the type resolver's package map
+		r.pkgs.Set(pkg.PkgPath, pkg)
+
+		// Recursively register dependencies
+		for _, dep := range pkg.Imports {
+			registerDeps(dep)
+		}
+	}
+
+	for _, pkg := range pkgs {
+		registerDeps(pkg)
+	}
+}
+
+// preparePackage registers a package with the resolver (package info, distance, comments
+// and documentation) and returns a context scoped to it together with its doc.Package
+func (r *defaultTypeResolver) preparePackage(ctx *ScanningContext, pkg *packages.Package) (*ScanningContext, *doc.Package, error) {
 	// Create package info
 	pkgInfo := gstypes.NewPackage(pkg.PkgPath, pkg.Name, pkg)
 	pkgInfo.SetLogger(r.logger)
+	pkgInfo.SetModule(moduleOf(pkg))
 	r.packages.Set(pkg.PkgPath, pkgInfo)
 
 	// Create a context with this package
@@ -359,6 +458,28 @@ func (r *defaultTypeResolver) ProcessPackage(ctx *ScanningContext, pkg *packages
 	if err := r.extractComments(pkgInfo, pkg); err != nil {
 		r.logger.Warnf("Failed to extract comments: %v", err)
 	}
+	r.extractDocLinks(pkgInfo, pkg)
+
+	// Inspect bodies before go/doc, which drops them from the AST
+	if r.config.ScanMode.Has(ScanModeFunctionBodies) {
+		r.extractBodyFlags(pkg)
+		r.extractEnumLabels(pkg)
+	}
+	r.extractLinkage(pkg)
+	if r.config.IncludePositions {
+		r.declEnds.Set(pkg.PkgPath, declarationEnds(pkg.Syntax))
+	}
+	if r.config.ScanMode.Has(ScanModeFunctions) {
+		r.extractInitFuncs(pkgInfo, pkg)
+	}
+	// Same for the comments holding the generated file marker
+	if r.config.SkipGenerated {
+		for _, file := range pkg.Syntax {
+			if ast.IsGenerated(file) {
+				r.generatedFiles.Set(pkg.Fset.Position(file.Pos()).Filename, true)
+			}
+		}
+	}
 
 	// Extract documentation - check cache first
 	docPkg, cached := r.docPackages.Get(pkg.PkgPath)
@@ -372,7 +493,7 @@ func (r *defaultTypeResolver) ProcessPackage(ctx *ScanningContext, pkg *packages
 			doc.AllMethods|doc.AllDecls,
 		)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		r.docPackages.Set(pkg.PkgPath, docPkg)
 	}
@@ -380,9 +501,6 @@ func (r *defaultTypeResolver) ProcessPackage(ctx *ScanningContext, pkg *packages
 	r.pkgs.Set(pkg.PkgPath, pkg)
 	r.loadedPkgs.Set(pkg.PkgPath, true)
 
-	// Cache scope for efficiency
-	scope := pkg.Types.Scope()
-
 	// Package-level functions documentation
 	for _, docFunc := range docPkg.Funcs {
 		var sb strings.Builder
@@ -393,40 +511,119 @@ func (r *defaultTypeResolver) ProcessPackage(ctx *ScanningContext, pkg *packages
 		r.docFuncs.Set(canonical, docFunc)
 	}
 
-	// Types + associated functions
-	if r.config.ScanMode.Has(ScanModeTypes) {
-		for _, docType := range docPkg.Types {
+	// Type documentation and factory functions associated with the types
+	// Registered up front so types referenced before their own declaration still get their docs
+	for _, docType := range docPkg.Types {
+		var sb strings.Builder
+		sb.WriteString(pkg.PkgPath)
+		sb.WriteString(".")
+		sb.WriteString(docType.Name)
+		typeCanonical := sb.String()
+		r.docTypes.Set(typeCanonical, docType)
+
+		for _, typeFunc := range docType.Funcs {
 			var sb strings.Builder
 			sb.WriteString(pkg.PkgPath)
 			sb.WriteString(".")
-			sb.WriteString(docType.Name)
-			typeCanonical := sb.String()
-			r.docTypes.Set(typeCanonical, docType)
+			sb.WriteString(typeFunc.Name)
+			funcCanonical := sb.String()
+			r.docFuncs.Set(funcCanonical, typeFunc)
+		}
+	}
 
-			// Factory functions associated with the type
-			for _, typeFunc := range docType.Funcs {
-				var sb strings.Builder
-				sb.WriteString(pkg.PkgPath)
-				sb.WriteString(".")
-				sb.WriteString(typeFunc.Name)
-				funcCanonical := sb.String()
-				r.docFuncs.Set(funcCanonical, typeFunc)
+	return ctx, docPkg, nil
+}
+
+// resolveNamed resolves a single named type declared in pkg without processing the rest of
+// the package. Types reachable from it are resolved on demand as usual.
+func (r *defaultTypeResolver) resolveNamed(ctx *ScanningContext, pkg *packages.Package, name string) (gstypes.Type, error) {
+	ctx, _, err := r.preparePackage(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := pkg.Types.Scope().Lookup(name)
+	if obj == nil {
+		return nil, fmt.Errorf("type %s not found in package %s", name, pkg.PkgPath)
+	}
+	typeName, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%s.%s is not a type", pkg.PkgPath, name)
+	}
+
+	t := r.ResolveType(ctx, typeName.Type())
+	if t == nil {
+		return nil, fmt.Errorf("failed to resolve type %s.%s", pkg.PkgPath, name)
+	}
+	return t, nil
+}
+
+// ProcessPackage processes a package to extract type information
+func (r *defaultTypeResolver) ProcessPackage(ctx *ScanningContext, pkg *packages.Package) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ctx, docPkg, err := r.preparePackage(ctx, pkg)
+	if err != nil {
+		return err
+	}
+
+	// Cache scope for efficiency
+	scope := pkg.Types.Scope()
+
+	// Declarations of generated files and auxiliary packages are skipped, they're still
+	// resolved when referenced
+	auxiliary, _ := r.auxiliary.Get(pkg.PkgPath)
+	keepPackage := r.keepPackage(pkg.PkgPath)
+	skip := func(obj types.Object) bool {
+		if obj == nil || auxiliary || !keepPackage {
+			return true
+		}
+		if _, ok := obj.(*types.TypeName); ok && !r.keepTypeName(pkg.PkgPath, obj.Name()) {
+			return true
+		}
+		generated, _ := r.generatedFiles.Get(pkg.Fset.Position(obj.Pos()).Filename)
+		return generated
+	}
+
+	// Constants are added to their enums as they're parsed, the enums are sorted once done
+	enums := map[*gstypes.Enum]bool{}
+	parseConst := func(obj types.Object, decl *doc.Value) {
+		if value, ok := r.parseValue(ctx, obj, decl).(*gstypes.Value); ok {
+			if enum, ok := value.Parent().(*gstypes.Enum); ok {
+				enums[enum] = true
 			}
+		}
+	}
 
+	// Types + associated functions
+	if r.config.ScanMode.Has(ScanModeTypes) {
+		for _, docType := range docPkg.Types {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			// Resolve the actual type
 			obj := scope.Lookup(docType.Name)
 			if obj == nil {
 				continue
 			}
 
-			r.ResolveType(ctx, obj.Type())
+			// Constants of filtered types go with them
+			if !r.keepTypeName(pkg.PkgPath, obj.Name()) {
+				continue
+			}
+			if !skip(obj) {
+				r.ResolveType(ctx, obj.Type())
+			}
 
 			// Parse constants associated with this type
 			if r.config.ScanMode.Has(ScanModeConsts) {
 				for _, constDecl := range docType.Consts {
 					for _, name := range constDecl.Names {
 						obj := scope.Lookup(name)
-						r.parseValue(ctx, obj, constDecl)
+						if !skip(obj) {
+							parseConst(obj, constDecl)
+						}
 					}
 				}
 			}
@@ -440,7 +637,7 @@ func (r *defaultTypeResolver) ProcessPackage(ctx *ScanningContext, pkg *packages
 			// Check if it's a type name (TypeName objects represent type declarations)
 			if typeName, ok := obj.(*types.TypeName); ok {
 				// Check if it's a type alias (not already processed via docPkg.Types)
-				if _, isAlias := typeName.Type().(*types.Alias); isAlias {
+				if _, isAlias := typeName.Type().(*types.Alias); isAlias && !skip(obj) {
 					// Resolve the alias type
 					r.ResolveType(ctx, typeName.Type())
 				}
@@ -453,17 +650,24 @@ func (r *defaultTypeResolver) ProcessPackage(ctx *ScanningContext, pkg *packages
 		for _, value := range docPkg.Consts {
 			for _, name := range value.Names {
 				obj := scope.Lookup(name)
-				r.parseValue(ctx, obj, value)
+				if !skip(obj) {
+					parseConst(obj, value)
+				}
 			}
 		}
 	}
+	for enum := range enums {
+		sortEnumValues(enum)
+	}
 
 	// Variables
 	if r.config.ScanMode.Has(ScanModeVariables) {
 		for _, value := range docPkg.Vars {
 			for _, name := range value.Names {
 				obj := scope.Lookup(name)
-				r.parseValue(ctx, obj, value)
+				if !skip(obj) {
+					r.parseValue(ctx, obj, value)
+				}
 			}
 		}
 	}
@@ -476,7 +680,7 @@ func (r *defaultTypeResolver) ProcessPackage(ctx *ScanningContext, pkg *packages
 			if f, ok := obj.(*types.Func); ok {
 				// Skip methods - they have a receiver and are handled by their parent struct/interface
 				sig, ok := f.Type().(*types.Signature)
-				if !ok || sig.Recv() != nil {
+				if !ok || sig.Recv() != nil || skip(f) {
 					continue
 				}
 
@@ -551,6 +755,11 @@ func (r *defaultTypeResolver) checkCaches(t types.Type) gstypes.Type {
 		return ti
 	}
 
+	// any and interface{} (and any other interface satisfied by all types) are the shared any
+	if iface, ok := t.(*types.Interface); typeName == gstypes.AnyID || ok && iface.Empty() {
+		return r.anyType
+	}
+
 	// Handle predeclared types (error, comparable) as basic types
 	if typeName == "error" || typeName == "comparable" {
 		if basicType, exists := r.basicTypes.Get(typeName); exists {
@@ -579,23 +788,29 @@ func (r *defaultTypeResolver) handleSpecialCases(ctx *ScanningContext, t types.T
 		rhsType := alias.Rhs()
 		if named, ok := rhsType.(*types.Named); ok && named.TypeArgs() != nil && named.TypeArgs().Len() > 0 {
 			// The alias is for an instantiated generic
-			origin := r.ResolveType(ctx, named.Origin())
-			typeArgs := r.extractTypeArgumentsWithParams(ctx, named.Origin(), named.TypeArgs())
-			return r.makeInstantiatedGeneric(typeName, origin, typeArgs)
+			return r.resolveInstance(ctx, typeName, named)
 		}
 	}
 
 	// Check if it's a named type with type arguments (instantiated generic)
 	if named, ok := t.(*types.Named); ok && named.TypeArgs() != nil && named.TypeArgs().Len() > 0 {
 		// This is an instantiated generic like List[int]
-		origin := r.ResolveType(ctx, named.Origin())
-		typeArgs := r.extractTypeArgumentsWithParams(ctx, named.Origin(), named.TypeArgs())
-		return r.makeInstantiatedGeneric(typeName, origin, typeArgs)
+		return r.resolveInstance(ctx, typeName, named)
 	}
 
 	return nil
 }
 
+// resolveInstance resolves the instantiated generic named. The instance is cached before its
+// type arguments are resolved, they may refer back to it: T in func Max[T Comparer[T]] is
+// constrained by Comparer[T] itself.
+func (r *defaultTypeResolver) resolveInstance(ctx *ScanningContext, id string, named *types.Named) *gstypes.InstantiatedGeneric {
+	origin := r.ResolveType(ctx, named.Origin())
+	ig := r.makeInstantiatedGeneric(ctx, id, named, origin, nil)
+	ig.SetTypeArgs(r.extractTypeArgumentsWithParams(ctx, named.Origin(), named.TypeArgs()))
+	return ig
+}
+
 // resolveUnderlyingType unwraps named types and resolves the underlying type
 func (r *defaultTypeResolver) resolveUnderlyingType(ctx *ScanningContext, t types.Type) gstypes.Type {
 	typeName := r.GetCanonicalName(t)
@@ -633,7 +848,11 @@ func (r *defaultTypeResolver) resolveUnderlyingType(ctx *ScanningContext, t type
 
 	switch gt := t.(type) {
 	case *types.Basic:
-		ti = r.makeBasic(ctx, typeName, gt, namedType, obj)
+		if namedType != nil && r.config.ScanMode.Has(ScanModeEnums) && r.hasConstants(namedType) {
+			ti = r.makeEnum(ctx, typeName, gt, namedType, obj, docType)
+		} else {
+			ti = r.makeBasic(ctx, typeName, gt, namedType, obj)
+		}
 
 	case *types.Pointer:
 		ti = r.makePointer(ctx, typeName, gt, namedType, obj, docType)
@@ -666,13 +885,13 @@ func (r *defaultTypeResolver) resolveUnderlyingType(ctx *ScanningContext, t type
 		ti = r.makeUnion(ctx, typeName, gt)
 
 	default:
-		r.logger.Warnf("Unsupported type: %s (%T)", t.String(), t)
+		r.warnf("Unsupported type: %s (%T)", t.String(), t)
 	}
 
 	if ti != nil {
 		// Check if the interface contains a nil pointer
 		if isNilType(ti) {
-			r.logger.Warnf("Type resolution returned typed nil for: %s", typeName)
+			r.warnf("Type resolution returned typed nil for: %s", typeName)
 			return nil
 		}
 	}
@@ -692,9 +911,33 @@ func (r *defaultTypeResolver) cache(t gstypes.Type) {
 			return
 		}
 	}
+	if len(r.processors) > 0 && !r.types.Has(t.Id()) {
+		r.process(t)
+	}
 	r.types.Set(t.Id(), t)
 }
 
+// process passes a type about to be cached to the processors, recording their veto
+func (r *defaultTypeResolver) process(t gstypes.Type) {
+	for _, p := range r.processors {
+		keep := true
+		var err error
+		if tp, ok := p.(TypeProcessor); ok {
+			keep, err = tp.ProcessResolvedType(t)
+		} else if obj := t.Object(); obj != nil {
+			keep, err = p.ProcessType(obj.Type())
+		}
+		if err != nil {
+			r.warnf("processor failed on %s: %v", t.Id(), err)
+			continue
+		}
+		if !keep {
+			r.vetoed.Set(t.Id(), true)
+			return
+		}
+	}
+}
+
 // setupCommonTypeFields sets common fields on a type (package, object, doc, goType, files, exported, distance)
 func (r *defaultTypeResolver) setupCommonTypeFields(ctx *ScanningContext, t gstypes.Type, obj types.Object, docType *doc.Type, goType types.Type) {
 	pkgInfo := r.getPackageInfo(ctx, obj)
@@ -704,6 +947,8 @@ func (r *defaultTypeResolver) setupCommonTypeFields(ctx *ScanningContext, t gsty
 	if pkgInfo != nil {
 		if dist, exists := r.packageDistances.Get(pkgInfo.Path()); exists {
 			t.SetDistance(dist)
+			// Named types beyond the max distance are referenced without their members
+			t.SetOpaque(obj != nil && r.beyondMaxDistance(dist))
 		} else {
 			// If not in map, default to distance 999
 			t.SetDistance(999)
@@ -714,6 +959,10 @@ func (r *defaultTypeResolver) setupCommonTypeFields(ctx *ScanningContext, t gsty
 		t.SetObject(obj)
 		// Set whether the type is exported
 		t.SetExported(obj.Exported())
+		// Aliases share the object kind of type definitions
+		if typeName, ok := obj.(*types.TypeName); ok && !typeName.IsAlias() {
+			t.SetDefined(true)
+		}
 		// Set the file where this type is defined
 		if obj.Pos().IsValid() {
 			pkg := r.getPackageForObj(obj)
@@ -723,6 +972,7 @@ func (r *defaultTypeResolver) setupCommonTypeFields(ctx *ScanningContext, t gsty
 					// Convert OS path to module-relative path
 					modulePath := r.getModuleRelativePath(pos.Filename, obj.Pkg().Path())
 					t.SetFiles([]string{modulePath})
+					t.SetPosition(r.positionOf(obj))
 				}
 			}
 		}
@@ -735,6 +985,92 @@ func (r *defaultTypeResolver) setupCommonTypeFields(ctx *ScanningContext, t gsty
 	}
 }
 
+// positionOf returns the position of the declaration of obj, nil unless
+// Config.IncludePositions is set or when it isn't in a scanned package
+func (r *defaultTypeResolver) positionOf(obj types.Object) *gstypes.Position {
+	if !r.config.IncludePositions || obj == nil || !obj.Pos().IsValid() {
+		return nil
+	}
+	pkg := r.getPackageForObj(obj)
+	if pkg == nil {
+		return nil
+	}
+	return r.position(pkg, obj.Pos(), r.declEnd(pkg, obj.Pos()))
+}
+
+// position converts pos, and end when valid, into a module relative position in pkg
+func (r *defaultTypeResolver) position(pkg *packages.Package, pos, end token.Pos) *gstypes.Position {
+	p := pkg.Fset.Position(pos)
+	if p.Filename == "" {
+		return nil
+	}
+	position := &gstypes.Position{
+		File:   r.getModuleRelativePath(p.Filename, pkg.PkgPath),
+		Line:   p.Line,
+		Column: p.Column,
+	}
+	if end.IsValid() {
+		position.EndLine = pkg.Fset.Position(end).Line
+	}
+	return position
+}
+
+// declEnd returns the end of the declaration named by the identifier at pos in pkg: a type,
+// function, constant, variable, field or parameter. NoPos for the packages not processed.
+func (r *defaultTypeResolver) declEnd(pkg *packages.Package, pos token.Pos) token.Pos {
+	ends, _ := r.declEnds.Get(pkg.PkgPath)
+	return ends[pos]
+}
+
+// declarationEnds maps the position of the names declared in files to the end of their
+// declaration. Embedded fields are keyed by their type name, like their go/types object.
+// It must run before go/doc drops the function bodies.
+func declarationEnds(files []*ast.File) map[token.Pos]token.Pos {
+	ends := map[token.Pos]token.Pos{}
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.TypeSpec:
+				ends[n.Name.Pos()] = n.End()
+			case *ast.FuncDecl:
+				ends[n.Name.Pos()] = n.End()
+			case *ast.ValueSpec:
+				for _, name := range n.Names {
+					ends[name.Pos()] = n.End()
+				}
+			case *ast.Field:
+				for _, name := range n.Names {
+					ends[name.Pos()] = n.End()
+				}
+				if len(n.Names) == 0 {
+					if name := embeddedName(n.Type); name != nil {
+						ends[name.Pos()] = n.End()
+					}
+				}
+			}
+			return true
+		})
+	}
+	return ends
+}
+
+// embeddedName returns the type name of an embedded field (T, *T, pkg.T, T[A])
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.IndexExpr:
+		return embeddedName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedName(e.X)
+	}
+	return nil
+}
+
 // normalizeUntyped converts untyped constants to their typed equivalents
 func (r *defaultTypeResolver) normalizeUntyped(t types.Type) types.Type {
 	if basic, ok := t.(*types.Basic); ok {
@@ -844,7 +1180,7 @@ func (r *defaultTypeResolver) makePointer(ctx *ScanningContext,
 	// Resolve the element type (the type being pointed to)
 	elem := r.ResolveType(ctx, elemType)
 	if elem == nil {
-		r.logger.Warnf("Failed to resolve pointer element type: %v", elemType)
+		r.warnf("Failed to resolve pointer element type: %v", elemType)
 		return nil
 	}
 
@@ -909,7 +1245,7 @@ func (r *defaultTypeResolver) makeCollection(ctx *ScanningContext,
 		// Resolve the underlying element type
 		elem = r.ResolveType(ctx, elemType)
 		if elem == nil {
-			r.logger.Warnf("Failed to resolve collection element type: %v", elemType)
+			r.warnf("Failed to resolve collection element type: %v", elemType)
 			return nil
 		}
 
@@ -929,13 +1265,13 @@ func (r *defaultTypeResolver) makeCollection(ctx *ScanningContext,
 	}
 
 	if elem == nil {
-		r.logger.Warnf("Failed to resolve collection element type: %v", elemType)
+		r.warnf("Failed to resolve collection element type: %v", elemType)
 		return nil
 	}
 
 	// For unnamed element types, set package to parent's package (except basic types)
 	if !elem.IsNamed() {
-		if _, isBasic := elem.(*gstypes.Basic); !isBasic {
+		if !isPredeclared(elem) {
 			if namedType != nil && obj != nil {
 				elem.SetPackage(r.getPackageInfo(ctx, obj))
 			}
@@ -1017,7 +1353,7 @@ func (r *defaultTypeResolver) makeMap(ctx *ScanningContext,
 		keyType, keyPointerDepth = r.deferPtr(keyType)
 		key = r.ResolveType(ctx, keyType)
 		if key == nil {
-			r.logger.Warnf("Failed to resolve map key type: %v", keyType)
+			r.warnf("Failed to resolve map key type: %v", keyType)
 			return nil
 		}
 		if keyPointerDepth > 0 {
@@ -1034,7 +1370,7 @@ func (r *defaultTypeResolver) makeMap(ctx *ScanningContext,
 		}
 	}
 	if key == nil {
-		r.logger.Warnf("Failed to resolve map key type: %v", keyType)
+		r.warnf("Failed to resolve map key type: %v", keyType)
 		return nil
 	}
 
@@ -1050,7 +1386,7 @@ func (r *defaultTypeResolver) makeMap(ctx *ScanningContext,
 		valueType, valuePointerDepth = r.deferPtr(valueType)
 		value = r.ResolveType(ctx, valueType)
 		if value == nil {
-			r.logger.Warnf("Failed to resolve map value type: %v", valueType)
+			r.warnf("Failed to resolve map value type: %v", valueType)
 			return nil
 		}
 		if valuePointerDepth > 0 {
@@ -1067,20 +1403,20 @@ func (r *defaultTypeResolver) makeMap(ctx *ScanningContext,
 		}
 	}
 	if value == nil {
-		r.logger.Warnf("Failed to resolve map value type: %v", valueType)
+		r.warnf("Failed to resolve map value type: %v", valueType)
 		return nil
 	}
 
 	// For unnamed key/value types, set package to parent's package (except basic types)
 	if !key.IsNamed() {
-		if _, isBasic := key.(*gstypes.Basic); !isBasic {
+		if !isPredeclared(key) {
 			if namedType != nil && obj != nil {
 				key.SetPackage(r.getPackageInfo(ctx, obj))
 			}
 		}
 	}
 	if !value.IsNamed() {
-		if _, isBasic := value.(*gstypes.Basic); !isBasic {
+		if !isPredeclared(value) {
 			if namedType != nil && obj != nil {
 				value.SetPackage(r.getPackageInfo(ctx, obj))
 			}
@@ -1164,7 +1500,7 @@ func (r *defaultTypeResolver) makeChannel(ctx *ScanningContext,
 		elemType, pointerDepth = r.deferPtr(elemType)
 		elem = r.ResolveType(ctx, elemType)
 		if elem == nil {
-			r.logger.Warnf("Failed to resolve channel element type: %v", elemType)
+			r.warnf("Failed to resolve channel element type: %v", elemType)
 			return nil
 		}
 		if pointerDepth > 0 {
@@ -1181,13 +1517,13 @@ func (r *defaultTypeResolver) makeChannel(ctx *ScanningContext,
 		}
 	}
 	if elem == nil {
-		r.logger.Warnf("Failed to resolve channel element type: %v", elemType)
+		r.warnf("Failed to resolve channel element type: %v", elemType)
 		return nil
 	}
 
 	// For unnamed element types, set package to parent's package (except basic types)
 	if !elem.IsNamed() {
-		if _, isBasic := elem.(*gstypes.Basic); !isBasic {
+		if !isPredeclared(elem) {
 			// Inherit package from parent if parent is named, otherwise use current
 			if namedType != nil && obj != nil {
 				elem.SetPackage(r.getPackageInfo(ctx, obj))
@@ -1243,9 +1579,30 @@ func (r *defaultTypeResolver) extractMethods(ctx *ScanningContext,
 	namedType *types.Named,
 	parent gstypes.Type,
 ) ([]*gstypes.Method, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if parent.Opaque() {
+		return nil, nil
+	}
 	methods := make([]*gstypes.Method, 0, namedType.NumMethods())
 
-	for method := range namedType.Methods() {
+	// Methods of a generic type can rename its type parameters in the receiver
+	// (func (c *Cache[Key, Val]) ...): the origin instantiated with its own parameters has
+	// the signatures in terms of the declared ones
+	signatures := namedType
+	if tparams := namedType.TypeParams(); tparams.Len() > 0 && namedType.TypeArgs().Len() == 0 {
+		args := make([]types.Type, tparams.Len())
+		for i := range args {
+			args[i] = tparams.At(i)
+		}
+		if instance, err := types.Instantiate(nil, namedType, args, false); err == nil {
+			signatures = instance.(*types.Named)
+		}
+	}
+
+	for i := 0; i < namedType.NumMethods(); i++ {
+		method := namedType.Method(i)
 
 		// Check if method should be exported
 		if !r.shouldExport(ctx, method) {
@@ -1253,8 +1610,13 @@ func (r *defaultTypeResolver) extractMethods(ctx *ScanningContext,
 			continue
 		}
 
+		if r.isMethodExcluded(parent, method.Name()) {
+			r.logger.Debugf("Skipping excluded %s method: %s.%s", parent.Kind(), parent.Id(), method.Name())
+			continue
+		}
+
 		// Get method signature
-		sig, ok := method.Type().(*types.Signature)
+		sig, ok := signatures.Method(i).Type().(*types.Signature)
 		if !ok {
 			continue
 		}
@@ -1281,24 +1643,133 @@ func (r *defaultTypeResolver) extractMethods(ctx *ScanningContext,
 			m.AddResult(r)
 		}
 
+		if flags, ok := r.bodyFlags.Get(method.Origin()); ok {
+			m.SetBodyFlags(flags)
+		}
+		if linkage, ok := r.linkage.Get(method.Origin()); ok {
+			m.SetLinkage(linkage)
+		}
+
 		// Set object and doc
 		m.SetObject(method)
+		m.SetPosition(r.positionOf(method))
 		methods = append(methods, m)
 
 	}
 
+	parent.SetMethodSets(r.methodSetIDs(ctx, namedType, parent), r.methodSetIDs(ctx, types.NewPointer(namedType), parent))
+
 	return methods, nil
 
 }
 
+// methodSetIDs returns the ids of the methods in the method set of t (a named type or a
+// pointer to it). Methods declared by parent use its method ids, promoted ones the id of
+// the method on the embedded type.
+func (r *defaultTypeResolver) methodSetIDs(ctx *ScanningContext, t types.Type, parent gstypes.Type) []string {
+	mset := types.NewMethodSet(t)
+	ids := make([]string, 0, mset.Len())
+	for sel := range mset.Methods() {
+		fn, ok := sel.Obj().(*types.Func)
+		if !ok || !r.shouldExport(ctx, fn) {
+			continue
+		}
+		if len(sel.Index()) == 1 {
+			if r.isMethodExcluded(parent, fn.Name()) {
+				continue
+			}
+			ids = append(ids, parent.Id()+"#"+fn.Name())
+			continue
+		}
+
+		// Promoted: the receiver names the embedded type declaring the method
+		recv := fn.Signature().Recv()
+		if recv == nil {
+			continue
+		}
+		recvType := recv.Type()
+		if ptr, ok := recvType.(*types.Pointer); ok {
+			recvType = ptr.Elem()
+		}
+		ids = append(ids, r.GetCanonicalName(recvType)+"#"+fn.Name())
+	}
+	return ids
+}
+
+// isMethodExcluded reports whether a method of parent is filtered out by Config.IncludeMethods
+// or Config.ExcludeMethods
+func (r *defaultTypeResolver) isMethodExcluded(parent gstypes.Type, methodName string) bool {
+	if r.methodPatterns.empty() {
+		return false
+	}
+	return !r.methodPatterns.keep(methodName, parent.Name()+"."+methodName, parent.Id()+"."+methodName)
+}
+
+// keepField reports whether f passes Config.IncludeFields, Config.ExcludeFields and
+// Config.FieldFilter
+func (r *defaultTypeResolver) keepField(f *gstypes.Field) bool {
+	if !r.fieldPatterns.empty() {
+		name := f.Name()
+		forms := []string{name}
+		if parent := f.Parent(); parent != nil {
+			forms = append(forms, parent.Name()+"."+name, parent.Id()+"."+name)
+		}
+		if !r.fieldPatterns.keep(forms...) {
+			return false
+		}
+	}
+	return r.config.FieldFilter == nil || r.config.FieldFilter(f)
+}
+
+// setBitWidth sets the bit width of f from its Config.BitWidthTag tag, if any
+func (r *defaultTypeResolver) setBitWidth(f *gstypes.Field) {
+	if r.config.BitWidthTag == "" {
+		return
+	}
+	value, ok := reflect.StructTag(f.Tag()).Lookup(r.config.BitWidthTag)
+	if !ok {
+		return
+	}
+	bits, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || bits <= 0 || bits > 64 {
+		r.logger.Warnf("Invalid bit width %q of field %s, expected a number between 1 and 64", value, f.Id())
+		return
+	}
+	f.SetBitWidth(bits)
+}
+
+// structKey returns the structural key of an anonymous struct: identical structs (same field
+// names, types, tags and embedding) have the same key. Field names are qualified by the package
+// declaring the struct, so the key includes it, as structs of different packages with
+// unexported fields aren't identical and unnamed types take the package they're used in.
+func (r *defaultTypeResolver) structKey(st *types.Struct) string {
+	key := types.TypeString(st, r.qualifier)
+	if st.NumFields() > 0 && st.Field(0).Pkg() != nil {
+		key = st.Field(0).Pkg().Path() + " " + key
+	}
+	return key
+}
+
+// isPredeclared reports whether t is a predeclared type (the basic types, error, comparable
+// and any), which are shared and have no package
+func isPredeclared(t gstypes.Type) bool {
+	switch typed := t.(type) {
+	case *gstypes.Basic:
+		return true
+	case *gstypes.Interface:
+		return typed.IsPredeclared()
+	}
+	return false
+}
+
 // setUnnamedTypePackages recursively sets the package for all unnamed types in a type tree
 func (r *defaultTypeResolver) setUnnamedTypePackages(t gstypes.Type, pkg *gstypes.Package) {
 	if t == nil || pkg == nil || t.IsNamed() {
 		return
 	}
 
-	// Skip basic types - they have no package
-	if _, isBasic := t.(*gstypes.Basic); isBasic {
+	// Skip predeclared types - they have no package
+	if isPredeclared(t) {
 		return
 	}
 
@@ -1379,7 +1850,16 @@ func (r *defaultTypeResolver) processSignature(ctx *ScanningContext, sig *types.
 		}
 
 		isVariadic := sig.Variadic() && i == params.Len()-1
+		if slice, ok := finalParamType.(*gstypes.Slice); ok && isVariadic {
+			// ...T: the parameter type is T, the []T slice is kept apart
+			param := gstypes.NewParameter(paramVar.Name(), slice.Elem(), true)
+			param.SetSliceType(slice)
+			param.SetPosition(r.positionOf(paramVar))
+			parameters = append(parameters, param)
+			continue
+		}
 		param := gstypes.NewParameter(paramVar.Name(), finalParamType, isVariadic)
+		param.SetPosition(r.positionOf(paramVar))
 		parameters = append(parameters, param)
 	}
 
@@ -1416,6 +1896,7 @@ func (r *defaultTypeResolver) processSignature(ctx *ScanningContext, sig *types.
 		}
 
 		result := gstypes.NewResult(resultVar.Name(), finalResultType)
+		result.SetPosition(r.positionOf(resultVar))
 		results = append(results, result)
 	}
 
@@ -1455,6 +1936,13 @@ func (r *defaultTypeResolver) makeFunction(ctx *ScanningContext,
 		}
 	}
 
+	// Named function types are cached before their signature, which can refer back to them
+	// (type stateFn func(*lexer) stateFn). Processors get them once complete, below.
+	named := namedType != nil && forceKind != gstypes.TypeKindMethod
+	if named {
+		r.types.Set(fn.Id(), fn)
+	}
+
 	// Process signature using helper
 	parameters, results := r.processSignature(ctx, sig, ctx.CurrentPackage())
 	for _, p := range parameters {
@@ -1464,6 +1952,15 @@ func (r *defaultTypeResolver) makeFunction(ctx *ScanningContext,
 		fn.AddResult(r)
 	}
 
+	if f, ok := obj.(*types.Func); ok {
+		if flags, ok := r.bodyFlags.Get(f); ok {
+			fn.SetBodyFlags(flags)
+		}
+		if linkage, ok := r.linkage.Get(f); ok {
+			fn.SetLinkage(linkage)
+		}
+	}
+
 	// Set loader for named types
 	loaderCtx := ctx
 	if namedType != nil && obj != nil {
@@ -1483,28 +1980,33 @@ func (r *defaultTypeResolver) makeFunction(ctx *ScanningContext,
 
 	// Only cache functions, not methods
 	// Methods are stored in their parent struct/interface, not in the global types collection
-	if forceKind != gstypes.TypeKindMethod {
+	if named && len(r.processors) > 0 {
+		r.process(fn)
+	} else if forceKind != gstypes.TypeKindMethod && !named {
 		r.cache(fn)
 	}
 	return fn
 }
 
-// makeAlias creates an Alias type
+// makeAlias creates an Alias type for a declaration like `type A = B`. The aliased type is
+// the right hand side, so aliases of named types reference them (type A = B points to B,
+// not to B's underlying type). Direct aliases of generic instantiations are represented by
+// the instantiation itself, see handleSpecialCases.
 func (r *defaultTypeResolver) makeAlias(ctx *ScanningContext,
 	id string,
 	aliasType *types.Alias,
 	// forceKind types.TypeKind,
 ) *gstypes.Alias {
-	// Get the underlying type
-	underlyingType := aliasType.Underlying()
+	// Get the aliased type
+	rhsType := aliasType.Rhs()
 
-	// Use deferPtr to handle pointers in the underlying type
-	underlyingType, pointerDepth := r.deferPtr(underlyingType)
+	// Use deferPtr to handle pointers in the aliased type
+	rhsType, pointerDepth := r.deferPtr(rhsType)
 
-	// Resolve the underlying type
-	underlying := r.ResolveType(ctx, underlyingType)
+	// Resolve the aliased type
+	underlying := r.ResolveType(ctx, rhsType)
 	if underlying == nil {
-		r.logger.Warnf("Failed to resolve alias underlying type: %v", underlyingType)
+		r.warnf("Failed to resolve alias underlying type: %v", rhsType)
 		return nil
 	}
 
@@ -1513,17 +2015,14 @@ func (r *defaultTypeResolver) makeAlias(ctx *ScanningContext,
 	if pointerDepth > 0 {
 		ptrID := r.generateUnnamedID("pointer")
 		finalUnderlying = gstypes.NewPointer(ptrID, ptrID, underlying, pointerDepth)
-		finalUnderlying.SetGoType(types.NewPointer(underlyingType))
+		finalUnderlying.SetGoType(types.NewPointer(rhsType))
 	}
 
 	// Create alias type
-	alias := gstypes.NewAlias(id, id, finalUnderlying)
-	// Get package from the alias type's object
-	if aliasType.Obj() != nil {
-		alias.SetPackage(r.getPackageInfo(ctx, aliasType.Obj()))
-	} else {
-		alias.SetPackage(ctx.CurrentPackage())
-	}
+	obj := aliasType.Obj()
+	alias := gstypes.NewAlias(id, obj.Name(), finalUnderlying)
+	docType, _ := r.docTypes.Get(id)
+	r.setupCommonTypeFields(ctx, alias, obj, docType, nil)
 
 	// Cache and return
 	r.cache(alias)
@@ -1569,7 +2068,7 @@ func (r *defaultTypeResolver) makeInterface(ctx *ScanningContext,
 		var ok bool
 		underlying, ok = namedType.Underlying().(*types.Interface)
 		if !ok {
-			r.logger.Warnf("Failed to resolve interface underlying type: %v", namedType)
+			r.warnf("Failed to resolve interface underlying type: %v", namedType)
 			return nil
 		}
 	} else {
@@ -1579,7 +2078,20 @@ func (r *defaultTypeResolver) makeInterface(ctx *ScanningContext,
 
 	// Set loader to extract methods lazily
 	iface.SetLoader(func(t gstypes.Type) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if t.Opaque() {
+			return nil
+		}
 		loaderCtx := ctx
+		// The method set is the union of the explicit and embedded methods: a method declared
+		// explicitly or by an earlier embed (A and B both embedding io.Closer) is listed once
+		seen := make(map[string]bool, underlying.NumMethods())
+		for i := 0; i < underlying.NumExplicitMethods(); i++ {
+			seen[underlying.ExplicitMethod(i).Name()] = true
+		}
+
 		// Extract embedded interfaces
 		for i := 0; i < underlying.NumEmbeddeds(); i++ {
 			embeddedType := underlying.EmbeddedType(i)
@@ -1587,21 +2099,18 @@ func (r *defaultTypeResolver) makeInterface(ctx *ScanningContext,
 			if embeddedResolved != nil {
 				iface.AddEmbed(embeddedResolved)
 
-				// Promote methods from embedded interface using Go types to get instantiated types
-				// For instantiated generics, unwrap to get the underlying interface
-				underlyingEmbedded := embeddedType
-				if namedEmbedded, ok := embeddedType.(*types.Named); ok {
-					underlyingEmbedded = namedEmbedded.Underlying()
-				}
-
-				if embeddedIfaceType, ok := underlyingEmbedded.(*types.Interface); ok {
+				// Promote methods from embedded interface using Go types to get instantiated types.
+				// The underlying interface of an instantiated generic (Comparer[int], directly or
+				// through an alias) has its type arguments substituted in the method signatures.
+				if embeddedIfaceType, ok := embeddedType.Underlying().(*types.Interface); ok {
 					for j := 0; j < embeddedIfaceType.NumMethods(); j++ {
 						embeddedMethod := embeddedIfaceType.Method(j)
 
 						// Check if method should be exported
-						if !r.shouldExport(ctx, embeddedMethod) {
+						if !r.shouldExport(ctx, embeddedMethod) || seen[embeddedMethod.Name()] {
 							continue
 						}
+						seen[embeddedMethod.Name()] = true
 
 						sig, ok := embeddedMethod.Type().(*types.Signature)
 						if !ok {
@@ -1619,6 +2128,8 @@ func (r *defaultTypeResolver) makeInterface(ctx *ScanningContext,
 						promotedMethod.SetPackage(r.getPackageInfo(ctx, embeddedMethod))
 						promotedMethod.SetDistance(iface.Distance())
 						promotedMethod.SetPromotedFrom(embeddedResolved)
+						// The doc comment is on the declaration, maybe in an interface embedded deeper
+						promotedMethod.SetDocOwner(declaringInterface(embeddedMethod))
 						promotedMethod.SetStructure(sig.String())
 
 						// Process signature
@@ -1670,10 +2181,16 @@ func (r *defaultTypeResolver) makeInterface(ctx *ScanningContext,
 			}
 			// Set object and doc
 			m.SetObject(method)
+			m.SetPosition(r.positionOf(method))
 			methods = append(methods, m)
 		}
 		iface.AddMethods(methods...)
 
+		// General interfaces (interface{ ~int | ~int64 | Signed }) also restrict the types
+		if !underlying.IsMethodSet() {
+			iface.SetTypeSet(r.makeTypeSet(loaderCtx, underlying))
+		}
+
 		return nil
 	})
 
@@ -1683,6 +2200,17 @@ func (r *defaultTypeResolver) makeInterface(ctx *ScanningContext,
 	return iface
 }
 
+// declaringInterface returns the name of the named interface declaring the interface method
+// fn, empty for methods of anonymous interfaces
+func declaringInterface(fn *types.Func) string {
+	if recv := fn.Signature().Recv(); recv != nil {
+		if named, ok := recv.Type().(*types.Named); ok {
+			return named.Obj().Name()
+		}
+	}
+	return ""
+}
+
 // makeStruct creates a Struct type
 func (r *defaultTypeResolver) makeStruct(ctx *ScanningContext,
 	id string,
@@ -1692,12 +2220,17 @@ func (r *defaultTypeResolver) makeStruct(ctx *ScanningContext,
 	docType *doc.Type,
 ) *gstypes.Struct {
 	// Determine ID and name based on whether this is a named or unnamed struct
-	var typeID, name string
+	var typeID, name, anonKey string
 	if obj != nil {
 		// Named struct: use provided id
 		typeID = id
 		name = obj.Name()
 	} else {
+		// Identical anonymous structs share one type
+		anonKey = r.structKey(structType)
+		if existing, ok := r.anonStructs.Get(anonKey); ok {
+			return existing
+		}
 		// Unnamed/anonymous struct: generate ID
 		typeID = r.generateUnnamedID("struct")
 		name = typeID
@@ -1721,7 +2254,7 @@ func (r *defaultTypeResolver) makeStruct(ctx *ScanningContext,
 		var ok bool
 		underlying, ok = namedType.Underlying().(*types.Struct)
 		if !ok {
-			r.logger.Warnf("Failed to resolve struct underlying type: %v", namedType)
+			r.warnf("Failed to resolve struct underlying type: %v", namedType)
 			return nil
 		}
 	} else {
@@ -1731,6 +2264,12 @@ func (r *defaultTypeResolver) makeStruct(ctx *ScanningContext,
 
 	// Set loader to extract fields and methods lazily
 	strct.SetLoader(func(t gstypes.Type) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if t.Opaque() {
+			return nil
+		}
 		// Set resolving package context for nested type resolution
 		// Create a context with resolving package set for nested type resolution
 		loaderCtx := ctx
@@ -1740,6 +2279,12 @@ func (r *defaultTypeResolver) makeStruct(ctx *ScanningContext,
 
 		// Extract fields if needed
 		if r.config.ScanMode.Has(ScanModeFields) {
+			// Fields declared in the struct (embeds included) shadow promoted fields with the same name
+			declared := make(map[string]bool, underlying.NumFields())
+			for i := 0; i < underlying.NumFields(); i++ {
+				declared[underlying.Field(i).Name()] = true
+			}
+
 			for i := 0; i < underlying.NumFields(); i++ {
 				field := underlying.Field(i)
 
@@ -1752,8 +2297,8 @@ func (r *defaultTypeResolver) makeStruct(ctx *ScanningContext,
 
 				// For unnamed field types, set package to struct's package (except basic types)
 				if !fieldTypeResolved.IsNamed() {
-					// Skip basic types - they are predeclared and have no package
-					if _, isBasic := fieldTypeResolved.(*gstypes.Basic); !isBasic {
+					// Skip predeclared types - they have no package
+					if !isPredeclared(fieldTypeResolved) {
 						fieldTypeResolved.SetPackage(strct.Package())
 					}
 				}
@@ -1771,95 +2316,15 @@ func (r *defaultTypeResolver) makeStruct(ctx *ScanningContext,
 				// If this is an embedded field, track it separately and promote fields/methods
 				if field.Embedded() {
 					// Add to embeds list instead of fields
+					// Fields are walked in declaration order, so embeds keep the source order
 					strct.AddEmbed(finalFieldType)
 
-					// For embedded types, extract fields/methods from the Go type to get instantiated types
-					var embeddedGoType = fieldType
-
-					// Get the underlying struct type from Go
-					var embeddedStructType *types.Struct
-					if named, ok := embeddedGoType.(*types.Named); ok {
-						if st, ok := named.Underlying().(*types.Struct); ok {
-							embeddedStructType = st
-						}
-					} else if st, ok := embeddedGoType.(*types.Struct); ok {
-						embeddedStructType = st
-					}
-
-					if embeddedStructType != nil {
-						// Promote fields from the embedded struct using the Go type
-						for j := 0; j < embeddedStructType.NumFields(); j++ {
-							embeddedField := embeddedStructType.Field(j)
-
-							// Skip if this is itself an embedded field
-							if embeddedField.Embedded() {
-								continue
-							}
-
-							// Resolve the field type from Go
-							embeddedFieldType, embeddedPointerDepth := r.deferPtr(embeddedField.Type())
-							embeddedFieldTypeResolved := r.ResolveType(loaderCtx, embeddedFieldType)
-							if embeddedFieldTypeResolved == nil {
-								continue
-							}
-
-							// Create pointer wrapper if needed
-							var finalEmbeddedFieldType = embeddedFieldTypeResolved
-							if embeddedPointerDepth > 0 {
-								ptrID := r.generateUnnamedID("pointer")
-								finalEmbeddedFieldType = gstypes.NewPointer(ptrID, ptrID, embeddedFieldTypeResolved, embeddedPointerDepth)
-							}
-
-							promotedFieldID := id + "#" + embeddedField.Name()
-							promotedField := gstypes.NewField(promotedFieldID, embeddedField.Name(), finalEmbeddedFieldType, embeddedStructType.Tag(j), false, strct)
-							promotedField.SetDistance(strct.Distance())
-							promotedField.SetPromotedFrom(finalFieldType)
-							strct.AddField(promotedField)
-						}
-
-						// Promote methods from the embedded type using Go types
-						if namedEmbedded, ok := embeddedGoType.(*types.Named); ok {
-							for k := 0; k < namedEmbedded.NumMethods(); k++ {
-								embeddedMethod := namedEmbedded.Method(k)
-
-								// Check if method should be exported
-								if !r.shouldExport(ctx, embeddedMethod) {
-									continue
-								}
-
-								sig, ok := embeddedMethod.Type().(*types.Signature)
-								if !ok {
-									continue
-								}
-
-								// Create promoted method
-								promotedMethodID := id + "#" + embeddedMethod.Name()
-								isPointerReceiver := false
-								if sig.Recv() != nil {
-									_, isPointerReceiver = sig.Recv().Type().(*types.Pointer)
-								}
-								promotedMethod := gstypes.NewMethod(
-									promotedMethodID,
-									embeddedMethod.Name(),
-									strct,
-									isPointerReceiver,
-								)
-								promotedMethod.SetPackage(r.getPackageInfo(ctx, embeddedMethod))
-								promotedMethod.SetDistance(strct.Distance())
-
-								// Process signature
-								parameters, results := r.processSignature(ctx, sig, strct.Package())
-								for _, p := range parameters {
-									promotedMethod.AddParameter(p)
-								}
-								for _, res := range results {
-									promotedMethod.AddResult(res)
-								}
-
-								strct.AddMethods(promotedMethod)
-							}
-						}
+					// Promote its fields and methods
+					promotedFields, promotedMethods := r.promoteEmbedded(ctx, loaderCtx, strct, id, fieldType, finalFieldType, declared)
+					for _, f := range promotedFields {
+						strct.AddField(f)
 					}
+					strct.AddMethods(promotedMethods...)
 				} else {
 					// Regular field (not embedded)
 					fieldID := typeID + "#" + field.Name()
@@ -1867,7 +2332,12 @@ func (r *defaultTypeResolver) makeStruct(ctx *ScanningContext,
 					f.SetPackage(strct.Package())
 					f.SetDistance(strct.Distance())
 					f.SetObject(field)
-					strct.AddField(f)
+					f.SetPosition(r.positionOf(field))
+					f.SetIndex(i)
+					r.setBitWidth(f)
+					if r.keepField(f) {
+						strct.AddField(f)
+					}
 				}
 			}
 		}
@@ -1882,53 +2352,304 @@ func (r *defaultTypeResolver) makeStruct(ctx *ScanningContext,
 			strct.AddMethods(methods...)
 		}
 
+		if r.config.ScanMode.Has(ScanModeFunctions) && namedType != nil && docType != nil {
+			strct.SetConstructors(r.constructors(ctx, namedType, docType))
+		}
+
 		return nil
 	})
 
+	if anonKey != "" {
+		if existing, loaded := r.anonStructs.GetOrSet(anonKey, strct); loaded {
+			// The same shape was resolved concurrently from another place
+			return existing
+		}
+	}
+
 	// Register in cache after loader is set so concurrent loads see the loader
 	r.cache(strct)
 
 	return strct
 }
 
-// makeEnum creates an Enum type from a named type with associated constants
-// func (r *defaultTypeResolver) makeEnum(
-// 	id string,
-// 	basicType *types.Basic,
-// 	namedType *types.Named,
-// 	obj types.Object,
-// 	// docType *doc.Type,
-// 	// forceKind types.TypeKind,
-// ) *types.Enum {
-// 	if namedType == nil {
-// 		r.logger.Warn("Cannot create enum from unnamed type")
-// 		return nil
-// 	}
-
-// 	// Get the underlying basic type
-// 	basicTypeName := basicType.String()
-// 	cachedBasic, exists := r.basicTypes[basicTypeName]
-// 	if !exists {
-// 		// Create cached basic if missing
-// 		cachedBasic = types.NewBasic(basicTypeName, basicTypeName)
-// 		r.basicTypes[basicTypeName] = cachedBasic
-// 	}
-
-// 	// Create enum type
-// 	enum := types.NewEnum(id, obj.Name(), cachedBasic)
-// 	enum.SetPackage(r.getPackageInfo(ctx, obj))
-
-// 	// Set loader to extract enum values lazily
-// 	enum.SetLoader(func(t types.Type) error {
-// 		// Enum values will be added by ProcessPackage when it encounters constants
-// 		// associated with this type via docType.Consts
-// 		return nil
-// 	})
-
-// 	// Cache and return
-// 	r.cache(enum)
-// 	return enum
-// }
+// promoteEmbedded returns the fields and methods parent gets from its embedded field of Go
+// type embedded (embedType once resolved), leaving out the fields shadowed by the declared
+// ones. They're resolved from the Go types, so an instance gets them with its type arguments
+// substituted. Promoted ids are prefix#name.
+func (r *defaultTypeResolver) promoteEmbedded(ctx, loaderCtx *ScanningContext,
+	parent gstypes.Type,
+	prefix string,
+	embedded types.Type,
+	embedType gstypes.Type,
+	declared map[string]bool,
+) ([]*gstypes.Field, []*gstypes.Method) {
+	var fields []*gstypes.Field
+	var methods []*gstypes.Method
+
+	// Get the underlying struct type from Go
+	var embeddedStructType *types.Struct
+	if named, ok := embedded.(*types.Named); ok {
+		if st, ok := named.Underlying().(*types.Struct); ok {
+			embeddedStructType = st
+		}
+	} else if st, ok := embedded.(*types.Struct); ok {
+		embeddedStructType = st
+	}
+	if embeddedStructType == nil {
+		return nil, nil
+	}
+
+	// Promote fields from the embedded struct using the Go type
+	for j := 0; j < embeddedStructType.NumFields(); j++ {
+		embeddedField := embeddedStructType.Field(j)
+
+		// Skip if this is itself an embedded field or it's shadowed by the outer struct
+		if embeddedField.Embedded() || declared[embeddedField.Name()] {
+			continue
+		}
+
+		// Exported fields are promoted even from unexported embeds, same as methods
+		if !r.shouldExport(ctx, embeddedField) {
+			continue
+		}
+
+		// Resolve the field type from Go
+		embeddedFieldType, embeddedPointerDepth := r.deferPtr(embeddedField.Type())
+		embeddedFieldTypeResolved := r.ResolveType(loaderCtx, embeddedFieldType)
+		if embeddedFieldTypeResolved == nil {
+			continue
+		}
+
+		// Create pointer wrapper if needed
+		var finalEmbeddedFieldType = embeddedFieldTypeResolved
+		if embeddedPointerDepth > 0 {
+			ptrID := r.generateUnnamedID("pointer")
+			finalEmbeddedFieldType = gstypes.NewPointer(ptrID, ptrID, embeddedFieldTypeResolved, embeddedPointerDepth)
+		}
+
+		promotedField := gstypes.NewField(prefix+"#"+embeddedField.Name(), embeddedField.Name(), finalEmbeddedFieldType, embeddedStructType.Tag(j), false, parent)
+		promotedField.SetDistance(parent.Distance())
+		promotedField.SetPromotedFrom(embedType)
+		promotedField.SetIndex(j)
+		r.setBitWidth(promotedField)
+		if r.keepField(promotedField) {
+			fields = append(fields, promotedField)
+		}
+	}
+
+	// Promote methods from the embedded type using Go types
+	namedEmbedded, ok := embedded.(*types.Named)
+	if !ok {
+		return fields, nil
+	}
+	for k := 0; k < namedEmbedded.NumMethods(); k++ {
+		embeddedMethod := namedEmbedded.Method(k)
+
+		// Check if method should be exported
+		if !r.shouldExport(ctx, embeddedMethod) || r.isMethodExcluded(parent, embeddedMethod.Name()) {
+			continue
+		}
+
+		sig, ok := embeddedMethod.Type().(*types.Signature)
+		if !ok {
+			continue
+		}
+
+		// Create promoted method
+		isPointerReceiver := false
+		if sig.Recv() != nil {
+			_, isPointerReceiver = sig.Recv().Type().(*types.Pointer)
+		}
+		promotedMethod := gstypes.NewMethod(
+			prefix+"#"+embeddedMethod.Name(),
+			embeddedMethod.Name(),
+			parent,
+			isPointerReceiver,
+		)
+		promotedMethod.SetPackage(r.getPackageInfo(ctx, embeddedMethod))
+		promotedMethod.SetDistance(parent.Distance())
+		promotedMethod.SetPromotedFrom(embedType)
+
+		// Process signature
+		parameters, results := r.processSignature(ctx, sig, parent.Package())
+		for _, p := range parameters {
+			promotedMethod.AddParameter(p)
+		}
+		for _, res := range results {
+			promotedMethod.AddResult(res)
+		}
+
+		methods = append(methods, promotedMethod)
+	}
+	return fields, methods
+}
+
+// constructors returns the ids of the constructors of a named struct among the factory
+// functions go/doc associates with it (functions returning the type or a pointer to it):
+// the ones named New<Type>..., sorted by name
+func (r *defaultTypeResolver) constructors(ctx *ScanningContext, namedType *types.Named, docType *doc.Type) []string {
+	obj := namedType.Obj()
+	prefix := "New" + obj.Name()
+	var ids []string
+	for _, docFunc := range docType.Funcs {
+		if !strings.HasPrefix(docFunc.Name, prefix) {
+			continue
+		}
+		fn, ok := obj.Pkg().Scope().Lookup(docFunc.Name).(*types.Func)
+		if !ok || !r.shouldExport(ctx, fn) || !returnsType(fn, namedType) {
+			continue
+		}
+		ids = append(ids, obj.Pkg().Path()+"."+fn.Name())
+	}
+	return ids
+}
+
+// returnsType reports whether the first result of fn is named or a pointer to it, an
+// instantiation of a generic named (NewBox[T any]() *Box[T]) included
+func returnsType(fn *types.Func, named *types.Named) bool {
+	results := fn.Signature().Results()
+	if results.Len() == 0 {
+		return false
+	}
+	t := results.At(0).Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	result, ok := t.(*types.Named)
+	return ok && result.Origin() == named.Origin()
+}
+
+// makeEnum creates an Enum for a named basic type with constants (ScanModeEnums). Its values
+// are added as the constants are parsed, see parseValue.
+func (r *defaultTypeResolver) makeEnum(ctx *ScanningContext,
+	id string,
+	basicType *types.Basic,
+	namedType *types.Named,
+	obj types.Object,
+	docType *doc.Type,
+) *gstypes.Enum {
+	basicTypeName := basicType.String()
+	cachedBasic, exists := r.basicTypes.Get(basicTypeName)
+	if !exists {
+		cachedBasic = gstypes.NewBasic(basicTypeName, basicTypeName)
+		r.basicTypes.Set(basicTypeName, cachedBasic)
+	}
+
+	enum := gstypes.NewEnum(id, obj.Name(), cachedBasic)
+	r.setupCommonTypeFields(ctx, enum, obj, nil, namedType)
+	if docType != nil {
+		enum.SetIotaExpr(iotaExpression(docType.Consts))
+	}
+	enum.SetLoader(func(t gstypes.Type) error {
+		if r.config.ScanMode.Has(ScanModeMethods) {
+			m, err := r.extractMethods(ctx, namedType, t)
+			if err != nil {
+				return err
+			}
+			t.AddMethods(m...)
+		}
+		return nil
+	})
+
+	r.cache(enum)
+	return enum
+}
+
+// hasConstants reports whether the package declaring named declares constants of its type
+func (r *defaultTypeResolver) hasConstants(named *types.Named) bool {
+	pkg := named.Obj().Pkg()
+	if pkg == nil {
+		return false
+	}
+	typed, ok := r.enumTypes.Get(pkg)
+	if !ok {
+		typed = map[*types.TypeName]bool{}
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			c, ok := scope.Lookup(name).(*types.Const)
+			if !ok {
+				continue
+			}
+			if n, ok := c.Type().(*types.Named); ok && n.Obj().Pkg() == pkg {
+				typed[n.Obj()] = true
+			}
+		}
+		r.enumTypes.Set(pkg, typed)
+	}
+	return typed[named.Obj()]
+}
+
+// sortEnumValues sorts the values of enum in declaration order, whatever the order the
+// const blocks were parsed in
+func sortEnumValues(enum *gstypes.Enum) {
+	slices.SortStableFunc(enum.Values(), func(a, b *gstypes.Value) int {
+		return cmp.Compare(a.Object().Pos(), b.Object().Pos())
+	})
+}
+
+// iotaExpression returns the value expression that starts the first iota based const block
+// among consts (e.g. "iota", "iota + 1", "1 << iota"), or "" if no block uses iota.
+// Within a block only the first spec with explicit values is considered (a leading
+// `_ = iota` yields "iota"); blocks after the first iota based one are ignored.
+func iotaExpression(consts []*doc.Value) string {
+	for _, constDecl := range consts {
+		if constDecl.Decl == nil {
+			continue
+		}
+		for _, spec := range constDecl.Decl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok || len(valueSpec.Values) == 0 {
+				continue
+			}
+			if usesIota(valueSpec.Values[0]) {
+				return types.ExprString(valueSpec.Values[0])
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// constExpr returns the expression of the constant name declared by decl, the last explicit
+// one of the block for implicit specs, and the value of iota in it (the index of the spec),
+// -1 when it doesn't use iota
+func constExpr(decl *ast.GenDecl, name string) (string, int) {
+	var last []ast.Expr
+	for i, spec := range decl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if len(valueSpec.Values) > 0 {
+			last = valueSpec.Values
+		}
+		for j, ident := range valueSpec.Names {
+			if ident.Name != name {
+				continue
+			}
+			if j >= len(last) {
+				return "", -1
+			}
+			if usesIota(last[j]) {
+				return types.ExprString(last[j]), i
+			}
+			return types.ExprString(last[j]), -1
+		}
+	}
+	return "", -1
+}
+
+// usesIota reports whether expr references the predeclared iota
+func usesIota(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == "iota" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
 
 // parseValue creates a Value (constant or variable)
 func (r *defaultTypeResolver) parseValue(ctx *ScanningContext, obj types.Object, docValue *doc.Value) gstypes.Type {
@@ -1975,17 +2696,34 @@ func (r *defaultTypeResolver) parseValue(ctx *ScanningContext, obj types.Object,
 	switch v := obj.(type) {
 	case *types.Const:
 		value = gstypes.NewConstant(id, obj.Name(), finalValueType, v.Val())
+		if docValue != nil && docValue.Decl != nil {
+			value.SetExpr(constExpr(docValue.Decl, obj.Name()))
+		}
+		if named, ok := v.Type().(*types.Named); ok {
+			if labels, ok := r.enumLabels.Get(named.Obj()); ok {
+				value.SetLabel(labels[v.Val().ExactString()])
+			}
+			// By type, not by const block: go/doc only attaches the blocks mostly made of
+			// constants of the type to it
+			if named.Obj().Pkg() == v.Pkg() {
+				value.SetParent(valueTypeResolved)
+			}
+		}
 	case *types.Var:
 		value = gstypes.NewVariable(id, obj.Name(), finalValueType)
+		if r.config.IncludeInitializers && docValue != nil && docValue.Decl != nil {
+			r.setInitializer(value, obj, docValue.Decl)
+		}
 
 	default:
-		r.logger.Warnf("Unsupported value type: %T", obj)
+		r.warnf("Unsupported value type: %T", obj)
 		return nil
 	}
 
 	if value != nil {
 		value.SetPackage(r.getPackageInfo(ctx, obj))
 		value.SetObject(obj)
+		value.SetPosition(r.positionOf(obj))
 
 		// Set documentation if available
 		if docValue != nil && docValue.Doc != "" {
@@ -1995,16 +2733,57 @@ func (r *defaultTypeResolver) parseValue(ctx *ScanningContext, obj types.Object,
 		}
 
 		r.values.Set(id, value)
+		if enum, ok := value.Parent().(*gstypes.Enum); ok {
+			enum.AddValue(value)
+		}
 
 		// Load the value to trigger comment loading
 		if err := value.Load(); err != nil {
-			r.logger.Warnf("Failed to load value %s: %v", id, err)
+			r.warnf("Failed to load value %s: %v", id, err)
 		}
 	}
 
 	return value
 }
 
+// setInitializer sets the initializer of the variable obj declared by decl as the Expr of
+// value, and its value when the type checker folds it to a constant (Config.IncludeInitializers)
+func (r *defaultTypeResolver) setInitializer(value *gstypes.Value, obj types.Object, decl *ast.GenDecl) {
+	pkg := r.getPackageForObj(obj)
+	if pkg == nil {
+		return
+	}
+	for _, spec := range decl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok || len(valueSpec.Values) == 0 {
+			continue
+		}
+		for i, ident := range valueSpec.Names {
+			if ident.Name != obj.Name() {
+				continue
+			}
+			// var a, b = f() shares the call, and has no constant value
+			expr := valueSpec.Values[0]
+			single := len(valueSpec.Values) == len(valueSpec.Names)
+			if single {
+				expr = valueSpec.Values[i]
+			}
+			var sb strings.Builder
+			if err := format.Node(&sb, pkg.Fset, expr); err != nil {
+				r.warnf("Failed to print the initializer of %s: %v", value.Id(), err)
+				return
+			}
+			value.SetExpr(sb.String(), -1)
+			if single && pkg.TypesInfo != nil {
+				if tv, ok := pkg.TypesInfo.Types[expr]; ok && tv.Value != nil {
+					value.SetValue(tv.Value)
+				}
+			}
+			return
+		}
+	}
+}
+
 // makeTypeParameter creates a TypeParameter type
 func (r *defaultTypeResolver) makeTypeParameter(ctx *ScanningContext, id string, typeParam *types.TypeParam) *gstypes.TypeParameter {
 	// Get the constraint type
@@ -2012,6 +2791,8 @@ func (r *defaultTypeResolver) makeTypeParameter(ctx *ScanningContext, id string,
 
 	// For type constraints like `M map[string][]int` or `S struct{ Name string }`,
 	// Go wraps them in an unnamed interface. We need to extract the embedded type.
+	// Intersections (interface{ A; B }, interface{ A; ~int | ~string; M() }) keep the
+	// unnamed interface, listing every embedded element and the merged method set.
 	if iface, ok := constraintType.(*types.Interface); ok && iface.NumEmbeddeds() == 1 && iface.NumExplicitMethods() == 0 {
 		// This is an interface with a single embedded type and no methods
 		// Extract the embedded type as the actual constraint
@@ -2022,10 +2803,11 @@ func (r *defaultTypeResolver) makeTypeParameter(ctx *ScanningContext, id string,
 	// Resolve the constraint - this will create the full structure
 	constraint := r.ResolveType(ctx, constraintType)
 
-	// Force load the constraint to ensure its structure is populated
-	if constraint != nil {
+	// Force load the constraint to ensure its structure is populated. Instances are left to
+	// TypeParameter.Load: loading Comparer[T] in func Max[T Comparer[T]] resolves T again
+	if _, instance := constraint.(*gstypes.InstantiatedGeneric); constraint != nil && !instance {
 		if err := constraint.Load(); err != nil {
-			r.logger.Warnf("Failed to load constraint for type parameter %s: %v", id, err)
+			r.warnf("Failed to load constraint for type parameter %s: %v", id, err)
 		}
 	}
 
@@ -2045,7 +2827,7 @@ func (r *defaultTypeResolver) makeUnion(ctx *ScanningContext, id string, union *
 		term := union.Term(i)
 		termType := r.ResolveType(ctx, term.Type())
 		if termType == nil {
-			r.logger.Warnf("Failed to resolve union term type: %v", term.Type())
+			r.warnf("Failed to resolve union term type: %v", term.Type())
 			continue
 		}
 		terms[i] = gstypes.NewUnionTerm(termType, term.Tilde())
@@ -2059,7 +2841,7 @@ func (r *defaultTypeResolver) makeUnion(ctx *ScanningContext, id string, union *
 }
 
 // makeInstantiatedGeneric creates an InstantiatedGeneric type
-func (r *defaultTypeResolver) makeInstantiatedGeneric(id string, origin gstypes.Type, typeArgs []gstypes.TypeArgument) *gstypes.InstantiatedGeneric {
+func (r *defaultTypeResolver) makeInstantiatedGeneric(ctx *ScanningContext, id string, named *types.Named, origin gstypes.Type, typeArgs []gstypes.TypeArgument) *gstypes.InstantiatedGeneric {
 	// Extract simple name from id (last part after .)
 	name := id
 	if lastDot := strings.LastIndex(id, "."); lastDot >= 0 {
@@ -2068,12 +2850,135 @@ func (r *defaultTypeResolver) makeInstantiatedGeneric(id string, origin gstypes.
 
 	ig := gstypes.NewInstantiatedGeneric(id, name, origin, typeArgs)
 	ig.SetPackage(origin.Package())
+	ig.SetDistance(origin.Distance())
+	ig.SetOpaque(origin.Opaque())
+
+	// Set loader to resolve the members with the type arguments substituted
+	ig.SetLoader(func(t gstypes.Type) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if t.Opaque() {
+			return nil
+		}
+		return r.loadInstantiatedMembers(ctx, ig, named)
+	})
 
 	// Cache instantiated generics
 	r.cache(ig)
 	return ig
 }
 
+// loadInstantiatedMembers instantiates the origin of named with its type arguments using
+// go/types and resolves the fields and methods of the instance, so their types are the
+// concrete ones (Get() T of Container[int] returns int)
+func (r *defaultTypeResolver) loadInstantiatedMembers(ctx *ScanningContext, ig *gstypes.InstantiatedGeneric, named *types.Named) error {
+	args := make([]types.Type, named.TypeArgs().Len())
+	for i := range args {
+		args[i] = named.TypeArgs().At(i)
+	}
+	instantiated, err := types.Instantiate(nil, named.Origin(), args, false)
+	if err != nil {
+		return fmt.Errorf("failed to instantiate %s: %w", ig.Id(), err)
+	}
+	instance, ok := instantiated.(*types.Named)
+	if !ok {
+		return nil
+	}
+
+	loaderCtx := ctx
+	if ig.Package() != nil {
+		loaderCtx = ctx.WithResolvingPackage(ig.Package().Path())
+	}
+
+	var fields []*gstypes.Field
+	var methods, promoted []*gstypes.Method
+	switch underlying := instance.Underlying().(type) {
+	case *types.Struct:
+		if !r.config.ScanMode.Has(ScanModeFields) {
+			break
+		}
+		declared := make(map[string]bool, underlying.NumFields())
+		for i := 0; i < underlying.NumFields(); i++ {
+			declared[underlying.Field(i).Name()] = true
+		}
+		var embeds []gstypes.Type
+		for i := 0; i < underlying.NumFields(); i++ {
+			field := underlying.Field(i)
+			fieldType, pointerDepth := r.deferPtr(field.Type())
+			fieldTypeResolved := r.ResolveType(loaderCtx, fieldType)
+			if fieldTypeResolved == nil {
+				continue
+			}
+			if pointerDepth > 0 {
+				ptrID := r.generateUnnamedID("pointer")
+				ptr := gstypes.NewPointer(ptrID, ptrID, fieldTypeResolved, pointerDepth)
+				ptr.SetGoType(types.NewPointer(fieldType))
+				ptr.SetPackage(ig.Package())
+				fieldTypeResolved = ptr
+			}
+			if field.Embedded() {
+				embeds = append(embeds, fieldTypeResolved)
+				promotedFields, promotedMethods := r.promoteEmbedded(ctx, loaderCtx, ig, ig.Id(), fieldType, fieldTypeResolved, declared)
+				fields = append(fields, promotedFields...)
+				promoted = append(promoted, promotedMethods...)
+				continue
+			}
+			f := gstypes.NewField(ig.Id()+"#"+field.Name(), field.Name(), fieldTypeResolved, underlying.Tag(i), false, ig)
+			f.SetPackage(ig.Package())
+			f.SetDistance(ig.Distance())
+			f.SetObject(field.Origin())
+			f.SetPosition(r.positionOf(field.Origin()))
+			f.SetIndex(i)
+			r.setBitWidth(f)
+			if r.keepField(f) {
+				fields = append(fields, f)
+			}
+		}
+		ig.SetEmbeds(embeds)
+	case *types.Interface:
+		// The complete method set, embedded interfaces included
+		for i := 0; i < underlying.NumMethods(); i++ {
+			method := underlying.Method(i)
+			if !r.shouldExport(ctx, method) {
+				continue
+			}
+			sig, ok := method.Type().(*types.Signature)
+			if !ok {
+				continue
+			}
+			m := gstypes.NewMethod(ig.Id()+"#"+method.Name(), method.Name(), ig, false)
+			m.SetPackage(r.getPackageInfo(ctx, method))
+			m.SetDistance(ig.Distance())
+			m.SetStructure(sig.String())
+			parameters, results := r.processSignature(loaderCtx, sig, ig.Package())
+			for _, p := range parameters {
+				m.AddParameter(p)
+			}
+			for _, res := range results {
+				m.AddResult(res)
+			}
+			m.SetObject(method.Origin())
+			m.SetPosition(r.positionOf(method.Origin()))
+			methods = append(methods, m)
+		}
+		ig.SetMembers(fields, methods)
+		return nil
+	default:
+		// Slices, maps, channels, pointers, functions and basic types: their structure
+		ig.SetUnderlying(r.ResolveType(loaderCtx, underlying))
+	}
+
+	if r.config.ScanMode.Has(ScanModeMethods) {
+		methods, err = r.extractMethods(loaderCtx, instance, ig)
+		if err != nil {
+			return err
+		}
+	}
+	ig.SetMembers(fields, append(promoted, methods...))
+	return nil
+}
+
 // extractTypeArgumentsWithParams extracts type arguments with parameter names and indices
 func (r *defaultTypeResolver) extractTypeArgumentsWithParams(ctx *ScanningContext, originType *types.Named, typeList *types.TypeList) []gstypes.TypeArgument {
 	typeArgs := make([]gstypes.TypeArgument, typeList.Len())
@@ -2215,8 +3120,8 @@ func (r *defaultTypeResolver) extractComments(pkgInfo *gstypes.Package, pkg *pac
 		if file.Doc != nil {
 			pkgLevelComment := strings.TrimSpace(file.Doc.Text())
 			if pkgLevelComment != "" {
-				pkgInfo.AddComments(gstypes.PackageCommentID, []gstypes.Comment{gstypes.NewComment(pkgLevelComment, gstypes.CommentPlacementPackage)})
-				fileInfo.SetComments([]gstypes.Comment{gstypes.NewComment(pkgLevelComment, gstypes.CommentPlacementPackage)})
+				pkgInfo.AddComments(gstypes.PackageCommentID, []gstypes.Comment{r.newComment(pkg, file.Doc, pkgLevelComment, gstypes.CommentPlacementPackage)})
+				fileInfo.SetComments([]gstypes.Comment{r.newComment(pkg, file.Doc, pkgLevelComment, gstypes.CommentPlacementPackage)})
 			}
 		}
 
@@ -2229,6 +3134,9 @@ func (r *defaultTypeResolver) extractComments(pkgInfo *gstypes.Package, pkg *pac
 		// Add file to package
 		pkgInfo.AddFile(fileInfo)
 
+		// Extract //go:generate directives
+		r.extractGenerateDirectives(pkgInfo, file)
+
 		// Extract declarations
 		for _, decl := range file.Decls {
 			switch d := decl.(type) {
@@ -2238,20 +3146,20 @@ func (r *defaultTypeResolver) extractComments(pkgInfo *gstypes.Package, pkg *pac
 					switch s := spec.(type) {
 					case *ast.ValueSpec:
 						// Constants and variables
-						comment := r.extractComment(s.Doc, s.Comment, d.Doc)
+						comment := r.extractComment(pkg, s.Doc, s.Comment, d.Doc)
 						for _, name := range s.Names {
 							pkgInfo.AddComments(name.Name, comment)
 						}
 					case *ast.TypeSpec:
 						// Type declarations
-						comment := r.extractComment(s.Doc, s.Comment, d.Doc)
+						comment := r.extractComment(pkg, s.Doc, s.Comment, d.Doc)
 
 						pkgInfo.AddComments(s.Name.Name, comment)
 
 						// Extract struct field comments
 						if structType, ok := s.Type.(*ast.StructType); ok {
 							for _, field := range structType.Fields.List {
-								fieldComment := r.extractComment(field.Doc, field.Comment, nil)
+								fieldComment := r.extractComment(pkg, field.Doc, field.Comment, nil)
 								for _, fieldName := range field.Names {
 									pkgInfo.AddComments(s.Name.Name+"."+fieldName.Name, fieldComment)
 								}
@@ -2261,7 +3169,7 @@ func (r *defaultTypeResolver) extractComments(pkgInfo *gstypes.Package, pkg *pac
 						// Extract interface method comments
 						if interfaceType, ok := s.Type.(*ast.InterfaceType); ok {
 							for _, method := range interfaceType.Methods.List {
-								methodComment := r.extractComment(method.Doc, method.Comment, nil)
+								methodComment := r.extractComment(pkg, method.Doc, method.Comment, nil)
 								for _, methodName := range method.Names {
 									pkgInfo.AddComments(s.Name.Name+"."+methodName.Name, methodComment)
 								}
@@ -2287,7 +3195,7 @@ func (r *defaultTypeResolver) extractComments(pkgInfo *gstypes.Package, pkg *pac
 				}
 				comment = strings.TrimSpace(comment)
 				if comment != "" {
-					pkgInfo.AddComments(funcName, []gstypes.Comment{gstypes.NewComment(comment, gstypes.CommentPlacementAbove)})
+					pkgInfo.AddComments(funcName, []gstypes.Comment{r.newComment(pkg, d.Doc, comment, gstypes.CommentPlacementAbove)})
 				}
 			}
 		}
@@ -2296,32 +3204,188 @@ func (r *defaultTypeResolver) extractComments(pkgInfo *gstypes.Package, pkg *pac
 	return nil
 }
 
+// extractBodyFlags records which functions and methods of pkg use unsafe, reflect or cgo,
+// from the objects their bodies refer to
+func (r *defaultTypeResolver) extractBodyFlags(pkg *packages.Package) {
+	if pkg.TypesInfo == nil {
+		return
+	}
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body == nil {
+				continue
+			}
+			fn, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+
+			var flags gstypes.BodyFlags
+			ast.Inspect(fd.Body, func(n ast.Node) bool {
+				ident, ok := n.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				var path string
+				switch obj := pkg.TypesInfo.Uses[ident].(type) {
+				case nil:
+					return true
+				case *types.PkgName:
+					path = obj.Imported().Path()
+				default:
+					if obj.Pkg() != nil {
+						path = obj.Pkg().Path()
+					}
+				}
+				switch {
+				case path == "unsafe":
+					flags.UsesUnsafe = true
+				case path == "reflect":
+					flags.UsesReflect = true
+				case path == "C" || strings.HasPrefix(ident.Name, "_Cfunc_"):
+					flags.UsesCGo = true
+				}
+				return true
+			})
+			r.bodyFlags.Set(fn, flags)
+		}
+	}
+}
+
+// extractLinkage records the functions and methods of pkg declared without a body, and the
+// functions named by a //go:linkname directive. A bodyless function is implemented in
+// assembly unless a directive links it to a symbol defined elsewhere.
+func (r *defaultTypeResolver) extractLinkage(pkg *packages.Package) {
+	if pkg.TypesInfo == nil {
+		return
+	}
+	for _, file := range pkg.Syntax {
+		// //go:linkname localname [importpath.name], the symbol defaults to the local one
+		linknames := map[string]string{}
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				args, ok := strings.CutPrefix(c.Text, "//go:linkname ")
+				if !ok {
+					continue
+				}
+				fields := strings.Fields(args)
+				switch len(fields) {
+				case 1:
+					linknames[fields[0]] = pkg.PkgPath + "." + fields[0]
+				case 2:
+					linknames[fields[0]] = fields[1]
+				}
+			}
+		}
+
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			fn, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			var linkage gstypes.Linkage
+			if fd.Recv == nil {
+				linkage.Linkname = linknames[fd.Name.Name]
+			}
+			linkage.Assembly = fd.Body == nil && linkage.Linkname == ""
+			if linkage != (gstypes.Linkage{}) {
+				r.linkage.Set(fn, linkage)
+			}
+		}
+	}
+}
+
+// extractInitFuncs records the positions of the init functions of pkg, which aren't part of
+// the scanned functions (they can't be referenced)
+func (r *defaultTypeResolver) extractInitFuncs(pkgInfo *gstypes.Package, pkg *packages.Package) {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil || fd.Name.Name != "init" {
+				continue
+			}
+			pos := pkg.Fset.Position(fd.Pos())
+			pkgInfo.AddInitFunc(&gstypes.Position{
+				File:   r.getModuleRelativePath(pos.Filename, pkg.PkgPath),
+				Line:   pos.Line,
+				Column: pos.Column,
+			})
+		}
+	}
+}
+
+// extractGenerateDirectives records the //go:generate directives of a file. Directives in the
+// doc comment of a type declaration are attached to that type, all of them to the package.
+func (r *defaultTypeResolver) extractGenerateDirectives(pkgInfo *gstypes.Package, file *ast.File) {
+	typeDocs := map[*ast.CommentGroup]string{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if ts.Doc != nil {
+				typeDocs[ts.Doc] = ts.Name.Name
+			}
+			// The declaration doc belongs to the type only when it declares a single one
+			if gen.Doc != nil && !gen.Lparen.IsValid() {
+				typeDocs[gen.Doc] = ts.Name.Name
+			}
+		}
+	}
+
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			command, ok := strings.CutPrefix(c.Text, "//go:generate ")
+			if !ok {
+				continue
+			}
+			pkgInfo.AddGenerateDirective(typeDocs[group], strings.TrimSpace(command))
+		}
+	}
+}
+
 // extractComment combines doc comments and inline comments
-func (r *defaultTypeResolver) extractComment(doc, comment, parentDoc *ast.CommentGroup) []gstypes.Comment {
+func (r *defaultTypeResolver) extractComment(pkg *packages.Package, doc, comment, parentDoc *ast.CommentGroup) []gstypes.Comment {
 	var parts []gstypes.Comment
 
 	// Add doc comment (above the declaration)
 	if doc != nil {
 		if text := strings.TrimSpace(doc.Text()); text != "" {
-			parts = append(parts, gstypes.NewComment(text, gstypes.CommentPlacementAbove))
+			parts = append(parts, r.newComment(pkg, doc, text, gstypes.CommentPlacementAbove))
 		}
 	} else if parentDoc != nil {
 		// Use parent doc if this spec has no doc comment of its own
 		if text := strings.TrimSpace(parentDoc.Text()); text != "" {
-			parts = append(parts, gstypes.NewComment(text, gstypes.CommentPlacementAbove))
+			parts = append(parts, r.newComment(pkg, parentDoc, text, gstypes.CommentPlacementAbove))
 		}
 	}
 
 	// Add inline comment (after the declaration)
 	if comment != nil {
 		if text := strings.TrimSpace(comment.Text()); text != "" {
-			parts = append(parts, gstypes.NewComment(text, gstypes.CommentPlacementInline))
+			parts = append(parts, r.newComment(pkg, comment, text, gstypes.CommentPlacementInline))
 		}
 	}
 
 	return parts
 }
 
+// newComment creates the comment of the group cg, positioned when Config.IncludePositions is set
+func (r *defaultTypeResolver) newComment(pkg *packages.Package, cg *ast.CommentGroup, text string, place gstypes.CommentPlacement) gstypes.Comment {
+	c := gstypes.NewComment(text, place)
+	if r.config.IncludePositions {
+		c.Position = r.position(pkg, cg.Pos(), cg.End())
+	}
+	return c
+}
+
 // getTypeName extracts the type name from an expression
 func (r *defaultTypeResolver) getTypeName(expr ast.Expr) string {
 	switch t := expr.(type) {
@@ -2,10 +2,14 @@ package scanner
 
 import (
 	"context"
+	"encoding/json"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"go/types"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/pablor21/goscanner/logger"
@@ -298,6 +302,75 @@ func TestTypeResolver_resolveSliceTypes(t *testing.T) {
 	}
 }
 
+func TestTypeResolver_multiDimensionalArrays(t *testing.T) {
+	src := `
+	package test
+
+	type Grid [3][3]int
+
+	type Matrix struct {
+		M [2][3]float64
+		S [][4]int
+		G [2]Grid
+	}
+	`
+	result := scanTestSource(t, src)
+	if err := result.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+
+	fieldTypes := func(r *ScanningResult) map[string]*gstypes.Slice {
+		t.Helper()
+		matrix, ok := r.Types.Get("test.Matrix")
+		if !ok {
+			t.Fatal("expected test.Matrix")
+		}
+		out := map[string]*gstypes.Slice{}
+		for _, f := range matrix.(*gstypes.Struct).Fields() {
+			out[f.Name()] = f.Type().(*gstypes.Slice)
+		}
+		return out
+	}
+
+	fields := fieldTypes(result)
+	for name, want := range map[string][]int64{"M": {2, 3}, "S": nil, "G": {2}} {
+		if got := fields[name].Dimensions(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s dimensions = %v, want %v", name, got, want)
+		}
+	}
+	inner := fields["M"].Elem().(*gstypes.Slice)
+	if !inner.IsArray() || inner.Len() != 3 || inner.Elem().Id() != "float64" {
+		t.Errorf("M element = %s (len %d), want [3]float64", inner.Kind(), inner.Len())
+	}
+	if got := fields["S"].Elem().(*gstypes.Slice).Dimensions(); !reflect.DeepEqual(got, []int64{4}) {
+		t.Errorf("S element dimensions = %v, want [4]", got)
+	}
+	grid, _ := result.Types.Get("test.Grid")
+	if got := grid.(*gstypes.Slice).Dimensions(); !reflect.DeepEqual(got, []int64{3, 3}) {
+		t.Errorf("Grid dimensions = %v, want [3 3]", got)
+	}
+
+	// The lengths survive serialization and the cache
+	data, err := json.Marshal(result.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"length":2,"dimensions":[2,3]`) {
+		t.Errorf("serialized M lacks its dimensions: %s", data)
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := reconstructFromCache(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fieldTypes(restored)["M"].Dimensions(); !reflect.DeepEqual(got, []int64{2, 3}) {
+		t.Errorf("restored M dimensions = %v, want [2 3]", got)
+	}
+}
+
 func TestTypeResolver_resolveMapTypes(t *testing.T) {
 	src := `
 	package test
@@ -336,7 +409,7 @@ func TestTypeResolver_resolveMapTypes(t *testing.T) {
 	}{
 		{"MyStringIntMap", gstypes.TypeKindMap, "test.MyString", "test.MyInt"},
 		{"MyIntStringMap", gstypes.TypeKindMap, "test.MyInt", "test.MyString"},
-		{"MyInterfaceMap", gstypes.TypeKindMap, "interface{}", "interface{}"},
+		{"MyInterfaceMap", gstypes.TypeKindMap, gstypes.AnyID, gstypes.AnyID},
 	}
 
 	for _, tt := range tests {
@@ -739,3 +812,705 @@ func TestTypeResolver_testMakeStruct(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeResolver_variadicParameters(t *testing.T) {
+	src := `
+	package test
+
+	type User struct{}
+
+	func Join(sep string, parts ...string) string { return "" }
+	func Save(users ...*User) {}
+	`
+
+	r, scanCtx, pkg := newTestResolver(t, src)
+
+	tests := []struct {
+		fn        string
+		wantElem  string
+		wantSlice string
+	}{
+		{"Join", "string", "[]string"},
+		{"Save", "test.User", "[]*test.User"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fn, func(t *testing.T) {
+			got := r.ResolveType(scanCtx, pkg.Scope().Lookup(tt.fn).Type())
+			fn, ok := got.(*gstypes.Function)
+			if !ok {
+				t.Fatalf("expected *Function, got %T", got)
+			}
+			params := fn.Parameters()
+			last := params[len(params)-1]
+			if !last.IsVariadic() || !fn.IsVariadic() {
+				t.Fatalf("expected a variadic last parameter")
+			}
+
+			elem := last.Type()
+			if ptr, ok := elem.(*gstypes.Pointer); ok {
+				elem = ptr.Elem()
+			}
+			if elem.Id() != tt.wantElem {
+				t.Errorf("parameter type = %s, want element type %s", elem.Id(), tt.wantElem)
+			}
+
+			slice, ok := last.SliceType().(*gstypes.Slice)
+			if !ok {
+				t.Fatalf("SliceType() = %T, want *Slice", last.SliceType())
+			}
+			if slice.Elem() != last.Type() {
+				t.Errorf("SliceType().Elem() must be the parameter type")
+			}
+			if slice.GoType() == nil || slice.GoType().String() != tt.wantSlice {
+				t.Errorf("SliceType() go type = %v, want %s", slice.GoType(), tt.wantSlice)
+			}
+
+			// The parameters before the variadic one are regular
+			for _, p := range params[:len(params)-1] {
+				if p.IsVariadic() {
+					t.Errorf("%s must not be variadic", p.Name())
+				}
+				if p.SliceType() != nil {
+					t.Errorf("%s.SliceType() = %v, must be nil for regular parameters", p.Name(), p.SliceType())
+				}
+			}
+		})
+	}
+}
+
+func TestTypeResolver_includePositions(t *testing.T) {
+	src := `package test
+
+type User struct {
+	ID int // the id
+}
+
+	type Role int
+
+// Admin is the admin role
+const Admin Role = 1
+
+func (u *User) Rename(name string) (ok bool) {
+	return true
+}
+`
+
+	result := scanTestSource(t, src)
+	user, _ := result.Types.Get("test.User")
+	if user.Position() != nil {
+		t.Errorf("positions must not be captured by default, got %v", user.Position())
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.IncludePositions = true
+	result = scanTestSourceWithConfig(t, src, cfg)
+
+	for id, want := range map[string]gstypes.Position{
+		"test.User": {File: "test/test.go", Line: 3, Column: 6, EndLine: 5},
+		"test.Role": {File: "test/test.go", Line: 7, Column: 7, EndLine: 7},
+	} {
+		typ, _ := result.Types.Get(id)
+		if got := typ.Position(); got == nil || *got != want {
+			t.Errorf("%s.Position() = %v, want %v", id, got, &want)
+		}
+	}
+
+	user, _ = result.Types.Get("test.User")
+	field := user.(*gstypes.Struct).Fields()[0]
+	rename := user.Methods()[0]
+	admin, _ := result.Values.Get("test.Admin")
+	if err := field.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := admin.Load(); err != nil {
+		t.Fatal(err)
+	}
+	for name, tc := range map[string]struct {
+		got  *gstypes.Position
+		want gstypes.Position
+	}{
+		"field":         {field.Position(), gstypes.Position{File: "test/test.go", Line: 4, Column: 2, EndLine: 4}},
+		"field comment": {field.Comments()[0].Position, gstypes.Position{File: "test/test.go", Line: 4, Column: 9, EndLine: 4}},
+		"constant":      {admin.Position(), gstypes.Position{File: "test/test.go", Line: 10, Column: 7, EndLine: 10}},
+		"const comment": {admin.Comments()[0].Position, gstypes.Position{File: "test/test.go", Line: 9, Column: 1, EndLine: 9}},
+		"method":        {rename.Position(), gstypes.Position{File: "test/test.go", Line: 12, Column: 16, EndLine: 14}},
+		"parameter":     {rename.Parameters()[0].Position(), gstypes.Position{File: "test/test.go", Line: 12, Column: 23, EndLine: 12}},
+		"named result":  {rename.Results()[0].Position(), gstypes.Position{File: "test/test.go", Line: 12, Column: 37, EndLine: 12}},
+	} {
+		if tc.got == nil || *tc.got != tc.want {
+			t.Errorf("%s position = %v, want %v", name, tc.got, &tc.want)
+		}
+	}
+
+	// Positions survive a cache round trip
+	cache := filepath.Join(t.TempDir(), "cache.json")
+	if err := result.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteCache(cache, result); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := ReadCache(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, _ = cached.Types.Get("test.User")
+	if got := user.Methods()[0].Parameters()[0].Position(); got == nil || got.Line != 12 {
+		t.Errorf("cached parameter position = %v", got)
+	}
+	if got := user.(*gstypes.Struct).Fields()[0].Position(); got == nil || got.Line != 4 {
+		t.Errorf("cached field position = %v", got)
+	}
+}
+
+func TestTypeResolver_functionBodies(t *testing.T) {
+	src := `
+	package test
+
+	import (
+		"reflect"
+		"unsafe"
+	)
+
+	type Buffer struct {
+		data []byte
+	}
+
+	func (b *Buffer) Ptr() unsafe.Pointer { return unsafe.Pointer(&b.data[0]) }
+
+	func (b Buffer) Kind() string { return reflect.TypeOf(b).Kind().String() }
+
+	func (b Buffer) Len() int { return len(b.data) }
+
+	func Size(v any) uintptr {
+		_ = reflect.ValueOf(v)
+		return unsafe.Sizeof(v)
+	}
+
+	func Plain() int { return 1 }
+	`
+
+	flagsOf := func(result *ScanningResult) map[string]gstypes.BodyFlags {
+		flags := map[string]gstypes.BodyFlags{}
+		for _, name := range []string{"Size", "Plain"} {
+			fn, _ := result.Types.Get("test." + name)
+			f := fn.(*gstypes.Function)
+			flags[name] = gstypes.BodyFlags{UsesUnsafe: f.UsesUnsafe(), UsesReflect: f.UsesReflect(), UsesCGo: f.UsesCGo()}
+		}
+		buffer, _ := result.Types.Get("test.Buffer")
+		for _, m := range buffer.Methods() {
+			flags[m.Name()] = gstypes.BodyFlags{UsesUnsafe: m.UsesUnsafe(), UsesReflect: m.UsesReflect(), UsesCGo: m.UsesCGo()}
+		}
+		return flags
+	}
+
+	// Opt-in: nothing is flagged with the full scan mode
+	for name, f := range flagsOf(scanTestSource(t, src)) {
+		if f != (gstypes.BodyFlags{}) {
+			t.Errorf("%s flagged without ScanModeFunctionBodies: %+v", name, f)
+		}
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.ScanMode |= ScanModeFunctionBodies
+	got := flagsOf(scanTestSourceWithConfig(t, src, cfg))
+	want := map[string]gstypes.BodyFlags{
+		"Size":  {UsesUnsafe: true, UsesReflect: true},
+		"Plain": {},
+		"Ptr":   {UsesUnsafe: true},
+		"Kind":  {UsesReflect: true},
+		"Len":   {},
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("%s flags = %+v, want %+v", name, got[name], w)
+		}
+	}
+}
+
+// Anonymous interfaces are loaded with the scan and serialize their methods inline
+func TestTypeResolver_anonymousInterfaces(t *testing.T) {
+	src := `
+	package test
+
+	type Service struct {
+		Runner interface{ Do() error }
+	}
+
+	func (s *Service) Use(c interface {
+		Close() error
+		Name() string
+	}) {
+	}
+
+	func Run(x interface{ Do(n int) error }) {}
+	`
+
+	result := scanTestSource(t, src)
+	methodNames := func(where string, typ gstypes.Type) []string {
+		t.Helper()
+		iface, ok := typ.(*gstypes.Interface)
+		if !ok {
+			t.Fatalf("%s type = %T, want *Interface", where, typ)
+		}
+		serialized := iface.Serialize().(*gstypes.SerializedInterface)
+		names := make([]string, 0, len(serialized.Methods))
+		for _, m := range serialized.Methods {
+			names = append(names, m.Name)
+		}
+		return names
+	}
+
+	svc, _ := result.Types.Get("test.Service")
+	strct := svc.(*gstypes.Struct)
+	if got := methodNames("Runner field", strct.Fields()[0].Type()); !reflect.DeepEqual(got, []string{"Do"}) {
+		t.Errorf("Runner field methods = %v, want [Do]", got)
+	}
+	use := strct.Methods()[0]
+	if got := methodNames("Use parameter", use.Parameters()[0].Type()); !reflect.DeepEqual(got, []string{"Close", "Name"}) {
+		t.Errorf("Use parameter methods = %v, want [Close Name]", got)
+	}
+
+	run, _ := result.Types.Get("test.Run")
+	if got := methodNames("Run parameter", run.(*gstypes.Function).Parameters()[0].Type()); !reflect.DeepEqual(got, []string{"Do"}) {
+		t.Errorf("Run parameter methods = %v, want [Do]", got)
+	}
+}
+
+func TestTypeResolver_recursiveFunctionTypes(t *testing.T) {
+	src := `
+	package test
+
+	type lexer struct{ state stateFn }
+
+	type stateFn func(*lexer) stateFn
+	`
+
+	result := scanTestSource(t, src)
+	if err := result.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := result.Types.Get("test.stateFn")
+	fn, ok := got.(*gstypes.Function)
+	if !ok {
+		t.Fatalf("test.stateFn = %T, want *gstypes.Function", got)
+	}
+	if len(fn.Results()) != 1 || fn.Results()[0].Type() != fn {
+		t.Errorf("stateFn result = %v, want stateFn itself", fn.Results())
+	}
+
+	// Processors receive the function type with its signature, not the early cached one
+	r, ctx, pkg := newTestResolver(t, src)
+	recorder := &signatureProcessor{results: map[string]int{}}
+	r.processors = []Processor{recorder}
+	r.ResolveType(ctx, pkg.Scope().Lookup("stateFn").Type())
+	if got, ok := recorder.results["test.stateFn"]; !ok || got != 1 {
+		t.Errorf("processor saw stateFn with %d results (seen %v), want 1", got, ok)
+	}
+}
+
+// signatureProcessor records the number of results of the function types it receives
+type signatureProcessor struct {
+	NoOpProcessor
+	results map[string]int
+}
+
+func (p *signatureProcessor) ProcessResolvedType(t gstypes.Type) (bool, error) {
+	if fn, ok := t.(*gstypes.Function); ok {
+		p.results[fn.Id()] = len(fn.Results())
+	}
+	return true, nil
+}
+
+func TestTypeResolver_namedResults(t *testing.T) {
+	src := `
+	package test
+
+	func Div(a, b int) (quotient, remainder int) { return }
+
+	type DivFunc func(a, b int) (quotient, remainder int)
+
+	type Calc struct{}
+
+	func (Calc) Div(a, b int) (quotient, remainder int) { return }
+
+	type Divider interface {
+		Div(a, b int) (quotient, remainder int)
+	}
+
+	type Gen[T any] struct{}
+
+	func (Gen[T]) Div(a, b T) (quotient, remainder T) { return }
+
+	type Holder struct {
+		Ints Gen[int]
+	}
+	`
+
+	result := scanTestSource(t, src)
+	if err := result.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"quotient", "remainder"}
+	names := func(results []*gstypes.Result) []string {
+		var out []string
+		for _, r := range results {
+			out = append(out, r.Name())
+		}
+		return out
+	}
+	check := func(what string, results []*gstypes.Result) {
+		t.Helper()
+		if got := names(results); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s results = %v, want %v", what, got, want)
+		}
+	}
+
+	fn, _ := result.Types.Get("test.Div")
+	check("func Div", fn.(*gstypes.Function).Results())
+	fnType, _ := result.Types.Get("test.DivFunc")
+	check("type DivFunc", fnType.(*gstypes.Function).Results())
+	for _, id := range []string{"test.Calc", "test.Divider"} {
+		typ, _ := result.Types.Get(id)
+		if methods := typ.Methods(); len(methods) != 1 {
+			t.Errorf("%s methods = %v", id, methods)
+		} else {
+			check(id+".Div", methods[0].Results())
+		}
+	}
+	holder, _ := result.Types.Get("test.Holder")
+	instance := holder.(*gstypes.Struct).Fields()[0].Type().(*gstypes.InstantiatedGeneric)
+	if err := instance.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if methods := instance.Methods(); len(methods) != 1 {
+		t.Errorf("Gen[int] methods = %v", methods)
+	} else {
+		check("Gen[int].Div", methods[0].Results())
+	}
+
+	// Every serialized result keeps its name
+	var serialized struct {
+		Types map[string]struct {
+			Results []*gstypes.SerializedResult `json:"results"`
+			Methods []struct {
+				Results []*gstypes.SerializedResult `json:"results"`
+			} `json:"methods"`
+		} `json:"types"`
+	}
+	data, err := json.Marshal(result.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for id, typ := range serialized.Types {
+		lists := [][]*gstypes.SerializedResult{typ.Results}
+		for _, m := range typ.Methods {
+			lists = append(lists, m.Results)
+		}
+		for _, results := range lists {
+			if len(results) == 0 {
+				continue
+			}
+			count++
+			if len(results) != 2 || results[0].Name != "quotient" || results[1].Name != "remainder" {
+				t.Errorf("%s serialized results lost their names: %+v", id, results)
+			}
+		}
+	}
+	if count != 6 {
+		t.Errorf("found %d serialized result lists, want 6", count)
+	}
+}
+
+func TestTypeResolver_sealedInterfaces(t *testing.T) {
+	src := `
+	package test
+
+	// Shape is sealed, only this package can implement it
+	type Shape interface {
+		Area() float64
+		isShape()
+	}
+
+	type Solid interface {
+		Shape
+		Volume() float64
+	}
+	`
+
+	methods := func(visibility VisibilityLevel) map[string]*gstypes.Method {
+		t.Helper()
+		cfg := NewDefaultConfig()
+		cfg.Visibility = visibility
+		result := scanTestSourceWithConfig(t, src, cfg)
+		out := map[string]*gstypes.Method{}
+		for _, id := range []string{"test.Shape", "test.Solid"} {
+			typ, ok := result.Types.Get(id)
+			if !ok {
+				t.Fatalf("expected %s", id)
+			}
+			for _, m := range typ.Methods() {
+				out[m.Id()] = m
+			}
+		}
+		return out
+	}
+
+	for _, visibility := range []VisibilityLevel{VisibilityLevelUnexported, VisibilityLevelAll} {
+		got := methods(visibility)
+		for _, id := range []string{"test.Shape#isShape", "test.Solid#isShape"} {
+			m, ok := got[id]
+			if !ok {
+				t.Errorf("visibility %v: expected the sealing method %s", visibility, id)
+				continue
+			}
+			if m.Package() == nil || m.Package().Path() != "test" {
+				t.Errorf("visibility %v: %s package = %v, want test", visibility, id, m.Package())
+			}
+		}
+		if _, ok := got["test.Shape#Area"]; ok != visibility.Has(VisibilityLevelExported) {
+			t.Errorf("visibility %v: exported method Area present = %v", visibility, ok)
+		}
+	}
+
+	got := methods(VisibilityLevelExported)
+	for _, id := range []string{"test.Shape#isShape", "test.Solid#isShape"} {
+		if _, ok := got[id]; ok {
+			t.Errorf("unexpected unexported %s with exported visibility", id)
+		}
+	}
+	if _, ok := got["test.Solid#Area"]; !ok {
+		t.Errorf("expected the promoted test.Solid#Area")
+	}
+}
+
+// any and interface{} resolve to one shared predeclared interface
+func TestTypeResolver_sharedAny(t *testing.T) {
+	src := `
+	package test
+
+	type Box struct {
+		First  any
+		Second any
+		Legacy interface{}
+		Items  []any
+	}
+
+	func Wrap(v any) interface{} { return v }
+	`
+
+	result := scanTestSource(t, src)
+	typ, _ := result.Types.Get("test.Box")
+	box := typ.(*gstypes.Struct)
+	fields := box.Fields()
+	shared := fields[0].Type()
+	iface, ok := shared.(*gstypes.Interface)
+	if !ok || !iface.IsPredeclared() {
+		t.Fatalf("First type = %T %v, want the predeclared any", shared, shared)
+	}
+
+	wrap, _ := result.Types.Get("test.Wrap")
+	fn := wrap.(*gstypes.Function)
+	uses := map[string]gstypes.Type{
+		"Second":      fields[1].Type(),
+		"Legacy":      fields[2].Type(),
+		"Items[]":     fields[3].Type().(*gstypes.Slice).Elem(),
+		"Wrap(v)":     fn.Parameters()[0].Type(),
+		"Wrap result": fn.Results()[0].Type(),
+	}
+	for name, use := range uses {
+		if use != shared {
+			t.Errorf("%s = %v, want the shared any", name, use)
+		}
+	}
+	if shared.Package() != nil {
+		t.Errorf("any has package %s, want none", shared.Package().Path())
+	}
+
+	data, err := json.Marshal(shared.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var serialized map[string]any
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"id": "any", "name": "any", "kind": "interface", "predeclared": true}
+	if !reflect.DeepEqual(serialized, want) {
+		t.Errorf("serialized any = %v, want %v", serialized, want)
+	}
+
+	// Restored results share it too
+	data, err = json.Marshal(result.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := reconstructFromCache(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ, _ = restored.Types.Get("test.Box")
+	fields = typ.(*gstypes.Struct).Fields()
+	if first, ok := fields[0].Type().(*gstypes.Interface); !ok || !first.IsPredeclared() || fields[2].Type() != first {
+		t.Errorf("restored any fields = %v, %v, want the same predeclared any", fields[0].Type(), fields[2].Type())
+	}
+}
+
+// Methods promoted from embedded interfaces keep the doc comment of their declaration
+func TestTypeResolver_promotedInterfaceMethodDocs(t *testing.T) {
+	src := `
+	package test
+
+	// Named has a name
+	type Named interface {
+		// Name returns the display name
+		Name() string
+	}
+
+	// Entity is named and identified
+	type Entity interface {
+		Named
+		// ID returns the unique id
+		ID() int
+	}
+
+	// Document is an entity with a body
+	type Document interface {
+		Entity
+		Body() string
+	}
+	`
+
+	result := scanTestSource(t, src)
+	for id, want := range map[string]map[string]string{
+		"test.Entity":   {"Name": "Name returns the display name", "ID": "ID returns the unique id"},
+		"test.Document": {"Name": "Name returns the display name", "ID": "ID returns the unique id", "Body": ""},
+	} {
+		typ, _ := result.Types.Get(id)
+		if got := methodDocs(t, typ.(*gstypes.Interface).Methods()); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s method docs = %v, want %v", id, got, want)
+		}
+	}
+}
+
+// Methods promoted from interfaces of other packages take their doc comment from the
+// package declaring them
+func TestTypeResolver_promotedExternalInterfaceMethodDocs(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.LogLevel = "error"
+	cfg.Packages = []string{"./testdata/embedded"}
+	result, err := NewScanner().ScanWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ScanWithConfig() error = %v", err)
+	}
+
+	typ, ok := result.Types.Get("github.com/pablor21/goscanner/scanner/testdata/embedded.Job")
+	if !ok {
+		t.Fatal("expected the Job interface")
+	}
+	docs := methodDocs(t, typ.(*gstypes.Interface).Methods())
+	if got := docs["Run"]; got != "Run runs the job" {
+		t.Errorf("Run doc = %q", got)
+	}
+	if got := docs["Done"]; !strings.HasPrefix(got, "Done returns a channel that's closed when work done on behalf of this") {
+		t.Errorf("Done doc = %q, want the doc of context.Context.Done", got)
+	}
+}
+
+// methodDocs loads the methods and returns their comments by name
+func methodDocs(t *testing.T, methods []*gstypes.Method) map[string]string {
+	t.Helper()
+	docs := map[string]string{}
+	for _, m := range methods {
+		if err := m.Load(); err != nil {
+			t.Fatal(err)
+		}
+		var texts []string
+		for _, c := range m.Comments() {
+			texts = append(texts, c.Text)
+		}
+		docs[m.Name()] = strings.Join(texts, "\n")
+	}
+	return docs
+}
+
+func TestTypeResolver_variableInitializers(t *testing.T) {
+	src := `package test
+
+type Options struct {
+	Name  string
+	Ports []int
+}
+
+const base = 10
+
+var (
+	Timeout         = base * 3
+	Name    string  = "server"
+	Ratio   float64 = 1.0 / 4
+	Debug           = false
+	Default         = Options{
+		Name:  "default",
+		Ports: []int{80, 443},
+	}
+	Left, Right = pair()
+	Unset       int
+)
+
+func pair() (int, int) { return 1, 2 }
+`
+	type initializer struct {
+		Expr  string
+		Value string
+	}
+	want := map[string]initializer{
+		"Timeout": {"base * 3", "30"},
+		"Name":    {`"server"`, `"server"`},
+		"Ratio":   {"1.0 / 4", "1/4"},
+		"Debug":   {"false", "false"},
+		"Default": {"Options{\n\tName:  \"default\",\n\tPorts: []int{80, 443},\n}", ""},
+		"Left":    {"pair()", ""},
+		"Right":   {"pair()", ""},
+		"Unset":   {"", ""},
+	}
+	initializers := func(result *ScanningResult) map[string]initializer {
+		t.Helper()
+		got := map[string]initializer{}
+		for name := range want {
+			v, ok := result.Values.Get("test." + name)
+			if !ok {
+				t.Fatalf("expected variable %s", name)
+			}
+			value := ""
+			if v.Value() != nil {
+				value = v.Value().(interface{ ExactString() string }).ExactString()
+			}
+			got[name] = initializer{v.Expr(), value}
+		}
+		return got
+	}
+
+	// Not captured by default
+	for name, got := range initializers(scanTestSource(t, src)) {
+		if got != (initializer{}) {
+			t.Errorf("%s initializer = %v without IncludeInitializers", name, got)
+		}
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.IncludeInitializers = true
+	if got := initializers(scanTestSourceWithConfig(t, src, cfg)); !reflect.DeepEqual(got, want) {
+		t.Errorf("initializers = %v, want %v", got, want)
+	}
+}
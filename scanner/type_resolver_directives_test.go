@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeResolver_generateDirectives(t *testing.T) {
+	src := `
+	// Package test has generators.
+	package test
+
+	//go:generate go run gen.go
+
+	// Kind is an enum.
+	//
+	//go:generate stringer -type=Kind
+	type Kind int
+
+	//go:generate mockgen -source=test.go
+	type (
+		Store interface{ Get() Kind }
+		Plain struct{}
+	)
+	`
+
+	result := scanTestSource(t, src)
+
+	pkg, ok := result.Packages.Get("test")
+	if !ok {
+		t.Fatal("package test not found")
+	}
+	wantPkg := []string{"go run gen.go", "stringer -type=Kind", "mockgen -source=test.go"}
+	if got := pkg.GenerateDirectives(); !reflect.DeepEqual(got, wantPkg) {
+		t.Errorf("Package.GenerateDirectives() = %v, want %v", got, wantPkg)
+	}
+
+	kind, _ := result.Types.Get("test.Kind")
+	if got := kind.GenerateDirectives(); !reflect.DeepEqual(got, []string{"stringer -type=Kind"}) {
+		t.Errorf("Kind.GenerateDirectives() = %v", got)
+	}
+
+	// The doc of a grouped declaration is not attached to its types
+	for _, id := range []string{"test.Store", "test.Plain"} {
+		typ, _ := result.Types.Get(id)
+		if got := typ.GenerateDirectives(); len(got) != 0 {
+			t.Errorf("%s.GenerateDirectives() = %v, want none", id, got)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+func TestTypeResolver_docLinks(t *testing.T) {
+	src := `
+	package test
+
+	// User is stored by [Store.Get] and [*Store] implements [io.Closer].
+	// Unknown names like [Missing] or [x] are left as text, see [fmt] and [the spec].
+	//
+	// [the spec]: https://go.dev/ref/spec
+	type User struct {
+		ID int
+	}
+
+	// Store keeps [User] values.
+	type Store struct{}
+
+	func (s *Store) Get(id int) *User { return nil }
+	`
+
+	result := scanTestSource(t, src)
+
+	user, _ := result.Types.Get("test.User")
+	comments := user.Comments()
+	if len(comments) == 0 {
+		t.Fatal("User has no comments")
+	}
+	want := []gstypes.DocLink{
+		{Text: "Store.Get", ImportPath: "test", Recv: "Store", Name: "Get", TypeID: "test.Store#Get"},
+		{Text: "*Store", ImportPath: "test", Name: "Store", TypeID: "test.Store"},
+		{Text: "io.Closer", ImportPath: "io", Name: "Closer", TypeID: "io.Closer"},
+		{Text: "fmt", ImportPath: "fmt"},
+	}
+	if got := comments[0].Links(); !reflect.DeepEqual(got, want) {
+		t.Errorf("User links =\n%+v\nwant\n%+v", got, want)
+	}
+	// The text is kept as written
+	if !strings.HasPrefix(comments[0].Text, "User is stored by [Store.Get]") {
+		t.Errorf("comment text changed: %q", comments[0].Text)
+	}
+
+	store, _ := result.Types.Get("test.Store")
+	if links := store.Comments()[0].Links(); len(links) != 1 || links[0].TypeID != "test.User" {
+		t.Errorf("Store links = %+v, want a link to test.User", links)
+	}
+}
@@ -0,0 +1,605 @@
+package scanner
+
+import (
+	"encoding/json"
+	"go/constant"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+func TestTypeResolver_enumIotaExpr(t *testing.T) {
+	src := `
+	package test
+
+	type Kind int
+
+	const (
+		KindA Kind = iota + 1
+		KindB
+		KindC
+	)
+
+	func (k Kind) String() string { return "kind" }
+
+	type Flag uint8
+
+	const (
+		FlagRead Flag = 1 << iota
+		FlagWrite
+	)
+
+	type Color string
+
+	const (
+		Red   Color = "red"
+		Green Color = "green"
+	)
+
+	type Level int
+
+	const (
+		_ Level = iota
+		LevelLow
+		LevelHigh
+	)
+
+	// Split across blocks: only the first iota block counts
+	type Code int
+
+	const (
+		CodeOK Code = iota
+		CodeFail
+	)
+
+	const (
+		CodeUser Code = iota + 100
+		CodeAdmin
+	)
+
+	// A leading explicit block doesn't hide a later iota block
+	type Mixed int
+
+	const MixedNone Mixed = -1
+
+	const (
+		MixedA Mixed = iota * 10
+		MixedB
+	)
+	`
+
+	cfg := NewDefaultConfig()
+	cfg.ScanMode |= ScanModeEnums
+	result := scanTestSourceWithConfig(t, src, cfg)
+
+	want := map[string]string{
+		"Kind":  "iota + 1",
+		"Flag":  "1 << iota",
+		"Color": "",
+		"Level": "iota",
+		"Code":  "iota",
+		"Mixed": "iota * 10",
+	}
+	for name, expected := range want {
+		typ, ok := result.Types.Get("test." + name)
+		if !ok {
+			t.Errorf("type %s not found", name)
+			continue
+		}
+		enum, ok := typ.(*gstypes.Enum)
+		if !ok {
+			t.Errorf("%s = %T, want an enum", name, typ)
+			continue
+		}
+		if got := enum.IotaExpr(); got != expected {
+			t.Errorf("%s IotaExpr() = %q, want %q", name, got, expected)
+		}
+	}
+
+	// Loading an enum resolves its methods
+	kind, _ := result.Types.Get("test.Kind")
+	if err := kind.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if methods := kind.Methods(); len(methods) != 1 || methods[0].Name() != "String" {
+		t.Errorf("Kind methods = %v, want String", methods)
+	}
+}
+
+func TestTypeResolver_enumStringLabels(t *testing.T) {
+	src := `
+	package test
+
+	// Hand written switch
+	type Color int
+
+	const (
+		Red Color = iota
+		Green
+		Blue
+	)
+
+	func (c Color) String() string {
+		switch c {
+		case Red:
+			return "red"
+		case Green, Blue:
+			return "greenish"
+		}
+		return "unknown"
+	}
+
+	// Generated by stringer, starting at 1
+	type Level int
+
+	const (
+		LevelLow Level = iota + 1
+		LevelMid
+		LevelHigh
+	)
+
+	const _Level_name = "lowmediumhigh"
+
+	var _Level_index = [...]uint8{0, 3, 9, 13}
+
+	func (i Level) String() string {
+		i -= 1
+		if i < 0 || i >= Level(len(_Level_index)-1) {
+			return "Level(?)"
+		}
+		return _Level_name[_Level_index[i]:_Level_index[i+1]]
+	}
+
+	// Lookup tables
+	type Shape uint8
+
+	const (
+		Circle Shape = iota
+		Square
+	)
+
+	var shapeNames = [...]string{Square: "square", Circle: "circle"}
+
+	func (s *Shape) String() string { return shapeNames[*s] }
+
+	type Mode string
+
+	const (
+		ModeRead  Mode = "r"
+		ModeWrite Mode = "w"
+	)
+
+	var modeNames = map[Mode]string{ModeRead: "read", ModeWrite: "write"}
+
+	func (m Mode) String() string { return modeNames[m] }
+
+	// No String() method
+	type Plain int
+
+	const PlainA Plain = 1
+	`
+
+	want := map[string]string{
+		"Red": "red", "Green": "greenish", "Blue": "greenish",
+		"LevelLow": "low", "LevelMid": "medium", "LevelHigh": "high",
+		"Circle": "circle", "Square": "square",
+		"ModeRead": "read", "ModeWrite": "write",
+		"PlainA": "",
+	}
+	labels := func(cfg *Config) map[string]string {
+		t.Helper()
+		result := scanTestSourceWithConfig(t, src, cfg)
+		got := map[string]string{}
+		for name := range want {
+			v, ok := result.Values.Get("test." + name)
+			if !ok {
+				t.Fatalf("expected constant %s", name)
+			}
+			got[name] = v.Label()
+		}
+		return got
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.ScanMode |= ScanModeFunctionBodies
+	if got := labels(cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("labels = %v, want %v", got, want)
+	}
+
+	// Bodies are only inspected with ScanModeFunctionBodies
+	for name, label := range labels(NewDefaultConfig()) {
+		if label != "" {
+			t.Errorf("%s label = %q without ScanModeFunctionBodies", name, label)
+		}
+	}
+}
+
+func TestTypeResolver_enumConstsInSeparateBlock(t *testing.T) {
+	src := `
+	package test
+
+	type Status int
+
+	type Priority int
+
+	// Attached to Status by go/doc
+	const (
+		StatusActive Status = iota
+		StatusInactive
+	)
+
+	// Mostly untyped, go/doc leaves it at package level
+	const (
+		PriorityLow Priority = iota
+		PriorityHigh
+		MaxRetries = 3
+		Timeout    = 10
+	)
+
+	const StatusDeleted Status = 9
+	`
+	result := scanTestSource(t, src)
+
+	want := map[string]string{
+		"StatusActive":   "test.Status",
+		"StatusInactive": "test.Status",
+		"StatusDeleted":  "test.Status",
+		"PriorityLow":    "test.Priority",
+		"PriorityHigh":   "test.Priority",
+		"MaxRetries":     "",
+		"Timeout":        "",
+	}
+	for name, parent := range want {
+		v, ok := result.Values.Get("test." + name)
+		if !ok {
+			t.Fatalf("expected constant %s", name)
+		}
+		got := ""
+		if v.Parent() != nil {
+			got = v.Parent().Id()
+		}
+		if got != parent {
+			t.Errorf("%s parent = %q, want %q", name, got, parent)
+		}
+	}
+
+	// The association survives the cache
+	data, err := json.Marshal(result.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := reconstructFromCache(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, _ := restored.Types.Get("test.Status")
+	if v, ok := restored.Values.Get("test.PriorityHigh"); !ok || v.Parent() == nil || v.Parent().Id() != "test.Priority" {
+		t.Errorf("restored PriorityHigh lost its parent: %v", v)
+	}
+	if v, ok := restored.Values.Get("test.StatusDeleted"); !ok || status == nil || v.Parent() != status {
+		t.Errorf("restored StatusDeleted parent isn't the restored Status: %v", v)
+	}
+}
+
+func TestTypeResolver_enumConstantComments(t *testing.T) {
+	src := `
+	package test
+
+	type Status int
+
+	const (
+		// Active means the user can log in
+		Active Status = iota
+		Blocked // Blocked users can't log in
+		// Deleted users are gone
+		Deleted
+		Unknown
+	)
+	`
+
+	want := map[string]string{
+		"Active":  "Active means the user can log in",
+		"Blocked": "Blocked users can't log in",
+		"Deleted": "Deleted users are gone",
+		"Unknown": "",
+	}
+	comments := func(result *ScanningResult) map[string]string {
+		t.Helper()
+		got := map[string]string{}
+		for name := range want {
+			v, ok := result.Values.Get("test." + name)
+			if !ok {
+				t.Fatalf("expected constant %s", name)
+			}
+			if err := v.Load(); err != nil {
+				t.Fatal(err)
+			}
+			got[name] = joinComments(v.Comments())
+		}
+		return got
+	}
+
+	result := scanTestSource(t, src)
+	if got := comments(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("comments = %v, want %v", got, want)
+	}
+
+	// The comments and the constants survive the cache
+	data, err := json.Marshal(result.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := reconstructFromCache(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := comments(restored); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored comments = %v, want %v", got, want)
+	}
+	if v, _ := restored.Values.Get("test.Deleted"); v.Kind() != "constant" {
+		t.Errorf("restored Deleted kind = %s, want constant", v.Kind())
+	}
+}
+
+func TestTypeResolver_enums(t *testing.T) {
+	src := `
+	package test
+
+	// Status of a user
+	type Status int
+
+	const StatusDeleted Status = 9
+
+	const (
+		// StatusActive users can log in
+		StatusActive Status = iota
+		StatusBlocked // StatusBlocked users can't
+	)
+
+	type Color string
+
+	const (
+		Red   Color = "red"
+		Green Color = "green"
+	)
+
+	// No constants
+	type Name string
+	`
+	cfg := NewDefaultConfig()
+	cfg.ScanMode |= ScanModeEnums
+	result := scanTestSourceWithConfig(t, src, cfg)
+
+	status, _ := result.Types.Get("test.Status")
+	enum, ok := status.(*gstypes.Enum)
+	if !ok {
+		t.Fatalf("Status = %T, want an enum", status)
+	}
+	if enum.IotaExpr() != "iota" || enum.Underlying().Id() != "int" {
+		t.Errorf("Status iota = %q, underlying = %s", enum.IotaExpr(), enum.Underlying().Id())
+	}
+	var names []string
+	for _, v := range enum.Values() {
+		names = append(names, v.Name())
+		if v.Parent() != enum {
+			t.Errorf("%s parent = %v, want the enum", v.Name(), v.Parent())
+		}
+		if scanned, _ := result.Values.Get(v.Id()); scanned != v {
+			t.Errorf("%s isn't the value of the result", v.Name())
+		}
+	}
+	if want := []string{"StatusDeleted", "StatusActive", "StatusBlocked"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Status values = %v, want %v", names, want)
+	}
+	if got := joinComments(enum.Values()[2].Comments()); got != "StatusBlocked users can't" {
+		t.Errorf("StatusBlocked comments = %q", got)
+	}
+
+	if color, _ := result.Types.Get("test.Color"); color.Kind() != gstypes.TypeKindEnum || len(color.(*gstypes.Enum).Values()) != 2 {
+		t.Errorf("Color = %v, want an enum of 2 values", color)
+	}
+	if name, _ := result.Types.Get("test.Name"); name.Kind() != gstypes.TypeKindBasic {
+		t.Errorf("Name kind = %s, want basic", name.Kind())
+	}
+
+	// Named basic types stay basic without ScanModeEnums
+	if status, _ := scanTestSource(t, src).Types.Get("test.Status"); status.Kind() != gstypes.TypeKindBasic {
+		t.Errorf("Status kind = %s without ScanModeEnums, want basic", status.Kind())
+	}
+
+	// Enum values are constants, "enums" scans them too
+	if mode := ScanModeNone.FromString("types,enums"); !mode.Has(ScanModeConsts) {
+		t.Errorf("FromString(\"types,enums\") = %v, want ScanModeConsts", mode)
+	}
+}
+
+func TestTypeResolver_constantExpressions(t *testing.T) {
+	src := `
+	package test
+
+	type Flag uint8
+
+	const (
+		FlagRead Flag = 1 << iota
+		FlagWrite
+		FlagExec
+	)
+
+	type Code int
+
+	const (
+		_ Code = iota + 100
+		CodeUser
+		CodeAdmin, CodeRoot = iota * 10, iota * 20
+		CodeGuest, CodeNobody
+	)
+
+	type Color string
+
+	const Red Color = "red"
+
+	const MaxRetries = 3
+	`
+	type expr struct {
+		Expr  string
+		Iota  int
+		Value string
+	}
+	want := map[string]expr{
+		"FlagRead":   {"1 << iota", 0, "1"},
+		"FlagWrite":  {"1 << iota", 1, "2"},
+		"FlagExec":   {"1 << iota", 2, "4"},
+		"CodeUser":   {"iota + 100", 1, "101"},
+		"CodeAdmin":  {"iota * 10", 2, "20"},
+		"CodeRoot":   {"iota * 20", 2, "40"},
+		"CodeGuest":  {"iota * 10", 3, "30"},
+		"CodeNobody": {"iota * 20", 3, "60"},
+		"Red":        {`"red"`, -1, `"red"`},
+		"MaxRetries": {"3", -1, "3"},
+	}
+	exprs := func(result *ScanningResult, restored bool) map[string]expr {
+		t.Helper()
+		got := map[string]expr{}
+		for name := range want {
+			v, ok := result.Values.Get("test." + name)
+			if !ok {
+				t.Fatalf("expected constant %s", name)
+			}
+			// Cached values are JSON, the computed ones are only compared as scanned
+			value := want[name].Value
+			if !restored {
+				c, _ := v.Value().(constant.Value)
+				value = c.ExactString()
+			}
+			got[name] = expr{v.Expr(), v.Iota(), value}
+		}
+		return got
+	}
+
+	result := scanTestSource(t, src)
+	if got := exprs(result, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("expressions = %v, want %v", got, want)
+	}
+
+	data, err := json.Marshal(result.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := reconstructFromCache(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := exprs(restored, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored expressions = %v, want %v", got, want)
+	}
+}
+
+func TestTypeResolver_enumShape(t *testing.T) {
+	src := `
+	package test
+
+	type Level int
+
+	const (
+		LevelLow Level = iota
+		LevelMid
+		LevelHigh
+		LevelDefault = LevelMid
+	)
+
+	type Flag uint8
+
+	const (
+		FlagRead Flag = 1 << iota
+		FlagWrite
+		FlagExec
+	)
+
+	type Code int
+
+	const (
+		CodeUser Code = iota + 100
+		CodeAdmin
+	)
+
+	// Declared apart from the iota block
+	const CodeRoot Code = 102
+
+	type Mode string
+
+	const (
+		// ModeRead opens for reading
+		ModeRead Mode = "r"
+		ModeWrite Mode = "w" // ModeWrite opens for writing
+	)
+	`
+	type shape struct {
+		Values     int
+		FromIota   bool
+		Duplicates bool
+		Contiguous bool
+	}
+	want := map[string]shape{
+		"Level": {4, true, true, true},
+		"Flag":  {3, true, false, false},
+		"Code":  {3, true, false, true},
+		"Mode":  {2, false, false, false},
+	}
+	shapes := func(result *ScanningResult) map[string]shape {
+		t.Helper()
+		got := map[string]shape{}
+		for name := range want {
+			typ, _ := result.Types.Get("test." + name)
+			enum, ok := typ.(*gstypes.Enum)
+			if !ok {
+				t.Fatalf("%s = %T, want an enum", name, typ)
+			}
+			got[name] = shape{len(enum.Values()), enum.FromIota(), enum.HasDuplicateValues(), enum.IsContiguous()}
+		}
+		return got
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.ScanMode |= ScanModeEnums
+	result := scanTestSourceWithConfig(t, src, cfg)
+	if got := shapes(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("enum shapes = %v, want %v", got, want)
+	}
+
+	// Shapes and constant comments survive the cache
+	cacheFile := filepath.Join(t.TempDir(), "enums.cache")
+	if err := result.ToCache(cacheFile); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := ReadCache(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := shapes(cached); !reflect.DeepEqual(got, want) {
+		t.Errorf("cached enum shapes = %v, want %v", got, want)
+	}
+	mode, _ := cached.Types.Get("test.Mode")
+	for _, v := range mode.(*gstypes.Enum).Values() {
+		if err := v.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if got := joinComments(v.Comments()); !strings.HasPrefix(got, v.Name()+" opens") {
+			t.Errorf("cached %s comments = %q", v.Name(), got)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"encoding/json"
 	"go/ast"
 	"go/parser"
 	"go/token"
@@ -529,3 +530,74 @@ func TestTypeResolver_GenericAliases(t *testing.T) {
 		}
 	})
 }
+
+func TestTypeResolver_aliasVsDefined(t *testing.T) {
+	src := `
+	package test
+
+	type Point struct{ X, Y int }
+
+	// Celsius is a defined type
+	type Celsius float64
+
+	// Degrees is an alias
+	type Degrees = float64
+
+	type Location Point
+
+	type Position = Point
+
+	type Path = []Point
+	`
+
+	result := scanTestSource(t, src)
+	serialized := func(id string) map[string]any {
+		t.Helper()
+		typ, ok := result.Types.Get(id)
+		if !ok {
+			t.Fatalf("expected %s", id)
+		}
+		data, err := json.Marshal(typ.Serialize())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tree map[string]any
+		if err := json.Unmarshal(data, &tree); err != nil {
+			t.Fatal(err)
+		}
+		return tree
+	}
+
+	for id, kind := range map[string]string{"test.Celsius": "basic", "test.Location": "struct", "test.Point": "struct"} {
+		tree := serialized(id)
+		if tree["kind"] != kind || tree["defined"] != true {
+			t.Errorf("%s = kind %v defined %v, want a defined %s", id, tree["kind"], tree["defined"], kind)
+		}
+	}
+
+	// Aliases reference the aliased type, named ones by id
+	for id, target := range map[string]string{"test.Degrees": "float64", "test.Position": "test.Point"} {
+		tree := serialized(id)
+		underlying, _ := tree["underlying"].(map[string]any)
+		if tree["kind"] != "alias" || tree["defined"] != nil || underlying["id"] != target {
+			t.Errorf("%s = %v, want an alias of %s", id, tree, target)
+		}
+		if _, inlined := underlying["fields"]; inlined {
+			t.Errorf("%s inlines the aliased type: %v", id, underlying)
+		}
+	}
+	path := serialized("test.Path")
+	if underlying := path["underlying"].(map[string]any); path["kind"] != "alias" || underlying["kind"] != "slice" {
+		t.Errorf("test.Path = %v, want an alias of a slice", path)
+	}
+	degrees, _ := result.Types.Get("test.Degrees")
+	if comments := degrees.Comments(); len(comments) == 0 || comments[0].Text != "Degrees is an alias" {
+		t.Errorf("Degrees comments = %v", comments)
+	}
+
+	// Unnamed types are not defined
+	pathAlias, _ := result.Types.Get("test.Path")
+	if slice := pathAlias.(*gstypes.Alias).UnderlyingType(); slice.IsDefined() {
+		t.Errorf("%s must not be defined", slice.Id())
+	}
+}
@@ -1,11 +1,16 @@
 package scanner
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"go/types"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/pablor21/goscanner/logger"
@@ -279,3 +284,723 @@ func TestTypeResolver_GenericOriginTypes(t *testing.T) {
 		}
 	})
 }
+
+// Nested instantiations keep their structure: the arg of Map[string, List[Pair[int, bool]]]
+// is an instantiated List whose arg is an instantiated Pair
+func TestTypeResolver_nestedTypeArguments(t *testing.T) {
+	src := `
+	package test
+
+	type Pair[K comparable, V any] struct {
+		Key   K
+		Value V
+	}
+
+	type List[T any] []T
+
+	type Map[K comparable, V any] map[K]V
+
+	type Index struct {
+		Entries Map[string, List[Pair[int, bool]]]
+	}
+	`
+
+	result := scanTestSource(t, src)
+	idx, _ := result.Types.Get("test.Index")
+	entries := idx.(*gstypes.Struct).Fields()[0]
+
+	outer, ok := entries.Type().(*gstypes.InstantiatedGeneric)
+	if !ok {
+		t.Fatalf("Entries type = %T, want *InstantiatedGeneric", entries.Type())
+	}
+	args := outer.TypeArgs()
+	if len(args) != 2 || args[0].Type.Id() != "string" || args[1].Param != "V" {
+		t.Fatalf("unexpected Map type args: %+v", args)
+	}
+
+	list, ok := args[1].Type.(*gstypes.InstantiatedGeneric)
+	if !ok {
+		t.Fatalf("Map arg V = %T, want *InstantiatedGeneric", args[1].Type)
+	}
+	if list.Origin().Id() != "test.List" || len(list.TypeArgs()) != 1 {
+		t.Fatalf("unexpected List instantiation: origin %s, args %+v", list.Origin().Id(), list.TypeArgs())
+	}
+
+	pair, ok := list.TypeArgs()[0].Type.(*gstypes.InstantiatedGeneric)
+	if !ok {
+		t.Fatalf("List arg T = %T, want *InstantiatedGeneric", list.TypeArgs()[0].Type)
+	}
+	if pair.Origin().Id() != "test.Pair" {
+		t.Errorf("List arg origin = %s, want test.Pair", pair.Origin().Id())
+	}
+	if got := []string{pair.TypeArgs()[0].Type.Id(), pair.TypeArgs()[1].Type.Id()}; got[0] != "int" || got[1] != "bool" {
+		t.Errorf("Pair args = %v, want [int bool]", got)
+	}
+
+	// The serialized form keeps the nesting
+	serialized := outer.Serialize().(map[string]any)
+	listArg := serialized["typeArgs"].([]any)[1].(map[string]any)["type"].(map[string]any)
+	if listArg["origin"] != "test.List" {
+		t.Fatalf("serialized Map arg = %v, want an instantiated List", listArg)
+	}
+	pairArg := listArg["typeArgs"].([]any)[0].(map[string]any)["type"].(map[string]any)
+	if pairArg["origin"] != "test.Pair" || len(pairArg["typeArgs"].([]any)) != 2 {
+		t.Errorf("serialized List arg = %v, want an instantiated Pair", pairArg)
+	}
+
+	// And survives a cache roundtrip
+	cacheFile := filepath.Join(t.TempDir(), "nested.cache")
+	if err := result.ToCache(cacheFile); err != nil {
+		t.Fatalf("ToCache() error = %v", err)
+	}
+	cachedResult, err := ReadCache(cacheFile)
+	if err != nil {
+		t.Fatalf("ReadCache() error = %v", err)
+	}
+	cached, ok := cachedResult.Types.Get(outer.Id())
+	if !ok {
+		t.Fatalf("%s missing from cache", outer.Id())
+	}
+	cachedArgs := cached.(*gstypes.InstantiatedGeneric).TypeArgs()
+	if len(cachedArgs) != 2 || cachedArgs[1].Param != "V" || cachedArgs[1].Index != 1 {
+		t.Fatalf("cached Map args = %+v", cachedArgs)
+	}
+	cachedList, ok := cachedArgs[1].Type.(*gstypes.InstantiatedGeneric)
+	if !ok || len(cachedList.TypeArgs()) != 1 {
+		t.Fatalf("cached Map arg V = %T, want an instantiated List", cachedArgs[1].Type)
+	}
+	if _, ok := cachedList.TypeArgs()[0].Type.(*gstypes.InstantiatedGeneric); !ok {
+		t.Errorf("cached List arg T = %T, want an instantiated Pair", cachedList.TypeArgs()[0].Type)
+	}
+}
+
+// Intersection constraints list every embedded element, and the method set is merged
+// without duplicates
+func TestTypeResolver_intersectionConstraints(t *testing.T) {
+	src := `
+	package test
+
+	type Reader interface{ Read() int }
+	type Closer interface{ Close() error }
+	type ReadCloser interface {
+		Reader
+		Close() error
+	}
+
+	type Pool[T interface {
+		ReadCloser
+		Closer
+	}] struct {
+		Items []T
+	}
+
+	type Labeled[T interface {
+		Reader
+		~int | ~string
+		Label() string
+	}] struct {
+		Item T
+	}
+	`
+
+	result := scanTestSource(t, src)
+	constraint := func(id string) *gstypes.Interface {
+		t.Helper()
+		typ, _ := result.Types.Get(id)
+		params := typ.(*gstypes.Struct).TypeParams()
+		if len(params) != 1 {
+			t.Fatalf("%s has %d type params, want 1", id, len(params))
+		}
+		iface, ok := params[0].Constraint().(*gstypes.Interface)
+		if !ok {
+			t.Fatalf("%s constraint = %T, want *Interface", id, params[0].Constraint())
+		}
+		if err := iface.Load(); err != nil {
+			t.Fatal(err)
+		}
+		return iface
+	}
+	methodNames := func(iface *gstypes.Interface) map[string]int {
+		names := map[string]int{}
+		for _, m := range iface.Methods() {
+			names[m.Name()]++
+		}
+		return names
+	}
+
+	pool := constraint("test.Pool")
+	if embeds := pool.Embeds(); len(embeds) != 2 || embeds[0].Id() != "test.ReadCloser" || embeds[1].Id() != "test.Closer" {
+		t.Errorf("Pool constraint embeds = %v", embeds)
+	}
+	if names := methodNames(pool); len(names) != 2 || names["Read"] != 1 || names["Close"] != 1 {
+		t.Errorf("Pool constraint methods = %v, want Read and Close once", names)
+	}
+
+	labeled := constraint("test.Labeled")
+	embeds := labeled.Embeds()
+	if len(embeds) != 2 || embeds[0].Id() != "test.Reader" {
+		t.Fatalf("Labeled constraint embeds = %v", embeds)
+	}
+	union, ok := embeds[1].(*gstypes.Union)
+	if !ok || len(union.Terms()) != 2 || !union.Terms()[0].Approximation() {
+		t.Errorf("Labeled constraint second embed = %v, want the ~int | ~string union", embeds[1])
+	}
+	if names := methodNames(labeled); len(names) != 2 || names["Read"] != 1 || names["Label"] != 1 {
+		t.Errorf("Labeled constraint methods = %v, want Read and Label", names)
+	}
+}
+
+// General interfaces carry their type set, with the unions and the constraints used as
+// union terms flattened and the embedded elements intersected
+func TestTypeResolver_constraintTypeSets(t *testing.T) {
+	src := `
+	package test
+
+	type Signed interface {
+		~int | ~int8 | ~int16 | ~int32 | ~int64
+	}
+
+	type Unsigned interface {
+		~uint | ~uint64
+	}
+
+	type Integer interface {
+		Signed | Unsigned
+	}
+
+	type Number interface {
+		Integer | ~float32 | ~float64
+	}
+
+	type Stringish interface {
+		~string
+		String() string
+	}
+
+	type Small interface {
+		Integer
+		~int8 | ~uint8 | ~int
+	}
+
+	type MyInt int
+
+	type OnlyMine interface {
+		Signed
+		MyInt
+	}
+
+	type Reader interface {
+		Read() int
+	}
+	`
+
+	result := scanTestSource(t, src)
+	typeSet := func(id string) *gstypes.TypeSet {
+		t.Helper()
+		typ, _ := result.Types.Get(id)
+		iface := typ.(*gstypes.Interface)
+		if err := iface.Load(); err != nil {
+			t.Fatal(err)
+		}
+		return iface.TypeSet()
+	}
+	termsOf := func(ts *gstypes.TypeSet) []string {
+		var terms []string
+		for _, term := range ts.Terms {
+			s := term.Type().Id()
+			if term.Approximation() {
+				s = "~" + s
+			}
+			terms = append(terms, s)
+		}
+		return terms
+	}
+
+	number := typeSet("test.Number")
+	if number == nil {
+		t.Fatalf("Number has no type set")
+	}
+	want := []string{"~int", "~int8", "~int16", "~int32", "~int64", "~uint", "~uint64", "~float32", "~float64"}
+	if got := termsOf(number); !reflect.DeepEqual(got, want) {
+		t.Errorf("Number terms = %v, want %v", got, want)
+	}
+
+	stringish := typeSet("test.Stringish")
+	if got := termsOf(stringish); !reflect.DeepEqual(got, []string{"~string"}) || !reflect.DeepEqual(stringish.Methods, []string{"String"}) {
+		t.Errorf("Stringish type set = %v %v", got, stringish.Methods)
+	}
+
+	if got := termsOf(typeSet("test.Small")); !reflect.DeepEqual(got, []string{"~int", "~int8"}) {
+		t.Errorf("Small terms = %v, want the intersection [~int ~int8]", got)
+	}
+	if got := termsOf(typeSet("test.OnlyMine")); !reflect.DeepEqual(got, []string{"test.MyInt"}) {
+		t.Errorf("OnlyMine terms = %v, want [test.MyInt]", got)
+	}
+
+	if typeSet("test.Reader") != nil {
+		t.Errorf("basic interfaces have no type set")
+	}
+
+	typ, _ := result.Types.Get("test.Number")
+	serialized := typ.Serialize().(*gstypes.SerializedInterface)
+	if serialized.TypeSet == nil || len(serialized.TypeSet.Terms) != len(want) {
+		t.Errorf("serialized type set = %+v", serialized.TypeSet)
+	}
+}
+
+func TestTypeResolver_instantiatedMembers(t *testing.T) {
+	src := `
+	package test
+
+	// Container holds values
+	type Container[T any] interface {
+		// Get returns the first value
+		Get() T
+		All() []T
+	}
+
+	type Base struct {
+		ID int
+	}
+
+	type Box[T any] struct {
+		Base
+		Value *T
+	}
+
+	func (b Box[T]) Unwrap() T { return *b.Value }
+
+	type Holder struct {
+		Ints  Container[int]
+		Names Box[string]
+	}
+	`
+
+	result := scanTestSource(t, src)
+	holder, _ := result.Types.Get("test.Holder")
+	fields := holder.(*gstypes.Struct).Fields()
+	if len(fields) != 2 {
+		t.Fatalf("Holder fields = %d, want 2", len(fields))
+	}
+
+	container, ok := fields[0].Type().(*gstypes.InstantiatedGeneric)
+	if !ok {
+		t.Fatalf("Ints type = %T, want *InstantiatedGeneric", fields[0].Type())
+	}
+	if err := container.Load(); err != nil {
+		t.Fatal(err)
+	}
+	methods := map[string]*gstypes.Method{}
+	for _, m := range container.Methods() {
+		methods[m.Name()] = m
+	}
+	get := methods["Get"]
+	if get == nil || len(get.Results()) != 1 || get.Results()[0].Type().Id() != "int" {
+		t.Fatalf("Container[int].Get must return int, methods: %v", container.Methods())
+	}
+	if err := get.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if comments := get.Comments(); len(comments) == 0 || comments[0].Text != "Get returns the first value" {
+		t.Errorf("Get comments = %v, want the origin's", comments)
+	}
+	if all := methods["All"]; all == nil || all.Results()[0].Type().(*gstypes.Slice).Elem().Id() != "int" {
+		t.Errorf("Container[int].All must return []int")
+	}
+
+	box := fields[1].Type().(*gstypes.InstantiatedGeneric)
+	if err := box.Load(); err != nil {
+		t.Fatal(err)
+	}
+	boxFields := map[string]*gstypes.Field{}
+	for _, f := range box.Fields() {
+		boxFields[f.Name()] = f
+	}
+	if f := boxFields["Value"]; f == nil || f.Type().(*gstypes.Pointer).Elem().Id() != "string" {
+		t.Errorf("Box[string].Value must be *string, fields: %v", box.Fields())
+	}
+	if f := boxFields["ID"]; f == nil || f.PromotedFrom() == nil || f.PromotedFrom().Id() != "test.Base" {
+		t.Errorf("Box[string] must promote Base.ID, fields: %v", box.Fields())
+	}
+	if e := box.Embeds(); len(e) != 1 || e[0].Id() != "test.Base" {
+		t.Errorf("Box[string] embeds = %v, want test.Base", e)
+	}
+	if m := box.Methods(); len(m) != 1 || m[0].Results()[0].Type().Id() != "string" {
+		t.Errorf("Box[string].Unwrap must return string, methods: %v", m)
+	}
+
+	// The serialized instance shows the concrete types and promoted fields, also when read
+	// from a cache
+	serialize := func(typ gstypes.Type) map[string]any {
+		data, err := json.Marshal(typ.Serialize())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tree map[string]any
+		if err := json.Unmarshal(data, &tree); err != nil {
+			t.Fatal(err)
+		}
+		return tree
+	}
+	fieldNames := func(typ gstypes.Type) []any {
+		var names []any
+		for _, f := range serialize(typ)["fields"].([]any) {
+			names = append(names, f.(map[string]any)["name"])
+		}
+		return names
+	}
+	resultType := func(typ gstypes.Type) any {
+		tree := serialize(typ)
+		for _, m := range tree["methods"].([]any) {
+			if method := m.(map[string]any); method["name"] == "Get" {
+				return method["results"].([]any)[0].(map[string]any)["type"].(map[string]any)["id"]
+			}
+		}
+		return nil
+	}
+	if got := resultType(container); got != "int" {
+		t.Errorf("serialized Get result = %v, want int", got)
+	}
+	if got := fieldNames(box); !slices.Contains(got, "ID") {
+		t.Errorf("serialized Box[string] fields = %v, want ID", got)
+	}
+
+	cacheFile := filepath.Join(t.TempDir(), "generics.cache")
+	if err := result.ToCache(cacheFile); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := ReadCache(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cachedContainer, ok := cached.Types.Get(container.Id())
+	if !ok {
+		t.Fatalf("%s not cached", container.Id())
+	}
+	if got := resultType(cachedContainer); got != "int" {
+		t.Errorf("cached Get result = %v, want int", got)
+	}
+	cachedBox, ok := cached.Types.Get(box.Id())
+	if !ok {
+		t.Fatalf("%s not cached", box.Id())
+	}
+	if got := fieldNames(cachedBox); !slices.Contains(got, "ID") {
+		t.Errorf("cached Box[string] fields = %v, want ID", got)
+	}
+}
+
+func TestTypeResolver_predeclaredConstraints(t *testing.T) {
+	src := `
+	package test
+
+	type Cache[K comparable, V any, E interface{}] struct {
+		Items map[K]V
+		Err   error
+		Size  int
+	}
+	`
+
+	result := scanTestSource(t, src)
+	typ, _ := result.Types.Get("test.Cache")
+	cache := typ.(*gstypes.Struct)
+	if err := cache.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	params := cache.TypeParams()
+	if len(params) != 3 {
+		t.Fatalf("got %d type params, want 3", len(params))
+	}
+	if basic, ok := params[0].Constraint().(*gstypes.Basic); !ok || !basic.IsPredeclared() || basic.PredeclaredName() != "comparable" {
+		t.Errorf("K constraint = %v, want predeclared comparable", params[0].Constraint())
+	}
+	// any and interface{} are the same predeclared any
+	for _, param := range params[1:] {
+		iface, ok := param.Constraint().(*gstypes.Interface)
+		if !ok || !iface.IsPredeclared() || iface.Id() != gstypes.AnyID {
+			t.Errorf("%s constraint = %v, want predeclared any", param.Name(), param.Constraint())
+		}
+	}
+	if params[1].Constraint() != params[2].Constraint() {
+		t.Errorf("any and interface{} constraints are distinct types")
+	}
+
+	// The serialized constraint is recognizable, not a plain basic
+	data, err := json.Marshal(params[0].Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var serialized struct {
+		Constraint map[string]any `json:"constraint"`
+	}
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		t.Fatal(err)
+	}
+	if serialized.Constraint["predeclared"] != true || serialized.Constraint["predeclaredName"] != "comparable" {
+		t.Errorf("serialized constraint = %v", serialized.Constraint)
+	}
+
+	for _, f := range cache.Fields() {
+		basic, ok := f.Type().(*gstypes.Basic)
+		if !ok {
+			continue
+		}
+		if want := f.Name() == "Err"; basic.IsPredeclared() != want {
+			t.Errorf("%s (%s) IsPredeclared() = %v, want %v", f.Name(), basic.Id(), basic.IsPredeclared(), want)
+		}
+	}
+}
+
+func TestTypeResolver_genericReceivers(t *testing.T) {
+	src := `
+	package test
+
+	type Cache[K comparable, V any] struct {
+		items map[K]V
+	}
+
+	func (c *Cache[K, V]) Get(k K) (V, bool) {
+		v, ok := c.items[k]
+		return v, ok
+	}
+
+	func (c *Cache[Key, Val]) Set(k Key, v Val) {}
+
+	type Service struct {
+		Users *Cache[string, int]
+	}
+	`
+
+	result := scanTestSource(t, src)
+	methods := func(typ gstypes.Type) map[string]*gstypes.Method {
+		t.Helper()
+		if err := typ.Load(); err != nil {
+			t.Fatal(err)
+		}
+		out := map[string]*gstypes.Method{}
+		for _, m := range typ.Methods() {
+			out[m.Name()] = m
+		}
+		if len(out) != 2 {
+			t.Fatalf("%s methods = %v, want Get and Set", typ.Id(), typ.Methods())
+		}
+		return out
+	}
+	signature := func(m *gstypes.Method) []string {
+		var out []string
+		for _, p := range m.Parameters() {
+			out = append(out, p.Type().Id())
+		}
+		for _, r := range m.Results() {
+			out = append(out, r.Type().Id())
+		}
+		return out
+	}
+
+	// The origin's methods refer to the type parameters, renamed receiver parameters included
+	origin, _ := result.Types.Get("test.Cache")
+	originMethods := methods(origin)
+	for name, want := range map[string][]string{"Get": {"K", "V", "bool"}, "Set": {"K", "V"}} {
+		m := originMethods[name]
+		if got := signature(m); !reflect.DeepEqual(got, want) {
+			t.Errorf("Cache.%s types = %v, want %v", name, got, want)
+		}
+		if !m.IsPointerReceiver() {
+			t.Errorf("Cache.%s must have a pointer receiver", name)
+		}
+		if _, ok := m.Parameters()[0].Type().(*gstypes.TypeParameter); !ok {
+			t.Errorf("Cache.%s first parameter = %T, want *TypeParameter", name, m.Parameters()[0].Type())
+		}
+	}
+
+	// The instance substitutes them
+	service, _ := result.Types.Get("test.Service")
+	instance := service.(*gstypes.Struct).Fields()[0].Type().(*gstypes.Pointer).Elem()
+	for name, want := range map[string][]string{"Get": {"string", "int", "bool"}, "Set": {"string", "int"}} {
+		if got := signature(methods(instance)[name]); !reflect.DeepEqual(got, want) {
+			t.Errorf("Cache[string, int].%s types = %v, want %v", name, got, want)
+		}
+	}
+
+	data, err := json.Marshal(instance.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte(`"kind":"type_parameter"`)) || bytes.Contains(data, []byte(`"id":"K"`)) {
+		t.Errorf("serialized instance has unbound type parameters: %s", data)
+	}
+}
+
+func TestTypeResolver_embeddedGenericInterfaces(t *testing.T) {
+	src := `
+	package test
+
+	type Comparer[T any] interface {
+		Compare(other T) int
+		Clone() T
+	}
+
+	type IntComparer interface {
+		Comparer[int]
+	}
+
+	type StringComparer = Comparer[string]
+
+	type ViaAlias interface {
+		StringComparer
+	}
+
+	type Sorter interface {
+		IntComparer
+		Len() int
+	}
+
+	type Holder struct {
+		C interface{ Comparer[bool] }
+	}
+	`
+
+	result := scanTestSource(t, src)
+	signatures := func(typ gstypes.Type) map[string][]string {
+		t.Helper()
+		if err := typ.Load(); err != nil {
+			t.Fatal(err)
+		}
+		out := map[string][]string{}
+		for _, m := range typ.Methods() {
+			var types []string
+			for _, p := range m.Parameters() {
+				types = append(types, p.Type().Id())
+			}
+			for _, r := range m.Results() {
+				types = append(types, r.Type().Id())
+			}
+			out[m.Name()] = types
+		}
+		return out
+	}
+	get := func(id string) gstypes.Type {
+		t.Helper()
+		typ, ok := result.Types.Get(id)
+		if !ok {
+			t.Fatalf("expected %s", id)
+		}
+		return typ
+	}
+	holder := get("test.Holder").(*gstypes.Struct).Fields()[0].Type()
+
+	for _, tc := range []struct {
+		name string
+		typ  gstypes.Type
+		want map[string][]string
+	}{
+		{"IntComparer", get("test.IntComparer"), map[string][]string{"Compare": {"int", "int"}, "Clone": {"int"}}},
+		{"ViaAlias", get("test.ViaAlias"), map[string][]string{"Compare": {"string", "int"}, "Clone": {"string"}}},
+		{"Sorter", get("test.Sorter"), map[string][]string{"Compare": {"int", "int"}, "Clone": {"int"}, "Len": {"int"}}},
+		{"Holder.C", holder, map[string][]string{"Compare": {"bool", "int"}, "Clone": {"bool"}}},
+	} {
+		if got := signatures(tc.typ); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s methods = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// Test generics referring to themselves through their own type parameters
+func TestTypeResolver_recursiveGenerics(t *testing.T) {
+	src := `
+	package test
+
+	type Tree[T any] struct {
+		Value    T
+		Children []*Tree[T]
+	}
+
+	type Forest struct {
+		Trees []Tree[string]
+	}
+
+	type Comparer[T any] interface {
+		Compare(other T) int
+	}
+
+	func Max[T Comparer[T]](a, b T) T {
+		if a.Compare(b) > 0 {
+			return a
+		}
+		return b
+	}
+
+	type Left[T any] struct{ Right *Right[T] }
+	type Right[T any] struct{ Left *Left[T] }
+	`
+
+	result := scanTestSource(t, src)
+	if err := result.EnsureFullyLoaded(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(result.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree, _ := result.Types.Get("test.Tree")
+	children := tree.(*gstypes.Struct).Fields()[1].Type().(*gstypes.Slice).Elem().(*gstypes.Pointer).Elem()
+	self, ok := children.(*gstypes.InstantiatedGeneric)
+	if !ok || self.Origin() != tree {
+		t.Fatalf("Children element = %v, want an instance of test.Tree", children)
+	}
+	if _, ok := self.TypeArgs()[0].Type.(*gstypes.TypeParameter); !ok || self.TypeArgs()[0].Param != "T" {
+		t.Errorf("Children type args = %+v, want T", self.TypeArgs())
+	}
+
+	// The self reference is serialized as a reference to the origin, without members
+	var field map[string]any
+	raw, _ := json.Marshal(tree.(*gstypes.Struct).Fields()[1].Serialize())
+	if err := json.Unmarshal(raw, &field); err != nil {
+		t.Fatal(err)
+	}
+	ref := field["type"].(map[string]any)["element"].(map[string]any)["element"].(map[string]any)
+	if ref["origin"] != "test.Tree" || ref["fields"] != nil {
+		t.Errorf("Children reference = %v, want test.Tree without fields", ref)
+	}
+	arg := ref["typeArgs"].([]any)[0].(map[string]any)["type"].(map[string]any)
+	if arg["kind"] != string(gstypes.TypeKindTypeParameter) {
+		t.Errorf("Children type arg = %v, want a type parameter", arg)
+	}
+
+	// Concrete instances keep their members, with the nested self reference substituted
+	instance, ok := result.Types.Get("test.Tree[string]")
+	if !ok {
+		t.Fatal("expected test.Tree[string]")
+	}
+	fields := instance.(*gstypes.InstantiatedGeneric).Fields()
+	if len(fields) != 2 || fields[0].Type().Id() != "string" {
+		t.Fatalf("test.Tree[string] fields = %v", fields)
+	}
+	nested := fields[1].Type().(*gstypes.Slice).Elem().(*gstypes.Pointer).Elem()
+	if nested != instance {
+		t.Errorf("test.Tree[string] Children element = %v, want itself", nested)
+	}
+
+	// Constraints referring to their own type parameter resolve to the instance
+	maxFn, _ := result.Types.Get("test.Max")
+	constraint := maxFn.(*gstypes.Function).TypeParams()[0].Constraint()
+	if ig, ok := constraint.(*gstypes.InstantiatedGeneric); !ok || ig.Origin().Id() != "test.Comparer" {
+		t.Errorf("Max constraint = %v, want an instance of test.Comparer", constraint)
+	}
+
+	// The cache is rebuilt from the references
+	var serialized map[string]any
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := reconstructFromCache(serialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"test.Tree", "test.Tree[T]", "test.Left[T]", "test.Right[T]"} {
+		if _, ok := cached.Types.Get(id); !ok {
+			t.Errorf("cache is missing %s", id)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"context"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/pablor21/goscanner/logger"
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// newTestPackage parses and type checks src as package "test"
+func newTestPackage(t *testing.T, src string) *packages.Package {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	cfg := &types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := cfg.Check("test", fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &packages.Package{
+		ID:        "test",
+		Name:      "test",
+		PkgPath:   "test",
+		Fset:      fset,
+		Syntax:    []*ast.File{file},
+		Types:     pkg,
+		TypesInfo: info,
+	}
+}
+
+// newTestResolver type checks src and returns a resolver with a scanning context
+// positioned on the "test" package, ready for ResolveType calls
+func newTestResolver(t *testing.T, src string) (*defaultTypeResolver, *ScanningContext, *types.Package) {
+	t.Helper()
+	return newTestResolverWithConfig(t, src, NewDefaultConfig())
+}
+
+// newTestResolverWithConfig is newTestResolver with a custom configuration
+func newTestResolverWithConfig(t *testing.T, src string, cfg *Config) (*defaultTypeResolver, *ScanningContext, *types.Package) {
+	t.Helper()
+
+	pkg := newTestPackage(t, src)
+
+	l := logger.NewDefaultLogger()
+	r := NewDefaultTypeResolver(cfg, l)
+	scanCtx := NewScanningContext(context.Background(), cfg)
+	testPkg := gstypes.NewPackage("test", "test", nil)
+	testPkg.SetLogger(l)
+	scanCtx = scanCtx.WithPackage(testPkg)
+
+	return r, scanCtx, pkg.Types
+}
+
+// scanTestSource processes src as package "test" and returns the fully loaded result
+func scanTestSource(t *testing.T, src string) *ScanningResult {
+	t.Helper()
+	return scanTestSourceWithConfig(t, src, NewDefaultConfig())
+}
+
+// scanTestSourceWithConfig is scanTestSource with a custom configuration
+func scanTestSourceWithConfig(t *testing.T, src string, cfg *Config) *ScanningResult {
+	t.Helper()
+
+	cfg.LogLevel = "error"
+	r := NewDefaultTypeResolver(cfg, logger.NewDefaultLogger())
+	ctx := NewScanningContext(context.Background(), cfg)
+	if err := r.ProcessPackage(ctx, newTestPackage(t, src)); err != nil {
+		t.Fatalf("ProcessPackage() error = %v", err)
+	}
+
+	result := &ScanningResult{
+		Types:    r.GetTypes(),
+		Values:   r.GetValues(),
+		Packages: r.GetPackages(),
+		warnings: r.warnings,
+	}
+	loadTypes(ctx, result.Types)
+	result.Types = filterTypes(result.Types, r.resultFilter())
+	return result
+}
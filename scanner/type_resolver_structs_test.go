@@ -0,0 +1,710 @@
+package scanner
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+func TestTypeResolver_structEmbeddedNamesOrder(t *testing.T) {
+	src := `
+	package test
+
+	type Alpha struct{ A int }
+	type Beta struct{ B int }
+	type Gamma struct{ C int }
+
+	type Composite struct {
+		Gamma
+		Name string
+		*Alpha
+		Beta
+	}
+	`
+
+	r, scanCtx, pkg := newTestResolver(t, src)
+
+	obj := pkg.Scope().Lookup("Composite")
+	got := r.ResolveType(scanCtx, obj.Type())
+	if err := got.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	s, ok := got.(*gstypes.Struct)
+	if !ok {
+		t.Fatalf("expected *Struct, got %T", got)
+	}
+
+	want := []string{"test.Gamma", "test.Alpha", "test.Beta"}
+	if names := s.EmbeddedNames(); !reflect.DeepEqual(names, want) {
+		t.Errorf("EmbeddedNames() = %v, want %v", names, want)
+	}
+}
+
+func TestTypeResolver_structPromotesFromUnexportedEmbed(t *testing.T) {
+	src := `
+	package test
+
+	type base struct {
+		ID     int
+		secret string
+	}
+
+	func (b *base) GetID() int { return b.ID }
+	func (b base) hidden() {}
+
+	type Outer struct {
+		base
+		Name string
+	}
+	`
+
+	r, scanCtx, pkg := newTestResolver(t, src)
+	r.config.Visibility = VisibilityLevelExported
+
+	got := r.ResolveType(scanCtx, pkg.Scope().Lookup("Outer").Type())
+	if err := got.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	outer, ok := got.(*gstypes.Struct)
+	if !ok {
+		t.Fatalf("expected *Struct, got %T", got)
+	}
+
+	fields := map[string]*gstypes.Field{}
+	for _, f := range outer.Fields() {
+		fields[f.Name()] = f
+	}
+	id, ok := fields["ID"]
+	if !ok {
+		t.Fatalf("promoted field ID missing from Outer, fields: %v", outer.Fields())
+	}
+	if id.PromotedFrom() == nil || id.PromotedFrom().Id() != "test.base" {
+		t.Errorf("ID.PromotedFrom() = %v, want test.base", id.PromotedFrom())
+	}
+	if _, ok := fields["secret"]; ok {
+		t.Errorf("unexported field secret must not be promoted in exported-only mode")
+	}
+
+	methods := map[string]bool{}
+	for _, m := range outer.Methods() {
+		methods[m.Name()] = true
+	}
+	if !methods["GetID"] {
+		t.Errorf("promoted method GetID missing from Outer")
+	}
+	if methods["hidden"] {
+		t.Errorf("unexported method hidden must not be promoted in exported-only mode")
+	}
+}
+
+func TestTypeResolver_structShadowsPromotedFields(t *testing.T) {
+	src := `
+	package test
+
+	type Base struct {
+		ID    int    ` + "`json:\"base_id\"`" + `
+		Email string ` + "`json:\"email\" validate:\"email\"`" + `
+	}
+
+	type Outer struct {
+		Base
+		ID string ` + "`json:\"id\"`" + `
+	}
+	`
+
+	r, scanCtx, pkg := newTestResolver(t, src)
+	got := r.ResolveType(scanCtx, pkg.Scope().Lookup("Outer").Type())
+	if err := got.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	outer := got.(*gstypes.Struct)
+
+	var ids []*gstypes.Field
+	var email *gstypes.Field
+	for _, f := range outer.Fields() {
+		switch f.Name() {
+		case "ID":
+			ids = append(ids, f)
+		case "Email":
+			email = f
+		}
+	}
+
+	// The outer ID shadows Base.ID
+	if len(ids) != 1 {
+		t.Fatalf("expected a single ID field, got %d", len(ids))
+	}
+	if ids[0].PromotedFrom() != nil || ids[0].Tag() != `json:"id"` {
+		t.Errorf("ID = (promotedFrom %v, tag %q), want the outer declaration", ids[0].PromotedFrom(), ids[0].Tag())
+	}
+	if ids[0].Type().Id() != "string" {
+		t.Errorf("ID type = %s, want string", ids[0].Type().Id())
+	}
+
+	// Email is promoted with the tag of the embedded definition
+	if email == nil || email.PromotedFrom() == nil {
+		t.Fatalf("expected Email to be promoted from Base, got %v", email)
+	}
+	if email.Tag() != `json:"email" validate:"email"` {
+		t.Errorf("Email.Tag() = %q, want the embedded field tag", email.Tag())
+	}
+}
+
+func TestTypeResolver_excludeMethods(t *testing.T) {
+	src := `
+	package test
+
+	type Base struct{}
+
+	func (Base) Descriptor() []byte { return nil }
+
+	type Message struct{ Base }
+
+	func (Message) String() string                { return "" }
+	func (Message) MarshalJSON() ([]byte, error)  { return nil, nil }
+	func (Message) Reset()                        {}
+	func (Message) Get() int                      { return 0 }
+
+	type Other struct{}
+
+	func (Other) Reset() {}
+
+	type Stringer interface{ String() string }
+	`
+
+	cfg := NewDefaultConfig()
+	cfg.ExcludeMethods = []string{"String|MarshalJSON", "Message.Reset", "test.Base.Descriptor", "Descr"}
+	r, scanCtx, pkg := newTestResolverWithConfig(t, src, cfg)
+
+	methodNames := func(name string) map[string]bool {
+		got := r.ResolveType(scanCtx, pkg.Scope().Lookup(name).Type())
+		if err := got.Load(); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		names := map[string]bool{}
+		for _, m := range got.Methods() {
+			names[m.Name()] = true
+		}
+		return names
+	}
+
+	if got := methodNames("Message"); len(got) != 2 || !got["Get"] || !got["Descriptor"] {
+		// Descriptor is promoted from Base, the pattern targets Base's own method only
+		t.Errorf("Message methods = %v, want [Descriptor Get]", got)
+	}
+	if got := methodNames("Base"); len(got) != 0 {
+		t.Errorf("Base methods = %v, want none", got)
+	}
+	if got := methodNames("Other"); !got["Reset"] {
+		t.Errorf("Other.Reset must be kept, got %v", got)
+	}
+	// Interface methods define the interface and are never excluded
+	if got := methodNames("Stringer"); !got["String"] {
+		t.Errorf("Stringer.String must be kept, got %v", got)
+	}
+}
+
+// The value method set excludes pointer receiver methods, promoted methods are listed by
+// the id of the method on the embedded type
+func TestTypeResolver_methodSets(t *testing.T) {
+	src := `
+	package test
+
+	type Base struct{}
+
+	func (Base) ID() int       { return 0 }
+	func (*Base) SetID(id int) {}
+
+	type Logger struct{}
+
+	func (*Logger) Log(msg string) {}
+
+	type User struct {
+		Base
+		*Logger
+	}
+
+	func (u User) Name() string      { return "" }
+	func (u *User) SetName(n string) {}
+	`
+
+	result := scanTestSource(t, src)
+	user, _ := result.Types.Get("test.User")
+
+	wantValue := []string{"test.Base#ID", "test.Logger#Log", "test.User#Name"}
+	if got := user.ValueMethods(); !reflect.DeepEqual(got, wantValue) {
+		t.Errorf("ValueMethods() = %v, want %v", got, wantValue)
+	}
+	wantPointer := []string{"test.Base#ID", "test.Logger#Log", "test.Base#SetID", "test.User#SetName", "test.User#Name"}
+	sort.Strings(wantPointer)
+	got := append([]string(nil), user.PointerMethods()...)
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, wantPointer) {
+		t.Errorf("PointerMethods() = %v, want %v", got, wantPointer)
+	}
+
+	base, _ := result.Types.Get("test.Base")
+	if got := base.ValueMethods(); !reflect.DeepEqual(got, []string{"test.Base#ID"}) {
+		t.Errorf("Base ValueMethods() = %v", got)
+	}
+
+	serialized := user.Serialize().(*gstypes.SerializedStruct)
+	if len(serialized.ValueMethods) != 3 || len(serialized.PointerMethods) != 5 {
+		t.Errorf("serialized method sets = %v / %v", serialized.ValueMethods, serialized.PointerMethods)
+	}
+}
+
+func TestTypeResolver_constructors(t *testing.T) {
+	src := `
+	package test
+
+	type Options struct{}
+
+	type Client struct{}
+
+	func NewClient() *Client { return nil }
+
+	func NewClientWithOptions(opts Options) (*Client, error) { return nil, nil }
+
+	func newClient() Client { return Client{} }
+
+	func MakeClient() Client { return Client{} }
+
+	type ClientPool struct{}
+
+	func NewClientPool() ClientPool { return ClientPool{} }
+
+	type Box[T any] struct{ v T }
+
+	func NewBox[T any](v T) *Box[T] { return &Box[T]{v: v} }
+
+	type Empty struct{}
+	`
+
+	result := scanTestSource(t, src)
+	for id, want := range map[string][]string{
+		"test.Client":     {"test.NewClient", "test.NewClientWithOptions"},
+		"test.ClientPool": {"test.NewClientPool"},
+		"test.Box":        {"test.NewBox"},
+		"test.Empty":      nil,
+	} {
+		typ, _ := result.Types.Get(id)
+		strct := typ.(*gstypes.Struct)
+		if err := strct.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if got := strct.Constructors(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s.Constructors() = %v, want %v", id, got, want)
+		}
+	}
+
+	client, _ := result.Types.Get("test.Client")
+	if serialized := client.Serialize().(*gstypes.SerializedStruct); len(serialized.Constructors) != 2 {
+		t.Errorf("serialized constructors = %v", serialized.Constructors)
+	}
+}
+
+func TestTypeResolver_bitWidthTag(t *testing.T) {
+	src := `
+	package test
+
+	type Flags struct {
+		Kind    uint8  ` + "`bits:\"4\"`" + `
+		Ready   bool   ` + "`bits:\"1\" json:\"ready\"`" + `
+		Invalid uint8  ` + "`bits:\"wide\"`" + `
+		Plain   uint16
+	}
+
+	type Header struct {
+		Flags
+		Length uint32 ` + "`bits:\"24\"`" + `
+	}
+	`
+
+	widths := func(cfg *Config, id string) map[string]int {
+		t.Helper()
+		result := scanTestSourceWithConfig(t, src, cfg)
+		typ, _ := result.Types.Get(id)
+		got := map[string]int{}
+		for _, f := range typ.(*gstypes.Struct).Fields() {
+			got[f.Name()] = f.BitWidth()
+		}
+		return got
+	}
+
+	// Off by default
+	for name, bits := range widths(NewDefaultConfig(), "test.Flags") {
+		if bits != 0 {
+			t.Errorf("%s.BitWidth() = %d without BitWidthTag", name, bits)
+		}
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.BitWidthTag = "bits"
+	want := map[string]int{"Kind": 4, "Ready": 1, "Invalid": 0, "Plain": 0}
+	if got := widths(cfg, "test.Flags"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Flags widths = %v, want %v", got, want)
+	}
+	// Promoted fields keep the width
+	cfg = NewDefaultConfig()
+	cfg.BitWidthTag = "bits"
+	if got := widths(cfg, "test.Header"); got["Kind"] != 4 || got["Length"] != 24 {
+		t.Errorf("Header widths = %v", got)
+	}
+}
+
+func TestTypeResolver_fieldFilter(t *testing.T) {
+	src := `
+	package test
+
+	type Base struct {
+		ID       int
+		Internal string ` + "`codegen:\"skip\"`" + `
+	}
+
+	type User struct {
+		Base
+		Name     string
+		Password string ` + "`json:\"-\"`" + `
+	}
+
+	type Page[T any] struct {
+		Items  []T
+		cursor string ` + "`codegen:\"skip\"`" + `
+	}
+
+	type Users struct {
+		Page Page[User]
+	}
+	`
+
+	cfg := NewDefaultConfig()
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	cfg.FieldFilter = func(f *gstypes.Field) bool {
+		mu.Lock()
+		seen[f.Id()] = true
+		mu.Unlock()
+		tags := ParseTags(f.Tag())
+		return tags["json"] != "-" && tags["codegen"] != "skip"
+	}
+	result := scanTestSourceWithConfig(t, src, cfg)
+
+	names := func(fields []*gstypes.Field) []string {
+		var out []string
+		for _, f := range fields {
+			out = append(out, f.Name())
+		}
+		sort.Strings(out)
+		return out
+	}
+	base, _ := result.Types.Get("test.Base")
+	if got := names(base.(*gstypes.Struct).Fields()); !reflect.DeepEqual(got, []string{"ID"}) {
+		t.Errorf("Base fields = %v, want [ID]", got)
+	}
+	// Promoted fields are filtered too
+	user, _ := result.Types.Get("test.User")
+	if got := names(user.(*gstypes.Struct).Fields()); !reflect.DeepEqual(got, []string{"ID", "Name"}) {
+		t.Errorf("User fields = %v, want [ID Name]", got)
+	}
+	// And the fields of instantiated generics
+	users, _ := result.Types.Get("test.Users")
+	page := users.(*gstypes.Struct).Fields()[0].Type().(*gstypes.InstantiatedGeneric)
+	if err := page.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(page.Fields()); !reflect.DeepEqual(got, []string{"Items"}) {
+		t.Errorf("Page[User] fields = %v, want [Items]", got)
+	}
+
+	if !seen["test.User#Password"] {
+		t.Errorf("FieldFilter not called for test.User#Password")
+	}
+}
+
+func TestTypeResolver_fieldIndex(t *testing.T) {
+	src := `
+	package test
+
+	type Base struct {
+		ID      int
+		version int
+		Created string
+	}
+
+	type User struct {
+		Name string
+		Base
+		secret string
+		Email  string
+	}
+
+	type Page[T any] struct {
+		Items []T
+		Total int
+	}
+
+	type Users struct {
+		Page Page[User]
+	}
+	`
+	result := scanTestSource(t, src)
+
+	indexes := func(fields []*gstypes.Field) map[string]int {
+		out := map[string]int{}
+		for _, f := range fields {
+			out[f.Name()] = f.Index()
+		}
+		return out
+	}
+	// Embedded fields count, promoted fields keep their index in the embed
+	user, _ := result.Types.Get("test.User")
+	fields := user.(*gstypes.Struct).Fields()
+	if got, want := indexes(fields), map[string]int{"Name": 0, "ID": 0, "version": 1, "Created": 2, "secret": 2, "Email": 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("User field indexes = %v, want %v", got, want)
+	}
+	data, err := json.Marshal(fields[0].Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"index":0`) {
+		t.Errorf("serialized field has no index: %s", data)
+	}
+
+	users, _ := result.Types.Get("test.Users")
+	page := users.(*gstypes.Struct).Fields()[0].Type().(*gstypes.InstantiatedGeneric)
+	if err := page.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := indexes(page.Fields()), map[string]int{"Items": 0, "Total": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Page[User] field indexes = %v, want %v", got, want)
+	}
+}
+
+func TestTypeResolver_typeFilter(t *testing.T) {
+	src := `
+	package test
+
+	type Role string
+
+	type Store interface {
+		Save(u User) error
+	}
+
+	type User struct {
+		Name string
+		Role Role
+	}
+
+	type Empty struct{}
+	`
+
+	cfg := NewDefaultConfig()
+	cfg.TypeFilter = func(typ gstypes.Type) bool {
+		// Types are loaded: the fields of structs are known
+		strct, ok := typ.(*gstypes.Struct)
+		return ok && len(strct.Fields()) > 0
+	}
+	result := scanTestSourceWithConfig(t, src, cfg)
+
+	for _, id := range []string{"test.Role", "test.Store", "test.Empty"} {
+		if result.Types.Has(id) {
+			t.Errorf("%s should be filtered out", id)
+		}
+	}
+	user, ok := result.Types.Get("test.User")
+	if !ok {
+		t.Fatal("expected test.User in the result")
+	}
+	// Filtered types are still resolved when referenced
+	if role := user.(*gstypes.Struct).Fields()[1].Type(); role == nil || role.Id() != "test.Role" {
+		t.Errorf("User.Role type = %v, want test.Role", role)
+	}
+}
+
+func TestTypeResolver_includeExcludePatterns(t *testing.T) {
+	src := `
+	package test
+
+	type Status int
+
+	const (
+		Active Status = iota
+		Inactive
+	)
+
+	type Profile struct {
+		Bio string
+	}
+
+	type User struct {
+		ID       int
+		Password string
+		Profile  Profile
+	}
+
+	func (u User) Hash() string     { return "" }
+	func (u User) Validate() error { return nil }
+
+	type UserRequest struct {
+		Name string
+	}
+	`
+
+	namedIDs := func(result *ScanningResult) []string {
+		var ids []string
+		for _, typ := range result.Types.Values() {
+			if typ.IsNamed() && typ.Package() != nil {
+				ids = append(ids, typ.Id())
+			}
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.IncludeTypes = []string{"User.*"}
+	cfg.ExcludeTypes = []string{"test\\..*Request"}
+	cfg.ExcludeFields = []string{"User.Password"}
+	cfg.IncludeMethods = []string{"Validate"}
+	result := scanTestSourceWithConfig(t, src, cfg)
+
+	// Profile is resolved as the type of a field, but left out of the result
+	if got, want := namedIDs(result), []string{"test.User"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("types = %v, want %v", got, want)
+	}
+	// The constants of filtered types go with them
+	if n := len(result.Values.Keys()); n != 0 {
+		t.Errorf("values = %v, want none", result.Values.Keys())
+	}
+	user, _ := result.Types.Get("test.User")
+	var fields, methods []string
+	for _, f := range user.(*gstypes.Struct).Fields() {
+		fields = append(fields, f.Name())
+	}
+	for _, m := range user.Methods() {
+		methods = append(methods, m.Name())
+	}
+	if want := []string{"ID", "Profile"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("User fields = %v, want %v", fields, want)
+	}
+	if want := []string{"Validate"}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("User methods = %v, want %v", methods, want)
+	}
+
+	cfg = NewDefaultConfig()
+	cfg.ExcludePackages = []string{"te.t"}
+	if got := namedIDs(scanTestSourceWithConfig(t, src, cfg)); len(got) != 0 {
+		t.Errorf("types of an excluded package = %v, want none", got)
+	}
+}
+
+func TestTypeResolver_anonymousStructDedup(t *testing.T) {
+	src := `
+	package test
+
+	type Order struct {
+		Billing  struct{ Street, City string }
+		Shipping struct{ Street, City string }
+		Tagged   struct {
+			Street string ` + "`json:\"street\"`" + `
+			City   string
+		}
+		History []struct{ Street, City string }
+	}
+
+	type Customer struct {
+		Home struct{ Street, City string }
+	}
+	`
+
+	result := scanTestSource(t, src)
+	fieldTypes := func(id string) map[string]gstypes.Type {
+		t.Helper()
+		typ, ok := result.Types.Get(id)
+		if !ok {
+			t.Fatalf("expected type %s", id)
+		}
+		got := map[string]gstypes.Type{}
+		for _, f := range typ.(*gstypes.Struct).Fields() {
+			got[f.Name()] = f.Type()
+		}
+		return got
+	}
+
+	order := fieldTypes("test.Order")
+	billing := order["Billing"]
+	if _, ok := billing.(*gstypes.Struct); !ok || billing.IsNamed() {
+		t.Fatalf("Billing = %T, want an anonymous struct", billing)
+	}
+	if err := billing.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if fields := billing.(*gstypes.Struct).Fields(); len(fields) != 2 {
+		t.Errorf("shared struct fields = %v, want Street and City", fields)
+	}
+
+	same := map[string]gstypes.Type{
+		"Order.Shipping":  order["Shipping"],
+		"Order.History[]": order["History"].(*gstypes.Slice).Elem(),
+		"Customer.Home":   fieldTypes("test.Customer")["Home"],
+	}
+	for name, typ := range same {
+		if typ != billing {
+			t.Errorf("%s = %v, want the Billing struct %s", name, typ.Id(), billing.Id())
+		}
+	}
+	// Tags are part of the shape
+	if tagged := order["Tagged"]; tagged == billing || tagged.Id() == billing.Id() {
+		t.Errorf("Tagged shares the Billing struct, tags differ")
+	}
+}
+
+func TestTypeResolver_fieldKindPath(t *testing.T) {
+	src := `
+	package test
+
+	type User struct{ Name string }
+
+	type List[T any] []T
+
+	type Form struct {
+		Groups  map[string][]*User
+		Owner   **User
+		Matrix  [2][3]int
+		Events  chan User
+		Names   List[string]
+		Extra   any
+		Address struct{ Street string }
+		OnSave  func()
+	}
+	`
+
+	result := scanTestSource(t, src)
+	form, _ := result.Types.Get("test.Form")
+	if err := form.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"Groups":  []any{"map", "string", []any{"slice", []any{"pointer", "test.User"}}},
+		"Owner":   []any{"pointer", []any{"pointer", "test.User"}},
+		"Matrix":  []any{"array", int64(2), []any{"array", int64(3), "int"}},
+		"Events":  []any{"chan", "test.User"},
+		"Names":   "test.List[string]",
+		"Extra":   "any",
+		"Address": "struct",
+		"OnSave":  "function",
+	}
+	for _, f := range form.(*gstypes.Struct).Fields() {
+		if got := f.KindPath(); !reflect.DeepEqual(got, want[f.Name()]) {
+			t.Errorf("%s kind path = %#v, want %#v", f.Name(), got, want[f.Name()])
+		}
+	}
+}
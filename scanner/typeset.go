@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"go/types"
+
+	gstypes "github.com/pablor21/goscanner/types"
+)
+
+// makeTypeSet computes the type set of a general interface: the intersection of the terms
+// of its embedded elements, with unions and the constraint interfaces used in them flattened
+func (r *defaultTypeResolver) makeTypeSet(ctx *ScanningContext, iface *types.Interface) *gstypes.TypeSet {
+	typeSet := &gstypes.TypeSet{}
+	terms, _ := interfaceTerms(iface)
+	for _, term := range terms {
+		if resolved := r.ResolveType(ctx, term.Type()); resolved != nil {
+			typeSet.Terms = append(typeSet.Terms, gstypes.NewUnionTerm(resolved, term.Tilde()))
+		}
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		typeSet.Methods = append(typeSet.Methods, iface.Method(i).Name())
+	}
+	return typeSet
+}
+
+// interfaceTerms returns the terms of the type set of iface, all is set when the interface
+// doesn't restrict the types (a basic interface)
+func interfaceTerms(iface *types.Interface) (terms []*types.Term, all bool) {
+	all = true
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		elemTerms, elemAll := elementTerms(iface.EmbeddedType(i))
+		switch {
+		case elemAll:
+		case all:
+			terms, all = elemTerms, false
+		default:
+			terms = intersectTerms(terms, elemTerms)
+		}
+	}
+	return terms, all
+}
+
+// elementTerms returns the terms of an embedded interface element: a union, an interface or
+// a single type
+func elementTerms(t types.Type) ([]*types.Term, bool) {
+	if union, ok := t.(*types.Union); ok {
+		var terms []*types.Term
+		for i := 0; i < union.Len(); i++ {
+			term := union.Term(i)
+			if iface, ok := term.Type().Underlying().(*types.Interface); ok {
+				ifaceTerms, all := interfaceTerms(iface)
+				if all {
+					return nil, true
+				}
+				terms = appendTerms(terms, ifaceTerms...)
+				continue
+			}
+			terms = appendTerms(terms, term)
+		}
+		return terms, false
+	}
+	if iface, ok := t.Underlying().(*types.Interface); ok {
+		return interfaceTerms(iface)
+	}
+	return []*types.Term{types.NewTerm(false, t)}, false
+}
+
+// intersectTerms returns the terms in both a and b
+func intersectTerms(a, b []*types.Term) []*types.Term {
+	var res []*types.Term
+	for _, x := range a {
+		for _, y := range b {
+			if term := intersectTerm(x, y); term != nil {
+				res = appendTerms(res, term)
+			}
+		}
+	}
+	return res
+}
+
+// intersectTerm returns the intersection of two terms, nil when it's empty
+func intersectTerm(x, y *types.Term) *types.Term {
+	switch {
+	case x.Tilde() && y.Tilde():
+		if types.Identical(x.Type(), y.Type()) {
+			return x
+		}
+	case x.Tilde():
+		// ~int and MyInt: MyInt
+		if types.Identical(x.Type(), y.Type().Underlying()) {
+			return y
+		}
+	case y.Tilde():
+		if types.Identical(y.Type(), x.Type().Underlying()) {
+			return x
+		}
+	default:
+		if types.Identical(x.Type(), y.Type()) {
+			return x
+		}
+	}
+	return nil
+}
+
+// appendTerms appends the terms not already in terms
+func appendTerms(terms []*types.Term, add ...*types.Term) []*types.Term {
+	for _, term := range add {
+		dup := false
+		for _, existing := range terms {
+			if existing.Tilde() == term.Tilde() && types.Identical(existing.Type(), term.Type()) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
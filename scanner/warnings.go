@@ -0,0 +1,35 @@
+package scanner
+
+import (
+	"fmt"
+	"sync"
+)
+
+// warningLog collects the resolution warnings of a scan. Types load lazily, so warnings keep
+// being added after the scan returns (thread-safe).
+type warningLog struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (w *warningLog) add(msg string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, msg)
+}
+
+func (w *warningLog) list() []string {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.messages...)
+}
+
+// warnf logs a resolution warning and records it in the warnings of the result
+func (r *defaultTypeResolver) warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	r.logger.Warn(msg)
+	r.warnings.add(msg)
+}
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// yamlPlain matches the strings written as plain YAML scalars, the others are double quoted
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_./-]*$`)
+
+// yamlReserved are the plain scalars YAML reads as booleans or null
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"y": true, "n": true, "null": true,
+}
+
+// yamlWriter writes the root object of EncodeWithOptions as block style YAML, indented by two
+// spaces, with the keys of maps sorted. Write errors are kept and returned by end.
+type yamlWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func newYAMLWriter(w io.Writer) *yamlWriter {
+	return &yamlWriter{w: bufio.NewWriter(w)}
+}
+
+func (yw *yamlWriter) begin() {}
+
+func (yw *yamlWriter) writeField(key string, value any, _ bool) {
+	yw.writeString(yamlScalar(key) + ":")
+	yw.writeValue(value, 2)
+}
+
+func (yw *yamlWriter) openTable(key string, _ bool) {
+	yw.writeString(yamlScalar(key) + ":")
+}
+
+func (yw *yamlWriter) writeEntry(id string, node any, first bool) {
+	if first {
+		yw.writeString("\n")
+	}
+	yw.writeString("  " + yamlScalar(id) + ":")
+	yw.writeValue(node, 4)
+}
+
+func (yw *yamlWriter) closeTable(empty bool) {
+	if empty {
+		yw.writeString(" {}\n")
+	}
+}
+
+func (yw *yamlWriter) end() error {
+	if yw.err != nil {
+		return yw.err
+	}
+	return yw.w.Flush()
+}
+
+func (yw *yamlWriter) writeString(s string) {
+	if yw.err == nil {
+		_, yw.err = yw.w.WriteString(s)
+	}
+}
+
+// writeValue writes node after a "key:" or "-" on the current line, nested blocks indented
+// by indent spaces
+func (yw *yamlWriter) writeValue(node any, indent int) {
+	switch v := node.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			yw.writeString(" {}\n")
+			return
+		}
+		yw.writeString("\n")
+		yw.writeMap(v, indent, false)
+	case []any:
+		if len(v) == 0 {
+			yw.writeString(" []\n")
+			return
+		}
+		yw.writeString("\n")
+		yw.writeList(v, indent, false)
+	default:
+		yw.writeString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+// writeMap writes the entries of m at indent, the first one on the current line when inline
+func (yw *yamlWriter) writeMap(m map[string]any, indent int, inline bool) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		if i > 0 || !inline {
+			yw.writeString(strings.Repeat(" ", indent))
+		}
+		yw.writeString(yamlScalar(key) + ":")
+		yw.writeValue(m[key], indent+2)
+	}
+}
+
+// writeList writes the items of l at indent, the first one on the current line when inline.
+// Non empty maps and lists start on the line of their dash.
+func (yw *yamlWriter) writeList(l []any, indent int, inline bool) {
+	for i, item := range l {
+		if i > 0 || !inline {
+			yw.writeString(strings.Repeat(" ", indent))
+		}
+		switch v := item.(type) {
+		case map[string]any:
+			if len(v) > 0 {
+				yw.writeString("- ")
+				yw.writeMap(v, indent+2, true)
+				continue
+			}
+		case []any:
+			if len(v) > 0 {
+				yw.writeString("- ")
+				yw.writeList(v, indent+2, true)
+				continue
+			}
+		}
+		yw.writeString("-")
+		yw.writeValue(item, indent+2)
+	}
+}
+
+// yamlScalar renders a scalar of a generic JSON tree. Strings that could be read as another
+// type or hold special characters are double quoted, with JSON escapes (valid in YAML).
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if yamlPlain.MatchString(v) && !yamlReserved[strings.ToLower(v)] {
+			return v
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return `""`
+	}
+	return string(b)
+}
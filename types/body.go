@@ -0,0 +1,45 @@
+package types
+
+// BodyFlags are facts about a function or method body, captured with ScanModeFunctionBodies
+type BodyFlags struct {
+	UsesUnsafe  bool `json:"usesUnsafe,omitempty"`  // references the unsafe package
+	UsesReflect bool `json:"usesReflect,omitempty"` // references the reflect package
+	UsesCGo     bool `json:"usesCGo,omitempty"`     // calls into C through cgo
+}
+
+func (f *Function) UsesUnsafe() bool  { return f.body.UsesUnsafe }
+func (f *Function) UsesReflect() bool { return f.body.UsesReflect }
+func (f *Function) UsesCGo() bool     { return f.body.UsesCGo }
+
+func (f *Function) SetBodyFlags(flags BodyFlags) {
+	f.body = flags
+}
+
+func (m *Method) UsesUnsafe() bool  { return m.body.UsesUnsafe }
+func (m *Method) UsesReflect() bool { return m.body.UsesReflect }
+func (m *Method) UsesCGo() bool     { return m.body.UsesCGo }
+
+func (m *Method) SetBodyFlags(flags BodyFlags) {
+	m.body = flags
+}
+
+// Linkage tells how a function or method without a Go body is implemented, captured from
+// its declaration and the //go:linkname directives of its file
+type Linkage struct {
+	Assembly bool   `json:"assembly,omitempty"` // declared without a body, implemented in assembly
+	Linkname string `json:"linkname,omitempty"` // symbol of the //go:linkname directive naming it
+}
+
+func (f *Function) IsAssembly() bool { return f.linkage.Assembly }
+func (f *Function) Linkname() string { return f.linkage.Linkname }
+
+func (f *Function) SetLinkage(linkage Linkage) {
+	f.linkage = linkage
+}
+
+func (m *Method) IsAssembly() bool { return m.linkage.Assembly }
+func (m *Method) Linkname() string { return m.linkage.Linkname }
+
+func (m *Method) SetLinkage(linkage Linkage) {
+	m.linkage = linkage
+}
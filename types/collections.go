@@ -1,6 +1,9 @@
 package types
 
-import "sync"
+import (
+	"iter"
+	"sync"
+)
 
 // SyncMap is a generic goroutine-safe map with read-write mutex protection
 type SyncMap[K comparable, V any] struct {
@@ -75,17 +78,35 @@ func (m *SyncMap[K, V]) Clear() {
 }
 
 // Range calls the given function for each key-value pair in the map.
-// If the function returns false, iteration stops.
+// If the function returns false, iteration stops. It iterates over a snapshot, so fn may
+// modify the map (a lazy loader setting the types it resolves) without deadlocking.
 func (m *SyncMap[K, V]) Range(fn func(key K, value V) bool) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	for k, v := range m.values {
+	for k, v := range m.Snapshot() {
 		if !fn(k, v) {
 			break
 		}
 	}
 }
 
+// All returns an iterator over a snapshot of the map, like Range
+func (m *SyncMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.Range(yield)
+	}
+}
+
+// Snapshot returns a copy of the map, consistent at the time of the call. Later changes
+// to the map don't affect it.
+func (m *SyncMap[K, V]) Snapshot() map[K]V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	snapshot := make(map[K]V, len(m.values))
+	for k, v := range m.values {
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
 // GetOrSet returns the existing value for the key if present.
 // Otherwise, it stores and returns the given value.
 // The bool return value indicates whether the value was loaded (true) or stored (false).
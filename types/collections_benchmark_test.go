@@ -95,6 +95,29 @@ func BenchmarkSyncMap_Range(b *testing.B) {
 	}
 }
 
+// Benchmark SyncMap Range over a snapshot while other goroutines write
+func BenchmarkSyncMap_RangeConcurrentWrites(b *testing.B) {
+	m := NewSyncMap[string, int]()
+	for i := 0; i < 1000; i++ {
+		m.Set(fmt.Sprintf("key%d", i), i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%10 == 0 {
+				m.Range(func(key string, value int) bool {
+					return true
+				})
+			} else {
+				m.Set(fmt.Sprintf("key%d", i%1000), i)
+			}
+			i++
+		}
+	})
+}
+
 // Benchmark SyncSlice concurrent appends
 func BenchmarkSyncSlice_ConcurrentAppends(b *testing.B) {
 	s := NewSyncSlice[int]()
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestTypesCol_concurrentAccess(t *testing.T) {
+	col := NewTypesCol[Type]()
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("pkg.T%d", i)
+		col.Set(id, NewStruct(id, id))
+	}
+
+	// Callbacks may add to the collection, as lazy loaders do, without deadlocking
+	col.Range(func(id string, _ Type) bool {
+		col.Set(id+"Loaded", NewStruct(id+"Loaded", id))
+		return true
+	})
+	if col.Len() != 200 {
+		t.Fatalf("Len() = %d, want 200", col.Len())
+	}
+
+	// Readers query the collection while writers add to it
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				id := fmt.Sprintf("pkg.W%d_%d", w, i)
+				col.Set(id, NewStruct(id, id))
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				n := 0
+				for range col.All() {
+					n++
+				}
+				if n < 200 {
+					t.Errorf("All() yielded %d types, want at least 200", n)
+				}
+				if m := col.Serialize().(map[string]any); len(m) < 200 {
+					t.Errorf("Serialize() has %d types, want at least 200", len(m))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if col.Len() != 600 {
+		t.Errorf("Len() = %d, want 600", col.Len())
+	}
+}
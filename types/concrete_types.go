@@ -7,7 +7,9 @@
 package types
 
 import (
+	"go/constant"
 	"go/doc"
+	"slices"
 )
 
 // serializeTypeRef serializes a type as a reference (basic info only)
@@ -16,17 +18,22 @@ func serializeTypeRef(t Type) any {
 		return nil
 	}
 
+	// For InstantiatedGeneric, include full serialization with origin and typeArgs. Recursive
+	// ones (Tree[T] in the Children []*Tree[T] field of Tree[T]) are only a reference, their
+	// members are in the registry
+	if ig, ok := t.(*InstantiatedGeneric); ok {
+		if ig.isRecursive() {
+			return ig.serializeRef()
+		}
+		return ig.Serialize()
+	}
+
 	// For unnamed types, we need full serialization since they won't appear in the global types registry
 	// Named types can be just a reference since they're in the cache
 	if !t.IsNamed() {
 		return t.Serialize()
 	}
 
-	// For InstantiatedGeneric, include full serialization with origin and typeArgs
-	if ig, ok := t.(*InstantiatedGeneric); ok {
-		return ig.Serialize()
-	}
-
 	// For named types, return minimal reference (they're in the global registry)
 	return map[string]any{
 		"id":   t.Id(),
@@ -62,16 +69,36 @@ func serializeTypeOrID(t Type) any {
 // For named basic types like `type MyInt int`, the underlying field points to the cached basic type
 type Basic struct {
 	baseType
-	underlying Type // For named basic types, points to the primitive basic type
+	underlying  Type   // For named basic types, points to the primitive basic type
+	predeclared string // Stable name of the predeclared identifiers that aren't basic types
+}
+
+// predeclaredNames maps the ids of the predeclared identifiers represented as basics without
+// being basic types (interfaces and constraints) to a stable name
+var predeclaredNames = map[string]string{
+	"comparable": "comparable",
+	"error":      "error",
 }
 
 // NewBasic creates a new basic type
 func NewBasic(id string, name string) *Basic {
 	return &Basic{
-		baseType: newBaseType(id, name, TypeKindBasic),
+		baseType:    newBaseType(id, name, TypeKindBasic),
+		predeclared: predeclaredNames[id],
 	}
 }
 
+// IsPredeclared reports whether b is the predeclared comparable or error, which are
+// represented as basics but are interfaces (comparable only usable as a constraint)
+func (b *Basic) IsPredeclared() bool {
+	return b.predeclared != ""
+}
+
+// PredeclaredName returns the stable name of a predeclared identifier, empty for other types
+func (b *Basic) PredeclaredName() string {
+	return b.predeclared
+}
+
 // Underlying returns the underlying type (for named basic types)
 func (b *Basic) Underlying() Type {
 	return b.underlying
@@ -89,8 +116,10 @@ func (b *Basic) Serialize() any {
 	}
 
 	return &SerializedBasic{
-		SerializedType: b.serializeBase(),
-		Underlying:     underlyingSerialized,
+		SerializedType:  b.serializeBase(),
+		Underlying:      underlyingSerialized,
+		Predeclared:     b.IsPredeclared(),
+		PredeclaredName: b.predeclared,
 	}
 }
 
@@ -137,19 +166,11 @@ func (p *Pointer) Serialize() any {
 	// Avoid calling Load() here to prevent reentrancy deadlocks
 	var elemSerialized any
 	if p.elem != nil {
-		if p.elem.IsNamed() {
-			elemSerialized = serializeTypeRef(p.elem)
-		} else {
-			elemSerialized = p.elem.Serialize()
-		}
+		// Named types are references, instances and unnamed types are serialized in full
+		elemSerialized = serializeTypeRef(p.elem)
 	}
 
-	structure := p.name
-	if p.obj != nil && p.obj.Type() != nil {
-		structure = p.obj.Type().Underlying().String()
-	} else if p.goType != nil {
-		structure = p.goType.String()
-	}
+	structure := p.goStructure(p.name)
 
 	return &SerializedPointer{
 		SerializedType: p.serializeBase(),
@@ -208,30 +229,36 @@ func (s *Slice) IsArray() bool {
 	return s.len >= 0
 }
 
+// Dimensions returns the lengths of the nested unnamed arrays starting at s, outermost
+// first ([2 3] for [2][3]float64), nil for slices
+func (s *Slice) Dimensions() []int64 {
+	var dims []int64
+	for arr := s; arr != nil && arr.IsArray(); {
+		dims = append(dims, arr.len)
+		elem, ok := arr.elem.(*Slice)
+		if !ok || elem.IsNamed() {
+			break
+		}
+		arr = elem
+	}
+	return dims
+}
+
 func (s *Slice) Serialize() any {
 	// Avoid calling Load() here to prevent reentrancy deadlocks
 
 	var elemSerialized any
 	if s.elem != nil {
-		// If element is a named type, serialize only basic info (reference)
-		if s.elem.IsNamed() {
-			elemSerialized = serializeTypeRef(s.elem)
-		} else {
-			elemSerialized = s.elem.Serialize()
-		}
+		elemSerialized = serializeTypeRef(s.elem)
 	}
 
-	structure := s.name
-	if s.obj != nil && s.obj.Type() != nil {
-		structure = s.obj.Type().Underlying().String()
-	} else if s.goType != nil {
-		structure = s.goType.String()
-	}
+	structure := s.goStructure(s.name)
 
 	return &SerializedSlice{
 		SerializedType: s.serializeBase(),
 		Element:        elemSerialized,
 		Length:         s.len,
+		Dimensions:     s.Dimensions(),
 		Structure:      structure,
 	}
 }
@@ -279,19 +306,10 @@ func (c *Chan) Serialize() any {
 	// Avoid calling Load() here to prevent reentrancy deadlocks
 	var elemSerialized any
 	if c.elem != nil {
-		if c.elem.IsNamed() {
-			elemSerialized = serializeTypeRef(c.elem)
-		} else {
-			elemSerialized = c.elem.Serialize()
-		}
+		elemSerialized = serializeTypeRef(c.elem)
 	}
 
-	structure := c.name
-	if c.obj != nil && c.obj.Type() != nil {
-		structure = c.obj.Type().Underlying().String()
-	} else if c.goType != nil {
-		structure = c.goType.String()
-	}
+	structure := c.goStructure(c.name)
 
 	return &SerializedChan{
 		SerializedType: c.serializeBase(),
@@ -345,30 +363,15 @@ func (m *Map) Serialize() any {
 
 	var keySerialized any
 	if m.key != nil {
-		// If key is a named type, serialize only basic info (reference)
-		if m.key.IsNamed() {
-			keySerialized = serializeTypeRef(m.key)
-		} else {
-			keySerialized = m.key.Serialize()
-		}
+		keySerialized = serializeTypeRef(m.key)
 	}
 
 	var valueSerialized any
 	if m.value != nil {
-		// If value is a named type, serialize only basic info (reference)
-		if m.value.IsNamed() {
-			valueSerialized = serializeTypeRef(m.value)
-		} else {
-			valueSerialized = m.value.Serialize()
-		}
+		valueSerialized = serializeTypeRef(m.value)
 	}
 
-	structure := m.name
-	if m.obj != nil && m.obj.Type() != nil {
-		structure = m.obj.Type().Underlying().String()
-	} else if m.goType != nil {
-		structure = m.goType.String()
-	}
+	structure := m.goStructure(m.name)
 
 	return &SerializedMap{
 		SerializedType: m.serializeBase(),
@@ -416,14 +419,9 @@ func (a *Alias) UnderlyingType() Type {
 
 func (a *Alias) Serialize() any {
 	// Avoid calling Load() here to prevent reentrancy deadlocks
-	var underlyingSerialized any
-	if a.underlying != nil {
-		underlyingSerialized = a.underlying.Serialize()
-	}
-
 	return &SerializedAlias{
 		SerializedType: a.serializeBase(),
-		Underlying:     underlyingSerialized,
+		Underlying:     serializeTypeOrID(a.underlying),
 	}
 }
 
@@ -442,11 +440,14 @@ func (a *Alias) Load() error {
 	return err
 }
 
-// Parameter represents a function/method parameter
+// Parameter represents a function/method parameter.
+// For variadic parameters (...T) the type is the element type T, see SliceType for []T.
 type Parameter struct {
 	name       string
 	paramType  Type
 	isVariadic bool
+	sliceType  Type      // []T form of a variadic parameter
+	pos        *Position // Declaration position (only with Config.IncludePositions)
 }
 
 // NewParameter creates a new parameter
@@ -470,10 +471,40 @@ func (p *Parameter) IsVariadic() bool {
 	return p.isVariadic
 }
 
+// SliceType returns the []T type a variadic parameter has inside the function body,
+// or nil for regular parameters
+func (p *Parameter) SliceType() Type {
+	if !p.isVariadic {
+		return nil
+	}
+	if p.sliceType == nil && p.paramType != nil {
+		// Rebuilt parameters (e.g. from the cache) only know the element type
+		id := "[]" + p.paramType.Id()
+		slice := NewSlice(id, id, p.paramType)
+		slice.SetPackage(p.paramType.Package())
+		p.sliceType = slice
+	}
+	return p.sliceType
+}
+
+func (p *Parameter) SetSliceType(sliceType Type) {
+	p.sliceType = sliceType
+}
+
+// Position returns the declaration position (nil unless Config.IncludePositions is set)
+func (p *Parameter) Position() *Position {
+	return p.pos
+}
+
+func (p *Parameter) SetPosition(pos *Position) {
+	p.pos = pos
+}
+
 // Result represents a function/method return value
 type Result struct {
 	name       string
 	resultType Type
+	pos        *Position // Declaration position (only with Config.IncludePositions)
 }
 
 // NewResult creates a new result
@@ -492,6 +523,15 @@ func (r *Result) Type() Type {
 	return r.resultType
 }
 
+// Position returns the declaration position (nil unless Config.IncludePositions is set)
+func (r *Result) Position() *Position {
+	return r.pos
+}
+
+func (r *Result) SetPosition(pos *Position) {
+	r.pos = pos
+}
+
 // Function represents a function/signature type
 type Function struct {
 	baseType
@@ -501,6 +541,8 @@ type Function struct {
 	docFunc    *doc.Func        // for package-level functions
 	structure  string           // full signature string
 	typeParams []*TypeParameter // type parameters for generic functions
+	body       BodyFlags
+	linkage    Linkage
 }
 
 // NewFunction creates a new function type
@@ -544,6 +586,12 @@ func (f *Function) SetDocFunc(docFunc *doc.Func) {
 	f.docFunc = docFunc
 }
 
+// Detach drops the go/types and go/doc data of the function (see baseType.Detach)
+func (f *Function) Detach() {
+	f.baseType.Detach()
+	f.docFunc = nil
+}
+
 func (f *Function) SetStructure(structure string) {
 	f.structure = structure
 }
@@ -564,6 +612,7 @@ func (f *Function) Serialize() any {
 			Name:       p.name,
 			Type:       serializeTypeOrID(p.paramType),
 			IsVariadic: p.isVariadic,
+			Position:   p.pos,
 		}
 		// Old full serialization logic (commented out)
 		// var paramTypeSerialized any
@@ -579,8 +628,9 @@ func (f *Function) Serialize() any {
 	results := make([]*SerializedResult, len(f.results))
 	for i, r := range f.results {
 		results[i] = &SerializedResult{
-			Name: r.name,
-			Type: serializeTypeOrID(r.resultType),
+			Name:     r.name,
+			Type:     serializeTypeOrID(r.resultType),
+			Position: r.pos,
 		}
 		// Old full serialization logic (commented out)
 		// var resultTypeSerialized any
@@ -605,6 +655,8 @@ func (f *Function) Serialize() any {
 		IsVariadic:     f.isVariadic,
 		Structure:      f.structure,
 		TypeParams:     typeParams,
+		BodyFlags:      f.body,
+		Linkage:        f.linkage,
 	}
 }
 
@@ -615,10 +667,11 @@ func (f *Function) Load() error {
 		if f.loader != nil {
 			err = f.loader(f)
 		}
-		// Load parameter types
+		// Load parameter and result types. Named ones load on their own, loading them here
+		// deadlocks on recursive signatures (type stateFn func(*lexer) stateFn).
 		if err == nil {
 			for _, p := range f.params {
-				if p.paramType != nil {
+				if p.paramType != nil && !p.paramType.IsNamed() {
 					err = p.paramType.Load()
 					if err != nil {
 						return
@@ -626,10 +679,9 @@ func (f *Function) Load() error {
 				}
 			}
 		}
-		// Load result types
 		if err == nil {
 			for _, r := range f.results {
-				if r.resultType != nil {
+				if r.resultType != nil && !r.resultType.IsNamed() {
 					err = r.resultType.Load()
 					if err != nil {
 						return
@@ -644,8 +696,21 @@ func (f *Function) Load() error {
 // Interface represents an interface type
 type Interface struct {
 	baseType
-	embeds     []Type           // embedded types
-	typeParams []*TypeParameter // type parameters for generic interfaces
+	embeds          []Type           // embedded types
+	typeParams      []*TypeParameter // type parameters for generic interfaces
+	constraintOnly  bool             // only referenced as a type parameter constraint
+	implementers    []string         // ids of the types implementing it (Config.ComputeImplements)
+	implementations []*Struct        // the structs among the implementers
+	typeSet         *TypeSet         // type set of general interfaces, nil for method sets
+	predeclared     bool             // the predeclared any
+}
+
+// TypeSet describes the types satisfying a general interface (one with type terms, usable
+// only as a constraint): their type is one of the terms and they have all the methods.
+// No terms means no type satisfies it.
+type TypeSet struct {
+	Terms   []*UnionTerm
+	Methods []string // method names
 }
 
 // NewInterface creates a new interface type
@@ -656,6 +721,22 @@ func NewInterface(id string, name string) *Interface {
 	}
 }
 
+// AnyID is the id of the predeclared any, which interface{} resolves to as well
+const AnyID = "any"
+
+// NewAny creates the predeclared any: an interface without methods, shared by every any and
+// interface{} of a scan
+func NewAny() *Interface {
+	i := NewInterface(AnyID, AnyID)
+	i.predeclared = true
+	return i
+}
+
+// IsPredeclared reports whether i is the predeclared any
+func (i *Interface) IsPredeclared() bool {
+	return i.predeclared
+}
+
 func (i *Interface) Serialize() any {
 	// Avoid calling Load() here to prevent reentrancy deadlocks
 
@@ -674,14 +755,65 @@ func (i *Interface) Serialize() any {
 		typeParams[idx] = tp.Serialize().(*SerializedTypeParameter)
 	}
 
+	var typeSet *SerializedTypeSet
+	if i.typeSet != nil {
+		typeSet = &SerializedTypeSet{
+			Terms:   serializeUnionTerms(i.typeSet.Terms),
+			Methods: i.typeSet.Methods,
+		}
+	}
+
 	return &SerializedInterface{
 		SerializedType: i.serializeBase(),
 		Embeds:         embeds,
 		Methods:        methods,
 		TypeParams:     typeParams,
+		ConstraintOnly: i.constraintOnly,
+		Implementers:   i.implementers,
+		TypeSet:        typeSet,
+		Predeclared:    i.predeclared,
 	}
 }
 
+// TypeSet returns the type set of a general interface, nil for basic interfaces (method sets)
+func (i *Interface) TypeSet() *TypeSet {
+	return i.typeSet
+}
+
+func (i *Interface) SetTypeSet(typeSet *TypeSet) {
+	i.typeSet = typeSet
+}
+
+// ConstraintOnly reports whether the interface is only used as a type parameter constraint
+// (set by ScanningResult.MarkConstraintOnly after a scan)
+func (i *Interface) ConstraintOnly() bool {
+	return i.constraintOnly
+}
+
+func (i *Interface) SetConstraintOnly(constraintOnly bool) {
+	i.constraintOnly = constraintOnly
+}
+
+// Implementers returns the ids of the named types implementing the interface, by value or
+// pointer (set by ScanningResult.ComputeImplements)
+func (i *Interface) Implementers() []string {
+	return i.implementers
+}
+
+func (i *Interface) SetImplementers(ids []string) {
+	i.implementers = ids
+}
+
+// Implementations returns the structs implementing the interface, by value or pointer, in
+// id order (set by ScanningResult.ComputeImplements)
+func (i *Interface) Implementations() []*Struct {
+	return i.implementations
+}
+
+func (i *Interface) SetImplementations(structs []*Struct) {
+	i.implementations = structs
+}
+
 func (i *Interface) AddEmbed(embed Type) {
 	i.embeds = append(i.embeds, embed)
 }
@@ -715,6 +847,9 @@ type Struct struct {
 	embeds     []Type // embedded types
 	fields     []*Field
 	typeParams []*TypeParameter // type parameters for generic structs
+	implements []string         // ids of the interfaces it implements (Config.ComputeImplements)
+	// ids of the NewXxx functions of the package returning the struct or a pointer to it
+	constructors []string
 }
 
 // NewStruct creates a new struct type
@@ -744,6 +879,19 @@ func (s *Struct) Embeds() []Type {
 	return s.embeds
 }
 
+// EmbeddedNames returns the ids of the embedded types in source declaration order.
+// Pointer embeds (e.g. *Base) report the id of the pointed-to type.
+func (s *Struct) EmbeddedNames() []string {
+	names := make([]string, 0, len(s.embeds))
+	for _, e := range s.embeds {
+		if ptr, ok := e.(*Pointer); ok && ptr.Elem() != nil {
+			e = ptr.Elem()
+		}
+		names = append(names, e.Id())
+	}
+	return names
+}
+
 func (s *Struct) TypeParams() []*TypeParameter {
 	return s.typeParams
 }
@@ -752,6 +900,26 @@ func (s *Struct) AddTypeParam(tp *TypeParameter) {
 	s.typeParams = append(s.typeParams, tp)
 }
 
+// Implements returns the ids of the interfaces the struct (or a pointer to it) implements
+// (set by ScanningResult.ComputeImplements)
+func (s *Struct) Implements() []string {
+	return s.implements
+}
+
+func (s *Struct) SetImplements(ids []string) {
+	s.implements = ids
+}
+
+// Constructors returns the ids of the constructor functions of the struct: the functions of
+// its package named New<Struct>... (NewUser, NewUserWithOptions) returning it or a pointer to it
+func (s *Struct) Constructors() []string {
+	return s.constructors
+}
+
+func (s *Struct) SetConstructors(ids []string) {
+	s.constructors = ids
+}
+
 func (s *Struct) Serialize() any {
 	// Avoid calling Load() here to prevent reentrancy deadlocks
 
@@ -781,6 +949,8 @@ func (s *Struct) Serialize() any {
 		Fields:         fields,
 		Methods:        methods,
 		TypeParams:     typeParams,
+		Implements:     s.implements,
+		Constructors:   s.constructors,
 	}
 }
 
@@ -802,6 +972,9 @@ type Value struct {
 	value     any  // the actual constant/variable value
 	valueType Type // the type of this value
 	parent    Type // parent type (for enum values)
+	label     string
+	expr      string // source expression of a constant (e.g. "1 << iota")
+	iota      int    // value of iota in expr, -1 when expr doesn't use it
 }
 
 // NewConstant creates a new constant value
@@ -810,6 +983,7 @@ func NewConstant(id string, name string, valueType Type, value any) *Value {
 		baseType:  newBaseType(id, name, TypeKindConstant),
 		value:     value,
 		valueType: valueType,
+		iota:      -1,
 	}
 }
 
@@ -818,13 +992,21 @@ func NewVariable(id string, name string, valueType Type) *Value {
 	return &Value{
 		baseType:  newBaseType(id, name, TypeKindVariable),
 		valueType: valueType,
+		iota:      -1,
 	}
 }
 
+// Value returns the value of a constant, or of a variable whose initializer is constant
+// (Config.IncludeInitializers)
 func (v *Value) Value() any {
 	return v.value
 }
 
+// SetValue sets the value of a constant or variable
+func (v *Value) SetValue(value any) {
+	v.value = value
+}
+
 func (v *Value) ValueType() Type {
 	return v.valueType
 }
@@ -837,6 +1019,37 @@ func (v *Value) SetParent(parent Type) {
 	v.parent = parent
 }
 
+// Label returns the display string of an enum constant, as returned by the String() method
+// of its type (ScanModeFunctionBodies), or "" if unknown
+func (v *Value) Label() string {
+	return v.label
+}
+
+func (v *Value) SetLabel(label string) {
+	v.label = label
+}
+
+// Expr returns the expression of a constant as written in its declaration, the implicit ones
+// repeating the last explicit expression of their const block (B in `A Flag = 1 << iota; B`
+// is "1 << iota"), the initializer of a variable (Config.IncludeInitializers), or "" if
+// unknown. Value holds the computed value.
+func (v *Value) Expr() string {
+	return v.expr
+}
+
+// Iota returns the value of iota in the declaration of a constant whose Expr uses it (its
+// index in the const block), -1 otherwise
+func (v *Value) Iota() int {
+	return v.iota
+}
+
+// SetExpr sets the expression of a constant and the value of iota in it, -1 when it isn't
+// iota based
+func (v *Value) SetExpr(expr string, iotaValue int) {
+	v.expr = expr
+	v.iota = iotaValue
+}
+
 func (v *Value) Serialize() any {
 	parentID := ""
 	if v.parent != nil {
@@ -848,11 +1061,19 @@ func (v *Value) Serialize() any {
 		valueTypeSerialized = serializeTypeRef(v.valueType)
 	}
 
+	var iotaValue *int
+	if v.iota >= 0 {
+		iotaValue = &v.iota
+	}
+
 	return &SerializedValue{
 		SerializedType: v.serializeBase(),
 		Value:          v.value,
 		ValueType:      valueTypeSerialized,
 		Parent:         parentID,
+		Label:          v.label,
+		Expr:           v.expr,
+		Iota:           iotaValue,
 	}
 }
 
@@ -963,18 +1184,21 @@ func (u *Union) Terms() []*UnionTerm {
 }
 
 func (u *Union) Serialize() any {
-	serializedTerms := make([]SerializedUnionTerm, len(u.terms))
-	for i, term := range u.terms {
+	return &SerializedUnion{
+		SerializedType: u.serializeBase(),
+		Terms:          serializeUnionTerms(u.terms),
+	}
+}
+
+func serializeUnionTerms(terms []*UnionTerm) []SerializedUnionTerm {
+	serializedTerms := make([]SerializedUnionTerm, len(terms))
+	for i, term := range terms {
 		serializedTerms[i] = SerializedUnionTerm{
 			Type:          serializeTypeOrID(term.typ),
 			Approximation: term.approximation,
 		}
 	}
-
-	return &SerializedUnion{
-		SerializedType: u.serializeBase(),
-		Terms:          serializedTerms,
-	}
+	return serializedTerms
 }
 
 func (u *Union) Load() error {
@@ -1012,6 +1236,12 @@ type InstantiatedGeneric struct {
 	baseType
 	origin   Type           // The base generic type (e.g., List[T])
 	typeArgs []TypeArgument // The concrete type arguments with parameter info
+	// Members with the type arguments substituted, set by the scanner from the instantiated
+	// go/types type. Instances not loaded (opaque ones) serialize as a reference only.
+	fields     []*Field
+	embeds     []Type
+	underlying Type // structure of the instances of origins other than structs and interfaces
+	hasMembers bool
 }
 
 // NewInstantiatedGeneric creates a new instantiated generic type
@@ -1031,7 +1261,134 @@ func (ig *InstantiatedGeneric) TypeArgs() []TypeArgument {
 	return ig.typeArgs
 }
 
+func (ig *InstantiatedGeneric) SetTypeArgs(typeArgs []TypeArgument) {
+	ig.typeArgs = typeArgs
+}
+
+// Fields returns the substituted fields of an instantiated struct
+func (ig *InstantiatedGeneric) Fields() []*Field {
+	return ig.fields
+}
+
+// SetMembers sets the fields and methods of the instance, with the type arguments substituted
+func (ig *InstantiatedGeneric) SetMembers(fields []*Field, methods []*Method) {
+	ig.fields = fields
+	ig.methods = methods
+	ig.hasMembers = true
+}
+
+// Embeds returns the substituted embedded types of an instantiated struct
+func (ig *InstantiatedGeneric) Embeds() []Type {
+	return ig.embeds
+}
+
+// SetEmbeds sets the embedded types of an instantiated struct, with the type arguments
+// substituted (their promoted fields and methods are among the members)
+func (ig *InstantiatedGeneric) SetEmbeds(embeds []Type) {
+	ig.embeds = embeds
+}
+
+// Underlying returns the substituted structure of an instance of a generic slice, map,
+// channel, pointer, function or basic type ([]int for List[int] of type List[T any] []T),
+// nil for structs and interfaces
+func (ig *InstantiatedGeneric) Underlying() Type {
+	return ig.underlying
+}
+
+// SetUnderlying sets the substituted structure of the instance, see Underlying
+func (ig *InstantiatedGeneric) SetUnderlying(underlying Type) {
+	ig.underlying = underlying
+}
+
+// isRecursive reports whether ig is reachable from its own members through the unnamed types
+// and instances serialized inline with it
+func (ig *InstantiatedGeneric) isRecursive() bool {
+	seen := map[Type]bool{}
+	var reaches func(t Type) bool
+	reaches = func(t Type) bool {
+		if t == Type(ig) {
+			return true
+		}
+		if t == nil || seen[t] {
+			return false
+		}
+		seen[t] = true
+		if inst, ok := t.(*InstantiatedGeneric); ok {
+			return slices.ContainsFunc(inst.memberTypes(), reaches)
+		}
+		if t.IsNamed() {
+			return false
+		}
+		found := false
+		WalkReferences(t, func(ref Type, _ RefRole) {
+			found = found || reaches(ref)
+		})
+		return found
+	}
+	return slices.ContainsFunc(ig.memberTypes(), reaches)
+}
+
+// memberTypes returns the types referenced by the members serialized with ig
+func (ig *InstantiatedGeneric) memberTypes() []Type {
+	var types []Type
+	collect := func(ref Type, _ RefRole) {
+		types = append(types, ref)
+	}
+	if ig.hasMembers {
+		for _, f := range ig.fields {
+			types = append(types, f.Type())
+		}
+		for _, m := range ig.methods {
+			WalkReferences(m, collect)
+		}
+		types = append(types, ig.embeds...)
+		if ig.underlying != nil {
+			types = append(types, ig.underlying)
+		}
+	} else if ig.origin != nil {
+		WalkReferences(ig.origin, collect)
+	}
+	for _, arg := range ig.typeArgs {
+		types = append(types, arg.Type)
+	}
+	return types
+}
+
 func (ig *InstantiatedGeneric) Serialize() any {
+	result := ig.serializeRef()
+	if !ig.hasMembers {
+		return result
+	}
+
+	if len(ig.embeds) > 0 {
+		serializedEmbeds := make([]any, len(ig.embeds))
+		for i, e := range ig.embeds {
+			serializedEmbeds[i] = serializeTypeOrID(e)
+		}
+		result["embeds"] = serializedEmbeds
+	}
+	if ig.underlying != nil {
+		result["underlying"] = serializeTypeOrID(ig.underlying)
+	}
+	if len(ig.fields) > 0 {
+		serializedFields := make([]any, len(ig.fields))
+		for i, f := range ig.fields {
+			serializedFields[i] = f.Serialize()
+		}
+		result["fields"] = serializedFields
+	}
+	if len(ig.methods) > 0 {
+		serializedMethods := make([]any, len(ig.methods))
+		for i, m := range ig.methods {
+			serializedMethods[i] = m.Serialize()
+		}
+		result["methods"] = serializedMethods
+	}
+	return result
+}
+
+// serializeRef serializes ig without its members: the origin and the type arguments
+func (ig *InstantiatedGeneric) serializeRef() map[string]any {
 	serializedArgs := make([]any, len(ig.typeArgs))
 	for i, arg := range ig.typeArgs {
 		serializedArgs[i] = map[string]any{
@@ -1041,14 +1398,6 @@ func (ig *InstantiatedGeneric) Serialize() any {
 		}
 	}
 
-	// Build type parameter substitution map
-	typeSubstitutions := make(map[string]any)
-	for _, arg := range ig.typeArgs {
-		if arg.Type != nil {
-			typeSubstitutions[arg.Param] = serializeTypeOrID(arg.Type)
-		}
-	}
-
 	// Get origin ID
 	originID := ""
 	if ig.origin != nil {
@@ -1072,265 +1421,9 @@ func (ig *InstantiatedGeneric) Serialize() any {
 	if len(baseData.Comments) > 0 {
 		result["comments"] = baseData.Comments
 	}
-
-	// Copy fields and methods from origin
-	if ig.origin != nil {
-		switch origin := ig.origin.(type) {
-		case *Struct:
-			if fields := origin.Fields(); len(fields) > 0 {
-				serializedFields := make([]any, len(fields))
-				for i, f := range fields {
-					fieldData := f.Serialize()
-					// Substitute type parameters in field
-					ig.substituteTypes(fieldData, typeSubstitutions)
-					serializedFields[i] = fieldData
-				}
-				result["fields"] = serializedFields
-			}
-			if embeds := origin.Embeds(); len(embeds) > 0 {
-				serializedEmbeds := make([]any, len(embeds))
-				for i, e := range embeds {
-					embedData := serializeTypeOrID(e)
-					// Substitute if embed is a type parameter
-					if embedMap, ok := embedData.(map[string]any); ok {
-						if embedMap["kind"] == "type_parameter" {
-							if embedID, ok := embedMap["id"].(string); ok {
-								if concrete, exists := typeSubstitutions[embedID]; exists {
-									embedData = concrete
-								}
-							}
-						}
-					}
-					serializedEmbeds[i] = embedData
-				}
-				result["embeds"] = serializedEmbeds
-			}
-			if methods := origin.Methods(); len(methods) > 0 {
-				serializedMethods := make([]any, len(methods))
-				for i, m := range methods {
-					methodData := m.Serialize()
-					// Substitute type parameters in method
-					ig.substituteTypes(methodData, typeSubstitutions)
-					serializedMethods[i] = methodData
-				}
-				result["methods"] = serializedMethods
-			}
-		case *Interface:
-			if methods := origin.Methods(); len(methods) > 0 {
-				serializedMethods := make([]any, len(methods))
-				for i, m := range methods {
-					methodData := m.Serialize()
-					// Substitute type parameters in method
-					ig.substituteTypes(methodData, typeSubstitutions)
-					serializedMethods[i] = methodData
-				}
-				result["methods"] = serializedMethods
-			}
-			if embeds := origin.Embeds(); len(embeds) > 0 {
-				serializedEmbeds := make([]any, len(embeds))
-				for i, e := range embeds {
-					embedData := serializeTypeOrID(e)
-					// Substitute if embed is a type parameter
-					if embedMap, ok := embedData.(map[string]any); ok {
-						if embedMap["kind"] == "type_parameter" {
-							if embedID, ok := embedMap["id"].(string); ok {
-								if concrete, exists := typeSubstitutions[embedID]; exists {
-									embedData = concrete
-								}
-							}
-						}
-					}
-					serializedEmbeds[i] = embedData
-				}
-				result["embeds"] = serializedEmbeds
-			}
-		case *Slice:
-			// Include element type with substitution
-			if elem := origin.Elem(); elem != nil {
-				elemData := serializeTypeOrID(elem)
-				// Substitute if element is a type parameter
-				if elemMap, ok := elemData.(map[string]any); ok {
-					if elemMap["kind"] == "type_parameter" {
-						if elemID, ok := elemMap["id"].(string); ok {
-							if concrete, exists := typeSubstitutions[elemID]; exists {
-								elemData = concrete
-							}
-						}
-					}
-				}
-				result["elem"] = elemData
-			}
-			// Include length for arrays
-			if origin.Len() > 0 {
-				result["len"] = origin.Len()
-			}
-			// Include methods
-			if methods := origin.Methods(); len(methods) > 0 {
-				serializedMethods := make([]any, len(methods))
-				for i, m := range methods {
-					methodData := m.Serialize()
-					ig.substituteTypes(methodData, typeSubstitutions)
-					serializedMethods[i] = methodData
-				}
-				result["methods"] = serializedMethods
-			}
-		case *Map:
-			// Include key and value types with substitution
-			if key := origin.Key(); key != nil {
-				keyData := serializeTypeOrID(key)
-				if keyMap, ok := keyData.(map[string]any); ok {
-					if keyMap["kind"] == "type_parameter" {
-						if keyID, ok := keyMap["id"].(string); ok {
-							if concrete, exists := typeSubstitutions[keyID]; exists {
-								keyData = concrete
-							}
-						}
-					}
-				}
-				result["key"] = keyData
-			}
-			if value := origin.Value(); value != nil {
-				valueData := serializeTypeOrID(value)
-				if valueMap, ok := valueData.(map[string]any); ok {
-					if valueMap["kind"] == "type_parameter" {
-						if valueID, ok := valueMap["id"].(string); ok {
-							if concrete, exists := typeSubstitutions[valueID]; exists {
-								valueData = concrete
-							}
-						}
-					}
-				}
-				result["value"] = valueData
-			}
-			// Include methods
-			if methods := origin.Methods(); len(methods) > 0 {
-				serializedMethods := make([]any, len(methods))
-				for i, m := range methods {
-					methodData := m.Serialize()
-					ig.substituteTypes(methodData, typeSubstitutions)
-					serializedMethods[i] = methodData
-				}
-				result["methods"] = serializedMethods
-			}
-		case *Chan:
-			// Include element type with substitution
-			if elem := origin.Elem(); elem != nil {
-				elemData := serializeTypeOrID(elem)
-				if elemMap, ok := elemData.(map[string]any); ok {
-					if elemMap["kind"] == "type_parameter" {
-						if elemID, ok := elemMap["id"].(string); ok {
-							if concrete, exists := typeSubstitutions[elemID]; exists {
-								elemData = concrete
-							}
-						}
-					}
-				}
-				result["elem"] = elemData
-			}
-			// Include direction
-			result["dir"] = origin.Dir()
-			// Include methods
-			if methods := origin.Methods(); len(methods) > 0 {
-				serializedMethods := make([]any, len(methods))
-				for i, m := range methods {
-					methodData := m.Serialize()
-					ig.substituteTypes(methodData, typeSubstitutions)
-					serializedMethods[i] = methodData
-				}
-				result["methods"] = serializedMethods
-			}
-		case *Basic:
-			// Include underlying type
-			if underlying := origin.Underlying(); underlying != nil {
-				result["underlying"] = serializeTypeOrID(underlying)
-			}
-			// Include methods
-			if methods := origin.Methods(); len(methods) > 0 {
-				serializedMethods := make([]any, len(methods))
-				for i, m := range methods {
-					methodData := m.Serialize()
-					ig.substituteTypes(methodData, typeSubstitutions)
-					serializedMethods[i] = methodData
-				}
-				result["methods"] = serializedMethods
-			}
-		case *Pointer:
-			// Include element type with substitution
-			if elem := origin.Elem(); elem != nil {
-				elemData := serializeTypeOrID(elem)
-				if elemMap, ok := elemData.(map[string]any); ok {
-					if elemMap["kind"] == "type_parameter" {
-						if elemID, ok := elemMap["id"].(string); ok {
-							if concrete, exists := typeSubstitutions[elemID]; exists {
-								elemData = concrete
-							}
-						}
-					}
-				}
-				result["elem"] = elemData
-			}
-			result["depth"] = origin.Depth()
-		case *Function:
-			// Include parameters and results with substitution
-			if params := origin.Parameters(); len(params) > 0 {
-				serializedParams := make([]any, len(params))
-				for i, p := range params {
-					paramData := map[string]any{
-						"name": p.Name(),
-						"type": serializeTypeOrID(p.Type()),
-					}
-					ig.substituteTypes(paramData, typeSubstitutions)
-					serializedParams[i] = paramData
-				}
-				result["parameters"] = serializedParams
-			}
-			if results := origin.Results(); len(results) > 0 {
-				serializedResults := make([]any, len(results))
-				for i, r := range results {
-					resultData := map[string]any{
-						"name": r.Name(),
-						"type": serializeTypeOrID(r.Type()),
-					}
-					ig.substituteTypes(resultData, typeSubstitutions)
-					serializedResults[i] = resultData
-				}
-				result["results"] = serializedResults
-			}
-		}
-	}
-
 	return result
 }
 
-// substituteTypes recursively replaces type parameters with concrete types in serialized data
-func (ig *InstantiatedGeneric) substituteTypes(data any, substitutions map[string]any) {
-	switch v := data.(type) {
-	case map[string]any:
-		// Check if this is a type parameter reference
-		if v["kind"] == "type_parameter" {
-			if typeID, ok := v["id"].(string); ok {
-				if concrete, exists := substitutions[typeID]; exists {
-					// Replace the entire type reference with the concrete type
-					if concreteMap, ok := concrete.(map[string]any); ok {
-						for k, val := range concreteMap {
-							v[k] = val
-						}
-					}
-					return
-				}
-			}
-		}
-		// Recursively process nested structures
-		for _, val := range v {
-			ig.substituteTypes(val, substitutions)
-		}
-	case []any:
-		for _, item := range v {
-			ig.substituteTypes(item, substitutions)
-		}
-	}
-}
-
 func (ig *InstantiatedGeneric) Load() error {
 	var err error
 	ig.loadOnce.Do(func() {
@@ -1338,13 +1431,14 @@ func (ig *InstantiatedGeneric) Load() error {
 		if ig.loader != nil {
 			err = ig.loader(ig)
 		}
-		// Load origin and type args
+		// Load origin and type args. Type parameters are loaded by their generic, their
+		// constraint may be this instance (Comparer[T] in func Max[T Comparer[T]])
 		if err == nil && ig.origin != nil {
 			err = ig.origin.Load()
 		}
 		if err == nil {
 			for _, arg := range ig.typeArgs {
-				if arg.Type != nil {
+				if _, param := arg.Type.(*TypeParameter); arg.Type != nil && !param {
 					if loadErr := arg.Type.Load(); loadErr != nil {
 						err = loadErr
 						return
@@ -1356,76 +1450,158 @@ func (ig *InstantiatedGeneric) Load() error {
 	return err
 }
 
-// // Enum represents an enum type (named type with associated constants)
-// type Enum struct {
-// 	baseType
-// 	underlying Type     // the underlying type (usually int, string, etc.)
-// 	values     []*Value // the constant values that belong to this enum
-// }
+// Enum represents an enum type (named type with associated constants)
+type Enum struct {
+	baseType
+	underlying Type     // the underlying type (usually int, string, etc.)
+	values     []*Value // the constant values that belong to this enum
+	iotaExpr   string   // base expression of the const group (e.g. "iota + 1", "1 << iota")
+}
 
-// // NewEnum creates a new enum type
-// func NewEnum(id string, name string, underlying Type) *Enum {
-// 	return &Enum{
-// 		baseType:   newBaseType(id, name, TypeKindEnum),
-// 		underlying: underlying,
-// 		values:     []*Value{},
-// 	}
-// }
+// NewEnum creates a new enum type
+func NewEnum(id string, name string, underlying Type) *Enum {
+	return &Enum{
+		baseType:   newBaseType(id, name, TypeKindEnum),
+		underlying: underlying,
+		values:     []*Value{},
+	}
+}
 
-// func (e *Enum) Underlying() Type {
-// 	return e.underlying
-// }
+// IsNamed is always true: enums are declared types, even when rebuilt without a go/types object
+func (e *Enum) IsNamed() bool {
+	return true
+}
 
-// func (e *Enum) Values() []*Value {
-// 	return e.values
-// }
+func (e *Enum) Underlying() Type {
+	return e.underlying
+}
 
-// func (e *Enum) AddValue(value *Value) {
-// 	value.SetParent(e)
-// 	e.values = append(e.values, value)
-// }
+func (e *Enum) Values() []*Value {
+	return e.values
+}
 
-// func (e *Enum) Serialize() any {
-// 	if err := e.Load(); err != nil && e.pkg != nil && e.pkg.logger != nil {
-// 		e.pkg.logger.Error(fmt.Sprintf("failed to load type %s: %v", e.id, err))
-// 	}
-// 	var underlyingSerialized any
-// 	if e.underlying != nil {
-// 		underlyingSerialized = e.underlying.Serialize()
-// 	}
+func (e *Enum) AddValue(value *Value) {
+	value.SetParent(e)
+	e.values = append(e.values, value)
+}
 
-// 	values := make([]*SerializedValue, len(e.values))
-// 	for i, v := range e.values {
-// 		values[i] = v.Serialize().(*SerializedValue)
-// 	}
+// IotaExpr returns the value expression of the first constant of the enum's const group
+// when it is iota based (e.g. "iota", "iota + 1", "1 << iota"), or "" otherwise.
+// Only the first iota based const block of the enum is reflected; enums split across
+// several iota blocks keep the expression of the block declared first.
+func (e *Enum) IotaExpr() string {
+	return e.iotaExpr
+}
 
-// 	return &SerializedEnum{
-// 		SerializedType: e.serializeBase(),
-// 		Underlying:     underlyingSerialized,
-// 		Values:         values,
-// 	}
-// }
+// SetIotaExpr sets the iota base expression of the enum's const group
+func (e *Enum) SetIotaExpr(expr string) {
+	e.iotaExpr = expr
+}
 
-// func (e *Enum) Load() error {
-// 	var err error
-// 	e.loadOnce.Do(func() {
-// 		e.loadComments(false)
-// 		if e.loader != nil {
-// 			err = e.loader(e)
-// 		}
-// 		// Load underlying type
-// 		if err == nil && e.underlying != nil {
-// 			err = e.underlying.Load()
-// 		}
-// 		// Load all values
-// 		if err == nil {
-// 			for _, v := range e.values {
-// 				err = v.Load()
-// 				if err != nil {
-// 					return
-// 				}
-// 			}
-// 		}
-// 	})
-// 	return err
-// }
+// FromIota reports whether the enum's constants are declared with iota. Constants sharing a
+// named type with explicit values (`A Kind = "a"; B Kind = "b"`) make an enum all the same.
+func (e *Enum) FromIota() bool {
+	return e.iotaExpr != ""
+}
+
+// HasDuplicateValues reports whether two names of an integer enum share a value
+// (aliases like `Default = Medium`)
+func (e *Enum) HasDuplicateValues() bool {
+	ints, ok := e.intValues()
+	return ok && len(distinctInts(ints)) < len(ints)
+}
+
+// IsContiguous reports whether the distinct values of an integer enum form a range without
+// gaps (0, 1, 2... or 5, 6, 7), so lookups can be backed by an array
+func (e *Enum) IsContiguous() bool {
+	ints, ok := e.intValues()
+	if !ok || len(ints) == 0 {
+		return false
+	}
+	distinct := distinctInts(ints)
+	return distinct[len(distinct)-1]-distinct[0] == int64(len(distinct)-1)
+}
+
+// intValues returns the values of an integer enum, ok is false when one of them isn't an integer
+func (e *Enum) intValues() ([]int64, bool) {
+	ints := make([]int64, 0, len(e.values))
+	for _, v := range e.values {
+		n, ok := intValue(v.Value())
+		if !ok {
+			return nil, false
+		}
+		ints = append(ints, n)
+	}
+	return ints, true
+}
+
+// intValue converts a constant value (go/constant when scanned, a JSON number when read
+// from a cache) to an int64
+func intValue(value any) (int64, bool) {
+	switch v := value.(type) {
+	case constant.Value:
+		if v.Kind() != constant.Int {
+			return 0, false
+		}
+		return constant.Int64Val(v)
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case float64:
+		return int64(v), v == float64(int64(v))
+	}
+	return 0, false
+}
+
+// distinctInts returns the sorted distinct values of ints
+func distinctInts(ints []int64) []int64 {
+	sorted := append([]int64(nil), ints...)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
+
+func (e *Enum) Serialize() any {
+	// Avoid calling Load() here to prevent reentrancy deadlocks
+	var underlyingSerialized any
+	if e.underlying != nil {
+		underlyingSerialized = e.underlying.Serialize()
+	}
+
+	values := make([]*SerializedValue, len(e.values))
+	for i, v := range e.values {
+		values[i] = v.Serialize().(*SerializedValue)
+	}
+
+	methods := make([]*SerializedMethod, len(e.methods))
+	for i, m := range e.methods {
+		methods[i] = m.Serialize().(*SerializedMethod)
+	}
+
+	return &SerializedEnum{
+		SerializedType: e.serializeBase(),
+		Underlying:     underlyingSerialized,
+		IotaExpr:       e.iotaExpr,
+		FromIota:       e.FromIota(),
+		Values:         values,
+		Methods:        methods,
+
+		HasDuplicateValues: e.HasDuplicateValues(),
+		IsContiguous:       e.IsContiguous(),
+	}
+}
+
+func (e *Enum) Load() error {
+	var err error
+	e.loadOnce.Do(func() {
+		e.loadComments(false)
+		if e.loader != nil {
+			err = e.loader(e)
+		}
+		// Load underlying type
+		if err == nil && e.underlying != nil {
+			err = e.underlying.Load()
+		}
+	})
+	return err
+}
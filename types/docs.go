@@ -55,9 +55,25 @@ func (cp *CommentPlacement) UnmarshalJSON(data []byte) error {
 
 // Comment represents a comment associated with a Go code element
 type Comment struct {
-	ID    string           `json:"id,omitempty"`
-	Text  string           `json:"text,omitempty"`
-	Place CommentPlacement `json:"placement,omitempty"`
+	ID       string           `json:"id,omitempty"`
+	Text     string           `json:"text,omitempty"`
+	Place    CommentPlacement `json:"placement,omitempty"`
+	DocLinks []DocLink        `json:"links,omitempty"`
+	Position *Position        `json:"position,omitempty"` // only with Config.IncludePositions
+}
+
+// Links returns the doc links ([Name], [pkg.Name], [Type.Method]) found in the comment
+func (c Comment) Links() []DocLink {
+	return c.DocLinks
+}
+
+// DocLink is a go/doc link in a comment, see https://go.dev/doc/comment#doclinks
+type DocLink struct {
+	Text       string `json:"text"`                 // link text, without the brackets
+	ImportPath string `json:"importPath,omitempty"` // package of the target
+	Recv       string `json:"recv,omitempty"`       // receiver type for method links
+	Name       string `json:"name,omitempty"`       // target name, empty for package links
+	TypeID     string `json:"typeId,omitempty"`     // id of the linked type, function or method
 }
 
 func NewComment(text string, place CommentPlacement) Comment {
@@ -102,6 +118,16 @@ func (m *Module) AddPackage(pkg *Package) {
 	m.packages = append(m.packages, pkg)
 }
 
+// SerializedModule is the module a package was loaded from
+type SerializedModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"` // empty for the main module and local replacements
+}
+
+func (m *Module) Serialize() any {
+	return SerializedModule{Path: m.path, Version: m.version}
+}
+
 // Package represents a Go package
 type Package struct {
 	path        string
@@ -110,8 +136,13 @@ type Package struct {
 	types       *TypesCol[Type]
 	pkgComments []Comment
 	comments    map[string][]Comment // key is type/function/field name, value is comments
+	directives  []string             // go:generate directives of the package, in source order
+	typeDirs    map[string][]string  // go:generate directives attached to type declarations, by type name
 	pkg         *packages.Package    // the original go/packages.Package
 	logger      logger.Logger
+	distance    int         // 0 for scanned packages, how far from them in the dependency graph otherwise
+	module      *Module     // module the package was loaded from, nil outside modules (standard library)
+	initFuncs   []*Position // init functions, in source order
 }
 
 // NewPackage creates a new package
@@ -176,10 +207,64 @@ func (p *Package) AddComments(name string, comments []Comment) {
 	p.comments[name] = append(p.comments[name], comments...)
 }
 
+// WalkComments calls fn with every comment recorded in the package (package, file and
+// declaration comments), fn can modify them in place
+func (p *Package) WalkComments(fn func(c *Comment)) {
+	for i := range p.pkgComments {
+		fn(&p.pkgComments[i])
+	}
+	for _, comments := range p.comments {
+		for i := range comments {
+			fn(&comments[i])
+		}
+	}
+	for _, f := range p.files.Values() {
+		for i := range f.comments {
+			fn(&f.comments[i])
+		}
+	}
+}
+
+func (p *Package) Distance() int {
+	return p.distance
+}
+
+func (p *Package) SetDistance(distance int) {
+	p.distance = distance
+}
+
+// Module returns the module the package was loaded from, nil for packages outside modules
+func (p *Package) Module() *Module {
+	return p.module
+}
+
+func (p *Package) SetModule(module *Module) {
+	p.module = module
+}
+
+// HasInit reports whether the package declares init functions
+func (p *Package) HasInit() bool {
+	return len(p.initFuncs) > 0
+}
+
+// InitFuncs returns the positions of the init functions of the package, in source order
+func (p *Package) InitFuncs() []*Position {
+	return p.initFuncs
+}
+
+func (p *Package) AddInitFunc(pos *Position) {
+	p.initFuncs = append(p.initFuncs, pos)
+}
+
 func (p *Package) GoPackage() *packages.Package {
 	return p.pkg
 }
 
+// Detach drops the go/packages data of the package, GoPackage returns nil afterwards
+func (p *Package) Detach() {
+	p.pkg = nil
+}
+
 func (p *Package) SetLogger(logger logger.Logger) {
 	p.logger = logger
 }
@@ -196,6 +281,30 @@ func (p *Package) SetPackageComments(comments []Comment) {
 	p.pkgComments = comments
 }
 
+// GenerateDirectives returns the commands of every //go:generate directive in the package
+// (including those attached to types), in source order
+func (p *Package) GenerateDirectives() []string {
+	return p.directives
+}
+
+// TypeGenerateDirectives returns the //go:generate commands attached to the named type declaration
+func (p *Package) TypeGenerateDirectives(typeName string) []string {
+	return p.typeDirs[typeName]
+}
+
+// AddGenerateDirective records a //go:generate command, typeName is empty for directives
+// not attached to a type declaration
+func (p *Package) AddGenerateDirective(typeName string, command string) {
+	p.directives = append(p.directives, command)
+	if typeName == "" {
+		return
+	}
+	if p.typeDirs == nil {
+		p.typeDirs = make(map[string][]string)
+	}
+	p.typeDirs[typeName] = append(p.typeDirs[typeName], command)
+}
+
 func (p *Package) Serialize() any {
 	return struct {
 		Path  string `json:"path,omitempty"`
@@ -204,6 +313,11 @@ func (p *Package) Serialize() any {
 		// Types       any                  `json:"types,omitempty"`
 		PkgComments []Comment `json:"comments,omitempty"`
 		// Comments    map[string][]Comment `json:"comments,omitempty"`
+		GenerateDirectives []string    `json:"generateDirectives,omitempty"`
+		Distance           int         `json:"distance,omitempty"`
+		Module             any         `json:"module,omitempty"`
+		HasInit            bool        `json:"hasInit,omitempty"`
+		InitFuncs          []*Position `json:"initFuncs,omitempty"`
 	}{
 		Path:  p.path,
 		Name:  p.name,
@@ -211,7 +325,19 @@ func (p *Package) Serialize() any {
 		// Types:       p.types.Serialize(),
 		PkgComments: p.pkgComments,
 		// Comments:    p.comments,
+		GenerateDirectives: p.directives,
+		Distance:           p.distance,
+		Module:             p.serializeModule(),
+		HasInit:            p.HasInit(),
+		InitFuncs:          p.initFuncs,
+	}
+}
+
+func (p *Package) serializeModule() any {
+	if p.module == nil {
+		return nil
 	}
+	return p.module.Serialize()
 }
 
 // File represents a Go source file
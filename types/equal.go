@@ -0,0 +1,259 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Equal compares two types structurally: kind, name, fields, methods and their signatures,
+// embeds, type parameters... rather than by pointer.
+// The types themselves are compared in depth, while named types they reference (a field of
+// type User) are compared by id, so results from different scans can be compared.
+// Unnamed ids (generated counters) are ignored. Types are not loaded, load them first.
+func Equal(a, b Type) bool {
+	e := &equalizer{seen: map[[2]Type]bool{}}
+	return e.equal(a, b, true)
+}
+
+func (b *Basic) Equal(other Type) bool                { return Equal(b, other) }
+func (p *Pointer) Equal(other Type) bool              { return Equal(p, other) }
+func (s *Slice) Equal(other Type) bool                { return Equal(s, other) }
+func (c *Chan) Equal(other Type) bool                 { return Equal(c, other) }
+func (m *Map) Equal(other Type) bool                  { return Equal(m, other) }
+func (a *Alias) Equal(other Type) bool                { return Equal(a, other) }
+func (f *Function) Equal(other Type) bool             { return Equal(f, other) }
+func (i *Interface) Equal(other Type) bool            { return Equal(i, other) }
+func (s *Struct) Equal(other Type) bool               { return Equal(s, other) }
+func (v *Value) Equal(other Type) bool                { return Equal(v, other) }
+func (tp *TypeParameter) Equal(other Type) bool       { return Equal(tp, other) }
+func (u *Union) Equal(other Type) bool                { return Equal(u, other) }
+func (ig *InstantiatedGeneric) Equal(other Type) bool { return Equal(ig, other) }
+func (e *Enum) Equal(other Type) bool                 { return Equal(e, other) }
+func (f *Field) Equal(other Type) bool                { return Equal(f, other) }
+func (m *Method) Equal(other Type) bool               { return Equal(m, other) }
+
+type equalizer struct {
+	seen map[[2]Type]bool // pairs being compared (cycle protection)
+}
+
+// isRef reports whether t is compared by id when referenced from another type
+func isRef(t Type) bool {
+	_, instantiated := t.(*InstantiatedGeneric)
+	return t.IsNamed() && !instantiated
+}
+
+func (e *equalizer) equal(a, b Type, top bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if a == b {
+		return true
+	}
+	if a.Kind() != b.Kind() {
+		return false
+	}
+	if !top && (isRef(a) || isRef(b)) {
+		return isRef(a) && isRef(b) && a.Id() == b.Id()
+	}
+	if isRef(a) && a.Id() != b.Id() {
+		return false
+	}
+
+	pair := [2]Type{a, b}
+	if e.seen[pair] {
+		return true
+	}
+	e.seen[pair] = true
+
+	if !e.kindEqual(a, b) {
+		return false
+	}
+
+	// Methods of methods, fields and values are not part of their identity
+	switch a.(type) {
+	case *Method, *Field, *Value, *InstantiatedGeneric:
+		return true
+	}
+	return e.methodsEqual(a.Methods(), b.Methods())
+}
+
+// kindEqual compares the kind specific parts of a and b (same kind)
+func (e *equalizer) kindEqual(a, b Type) bool {
+	switch x := a.(type) {
+	case *Basic:
+		y, ok := b.(*Basic)
+		if !ok || x.Name() != y.Name() {
+			return false
+		}
+		// Predeclared basics are their own underlying type
+		if x.Underlying() == Type(x) || y.Underlying() == Type(y) {
+			return x.Underlying() == Type(x) && y.Underlying() == Type(y)
+		}
+		return e.equal(x.Underlying(), y.Underlying(), false)
+	case *Pointer:
+		y, ok := b.(*Pointer)
+		return ok && x.Depth() == y.Depth() && e.equal(x.Elem(), y.Elem(), false)
+	case *Slice:
+		y, ok := b.(*Slice)
+		return ok && x.IsArray() == y.IsArray() && x.Len() == y.Len() && e.equal(x.Elem(), y.Elem(), false)
+	case *Chan:
+		y, ok := b.(*Chan)
+		return ok && x.Dir() == y.Dir() && e.equal(x.Elem(), y.Elem(), false)
+	case *Map:
+		y, ok := b.(*Map)
+		return ok && e.equal(x.Key(), y.Key(), false) && e.equal(x.Value(), y.Value(), false)
+	case *Alias:
+		y, ok := b.(*Alias)
+		return ok && x.Name() == y.Name() && e.equal(x.UnderlyingType(), y.UnderlyingType(), false)
+	case *Function:
+		y, ok := b.(*Function)
+		return ok && e.typeParamsEqual(x.TypeParams(), y.TypeParams()) &&
+			e.signatureEqual(x.Parameters(), y.Parameters(), x.Results(), y.Results())
+	case *Method:
+		y, ok := b.(*Method)
+		return ok && x.Name() == y.Name() && x.IsPointerReceiver() == y.IsPointerReceiver() &&
+			e.signatureEqual(x.Parameters(), y.Parameters(), x.Results(), y.Results())
+	case *Interface:
+		y, ok := b.(*Interface)
+		return ok && e.typeParamsEqual(x.TypeParams(), y.TypeParams()) && e.typesEqual(x.Embeds(), y.Embeds())
+	case *Struct:
+		y, ok := b.(*Struct)
+		return ok && e.typeParamsEqual(x.TypeParams(), y.TypeParams()) && e.typesEqual(x.Embeds(), y.Embeds()) &&
+			e.fieldsEqual(x.Fields(), y.Fields())
+	case *Field:
+		y, ok := b.(*Field)
+		return ok && e.fieldEqual(x, y)
+	case *Value:
+		y, ok := b.(*Value)
+		return ok && x.Name() == y.Name() && fmt.Sprint(x.Value()) == fmt.Sprint(y.Value()) &&
+			e.equal(x.ValueType(), y.ValueType(), false)
+	case *TypeParameter:
+		y, ok := b.(*TypeParameter)
+		return ok && x.Name() == y.Name() && x.Index() == y.Index() && e.equal(x.Constraint(), y.Constraint(), false)
+	case *Union:
+		y, ok := b.(*Union)
+		if !ok || len(x.Terms()) != len(y.Terms()) {
+			return false
+		}
+		for i, term := range x.Terms() {
+			other := y.Terms()[i]
+			if term.Approximation() != other.Approximation() || !e.equal(term.Type(), other.Type(), false) {
+				return false
+			}
+		}
+		return true
+	case *InstantiatedGeneric:
+		y, ok := b.(*InstantiatedGeneric)
+		if !ok || len(x.TypeArgs()) != len(y.TypeArgs()) || !e.equal(x.Origin(), y.Origin(), false) {
+			return false
+		}
+		for i, arg := range x.TypeArgs() {
+			if !e.equal(arg.Type, y.TypeArgs()[i].Type, false) {
+				return false
+			}
+		}
+		return true
+	case *Enum:
+		y, ok := b.(*Enum)
+		if !ok || x.IotaExpr() != y.IotaExpr() || len(x.Values()) != len(y.Values()) || !e.equal(x.Underlying(), y.Underlying(), false) {
+			return false
+		}
+		for i, v := range x.Values() {
+			other := y.Values()[i]
+			if v.Name() != other.Name() || fmt.Sprint(v.Value()) != fmt.Sprint(other.Value()) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (e *equalizer) typesEqual(a, b []Type) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !e.equal(a[i], b[i], false) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *equalizer) typeParamsEqual(a, b []*TypeParameter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name() != b[i].Name() || !e.equal(a[i].Constraint(), b[i].Constraint(), false) {
+			return false
+		}
+	}
+	return true
+}
+
+// signatureEqual compares parameter and result types (names are not part of a signature)
+func (e *equalizer) signatureEqual(ap, bp []*Parameter, ar, br []*Result) bool {
+	if len(ap) != len(bp) || len(ar) != len(br) {
+		return false
+	}
+	for i := range ap {
+		if ap[i].IsVariadic() != bp[i].IsVariadic() || !e.equal(ap[i].Type(), bp[i].Type(), false) {
+			return false
+		}
+	}
+	for i := range ar {
+		if !e.equal(ar[i].Type(), br[i].Type(), false) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldsEqual compares declared fields in order, promoted fields follow from the embeds
+func (e *equalizer) fieldsEqual(a, b []*Field) bool {
+	declared := func(fields []*Field) []*Field {
+		res := make([]*Field, 0, len(fields))
+		for _, f := range fields {
+			if f.PromotedFrom() == nil {
+				res = append(res, f)
+			}
+		}
+		return res
+	}
+	a, b = declared(a), declared(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !e.fieldEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *equalizer) fieldEqual(a, b *Field) bool {
+	return a.Name() == b.Name() && a.Tag() == b.Tag() && a.IsEmbedded() == b.IsEmbedded() &&
+		e.equal(a.Type(), b.Type(), false)
+}
+
+// methodsEqual compares method sets by name, declaration order doesn't matter
+func (e *equalizer) methodsEqual(a, b []*Method) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sorted := func(methods []*Method) []*Method {
+		res := append([]*Method(nil), methods...)
+		sort.SliceStable(res, func(i, j int) bool { return res[i].Name() < res[j].Name() })
+		return res
+	}
+	a, b = sorted(a), sorted(b)
+	for i := range a {
+		if !e.kindEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
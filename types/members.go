@@ -1,5 +1,14 @@
 package types
 
+// commentOwner returns the name the comments of t's members are keyed by: instances of a
+// generic type share the comments of their origin
+func commentOwner(t Type) string {
+	if ig, ok := t.(*InstantiatedGeneric); ok && ig.origin != nil {
+		return ig.origin.Name()
+	}
+	return t.Name()
+}
+
 // Field represents a struct field
 type Field struct {
 	baseType
@@ -8,6 +17,8 @@ type Field struct {
 	embedded     bool
 	promotedFrom Type // if this field is promoted from an embedded type
 	parent       Type // the struct this field belongs to
+	bitWidth     int  // bits the field is packed in, from the configured tag (0 if unset)
+	index        int  // position in the declaring struct
 }
 
 // NewField creates a new field
@@ -21,7 +32,7 @@ func NewField(id string, name string, fieldType Type, tag string, embedded bool,
 	}
 	// For fields, comment key is "ParentStruct.FieldName"
 	if parent != nil {
-		f.commentId = parent.Name() + "." + name
+		f.commentId = commentOwner(parent) + "." + name
 	}
 	return f
 }
@@ -50,6 +61,67 @@ func (f *Field) Parent() Type {
 	return f.parent
 }
 
+// BitWidth returns the number of bits the field is packed in, 0 when the field has no width
+func (f *Field) BitWidth() int {
+	return f.bitWidth
+}
+
+func (f *Field) SetBitWidth(bits int) {
+	f.bitWidth = bits
+}
+
+// Index returns the 0-based position of the field in the struct declaring it, embedded and
+// filtered out fields included (as reflect.StructField.Index). Promoted fields have their
+// position in the struct they're promoted from.
+func (f *Field) Index() int {
+	return f.index
+}
+
+func (f *Field) SetIndex(index int) {
+	f.index = index
+}
+
+// KindPath describes the shape of the field type as nested lists headed by the kind, with
+// the ids of the named types, instances and basics as leaves. map[string][]*User is
+// ["map", "string", ["slice", ["pointer", "pkg.User"]]] and [3]int is ["array", 3, "int"];
+// any is "any" and the other unnamed types are their kind ("struct", "function", "interface").
+func (f *Field) KindPath() any {
+	return kindPath(f.fieldType)
+}
+
+func kindPath(t Type) any {
+	if t == nil {
+		return nil
+	}
+	if _, instance := t.(*InstantiatedGeneric); instance || t.IsNamed() {
+		return t.Id()
+	}
+	switch v := t.(type) {
+	case *Basic:
+		return v.Id()
+	case *Interface:
+		if v.Id() == AnyID {
+			return AnyID
+		}
+	case *Pointer:
+		path := kindPath(v.Elem())
+		for range max(v.Depth(), 1) {
+			path = []any{"pointer", path}
+		}
+		return path
+	case *Slice:
+		if v.IsArray() {
+			return []any{"array", v.Len(), kindPath(v.Elem())}
+		}
+		return []any{"slice", kindPath(v.Elem())}
+	case *Map:
+		return []any{"map", kindPath(v.Key()), kindPath(v.Value())}
+	case *Chan:
+		return []any{"chan", kindPath(v.Elem())}
+	}
+	return string(t.Kind())
+}
+
 func (f *Field) Serialize() any {
 	// Avoid calling Load() here to prevent reentrancy deadlocks
 	promotedFromID := ""
@@ -69,6 +141,8 @@ func (f *Field) Serialize() any {
 		IsEmbedded:     f.embedded,
 		PromotedFrom:   promotedFromID,
 		Parent:         parentID,
+		BitWidth:       f.bitWidth,
+		Index:          f.index,
 	}
 	// Old full serialization logic (commented out)
 	// var fieldTypeSerialized any
@@ -86,7 +160,7 @@ func (f *Field) Load() error {
 	f.loadOnce.Do(func() {
 		// For fields, comment key is "ParentStruct.FieldName"
 		if f.parent != nil {
-			f.commentId = f.parent.Name() + "." + f.name
+			f.commentId = commentOwner(f.parent) + "." + f.name
 		}
 		f.loadComments(false)
 		if f.loader != nil {
@@ -108,6 +182,10 @@ type Method struct {
 	receiver          Type   // the type this method belongs to
 	promotedFrom      Type   // if this method is promoted from an embedded type
 	structure         string // full signature string
+	body              BodyFlags
+	linkage           Linkage
+	satisfies         []string // ids of the interface methods it satisfies (Config.ComputeImplements)
+	docOwner          string   // type declaring a promoted method, holding its comments
 }
 
 // NewMethod creates a new method
@@ -149,6 +227,22 @@ func (m *Method) SetPromotedFrom(t Type) {
 	m.promotedFrom = t
 }
 
+// Satisfies returns the ids of the interface methods the method satisfies ("pkg.Reader#Read"),
+// set by ScanningResult.ComputeImplements
+func (m *Method) Satisfies() []string {
+	return m.satisfies
+}
+
+func (m *Method) SetSatisfies(ids []string) {
+	m.satisfies = ids
+}
+
+// SetDocOwner sets the name of the type declaring a promoted method, its comments are
+// looked up there (in the package of the method) instead of on the receiver
+func (m *Method) SetDocOwner(name string) {
+	m.docOwner = name
+}
+
 func (m *Method) SetStructure(structure string) {
 	m.structure = structure
 }
@@ -172,6 +266,7 @@ func (m *Method) Serialize() any {
 			Name:       p.name,
 			Type:       serializeTypeOrID(p.paramType),
 			IsVariadic: p.isVariadic,
+			Position:   p.pos,
 		}
 		// Old full serialization logic (commented out)
 		// var paramTypeSerialized any
@@ -187,8 +282,9 @@ func (m *Method) Serialize() any {
 	results := make([]*SerializedResult, len(m.results))
 	for i, r := range m.results {
 		results[i] = &SerializedResult{
-			Name: r.name,
-			Type: serializeTypeOrID(r.resultType),
+			Name:     r.name,
+			Type:     serializeTypeOrID(r.resultType),
+			Position: r.pos,
 		}
 		// Old full serialization logic (commented out)
 		// var resultTypeSerialized any
@@ -220,6 +316,9 @@ func (m *Method) Serialize() any {
 		Receiver:          receiverID,
 		PromotedFrom:      promotedFromID,
 		Structure:         m.structure,
+		BodyFlags:         m.body,
+		Linkage:           m.linkage,
+		Satisfies:         m.satisfies,
 	}
 }
 
@@ -227,8 +326,10 @@ func (m *Method) Load() error {
 	var err error
 	m.loadOnce.Do(func() {
 		// For methods, comment key is "ReceiverType.MethodName"
-		if m.receiver != nil {
-			m.commentId = m.receiver.Name() + "." + m.name
+		if m.docOwner != "" {
+			m.commentId = m.docOwner + "." + m.name
+		} else if m.receiver != nil {
+			m.commentId = commentOwner(m.receiver) + "." + m.name
 		}
 		m.loadComments(false)
 		if m.loader != nil {
@@ -0,0 +1,19 @@
+package types
+
+import "fmt"
+
+// Position is the source location of a declaration.
+// File is relative to the module root, like Files.
+type Position struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	EndLine int    `json:"endLine,omitempty"` // last line of the declaration, 0 when unknown
+}
+
+func (p *Position) String() string {
+	if p == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
@@ -6,11 +6,27 @@ type SerializedType struct {
 	Name     string    `json:"name"`
 	Kind     TypeKind  `json:"kind"`
 	IsNamed  bool      `json:"named,omitempty"`
+	Defined  bool      `json:"defined,omitempty"` // declared by a type definition, not an alias
 	Exported bool      `json:"exported,omitempty"`
 	Distance int       `json:"distance,omitempty"`
 	Package  string    `json:"package,omitempty"`
 	Files    []string  `json:"files,omitempty"`
+	Position *Position `json:"position,omitempty"`
 	Comments []Comment `json:"comments,omitempty"`
+	// GenerateDirectives are the //go:generate commands attached to a type declaration
+	GenerateDirectives []string `json:"generateDirectives,omitempty"`
+	// ValueMethods and PointerMethods are the method sets of T and *T for named types.
+	// Promoted methods are listed by the id of the method they're promoted from.
+	ValueMethods   []string `json:"valueMethods,omitempty"`
+	PointerMethods []string `json:"pointerMethods,omitempty"`
+	// FromCache is set on the types restored from a cache, to tell them from scanned ones
+	FromCache bool `json:"fromCache,omitempty"`
+	// Opaque is set on the named types beyond Config.MaxDistance, whose members aren't resolved
+	Opaque bool `json:"opaque,omitempty"`
+	// Metadata are the values processors attached to the type
+	Metadata map[string]any `json:"metadata,omitempty"`
+	// UsedBy are the places the named type is referenced from (ScanModeUsages)
+	UsedBy []Usage `json:"usedBy,omitempty"`
 }
 
 // serializeBase creates a SerializedType from baseType
@@ -23,12 +39,22 @@ func (b *baseType) serializeBase() SerializedType {
 		ID:       b.id,
 		Name:     b.name,
 		Kind:     b.kind,
-		IsNamed:  b.obj != nil,
+		IsNamed:  b.IsNamed(),
+		Defined:  b.defined,
 		Exported: b.exported,
 		Distance: b.distance,
 		Package:  pkgPath,
 		Files:    b.files,
+		Position: b.pos,
 		Comments: b.comments,
+
+		GenerateDirectives: b.GenerateDirectives(),
+		ValueMethods:       b.valueMethods,
+		PointerMethods:     b.pointerMethods,
+		FromCache:          b.fromCache,
+		Opaque:             b.opaque,
+		Metadata:           b.metadata,
+		UsedBy:             b.usedBy,
 	}
 }
 
@@ -36,6 +62,9 @@ func (b *baseType) serializeBase() SerializedType {
 type SerializedBasic struct {
 	SerializedType
 	Underlying interface{} `json:"underlying,omitempty"` // For named basic types
+	// Set for any, comparable and error, so emitters can map them apart from the basic types
+	Predeclared     bool   `json:"predeclared,omitempty"`
+	PredeclaredName string `json:"predeclaredName,omitempty"`
 }
 
 // SerializedPointer represents a serialized pointer type
@@ -49,9 +78,11 @@ type SerializedPointer struct {
 // SerializedSlice represents a serialized slice/array type
 type SerializedSlice struct {
 	SerializedType
-	Element   any    `json:"element"`
-	Length    int64  `json:"length,omitempty"` // -1 for slices, >= 0 for arrays
-	Structure string `json:"structure,omitempty"`
+	Element any   `json:"element"`
+	Length  int64 `json:"length,omitempty"` // -1 for slices, >= 0 for arrays
+	// Dimensions are the lengths of nested arrays, outermost first (see Slice.Dimensions)
+	Dimensions []int64 `json:"dimensions,omitempty"`
+	Structure  string  `json:"structure,omitempty"`
 }
 
 // SerializedChan represents a serialized channel type
@@ -78,15 +109,17 @@ type SerializedAlias struct {
 
 // SerializedParameter represents a serialized parameter
 type SerializedParameter struct {
-	Name       string `json:"name"`
-	Type       any    `json:"type"` // Type ID+kind or full type object for complex types
-	IsVariadic bool   `json:"is_variadic,omitempty"`
+	Name       string    `json:"name"`
+	Type       any       `json:"type"` // Type ID+kind or full type object for complex types
+	IsVariadic bool      `json:"is_variadic,omitempty"`
+	Position   *Position `json:"position,omitempty"`
 }
 
 // SerializedResult represents a serialized result
 type SerializedResult struct {
-	Name string `json:"name,omitempty"`
-	Type any    `json:"type"` // Type ID+kind or full type object for complex types
+	Name     string    `json:"name,omitempty"`
+	Type     any       `json:"type"` // Type ID+kind or full type object for complex types
+	Position *Position `json:"position,omitempty"`
 }
 
 // SerializedFunction represents a serialized function type
@@ -97,6 +130,8 @@ type SerializedFunction struct {
 	IsVariadic bool                       `json:"isVariadic,omitempty"`
 	Structure  string                     `json:"structure,omitempty"`
 	TypeParams []*SerializedTypeParameter `json:"typeParams,omitempty"`
+	BodyFlags
+	Linkage
 }
 
 // SerializedMethod represents a serialized method
@@ -109,6 +144,10 @@ type SerializedMethod struct {
 	Receiver          string                 `json:"receiver"` // ID of receiver type
 	PromotedFrom      string                 `json:"promotedFrom,omitempty"`
 	Structure         string                 `json:"structure,omitempty"`
+	BodyFlags
+	Linkage
+	// Satisfies are the ids of the interface methods the method satisfies
+	Satisfies []string `json:"satisfies,omitempty"`
 }
 
 // SerializedField represents a serialized field
@@ -119,6 +158,8 @@ type SerializedField struct {
 	IsEmbedded   bool   `json:"isEmbedded,omitempty"`
 	PromotedFrom string `json:"promotedFrom,omitempty"`
 	Parent       string `json:"parent"` // ID of parent type
+	BitWidth     int    `json:"bitWidth,omitempty"`
+	Index        int    `json:"index"` // declaration position, see Field.Index
 }
 
 // SerializedInterface represents a serialized interface type
@@ -127,6 +168,20 @@ type SerializedInterface struct {
 	Embeds     []any                      `json:"embeds,omitempty"`
 	Methods    []*SerializedMethod        `json:"methods,omitempty"`
 	TypeParams []*SerializedTypeParameter `json:"typeParams,omitempty"`
+	// ConstraintOnly is set for interfaces only referenced as type parameter constraints
+	ConstraintOnly bool `json:"constraintOnly,omitempty"`
+	// Implementers are the ids of the types implementing the interface
+	Implementers []string `json:"implementers,omitempty"`
+	// TypeSet is set for general interfaces (constraints with type terms)
+	TypeSet *SerializedTypeSet `json:"typeSet,omitempty"`
+	// Predeclared is set for the predeclared any
+	Predeclared bool `json:"predeclared,omitempty"`
+}
+
+// SerializedTypeSet represents the type set of a general interface
+type SerializedTypeSet struct {
+	Terms   []SerializedUnionTerm `json:"terms"`
+	Methods []string              `json:"methods,omitempty"`
 }
 
 // SerializedStruct represents a serialized struct type
@@ -136,6 +191,9 @@ type SerializedStruct struct {
 	Fields     []*SerializedField         `json:"fields,omitempty"`
 	Methods    []*SerializedMethod        `json:"methods,omitempty"`
 	TypeParams []*SerializedTypeParameter `json:"typeParams,omitempty"`
+	Implements []string                   `json:"implements,omitempty"` // ids of the implemented interfaces
+	// Constructors are the ids of the NewXxx functions returning the struct
+	Constructors []string `json:"constructors,omitempty"`
 }
 
 // SerializedValue represents a serialized constant or variable
@@ -144,6 +202,9 @@ type SerializedValue struct {
 	Value     any    `json:"value,omitempty"`
 	ValueType any    `json:"valueType"`
 	Parent    string `json:"parent,omitempty"` // ID of parent type (for enum values)
+	Label     string `json:"label,omitempty"`  // display string from the type's String() method
+	Expr      string `json:"expr,omitempty"`   // source expression of a constant
+	Iota      *int   `json:"iota,omitempty"`   // value of iota in Expr, when it uses it
 }
 
 // SerializedTypeParameter represents a serialized type parameter
@@ -170,11 +231,22 @@ type SerializedInstantiatedGeneric struct {
 	SerializedType
 	Origin   string `json:"origin"`   // ID of the base generic type
 	TypeArgs []any  `json:"typeArgs"` // Type arguments with param names
+	// Members with the type arguments substituted, see InstantiatedGeneric
+	Embeds     []any               `json:"embeds,omitempty"`
+	Underlying any                 `json:"underlying,omitempty"`
+	Fields     []*SerializedField  `json:"fields,omitempty"`
+	Methods    []*SerializedMethod `json:"methods,omitempty"`
 }
 
 // SerializedEnum represents a serialized enum type
 type SerializedEnum struct {
 	SerializedType
-	Underlying any                `json:"underlying"`
-	Values     []*SerializedValue `json:"values,omitempty"`
+	Underlying any                 `json:"underlying"`
+	IotaExpr   string              `json:"iotaExpr,omitempty"` // Base expression of an iota const group
+	FromIota   bool                `json:"fromIota,omitempty"`
+	Values     []*SerializedValue  `json:"values,omitempty"`
+	Methods    []*SerializedMethod `json:"methods,omitempty"`
+	// Shape of the values of integer enums
+	HasDuplicateValues bool `json:"hasDuplicateValues,omitempty"`
+	IsContiguous       bool `json:"isContiguous,omitempty"`
 }
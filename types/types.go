@@ -28,9 +28,7 @@ var BasicTypes = []string{
 	"uint32",
 	"uint64",
 	"uintptr",
-	"interface{}",
 	"slice",
-	"any",
 	"comparable",
 	"error",
 }
@@ -124,6 +122,12 @@ type Type interface {
 	// Comments returns the documentation comments for this type
 	Comments() []Comment
 
+	// GenerateDirectives returns the //go:generate commands attached to this type's declaration
+	GenerateDirectives() []string
+
+	// Equal compares this type structurally with other (see Equal)
+	Equal(other Type) bool
+
 	// SetPackage sets the package for this type
 	SetPackage(pkg *Package)
 
@@ -133,12 +137,40 @@ type Type interface {
 	// SetFiles sets the files where this type is defined
 	SetFiles(files []string)
 
+	// Position returns the declaration position (nil unless Config.IncludePositions is set)
+	Position() *Position
+
+	// SetPosition sets the declaration position
+	SetPosition(pos *Position)
+
+	// UsedBy returns the places the named type is referenced from (only with ScanModeUsages)
+	UsedBy() []Usage
+
+	// SetUsedBy sets the places the named type is referenced from
+	SetUsedBy(usages []Usage)
+
+	// ValueMethods returns the ids of the methods callable on a value of a named type
+	ValueMethods() []string
+
+	// PointerMethods returns the ids of the methods callable on a pointer to a named type
+	PointerMethods() []string
+
+	// SetMethodSets sets the value and pointer method sets
+	SetMethodSets(value, pointer []string)
+
 	// Exported returns true if this type is exported
 	Exported() bool
 
 	// SetExported sets whether this type is exported
 	SetExported(exported bool)
 
+	// IsDefined returns true for types declared by a type definition (type A B), false for
+	// aliases (type A = B) and unnamed types
+	IsDefined() bool
+
+	// SetDefined sets whether this type is declared by a type definition
+	SetDefined(defined bool)
+
 	// Distance returns the distance from scanned packages
 	Distance() int
 
@@ -151,6 +183,28 @@ type Type interface {
 	// GoType returns the original go/types.Type (used for unnamed types)
 	GoType() types.Type
 
+	// Detach drops the go/types data of the type, keeping what serialization needs
+	Detach()
+
+	// FromCache returns true if the type was restored from a cache rather than scanned
+	FromCache() bool
+
+	// SetFromCache sets whether the type was restored from a cache
+	SetFromCache(fromCache bool)
+
+	// Opaque returns true for named types beyond Config.MaxDistance, whose members
+	// (fields, methods, embeds) are not resolved
+	Opaque() bool
+
+	// SetOpaque sets whether the members of the type are left unresolved
+	SetOpaque(opaque bool)
+
+	// Metadata returns the values processors attached to the type (see SetMetadata)
+	Metadata() map[string]any
+
+	// SetMetadata attaches a value to the type under key, serialized in "metadata"
+	SetMetadata(key string, value any)
+
 	// Serializable implements
 	Serializable
 
@@ -162,17 +216,18 @@ type Type interface {
 }
 
 // TypesCol is a specialized SyncMap for Serializable types with string keys.
-// It embeds SyncMap and adds a Serialize method.
+// It embeds SyncMap and adds a Serialize method. It's safe for concurrent use: results can
+// be queried from several goroutines while lazy loaders add the types they resolve.
 type TypesCol[T Serializable] struct {
 	*SyncMap[string, T]
 }
 
-// Serialize converts the collection to a serializable map format.
+// Serialize converts the collection to a serializable map format. It serializes a snapshot
+// of the collection, the types resolved meanwhile are left for the next call.
 func (c *TypesCol[T]) Serialize() any {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	result := make(map[string]any, len(c.values))
-	for id, t := range c.values {
+	snapshot := c.Snapshot()
+	result := make(map[string]any, len(snapshot))
+	for id, t := range snapshot {
 		result[id] = t.Serialize()
 	}
 	return result
@@ -200,9 +255,25 @@ type baseType struct {
 	loadOnce       sync.Once
 	commentId      string
 	commentsLoaded bool
-	files          []string // Files where this type is defined
-	exported       bool     // Whether this type is exported
-	distance       int      // Distance from scanned packages (0 = in scanned package, 1 = direct dependency, etc.)
+	files          []string  // Files where this type is defined
+	pos            *Position // Declaration position (only with Config.IncludePositions)
+	usedBy         []Usage   // Places referencing the type (only with ScanModeUsages)
+	valueMethods   []string  // Method set of T, ids
+	pointerMethods []string  // Method set of *T, ids
+	exported       bool      // Whether this type is exported
+	defined        bool      // Whether this type is declared by a type definition (not an alias)
+	distance       int       // Distance from scanned packages (0 = in scanned package, 1 = direct dependency, etc.)
+	detached       *detachedType
+	fromCache      bool           // restored from a cache rather than scanned
+	opaque         bool           // beyond Config.MaxDistance, members not resolved
+	metadata       map[string]any // values attached by processors
+}
+
+// detachedType keeps what the serialized model reads from the go/types data of a detached type
+type detachedType struct {
+	named      bool
+	structure  string
+	directives []string
 }
 
 // newBaseType creates a new base type
@@ -235,7 +306,7 @@ func (b *baseType) Kind() TypeKind {
 
 // IsNamed returns true if this type has an associated Object
 func (b *baseType) IsNamed() bool {
-	return b.obj != nil
+	return b.obj != nil || (b.detached != nil && b.detached.named)
 }
 
 // Package returns the package
@@ -258,6 +329,21 @@ func (b *baseType) Comments() []Comment {
 	return b.comments
 }
 
+// GenerateDirectives returns the //go:generate commands attached to the type declaration
+func (b *baseType) GenerateDirectives() []string {
+	if b.detached != nil {
+		return b.detached.directives
+	}
+	// Only type declarations carry directives
+	if b.pkg == nil || b.obj == nil {
+		return nil
+	}
+	if _, ok := b.obj.(*types.TypeName); !ok {
+		return nil
+	}
+	return b.pkg.TypeGenerateDirectives(b.obj.Name())
+}
+
 // SetPackage sets the package
 func (b *baseType) SetPackage(pkg *Package) {
 	b.pkg = pkg
@@ -276,6 +362,35 @@ func (b *baseType) SetFiles(files []string) {
 	b.files = files
 }
 
+func (b *baseType) Position() *Position {
+	return b.pos
+}
+
+func (b *baseType) SetPosition(pos *Position) {
+	b.pos = pos
+}
+
+func (b *baseType) UsedBy() []Usage {
+	return b.usedBy
+}
+
+func (b *baseType) SetUsedBy(usages []Usage) {
+	b.usedBy = usages
+}
+
+func (b *baseType) ValueMethods() []string {
+	return b.valueMethods
+}
+
+func (b *baseType) PointerMethods() []string {
+	return b.pointerMethods
+}
+
+func (b *baseType) SetMethodSets(value, pointer []string) {
+	b.valueMethods = value
+	b.pointerMethods = pointer
+}
+
 // Exported returns true if this type is exported
 func (b *baseType) Exported() bool {
 	return b.exported
@@ -286,6 +401,16 @@ func (b *baseType) SetExported(exported bool) {
 	b.exported = exported
 }
 
+// IsDefined returns true for types declared by a type definition
+func (b *baseType) IsDefined() bool {
+	return b.defined
+}
+
+// SetDefined sets whether this type is declared by a type definition
+func (b *baseType) SetDefined(defined bool) {
+	b.defined = defined
+}
+
 // Distance returns the distance from scanned packages
 func (b *baseType) Distance() int {
 	return b.distance
@@ -296,6 +421,35 @@ func (b *baseType) SetDistance(distance int) {
 	b.distance = distance
 }
 
+// FromCache reports whether the type was restored from a cache rather than scanned
+func (b *baseType) FromCache() bool {
+	return b.fromCache
+}
+
+// SetFromCache sets whether the type was restored from a cache
+func (b *baseType) SetFromCache(fromCache bool) {
+	b.fromCache = fromCache
+}
+
+func (b *baseType) Opaque() bool {
+	return b.opaque
+}
+
+func (b *baseType) SetOpaque(opaque bool) {
+	b.opaque = opaque
+}
+
+func (b *baseType) Metadata() map[string]any {
+	return b.metadata
+}
+
+func (b *baseType) SetMetadata(key string, value any) {
+	if b.metadata == nil {
+		b.metadata = make(map[string]any)
+	}
+	b.metadata[key] = value
+}
+
 // SetObject sets the go/types.Object
 func (b *baseType) SetObject(obj types.Object) {
 	b.obj = obj
@@ -339,6 +493,37 @@ func (b *baseType) Load() error {
 	return err
 }
 
+// Detach drops the go/types and go/doc data of the type and its loader, keeping what
+// serialization needs. Load the type first: afterwards Load does nothing and Object, GoType
+// and Doc return nil.
+func (b *baseType) Detach() {
+	if b.detached == nil {
+		b.detached = &detachedType{
+			named:      b.IsNamed(),
+			structure:  b.goStructure(""),
+			directives: b.GenerateDirectives(),
+		}
+	}
+	b.loadOnce.Do(func() {})
+	b.obj = nil
+	b.goType = nil
+	b.docType = nil
+	b.loader = nil
+}
+
+// goStructure returns the go/types string of the type, fallback when it has none
+func (b *baseType) goStructure(fallback string) string {
+	switch {
+	case b.obj != nil && b.obj.Type() != nil:
+		return b.obj.Type().Underlying().String()
+	case b.goType != nil:
+		return b.goType.String()
+	case b.detached != nil && b.detached.structure != "":
+		return b.detached.structure
+	}
+	return fallback
+}
+
 // SetLoader sets the loader function
 func (b *baseType) SetLoader(loader func(Type) error) {
 	b.loader = loader
@@ -0,0 +1,8 @@
+package types
+
+// Usage is a place a named type is referenced from, see ScanningResult.ComputeUsages
+type Usage struct {
+	From   string  `json:"from"`             // id of the referencing type, function or value
+	Member string  `json:"member,omitempty"` // field or method of From holding the reference
+	Role   RefRole `json:"role"`             // position of the reference
+}
@@ -0,0 +1,147 @@
+package types
+
+// RefRole describes the position a type is referenced from
+type RefRole string
+
+const (
+	RefRoleField      RefRole = "field"      // struct field type
+	RefRoleEmbed      RefRole = "embed"      // embedded struct/interface type
+	RefRoleParam      RefRole = "param"      // function or method parameter
+	RefRoleResult     RefRole = "result"     // function or method result
+	RefRoleElem       RefRole = "elem"       // pointer, slice, array, chan element or map value
+	RefRoleKey        RefRole = "key"        // map key
+	RefRoleUnderlying RefRole = "underlying" // underlying type of named basics, aliases and enums
+	RefRoleConstraint RefRole = "constraint" // type parameter constraint (including union terms)
+	RefRoleOrigin     RefRole = "origin"     // generic origin of an instantiation
+	RefRoleTypeArg    RefRole = "type_arg"   // type argument of an instantiation
+	RefRoleValue      RefRole = "value"      // type of a constant or variable
+)
+
+// WalkReferences calls fn for every type referenced by t, named or not.
+// Unnamed types (pointers, slices, maps, anonymous structs, signatures...) and instantiated
+// generics are descended into; named types are reported but not descended.
+// The role is the position where the reference enters t, so for a `[]*User` field both the
+// slice, the pointer and User are reported with RefRoleField.
+// Types are not loaded, callers should load them first to see fields and methods.
+func WalkReferences(t Type, fn func(ref Type, role RefRole)) {
+	if t == nil {
+		return
+	}
+	w := &refWalker{fn: fn, seen: map[Type]bool{t: true}}
+	w.members(t, "")
+}
+
+type refWalker struct {
+	fn   func(ref Type, role RefRole)
+	seen map[Type]bool // unnamed types already walked (cycle protection)
+}
+
+// visit reports ref and descends into it when it is not a named type
+func (w *refWalker) visit(ref Type, role RefRole) {
+	if ref == nil {
+		return
+	}
+	w.fn(ref, role)
+
+	_, instantiated := ref.(*InstantiatedGeneric)
+	if (ref.IsNamed() && !instantiated) || w.seen[ref] {
+		return
+	}
+	w.seen[ref] = true
+	w.members(ref, role)
+}
+
+// members visits the types referenced by t. When role is set (t is nested in a reference),
+// it is used for every member instead of the member's own role.
+func (w *refWalker) members(t Type, role RefRole) {
+	as := func(own RefRole) RefRole {
+		if role != "" {
+			return role
+		}
+		return own
+	}
+
+	switch v := t.(type) {
+	case *Basic:
+		if v.Underlying() != t {
+			w.visit(v.Underlying(), as(RefRoleUnderlying))
+		}
+	case *Enum:
+		w.visit(v.Underlying(), as(RefRoleUnderlying))
+	case *Alias:
+		w.visit(v.UnderlyingType(), as(RefRoleUnderlying))
+	case *Pointer:
+		w.visit(v.Elem(), as(RefRoleElem))
+	case *Slice:
+		w.visit(v.Elem(), as(RefRoleElem))
+	case *Chan:
+		w.visit(v.Elem(), as(RefRoleElem))
+	case *Map:
+		w.visit(v.Key(), as(RefRoleKey))
+		w.visit(v.Value(), as(RefRoleElem))
+	case *Struct:
+		w.typeParams(v.TypeParams(), as)
+		for _, embed := range v.Embeds() {
+			w.visit(embed, as(RefRoleEmbed))
+		}
+		for _, f := range v.Fields() {
+			// Promoted fields belong to the embedded type
+			if f.PromotedFrom() == nil && !f.IsEmbedded() {
+				w.visit(f.Type(), as(RefRoleField))
+			}
+		}
+	case *Interface:
+		w.typeParams(v.TypeParams(), as)
+		for _, embed := range v.Embeds() {
+			w.visit(embed, as(RefRoleEmbed))
+		}
+	case *Function:
+		w.typeParams(v.TypeParams(), as)
+		w.signature(v.Parameters(), v.Results(), as)
+	case *Method:
+		w.signature(v.Parameters(), v.Results(), as)
+		return
+	case *Field:
+		w.visit(v.Type(), as(RefRoleField))
+		return
+	case *Value:
+		w.visit(v.ValueType(), as(RefRoleValue))
+		return
+	case *TypeParameter:
+		w.visit(v.Constraint(), as(RefRoleConstraint))
+		return
+	case *Union:
+		for _, term := range v.Terms() {
+			w.visit(term.Type(), as(RefRoleConstraint))
+		}
+	case *InstantiatedGeneric:
+		w.visit(v.Origin(), as(RefRoleOrigin))
+		for _, arg := range v.TypeArgs() {
+			w.visit(arg.Type, as(RefRoleTypeArg))
+		}
+		// Methods belong to the origin
+		return
+	}
+
+	for _, m := range t.Methods() {
+		// Promoted methods belong to the embedded type
+		if m.PromotedFrom() == nil {
+			w.signature(m.Parameters(), m.Results(), as)
+		}
+	}
+}
+
+func (w *refWalker) typeParams(params []*TypeParameter, as func(RefRole) RefRole) {
+	for _, tp := range params {
+		w.visit(tp.Constraint(), as(RefRoleConstraint))
+	}
+}
+
+func (w *refWalker) signature(params []*Parameter, results []*Result, as func(RefRole) RefRole) {
+	for _, p := range params {
+		w.visit(p.Type(), as(RefRoleParam))
+	}
+	for _, r := range results {
+		w.visit(r.Type(), as(RefRoleResult))
+	}
+}